@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	cmdutil "github.com/ohsu-comp-bio/funnel/cmd/util"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns the admin command
+func NewCommand() *cobra.Command {
+	cmd, _ := newCommandHooks()
+	return cmd
+}
+
+type hooks struct {
+	Restore func(ctx context.Context, conf config.Config, urls []string, w io.Writer) error
+}
+
+func newCommandHooks() (*cobra.Command, *hooks) {
+	hooks := &hooks{
+		Restore: Restore,
+	}
+
+	var (
+		configFile string
+		conf       config.Config
+		flagConf   config.Config
+	)
+
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Funnel server administration subcommands.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+
+			conf, err = cmdutil.MergeConfigFileWithFlags(configFile, flagConf)
+			if err != nil {
+				return fmt.Errorf("error processing config: %v", err)
+			}
+
+			return nil
+		},
+	}
+
+	serverFlags := cmdutil.ServerFlags(&flagConf, &configFile)
+	cmd.SetGlobalNormalizationFunc(cmdutil.NormalizeFlags)
+	f := cmd.PersistentFlags()
+	f.AddFlagSet(serverFlags)
+
+	restore := &cobra.Command{
+		Use:   "restore <url|path> ...",
+		Short: "Restore tasks from archives written by the server's retention policies.",
+		Long: `Restore reads task archives, written to Retention.ArchiveURL when tasks expire,
+and writes the tasks back to the server. Tasks which already exist are skipped.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return hooks.Restore(context.Background(), conf, args, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.AddCommand(restore)
+
+	return cmd, hooks
+}
@@ -0,0 +1,102 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/server"
+	"github.com/ohsu-comp-bio/funnel/storage"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"github.com/ohsu-comp-bio/funnel/util/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Restore runs the "admin restore" CLI command, which writes the tasks in the
+// given archives back to the server. Archives are storage URLs, or local paths.
+func Restore(ctx context.Context, conf config.Config, urls []string, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, err := rpc.Dial(ctx, conf.Server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	tasks := tes.NewTaskServiceClient(conn)
+
+	writer, err := events.NewRPCWriter(ctx, conf.Server)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	exists := func(ctx context.Context, id string) (bool, error) {
+		_, err := tasks.GetTask(ctx, &tes.GetTaskRequest{Id: id, View: tes.TaskView_MINIMAL})
+		if grpc.Code(err) == codes.NotFound {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	for _, url := range urls {
+		r, err := openArchive(ctx, conf, url)
+		if err != nil {
+			return err
+		}
+		restored, skipped, err := server.RestoreTasks(ctx, r, writer, exists)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("restoring %s: %v", url, err)
+		}
+		fmt.Fprintf(w, "%s: restored %d tasks, skipped %d existing tasks\n", url, restored, skipped)
+	}
+	return nil
+}
+
+// openArchive opens a local archive, or downloads an archive from storage
+// to a temporary directory which is removed when the archive is closed.
+func openArchive(ctx context.Context, conf config.Config, url string) (io.ReadCloser, error) {
+	if !strings.Contains(url, "://") {
+		return os.Open(url)
+	}
+
+	store, err := storage.NewMux(conf)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "funnel-archive-")
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, "archive.jsonl")
+	if _, err := store.Get(ctx, url, path); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("downloading %s: %v", url, err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &tempArchive{File: f, dir: dir}, nil
+}
+
+// tempArchive is a downloaded archive, removed when it's closed.
+type tempArchive struct {
+	*os.File
+	dir string
+}
+
+func (t *tempArchive) Close() error {
+	t.File.Close()
+	return os.RemoveAll(t.dir)
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	cmdutil "github.com/ohsu-comp-bio/funnel/cmd/util"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns the db command
+func NewCommand() *cobra.Command {
+	cmd, _ := newCommandHooks()
+	return cmd
+}
+
+type hooks struct {
+	Migrate func(ctx context.Context, from, to config.Config, checkpoint string, log *logger.Logger, w io.Writer) error
+}
+
+func newCommandHooks() (*cobra.Command, *hooks) {
+	hooks := &hooks{
+		Migrate: Migrate,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Funnel database subcommands.",
+	}
+	cmd.SetGlobalNormalizationFunc(cmdutil.NormalizeFlags)
+
+	var (
+		fromFile   string
+		toFile     string
+		checkpoint = "funnel-migrate.checkpoint"
+	)
+
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy all tasks and nodes from one database to another.",
+		Long: `Migrate copies all tasks and nodes from the database configured by --from
+to the database configured by --to. The server should be stopped during the migration.
+
+The progress is recorded in the checkpoint file, so that an interrupted migration
+resumes where it stopped when the command is run again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := cmdutil.MergeConfigFileWithFlags(fromFile, config.Config{})
+			if err != nil {
+				return fmt.Errorf("error processing --from config: %v", err)
+			}
+			to, err := cmdutil.MergeConfigFileWithFlags(toFile, config.Config{})
+			if err != nil {
+				return fmt.Errorf("error processing --to config: %v", err)
+			}
+			log := logger.NewLogger("migrate", from.Logger)
+			return hooks.Migrate(context.Background(), from, to, checkpoint, log, cmd.OutOrStdout())
+		},
+	}
+
+	f := migrate.Flags()
+	f.StringVar(&fromFile, "from", fromFile, "Config file of the source database")
+	f.StringVar(&toFile, "to", toFile, "Config file of the target database")
+	f.StringVar(&checkpoint, "checkpoint", checkpoint, "Path of the migration checkpoint file")
+	migrate.MarkFlagRequired("from")
+	migrate.MarkFlagRequired("to")
+
+	cmd.AddCommand(migrate)
+
+	return cmd, hooks
+}
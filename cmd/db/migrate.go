@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	servercmd "github.com/ohsu-comp-bio/funnel/cmd/server"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/server"
+)
+
+// Migrate runs the "db migrate" CLI command, which copies all tasks and nodes
+// from the database configured by "from" to the database configured by "to".
+func Migrate(ctx context.Context, from, to config.Config, checkpoint string, log *logger.Logger, w io.Writer) error {
+	if strings.EqualFold(from.Database, to.Database) && databaseConfig(from) == databaseConfig(to) {
+		return fmt.Errorf("the source and target databases are the same")
+	}
+
+	src, err := servercmd.NewDatabase(from)
+	if err != nil {
+		return fmt.Errorf("opening the source database: %v", err)
+	}
+	dst, err := servercmd.NewDatabase(to)
+	if err != nil {
+		return fmt.Errorf("opening the target database: %v", err)
+	}
+
+	m := &server.Migrator{
+		From:       src,
+		To:         dst,
+		Checkpoint: checkpoint,
+		Log:        log,
+	}
+	tasks, nodes, err := m.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "migrated %d tasks and %d nodes from %s to %s\n", tasks, nodes, from.Database, to.Database)
+	return nil
+}
+
+// databaseConfig returns the config of the selected database as a string.
+func databaseConfig(conf config.Config) string {
+	switch strings.ToLower(conf.Database) {
+	case "boltdb":
+		return conf.BoltDB.Path
+	case "badger":
+		return conf.Badger.Path
+	case "sqlite":
+		return conf.SQLite.Path
+	case "postgres":
+		return fmt.Sprintf("%+v", conf.Postgres)
+	case "mongodb":
+		return fmt.Sprintf("%+v", conf.MongoDB)
+	case "elastic":
+		return fmt.Sprintf("%+v", conf.Elastic)
+	case "dynamodb":
+		return fmt.Sprintf("%+v", conf.DynamoDB)
+	case "datastore":
+		return fmt.Sprintf("%+v", conf.Datastore)
+	}
+	return ""
+}
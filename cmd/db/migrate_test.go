@@ -0,0 +1,205 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	servercmd "github.com/ohsu-comp-bio/funnel/cmd/server"
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/server"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+func migrateConfigs(t *testing.T) (from, to config.Config, dir string) {
+	dir, err := ioutil.TempDir("", "funnel-test-migrate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from = config.DefaultConfig()
+	from.Database = "boltdb"
+	from.BoltDB.Path = filepath.Join(dir, "funnel.db")
+	to = config.DefaultConfig()
+	to.Database = "badger"
+	to.Badger.Path = filepath.Join(dir, "funnel.badger.db")
+	return from, to, dir
+}
+
+// writeTasks writes "n" tasks to the database, every other one completed.
+func writeTasks(ctx context.Context, t *testing.T, db events.Writer, n int) {
+	for i := 0; i < n; i++ {
+		task := &tes.Task{
+			Executors: []*tes.Executor{{Image: "alpine", Command: []string{"echo", "hi"}}},
+			Tags:      map[string]string{"i": fmt.Sprint(i)},
+		}
+		if err := tes.InitTask(task); err != nil {
+			t.Fatal(err)
+		}
+		evs := []*events.Event{events.NewTaskCreated(task)}
+		if i%2 == 0 {
+			now := time.Now()
+			evs = append(evs,
+				events.NewState(task.Id, tes.Running),
+				events.NewStartTime(task.Id, 0, now),
+				events.NewStdout(task.Id, 0, 0, "hi\n"),
+				events.NewExitCode(task.Id, 0, 0, 0),
+				events.NewEndTime(task.Id, 0, now),
+				events.NewState(task.Id, tes.Complete),
+			)
+		}
+		for _, ev := range evs {
+			if err := db.WriteEvent(ctx, ev); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// failingDatabase fails to write events after "n" tasks are created.
+type failingDatabase struct {
+	server.MigrationDatabase
+	n int
+}
+
+func (f *failingDatabase) WriteEvent(ctx context.Context, ev *events.Event) error {
+	if ev.Type == events.Type_TASK_CREATED {
+		if f.n == 0 {
+			return fmt.Errorf("interrupted")
+		}
+		f.n--
+	}
+	return f.MigrationDatabase.WriteEvent(ctx, ev)
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	from, to, dir := migrateConfigs(t)
+	defer os.RemoveAll(dir)
+	checkpoint := filepath.Join(dir, "migrate.checkpoint")
+	log := logger.NewLogger("test-migrate", logger.DebugConfig())
+
+	src, err := servercmd.NewDatabase(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTasks(ctx, t, src, 150)
+	node := &scheduler.Node{
+		Id:        "node-1",
+		State:     scheduler.NodeState_ALIVE,
+		Resources: &scheduler.Resources{Cpus: 4},
+		Metadata:  map[string]string{"pool": "a"},
+	}
+	if _, err := src.PutNode(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	dst, err := servercmd.NewDatabase(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupt the migration on the second page of tasks.
+	m := &server.Migrator{
+		From:       src,
+		To:         &failingDatabase{MigrationDatabase: dst, n: 120},
+		Checkpoint: checkpoint,
+		Log:        log,
+	}
+	if _, _, err := m.Migrate(ctx); err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatal("expected an interrupted migration, got", err)
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Fatal("expected a checkpoint", err)
+	}
+
+	// Resume the migration.
+	m.To = dst
+	tasks, nodes, err := m.Migrate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks != 150 || nodes != 1 {
+		t.Errorf("expected 150 tasks and 1 node, got %d and %d", tasks, nodes)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Error("expected the checkpoint to be removed", err)
+	}
+
+	resp, err := src.ListTasks(ctx, &tes.ListTasksRequest{View: tes.TaskView_FULL, PageSize: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range resp.Tasks {
+		task, err := dst.GetTask(ctx, &tes.GetTaskRequest{Id: expected.Id, View: tes.TaskView_FULL})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.State != expected.State || task.Tags["i"] != expected.Tags["i"] ||
+			task.CreationTime != expected.CreationTime {
+			t.Fatalf("expected %v, got %v", expected, task)
+		}
+		if task.State == tes.Complete && (len(task.Logs) != 1 || task.Logs[0].Logs[0].Stdout != "hi\n") {
+			t.Errorf("expected the task logs to be migrated, got %v", task.Logs)
+		}
+	}
+
+	// The event logs are migrated as they were written.
+	for _, task := range resp.Tasks[:10] {
+		expected, err := src.ListTaskEvents(ctx, task.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evs, err := dst.ListTaskEvents(ctx, task.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(evs) != len(expected) {
+			t.Fatalf("expected %d events, got %d", len(expected), len(evs))
+		}
+		for i := range evs {
+			if !proto.Equal(evs[i], expected[i]) {
+				t.Errorf("expected %v, got %v", expected[i], evs[i])
+			}
+		}
+	}
+
+	// The target's task queue is consistent with the task states.
+	queued, _ := dst.ReadQueue(1000, "")
+	if len(queued) != 75 {
+		t.Errorf("expected 75 queued tasks, got %d", len(queued))
+	}
+	for _, task := range queued {
+		if task.State != tes.Queued {
+			t.Errorf("unexpected queued task %v", task)
+		}
+	}
+
+	n, err := dst.GetNode(ctx, &scheduler.GetNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Resources.Cpus != 4 || n.Metadata["pool"] != "a" {
+		t.Errorf("unexpected node %v", n)
+	}
+}
+
+func TestMigrateSameDatabase(t *testing.T) {
+	from, _, dir := migrateConfigs(t)
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	log := logger.NewLogger("test-migrate", logger.DebugConfig())
+	err := Migrate(context.Background(), from, from, "", log, &out)
+	if err == nil {
+		t.Error("expected an error migrating a database to itself")
+	}
+}
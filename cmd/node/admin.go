@@ -0,0 +1,101 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"github.com/ohsu-comp-bio/funnel/util/rpc"
+)
+
+// Drain runs the "node drain" CLI command, which asks the server to stop
+// assigning tasks to the node. The node shuts down once its tasks are done.
+func Drain(ctx context.Context, conf config.Server, id string, w io.Writer) error {
+	return adminCall(ctx, conf, w, func(cli scheduler.NodeAdminServiceClient) (*scheduler.Node, error) {
+		return cli.DrainNode(ctx, &scheduler.DrainNodeRequest{Id: id})
+	})
+}
+
+// Cordon runs the "node cordon" CLI command, which asks the server to stop
+// assigning tasks to the node.
+func Cordon(ctx context.Context, conf config.Server, id string, w io.Writer) error {
+	return adminCall(ctx, conf, w, func(cli scheduler.NodeAdminServiceClient) (*scheduler.Node, error) {
+		return cli.CordonNode(ctx, &scheduler.CordonNodeRequest{Id: id})
+	})
+}
+
+// Uncordon runs the "node uncordon" CLI command, which allows the server
+// to assign tasks to a cordoned node again.
+func Uncordon(ctx context.Context, conf config.Server, id string, w io.Writer) error {
+	return adminCall(ctx, conf, w, func(cli scheduler.NodeAdminServiceClient) (*scheduler.Node, error) {
+		return cli.UncordonNode(ctx, &scheduler.UncordonNodeRequest{Id: id})
+	})
+}
+
+// Label runs the "node label" CLI command, which adds, updates or removes
+// node metadata labels. Labels are given as "key=value" to add or update
+// a label, or "key-" to remove it.
+func Label(ctx context.Context, conf config.Server, id string, labels []string, w io.Writer) error {
+	req, err := parseLabels(id, labels)
+	if err != nil {
+		return err
+	}
+	return adminCall(ctx, conf, w, func(cli scheduler.NodeAdminServiceClient) (*scheduler.Node, error) {
+		return cli.LabelNode(ctx, req)
+	})
+}
+
+// Evict runs the "node evict" CLI command, which removes tasks from the node.
+// If no task IDs are given, all the tasks assigned to the node are evicted.
+func Evict(ctx context.Context, conf config.Server, id string, taskIDs []string, w io.Writer) error {
+	return adminCall(ctx, conf, w, func(cli scheduler.NodeAdminServiceClient) (*scheduler.Node, error) {
+		return cli.EvictNode(ctx, &scheduler.EvictNodeRequest{Id: id, TaskIds: taskIDs})
+	})
+}
+
+func parseLabels(id string, labels []string) (*scheduler.LabelNodeRequest, error) {
+	req := &scheduler.LabelNodeRequest{Id: id, Labels: map[string]string{}}
+	for _, l := range labels {
+		switch {
+		case strings.Contains(l, "="):
+			kv := strings.SplitN(l, "=", 2)
+			if kv[0] == "" {
+				return nil, fmt.Errorf("invalid label %q: empty key", l)
+			}
+			req.Labels[kv[0]] = kv[1]
+		case strings.HasSuffix(l, "-") && len(l) > 1:
+			req.Remove = append(req.Remove, strings.TrimSuffix(l, "-"))
+		default:
+			return nil, fmt.Errorf("invalid label %q: expected key=value or key-", l)
+		}
+	}
+	return req, nil
+}
+
+// adminCall connects to the server, makes a node admin call and writes
+// the updated node to "w" as JSON.
+func adminCall(ctx context.Context, conf config.Server, w io.Writer, call func(scheduler.NodeAdminServiceClient) (*scheduler.Node, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, err := rpc.Dial(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	node, err := call(scheduler.NewNodeAdminServiceClient(conn))
+	if err != nil {
+		return err
+	}
+
+	out, err := tes.Marshaler.MarshalToString(node)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, out)
+	return nil
+}
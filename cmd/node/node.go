@@ -3,6 +3,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"io"
 
 	cmdutil "github.com/ohsu-comp-bio/funnel/cmd/util"
 	"github.com/ohsu-comp-bio/funnel/config"
@@ -17,12 +18,22 @@ func NewCommand() *cobra.Command {
 }
 
 type hooks struct {
-	Run func(ctx context.Context, conf config.Config, log *logger.Logger) error
+	Run      func(ctx context.Context, conf config.Config, log *logger.Logger) error
+	Drain    func(ctx context.Context, conf config.Server, id string, w io.Writer) error
+	Cordon   func(ctx context.Context, conf config.Server, id string, w io.Writer) error
+	Uncordon func(ctx context.Context, conf config.Server, id string, w io.Writer) error
+	Label    func(ctx context.Context, conf config.Server, id string, labels []string, w io.Writer) error
+	Evict    func(ctx context.Context, conf config.Server, id string, taskIDs []string, w io.Writer) error
 }
 
 func newCommandHooks() (*cobra.Command, *hooks) {
 	hooks := &hooks{
-		Run: Run,
+		Run:      Run,
+		Drain:    Drain,
+		Cordon:   Cordon,
+		Uncordon: Uncordon,
+		Label:    Label,
+		Evict:    Evict,
 	}
 
 	var (
@@ -65,7 +76,53 @@ func newCommandHooks() (*cobra.Command, *hooks) {
 		},
 	}
 
-	cmd.AddCommand(run)
+	drain := &cobra.Command{
+		Use:   "drain <nodeID>",
+		Short: "Stop assigning tasks to a node, and shut it down once its tasks are done.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return hooks.Drain(context.Background(), conf.Server, args[0], cmd.OutOrStdout())
+		},
+	}
+
+	cordon := &cobra.Command{
+		Use:   "cordon <nodeID>",
+		Short: "Stop assigning tasks to a node.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return hooks.Cordon(context.Background(), conf.Server, args[0], cmd.OutOrStdout())
+		},
+	}
+
+	uncordon := &cobra.Command{
+		Use:   "uncordon <nodeID>",
+		Short: "Resume assigning tasks to a cordoned node.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return hooks.Uncordon(context.Background(), conf.Server, args[0], cmd.OutOrStdout())
+		},
+	}
+
+	label := &cobra.Command{
+		Use:   "label <nodeID> key=value|key- ...",
+		Short: "Add, update or remove node metadata labels.",
+		Long:  `Labels are given as "key=value" to add or update a label, or "key-" to remove it.`,
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return hooks.Label(context.Background(), conf.Server, args[0], args[1:], cmd.OutOrStdout())
+		},
+	}
+
+	evict := &cobra.Command{
+		Use:   "evict <nodeID> [taskID ...]",
+		Short: "Remove tasks from a node. If no task IDs are given, all tasks are evicted.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return hooks.Evict(context.Background(), conf.Server, args[0], args[1:], cmd.OutOrStdout())
+		},
+	}
+
+	cmd.AddCommand(run, drain, cordon, uncordon, label, evict)
 
 	return cmd, hooks
 }
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"io"
 	"os"
 	"path"
 	"testing"
@@ -43,3 +44,41 @@ func TestPersistentPreRun(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestLabel(t *testing.T) {
+	c, h := newCommandHooks()
+	h.Label = func(ctx context.Context, conf config.Server, id string, labels []string, w io.Writer) error {
+		if id != "node-1" {
+			t.Errorf("unexpected node ID: %s", id)
+		}
+		if len(labels) != 2 || labels[0] != "pool=gpu" || labels[1] != "zone-" {
+			t.Errorf("unexpected labels: %#v", labels)
+		}
+		return nil
+	}
+
+	c.SetArgs([]string{"label", "node-1", "pool=gpu", "zone-"})
+	err := c.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	req, err := parseLabels("node-1", []string{"pool=gpu", "empty=", "zone-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Labels["pool"] != "gpu" || req.Labels["empty"] != "" || len(req.Labels) != 2 {
+		t.Errorf("unexpected labels: %#v", req.Labels)
+	}
+	if len(req.Remove) != 1 || req.Remove[0] != "zone" {
+		t.Errorf("unexpected removed labels: %#v", req.Remove)
+	}
+
+	for _, bad := range []string{"pool", "=gpu", "-"} {
+		if _, err := parseLabels("node-1", []string{bad}); err == nil {
+			t.Errorf("expected error for label %q", bad)
+		}
+	}
+}
@@ -22,6 +22,9 @@ func Run(ctx context.Context, conf config.Config, log *logger.Logger) error {
 	if err != nil {
 		return err
 	}
+	// Tasks which are still running when the node shuts down are re-queued
+	// by the server, so the worker mustn't mark them as failed.
+	w.SkipStateOnStop = true
 
 	n, err := scheduler.NewNodeProcess(ctx, conf, w.Run, log)
 	if err != nil {
@@ -2,7 +2,9 @@
 package cmd
 
 import (
+	"github.com/ohsu-comp-bio/funnel/cmd/admin"
 	"github.com/ohsu-comp-bio/funnel/cmd/aws"
+	"github.com/ohsu-comp-bio/funnel/cmd/db"
 	"github.com/ohsu-comp-bio/funnel/cmd/examples"
 	"github.com/ohsu-comp-bio/funnel/cmd/gce"
 	"github.com/ohsu-comp-bio/funnel/cmd/node"
@@ -24,10 +26,12 @@ var RootCmd = &cobra.Command{
 }
 
 func init() {
+	RootCmd.AddCommand(admin.NewCommand())
 	RootCmd.AddCommand(aws.Cmd)
 	RootCmd.AddCommand(examples.Cmd)
 	RootCmd.AddCommand(gce.Cmd)
 	RootCmd.AddCommand(completionCmd)
+	RootCmd.AddCommand(db.NewCommand())
 	RootCmd.AddCommand(genMarkdownCmd)
 	RootCmd.AddCommand(node.NewCommand())
 	RootCmd.AddCommand(run.Cmd)
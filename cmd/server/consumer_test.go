@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/Shopify/sarama"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// consumerTestConfig returns a config for a server with a temporary
+// database, free ports, and no compute backend.
+func consumerTestConfig(t *testing.T) config.Config {
+	dir, err := ioutil.TempDir("", "funnel-test-consumer-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := config.DefaultConfig()
+	conf.Database = "boltdb"
+	conf.BoltDB.Path = filepath.Join(dir, "funnel.db")
+	conf.Compute = "noop"
+	conf.EventWriters = []string{"log"}
+	conf.Server.HTTPPort = freePort(t)
+	conf.Server.RPCPort = freePort(t)
+	return conf
+}
+
+func freePort(t *testing.T) string {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func createTestTask(ctx context.Context, t *testing.T, srv *Server) string {
+	resp, err := srv.Server.Tasks.CreateTask(ctx, &tes.Task{
+		Executors: []*tes.Executor{{Image: "alpine", Command: []string{"echo", "hello"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Id
+}
+
+// workerEvents returns the events a worker writes for a successful task.
+func workerEvents(id string) []*events.Event {
+	now := time.Now()
+	return []*events.Event{
+		events.NewState(id, tes.Initializing),
+		events.NewState(id, tes.Running),
+		events.NewStartTime(id, 0, now),
+		events.NewExecutorStartTime(id, 0, 0, now),
+		events.NewStdout(id, 0, 0, "hello\n"),
+		events.NewExitCode(id, 0, 0, 0),
+		events.NewExecutorEndTime(id, 0, 0, now),
+		events.NewEndTime(id, 0, now),
+		events.NewState(id, tes.Complete),
+	}
+}
+
+func marshalEvent(t *testing.T, ev *events.Event) string {
+	s, err := events.Marshal(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// waitFor polls "f" until it returns true, or fails the test after a timeout.
+func waitFor(t *testing.T, msg string, f func() bool) {
+	deadline := time.Now().Add(time.Second * 20)
+	for !f() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for", msg)
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+}
+
+func checkCompleted(ctx context.Context, t *testing.T, srv *Server, id string) {
+	task, err := srv.Server.Tasks.GetTask(ctx, &tes.GetTaskRequest{Id: id, View: tes.TaskView_FULL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.State != tes.Complete {
+		t.Fatal("unexpected state", task.State)
+	}
+	if task.Logs[0].Logs[0].Stdout != "hello\n" {
+		t.Fatal("unexpected stdout", task.Logs[0].Logs[0].Stdout)
+	}
+}
+
+func TestKafkaEventReader(t *testing.T) {
+	const (
+		topic      = "funnel"
+		group      = "funnel-server"
+		deadLetter = "funnel-dead-letter"
+		member     = "funnel-server-1"
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	metadata := sarama.NewMockMetadataResponse(t).
+		SetBroker(broker.Addr(), broker.BrokerID()).
+		SetLeader(topic, 0, broker.BrokerID()).
+		SetLeader(topic, 1, broker.BrokerID()).
+		SetLeader(deadLetter, 0, broker.BrokerID())
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": metadata,
+	})
+
+	conf := consumerTestConfig(t)
+	// The server shares the workers' config, but doesn't publish
+	// to the topic it consumes.
+	conf.EventWriters = []string{"kafka", "log"}
+	conf.EventReaders = []string{"kafka"}
+	conf.Kafka = config.Kafka{
+		Servers:         []string{broker.Addr()},
+		Topic:           topic,
+		Group:           group,
+		DeadLetterTopic: deadLetter,
+	}
+
+	srv, err := NewServer(ctx, conf, logger.NewLogger("test-kafka-consumer", logger.DebugConfig()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := createTestTask(ctx, t, srv)
+
+	// Partition 0 holds the worker's events. The group already committed
+	// offset 1 of partition 1, so the system error at offset 0 must not
+	// be applied. The rest of partition 1 must be dead-lettered.
+	fetch := sarama.NewMockFetchResponse(t, 10)
+	evs := workerEvents(id)
+	for i, ev := range evs {
+		fetch.SetMessage(topic, 0, int64(i), sarama.StringEncoder(marshalEvent(t, ev)))
+	}
+	fetch.SetMessage(topic, 1, 0, sarama.StringEncoder(marshalEvent(t, events.NewState(id, tes.SystemError))))
+	fetch.SetMessage(topic, 1, 1, sarama.StringEncoder("not json"))
+	fetch.SetMessage(topic, 1, 2, sarama.StringEncoder(marshalEvent(t, events.NewState("unknown-task", tes.Running))))
+	fetch.SetHighWaterMark(topic, 0, int64(len(evs)))
+	fetch.SetHighWaterMark(topic, 1, 3)
+
+	offsets := sarama.NewMockOffsetResponse(t).
+		SetOffset(topic, 0, sarama.OffsetOldest, 0).
+		SetOffset(topic, 0, sarama.OffsetNewest, int64(len(evs))).
+		SetOffset(topic, 1, sarama.OffsetOldest, 0).
+		SetOffset(topic, 1, sarama.OffsetNewest, 3)
+
+	committed := sarama.NewMockOffsetFetchResponse(t).
+		SetOffset(group, topic, 0, -1, "", sarama.ErrNoError).
+		SetOffset(group, topic, 1, 1, "", sarama.ErrNoError)
+
+	// The sync response carries the assignment the leader (this server) sent.
+	assignment := &sarama.SyncGroupRequest{}
+	assignment.AddGroupAssignmentMember(member, &sarama.ConsumerGroupMemberAssignment{
+		Topics: map[string][]int32{topic: {0, 1}},
+	})
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": metadata,
+		"ConsumerMetadataRequest": sarama.NewMockConsumerMetadataResponse(t).
+			SetCoordinator(group, broker),
+		"JoinGroupRequest": sarama.NewMockWrapper(&sarama.JoinGroupResponse{
+			GenerationId:  1,
+			GroupProtocol: "range",
+			LeaderId:      member,
+			MemberId:      member,
+			Members:       map[string][]byte{member: nil},
+		}),
+		"SyncGroupRequest": sarama.NewMockWrapper(&sarama.SyncGroupResponse{
+			MemberAssignment: assignment.GroupAssignments[member],
+		}),
+		"HeartbeatRequest":    sarama.NewMockWrapper(&sarama.HeartbeatResponse{}),
+		"LeaveGroupRequest":   sarama.NewMockWrapper(&sarama.LeaveGroupResponse{}),
+		"OffsetFetchRequest":  committed,
+		"OffsetRequest":       offsets,
+		"FetchRequest":        fetch,
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+		"ProduceRequest":      sarama.NewMockProduceResponse(t),
+	})
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Run(ctx)
+	}()
+
+	expected := map[int32]int64{0: int64(len(evs)), 1: 3}
+	waitFor(t, "offset commit", func() bool {
+		return reflect.DeepEqual(committedOffsets(broker, group, topic), expected)
+	})
+	checkCompleted(ctx, t, srv, id)
+
+	topics := producedTopics(broker)
+	if !topics[deadLetter] {
+		t.Error("expected rejected messages in the dead letter topic")
+	}
+	if topics[topic] {
+		t.Error("server published events to the topic it consumes")
+	}
+
+	cancel()
+	select {
+	case <-errc:
+	case <-time.After(time.Second * 10):
+		t.Fatal("timeout waiting for server to stop")
+	}
+
+	var left bool
+	for _, rr := range broker.History() {
+		req, ok := rr.Request.(*sarama.OffsetCommitRequest)
+		if ok && (req.ConsumerGroupGeneration != 1 || req.ConsumerID != member) {
+			t.Error("offsets committed outside the group generation", req.ConsumerGroupGeneration, req.ConsumerID)
+		}
+		if _, ok := rr.Request.(*sarama.LeaveGroupRequest); ok {
+			left = true
+		}
+	}
+	if !left {
+		t.Error("expected consumer to leave the group on shutdown")
+	}
+}
+
+// committedOffsets returns the last offset committed for each partition,
+// from the broker's request history.
+func committedOffsets(broker *sarama.MockBroker, group, topic string) map[int32]int64 {
+	out := map[int32]int64{}
+	for _, rr := range broker.History() {
+		req, ok := rr.Request.(*sarama.OffsetCommitRequest)
+		if !ok || req.ConsumerGroup != group {
+			continue
+		}
+		// The offsets of a commit request aren't exported.
+		blocks := reflect.ValueOf(req).Elem().FieldByName("blocks").MapIndex(reflect.ValueOf(topic))
+		if !blocks.IsValid() {
+			continue
+		}
+		for _, p := range blocks.MapKeys() {
+			out[int32(p.Int())] = blocks.MapIndex(p).Elem().FieldByName("offset").Int()
+		}
+	}
+	return out
+}
+
+// producedTopics returns the topics messages were produced to,
+// from the broker's request history.
+func producedTopics(broker *sarama.MockBroker) map[string]bool {
+	out := map[string]bool{}
+	for _, rr := range broker.History() {
+		req, ok := rr.Request.(*sarama.ProduceRequest)
+		if !ok {
+			continue
+		}
+		// The records of a produce request aren't exported.
+		for _, topic := range reflect.ValueOf(req).Elem().FieldByName("records").MapKeys() {
+			out[topic.String()] = true
+		}
+	}
+	return out
+}
+
+func TestPubSubEventReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake := pstest.NewServer()
+	os.Setenv("PUBSUB_EMULATOR_HOST", fake.Addr)
+	defer os.Unsetenv("PUBSUB_EMULATOR_HOST")
+
+	conf := consumerTestConfig(t)
+	conf.EventReaders = []string{"pubsub"}
+	conf.PubSub = config.PubSub{
+		Project:         "funnel-test",
+		Topic:           "funnel",
+		Subscription:    "funnel-server",
+		DeadLetterTopic: "funnel-dead-letter",
+	}
+
+	srv, err := NewServer(ctx, conf, logger.NewLogger("test-pubsub-consumer", logger.DebugConfig()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := createTestTask(ctx, t, srv)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Run(ctx)
+	}()
+
+	w, err := events.NewPubSubWriter(ctx, conf.PubSub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every message is acknowledged: the worker's events once written,
+	// the others once published to the dead letter topic.
+	var published int
+	waitForAck := func() {
+		published++
+		waitFor(t, "acknowledgement", func() bool {
+			var acked int
+			for _, m := range fake.Messages() {
+				if m.Acks > 0 {
+					acked++
+				}
+			}
+			return acked == published
+		})
+	}
+	write := func(ev *events.Event) {
+		if err := w.WriteEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+		waitForAck()
+	}
+
+	// Pub/Sub doesn't order delivery, so the worker's events are published
+	// one at a time, as a real worker would spread them out over time.
+	fake.Publish("projects/funnel-test/topics/funnel", []byte("not json"), nil)
+	waitForAck()
+	for _, ev := range workerEvents(id) {
+		write(ev)
+	}
+	write(events.NewState("unknown-task", tes.Running))
+
+	var rejected int
+	for _, m := range fake.Messages() {
+		if m.Attributes["error"] != "" {
+			rejected++
+		}
+	}
+	if rejected != 2 {
+		t.Error("expected 2 messages in the dead letter topic, got", rejected)
+	}
+	checkCompleted(ctx, t, srv, id)
+
+	cancel()
+	select {
+	case <-errc:
+	case <-time.After(time.Second * 10):
+		t.Fatal("timeout waiting for server to stop")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/ohsu-comp-bio/funnel/compute/batch"
 	"github.com/ohsu-comp-bio/funnel/compute/gridengine"
@@ -20,12 +21,18 @@ import (
 	"github.com/ohsu-comp-bio/funnel/database/dynamodb"
 	"github.com/ohsu-comp-bio/funnel/database/elastic"
 	"github.com/ohsu-comp-bio/funnel/database/mongodb"
+	"github.com/ohsu-comp-bio/funnel/database/sqldb"
 	"github.com/ohsu-comp-bio/funnel/events"
 	"github.com/ohsu-comp-bio/funnel/logger"
 	"github.com/ohsu-comp-bio/funnel/server"
+	"github.com/ohsu-comp-bio/funnel/storage"
 	"github.com/ohsu-comp-bio/funnel/tes"
 )
 
+// eventDedupSize is the number of recent event idempotency keys the server
+// remembers, to drop duplicate events replayed from worker event spools.
+const eventDedupSize = 10000
+
 // Run runs the "server run" command.
 func Run(ctx context.Context, conf config.Config, log *logger.Logger) error {
 	s, err := NewServer(ctx, conf, log)
@@ -40,28 +47,33 @@ func Run(ctx context.Context, conf config.Config, log *logger.Logger) error {
 type Server struct {
 	*server.Server
 	*scheduler.Scheduler
+	Autoscaler *scheduler.Autoscaler
+	Expirer    *server.Expirer
+	Readers    []EventReader
+}
+
+// EventReader consumes task events published by workers, e.g. to Kafka,
+// and writes them to the server's database.
+type EventReader interface {
+	Run(ctx context.Context) error
 }
 
 // Database represents the base funnel database interface
 type Database interface {
 	tes.ReadOnlyServer
 	events.Writer
+	events.EventLog
+	server.TaskDeleter
+	scheduler.SchedulerServiceServer
+	scheduler.TaskQueue
 	Init() error
 }
 
-// NewServer returns a new Funnel server + scheduler based on the given config.
-func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Server, error) {
-	log.Debug("NewServer", "config", conf)
-
+// NewDatabase returns the database selected by conf.Database,
+// with its resources initialized.
+func NewDatabase(conf config.Config) (Database, error) {
 	var database Database
-	var reader tes.ReadOnlyServer
-	var nodes scheduler.SchedulerServiceServer
-	var sched *scheduler.Scheduler
-	var queue scheduler.TaskQueue
-
-	writers := events.MultiWriter{}
 
-	// Database
 	switch strings.ToLower(conf.Database) {
 	case "boltdb":
 		b, err := boltdb.NewBoltDB(conf.BoltDB)
@@ -69,10 +81,6 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 			return nil, dberr(err)
 		}
 		database = b
-		reader = b
-		nodes = b
-		queue = b
-		writers = append(writers, b)
 
 	case "badger":
 		b, err := badger.NewBadger(conf.Badger)
@@ -80,8 +88,6 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 			return nil, dberr(err)
 		}
 		database = b
-		reader = b
-		writers = append(writers, b)
 
 	case "datastore":
 		d, err := datastore.NewDatastore(conf.Datastore)
@@ -89,8 +95,6 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 			return nil, dberr(err)
 		}
 		database = d
-		reader = d
-		writers = append(writers, d)
 
 	case "dynamodb":
 		d, err := dynamodb.NewDynamoDB(conf.DynamoDB)
@@ -98,8 +102,6 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 			return nil, dberr(err)
 		}
 		database = d
-		reader = d
-		writers = append(writers, d)
 
 	case "elastic":
 		e, err := elastic.NewElastic(conf.Elastic)
@@ -107,10 +109,6 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 			return nil, dberr(err)
 		}
 		database = e
-		reader = e
-		nodes = e
-		queue = e
-		writers = append(writers, e)
 
 	case "mongodb":
 		m, err := mongodb.NewMongoDB(conf.MongoDB)
@@ -118,10 +116,20 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 			return nil, dberr(err)
 		}
 		database = m
-		reader = m
-		nodes = m
-		queue = m
-		writers = append(writers, m)
+
+	case "postgres":
+		p, err := sqldb.NewPostgres(conf.Postgres)
+		if err != nil {
+			return nil, dberr(err)
+		}
+		database = p
+
+	case "sqlite":
+		s, err := sqldb.NewSQLite(conf.SQLite)
+		if err != nil {
+			return nil, dberr(err)
+		}
+		database = s
 
 	default:
 		return nil, fmt.Errorf("unknown database: '%s'", conf.Database)
@@ -131,17 +139,50 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 	if err := database.Init(); err != nil {
 		return nil, fmt.Errorf("error creating database resources: %v", err)
 	}
+	return database, nil
+}
+
+// NewServer returns a new Funnel server + scheduler based on the given config.
+func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Server, error) {
+	log.Debug("NewServer", "config", conf)
+
+	var sched *scheduler.Scheduler
+	var autoscaler *scheduler.Autoscaler
+
+	// Database
+	database, err := NewDatabase(conf)
+	if err != nil {
+		return nil, err
+	}
+	var reader tes.ReadOnlyServer = database
+	var nodes scheduler.SchedulerServiceServer = database
+	var queue scheduler.TaskQueue = database
+	writers := events.MultiWriter{database}
+
+	// Node sessions push task assignments and cancels to nodes.
+	sessions := &scheduler.NodeSessions{Log: log.Sub("node-sessions")}
+	writers = append(writers, sessions)
 
 	// Event writers
 	var writer events.Writer
-	var err error
 
 	eventWriterSet := make(map[string]interface{})
 	for _, w := range conf.EventWriters {
 		eventWriterSet[strings.ToLower(w)] = nil
 	}
+	eventReaderSet := make(map[string]interface{})
+	for _, r := range conf.EventReaders {
+		eventReaderSet[strings.ToLower(r)] = nil
+	}
 
 	for e := range eventWriterSet {
+		if _, ok := eventReaderSet[e]; ok {
+			// The server doesn't publish to the topic it consumes,
+			// which would write its own events twice. This allows
+			// the server and workers to share a config.
+			continue
+		}
+
 		switch e {
 		case strings.ToLower(conf.Database):
 			continue
@@ -159,8 +200,14 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 			writer, err = events.NewKafkaWriter(ctx, conf.Kafka)
 		case "pubsub":
 			writer, err = events.NewPubSubWriter(ctx, conf.PubSub)
+		case "webhook":
+			writer, err = events.NewWebhookWriter(ctx, conf.Webhook, reader, log.Sub("webhook"))
 		case "mongodb":
 			writer, err = mongodb.NewMongoDB(conf.MongoDB)
+		case "postgres":
+			writer, err = sqldb.NewPostgres(conf.Postgres)
+		case "sqlite":
+			writer, err = sqldb.NewSQLite(conf.SQLite)
 		default:
 			return nil, fmt.Errorf("unknown event writer: '%s'", e)
 		}
@@ -174,30 +221,60 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 
 	writer = &events.SystemLogFilter{Writer: &writers, Level: conf.Logger.Level}
 
+	// Event readers
+	consumed := events.NewDedup(writer, eventDedupSize)
+	var readers []EventReader
+	for e := range eventReaderSet {
+		var r EventReader
+		switch e {
+		case "kafka":
+			r, err = events.NewKafkaConsumer(conf.Kafka, consumed, log.Sub("kafka-consumer"))
+		case "pubsub":
+			r, err = events.NewPubSubConsumer(ctx, conf.PubSub, consumed, log.Sub("pubsub-consumer"))
+		default:
+			return nil, fmt.Errorf("unknown event reader: '%s'", e)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error occurred while initializing the %s event reader: %v", e, err)
+		}
+		readers = append(readers, r)
+	}
+
 	// Compute
 	var compute events.Writer
 	switch strings.ToLower(conf.Compute) {
 	case "manual":
-		if nodes == nil {
-			return nil, fmt.Errorf(
-				"cannot enable manual compute backend, database %s does not implement "+
-					"the scheduler service", conf.Database)
-		}
-		if queue == nil {
-			return nil, fmt.Errorf(
-				"cannot enable manual compute backend, database %s does not implement "+
-					"a task queue", conf.Database)
+		policy, err := scheduler.NewPolicy(conf.Scheduler)
+		if err != nil {
+			return nil, err
 		}
 
 		sched = &scheduler.Scheduler{
-			Conf:  conf.Scheduler,
-			Log:   log.Sub("scheduler"),
-			Nodes: nodes,
-			Queue: queue,
-			Event: &events.ErrLogger{Writer: writer, Log: log.Sub("scheduler")},
+			Conf:     conf.Scheduler,
+			Log:      log.Sub("scheduler"),
+			Nodes:    nodes,
+			Queue:    queue,
+			Event:    &events.ErrLogger{Writer: writer, Log: log.Sub("scheduler")},
+			Policy:   policy,
+			Tasks:    reader,
+			Sessions: sessions,
 		}
 		compute = events.Noop{}
 
+		if conf.Scheduler.Autoscaler.Provider != "" {
+			provider, err := scheduler.NewNodeProvider(conf.Scheduler.Autoscaler, log.Sub("autoscaler"))
+			if err != nil {
+				return nil, err
+			}
+			autoscaler = &scheduler.Autoscaler{
+				Conf:     conf.Scheduler,
+				Log:      log.Sub("autoscaler"),
+				Nodes:    nodes,
+				Queue:    queue,
+				Provider: provider,
+			}
+		}
+
 	case "aws-batch":
 		compute, err = batch.NewBackend(ctx, conf.AWSBatch, reader, writer)
 		if err != nil {
@@ -226,6 +303,29 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 
 	writer = &events.ErrLogger{Writer: writer, Log: log}
 
+	var expirer *server.Expirer
+	if len(conf.Retention.Policies) > 0 {
+		var store storage.Storage
+		if conf.Retention.ArchiveURL != "" {
+			store, err = storage.NewMux(conf)
+			if err != nil {
+				return nil, err
+			}
+		}
+		expirer, err = server.NewExpirer(conf.Retention, reader, database, store, log.Sub("retention"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	admin := &scheduler.NodeAdmin{
+		Nodes:    nodes,
+		Event:    writer,
+		Log:      log.Sub("node-admin"),
+		Tasks:    reader,
+		Sessions: sessions,
+	}
+
 	return &Server{
 		Server: &server.Server{
 			RPCAddress:       ":" + conf.Server.RPCPort,
@@ -241,10 +341,19 @@ func NewServer(ctx context.Context, conf config.Config, log *logger.Logger) (*Se
 				Read:    reader,
 				Log:     log,
 			},
-			Events: &events.Service{Writer: writer},
-			Nodes:  nodes,
+			Events: &events.Service{
+				Writer: events.NewDedup(writer, eventDedupSize),
+				Log:    database,
+				Tasks:  reader,
+			},
+			Nodes:        nodes,
+			NodeAdmin:    admin,
+			NodeSessions: sessions,
 		},
-		Scheduler: sched,
+		Scheduler:  sched,
+		Autoscaler: autoscaler,
+		Expirer:    expirer,
+		Readers:    readers,
 	}, nil
 }
 
@@ -266,9 +375,43 @@ func (s *Server) Run(ctx context.Context) error {
 		}()
 	}
 
+	// Start Autoscaler
+	if s.Autoscaler != nil {
+		go func() {
+			errch <- s.Autoscaler.Run(ctx)
+		}()
+	}
+
+	// Start task retention
+	if s.Expirer != nil {
+		go func() {
+			errch <- s.Expirer.Run(ctx)
+		}()
+	}
+
+	// Start event readers
+	readerCtx, stopReaders := context.WithCancel(ctx)
+	var readers sync.WaitGroup
+	for _, r := range s.Readers {
+		readers.Add(1)
+		go func(r EventReader) {
+			defer readers.Done()
+			err := r.Run(readerCtx)
+			select {
+			case errch <- err:
+			case <-readerCtx.Done():
+			}
+		}(r)
+	}
+
 	// Block until done.
 	// Server and scheduler must be stopped via the context.
-	return <-errch
+	err := <-errch
+
+	// Let the event readers commit their progress before returning.
+	stopReaders()
+	readers.Wait()
+	return err
 }
 
 func dberr(err error) error {
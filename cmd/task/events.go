@@ -0,0 +1,58 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+)
+
+// eventMarshaler writes an event on a single line.
+var eventMarshaler = &jsonpb.Marshaler{}
+
+// Events runs the "task events" CLI command, which connects to the server,
+// lists the event log of the task, optionally filtered by event type,
+// and writes one event per line, as JSON, to the given writer.
+func Events(server, id string, types []string, w io.Writer) error {
+	cli, err := tes.NewClient(server)
+	if err != nil {
+		return err
+	}
+
+	v := url.Values{}
+	for _, t := range types {
+		et, err := getEventType(t)
+		if err != nil {
+			return err
+		}
+		v.Add("types", et.String())
+	}
+
+	resp := &events.ListTaskEventsResponse{}
+	err = cli.GetJSON(context.Background(), "/v1/tasks/"+id+"/events", v, resp)
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range resp.Events {
+		out, err := eventMarshaler.MarshalToString(ev)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, out)
+	}
+	return nil
+}
+
+func getEventType(str string) (events.Type, error) {
+	i, ok := events.Type_value[strings.ToUpper(str)]
+	if !ok {
+		return events.Type_UNKNOWN, fmt.Errorf("Unknown event type: %s", str)
+	}
+	return events.Type(i), nil
+}
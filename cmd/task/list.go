@@ -9,10 +9,26 @@ import (
 	"golang.org/x/net/context"
 )
 
+// ListFilters holds the filters of the "task list" CLI command,
+// as given on the command line.
+type ListFilters struct {
+	// States are task state names. One state sets the State filter,
+	// more than one set the States filter.
+	States []string
+	// Tags are KEY=VALUE pairs.
+	Tags          []string
+	NamePrefix    string
+	CreatedAfter  string
+	CreatedBefore string
+	TagKeys       []string
+	// TagPrefixes are KEY=PREFIX pairs.
+	TagPrefixes []string
+}
+
 // List runs the "task list" CLI command, which connects to the server,
 // calls ListTasks() and requests the given task view.
 // Output is written to the given writer.
-func List(server, taskView, pageToken, stateFilter string, tagsFilter []string, pageSize uint32, all bool, writer io.Writer) error {
+func List(server, taskView, pageToken string, filters ListFilters, pageSize uint32, all bool, writer io.Writer) error {
 	cli, err := tes.NewClient(server)
 	if err != nil {
 		return err
@@ -25,18 +41,28 @@ func List(server, taskView, pageToken, stateFilter string, tagsFilter []string,
 
 	output := &tes.ListTasksResponse{}
 
-	state, err := getTaskState(stateFilter)
+	var states []tes.State
+	for _, s := range filters.States {
+		state, err := getTaskState(s)
+		if err != nil {
+			return err
+		}
+		states = append(states, state)
+	}
+	var state tes.State
+	if len(states) == 1 {
+		state = states[0]
+		states = nil
+	}
+
+	tags, err := parseKeyValues(filters.Tags, "tags", "KEY=VALUE")
 	if err != nil {
 		return err
 	}
 
-	tags := make(map[string]string)
-	for _, v := range tagsFilter {
-		parts := strings.Split(v, "=")
-		if len(parts) != 2 {
-			return fmt.Errorf("tags must be of the form: KEY=VALUE")
-		}
-		tags[parts[0]] = parts[1]
+	prefixes, err := parseKeyValues(filters.TagPrefixes, "tag prefixes", "KEY=PREFIX")
+	if err != nil {
+		return err
 	}
 
 	for {
@@ -46,6 +72,13 @@ func List(server, taskView, pageToken, stateFilter string, tagsFilter []string,
 			PageSize:  pageSize,
 			State:     state,
 			Tags:      tags,
+
+			NamePrefix:    filters.NamePrefix,
+			CreatedAfter:  filters.CreatedAfter,
+			CreatedBefore: filters.CreatedBefore,
+			States:        states,
+			TagKeys:       filters.TagKeys,
+			TagPrefixes:   prefixes,
 		}
 
 		resp, err := cli.ListTasks(context.Background(), req)
@@ -70,3 +103,17 @@ func List(server, taskView, pageToken, stateFilter string, tagsFilter []string,
 	fmt.Fprintf(writer, "%s\n", response)
 	return nil
 }
+
+// parseKeyValues parses a list of KEY=VALUE strings into a map.
+// "what" and "form" describe the strings in error messages.
+func parseKeyValues(kvs []string, what, form string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, v := range kvs {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s must be of the form: %s", what, form)
+		}
+		m[parts[0]] = parts[1]
+	}
+	return m, nil
+}
@@ -25,6 +25,7 @@ func newCommandHooks() (*cobra.Command, *hooks) {
 		List:   List,
 		Cancel: Cancel,
 		Wait:   Wait,
+		Events: Events,
 	}
 
 	var (
@@ -64,23 +65,27 @@ func newCommandHooks() (*cobra.Command, *hooks) {
 		pageSize    uint32
 		listAll     bool
 		listView    string
-		stateFilter string
-		tagsFilter  []string
+		listFilters ListFilters
 	)
 
 	list := &cobra.Command{
 		Use:   "list",
 		Short: "List all tasks.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return h.List(tesServer, listView, pageToken, stateFilter, tagsFilter, pageSize, listAll, cmd.OutOrStdout())
+			return h.List(tesServer, listView, pageToken, listFilters, pageSize, listAll, cmd.OutOrStdout())
 		},
 	}
 
 	lf := list.Flags()
 	lf.StringVarP(&listView, "view", "v", "basic", "Task view")
 	lf.StringVarP(&pageToken, "page-token", "p", pageToken, "Page token")
-	lf.StringVar(&stateFilter, "state", stateFilter, "State filter")
-	lf.StringSliceVar(&tagsFilter, "tag", tagsFilter, "Tag filter. May be used multiple times to specify more than one tag")
+	lf.StringSliceVar(&listFilters.States, "state", listFilters.States, "State filter. May be used multiple times to match any of the states")
+	lf.StringSliceVar(&listFilters.Tags, "tag", listFilters.Tags, "Tag filter. May be used multiple times to specify more than one tag")
+	lf.StringVar(&listFilters.NamePrefix, "name-prefix", listFilters.NamePrefix, "Task name prefix filter")
+	lf.StringVar(&listFilters.CreatedAfter, "created-after", listFilters.CreatedAfter, "Only list tasks created at or after this time (RFC 3339)")
+	lf.StringVar(&listFilters.CreatedBefore, "created-before", listFilters.CreatedBefore, "Only list tasks created before this time (RFC 3339)")
+	lf.StringSliceVar(&listFilters.TagKeys, "tag-key", listFilters.TagKeys, "Tag key filter. Lists tasks which have the tag, with any value. May be used multiple times")
+	lf.StringSliceVar(&listFilters.TagPrefixes, "tag-prefix", listFilters.TagPrefixes, "Tag value prefix filter, of the form KEY=PREFIX. May be used multiple times")
 	lf.Uint32VarP(&pageSize, "page-size", "s", pageSize, "Page size")
 	lf.BoolVar(&listAll, "all", listAll, "List all tasks")
 
@@ -115,16 +120,30 @@ func newCommandHooks() (*cobra.Command, *hooks) {
 		},
 	}
 
-	cmd.AddCommand(create, get, list, cancel, wait)
+	var eventTypes []string
+	evs := &cobra.Command{
+		Use:   "events [taskID]",
+		Short: "List the event log of a task.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.Events(tesServer, args[0], eventTypes, cmd.OutOrStdout())
+		},
+	}
+
+	ef := evs.Flags()
+	ef.StringSliceVar(&eventTypes, "type", eventTypes, "Event type filter, e.g. task_state. May be used multiple times to match any of the types")
+
+	cmd.AddCommand(create, get, list, cancel, wait, evs)
 	return cmd, h
 }
 
 type hooks struct {
 	Create func(server string, messages []string, r io.Reader, w io.Writer) error
 	Get    func(server string, ids []string, view string, w io.Writer) error
-	List   func(server, view, pageToken, stateFilter string, tagsFilter []string, pageSize uint32, all bool, w io.Writer) error
+	List   func(server, view, pageToken string, filters ListFilters, pageSize uint32, all bool, w io.Writer) error
 	Cancel func(server string, ids []string, w io.Writer) error
 	Wait   func(server string, ids []string) error
+	Events func(server, id string, types []string, w io.Writer) error
 }
 
 func getTaskState(str string) (tes.State, error) {
@@ -3,6 +3,7 @@ package task
 import (
 	"io"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -41,7 +42,7 @@ func TestGetDefaultView(t *testing.T) {
 func TestList(t *testing.T) {
 	cmd, h := newCommandHooks()
 
-	h.List = func(server, view, page, state string, tags []string, size uint32, all bool, w io.Writer) error {
+	h.List = func(server, view, page string, filters ListFilters, size uint32, all bool, w io.Writer) error {
 		if view != "FULL" {
 			t.Errorf("expected FULL view, got '%s'", view)
 		}
@@ -62,7 +63,7 @@ func TestServerDefault(t *testing.T) {
 		}
 		return nil
 	}
-	h.List = func(server, view, page, state string, tags []string, size uint32, all bool, w io.Writer) error {
+	h.List = func(server, view, page string, filters ListFilters, size uint32, all bool, w io.Writer) error {
 		if server != "http://localhost:8000" {
 			t.Errorf("expected localhost default, got '%s'", server)
 		}
@@ -116,7 +117,7 @@ func TestServerEnv(t *testing.T) {
 		}
 		return nil
 	}
-	h.List = func(server, view, page, state string, tags []string, size uint32, all bool, w io.Writer) error {
+	h.List = func(server, view, page string, filters ListFilters, size uint32, all bool, w io.Writer) error {
 		if server != "foobar" {
 			t.Error("expected foobar")
 		}
@@ -170,7 +171,7 @@ func TestServerFlagOverride(t *testing.T) {
 		}
 		return nil
 	}
-	h.List = func(server, view, page, state string, tags []string, size uint32, all bool, w io.Writer) error {
+	h.List = func(server, view, page string, filters ListFilters, size uint32, all bool, w io.Writer) error {
 		if server != "flagval" {
 			t.Error("expected flagval")
 		}
@@ -210,3 +211,52 @@ func TestServerFlagOverride(t *testing.T) {
 	cmd.SetArgs([]string{"wait", "-S", srv, "1"})
 	cmd.Execute()
 }
+
+func TestListFilters(t *testing.T) {
+	cmd, h := newCommandHooks()
+
+	h.List = func(server, view, page string, filters ListFilters, size uint32, all bool, w io.Writer) error {
+		expected := ListFilters{
+			States:        []string{"queued", "running"},
+			Tags:          []string{"foo=bar"},
+			NamePrefix:    "align-",
+			CreatedAfter:  "2018-01-01T00:00:00Z",
+			CreatedBefore: "2018-02-01T00:00:00Z",
+			TagKeys:       []string{"project"},
+			TagPrefixes:   []string{"sample=NA12"},
+		}
+		if !reflect.DeepEqual(filters, expected) {
+			t.Errorf("unexpected filters\nexpected: %#v\nactual: %#v", expected, filters)
+		}
+		return nil
+	}
+
+	cmd.SetArgs([]string{"list",
+		"--state", "queued", "--state", "running",
+		"--tag", "foo=bar",
+		"--name-prefix", "align-",
+		"--created-after", "2018-01-01T00:00:00Z",
+		"--created-before", "2018-02-01T00:00:00Z",
+		"--tag-key", "project",
+		"--tag-prefix", "sample=NA12",
+	})
+	cmd.Execute()
+}
+
+func TestEvents(t *testing.T) {
+	cmd, h := newCommandHooks()
+
+	h.Events = func(server, id string, types []string, w io.Writer) error {
+		if id != "1" {
+			t.Errorf("unexpected id: %s", id)
+		}
+		expected := []string{"task_state", "system_log"}
+		if !reflect.DeepEqual(types, expected) {
+			t.Errorf("expected types %#v, got %#v", expected, types)
+		}
+		return nil
+	}
+
+	cmd.SetArgs([]string{"events", "--type", "task_state", "--type", "system_log", "1"})
+	cmd.Execute()
+}
@@ -144,6 +144,13 @@ func dbFlags(flagConf *config.Config) *pflag.FlagSet {
 	f.StringVar(&flagConf.MongoDB.Database, "MongoDB.Database", flagConf.MongoDB.Database, "Database name in MongoDB")
 	f.Var(&flagConf.MongoDB.Timeout, "MongoDB.Timeout", "Timeout in seconds for initial connection and follow up operations")
 
+	// postgres
+	f.StringVar(&flagConf.Postgres.URL, "Postgres.URL", flagConf.Postgres.URL, "PostgreSQL connection URL")
+	f.StringVar(&flagConf.Postgres.Schema, "Postgres.Schema", flagConf.Postgres.Schema, "Schema name in PostgreSQL")
+
+	// sqlite
+	f.StringVar(&flagConf.SQLite.Path, "SQLite.Path", flagConf.SQLite.Path, "Path to SQLite database")
+
 	return f
 }
 
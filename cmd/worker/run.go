@@ -3,13 +3,16 @@ package worker
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ohsu-comp-bio/funnel/config"
 	"github.com/ohsu-comp-bio/funnel/database/datastore"
 	"github.com/ohsu-comp-bio/funnel/database/dynamodb"
 	"github.com/ohsu-comp-bio/funnel/database/elastic"
 	"github.com/ohsu-comp-bio/funnel/database/mongodb"
+	"github.com/ohsu-comp-bio/funnel/database/sqldb"
 	"github.com/ohsu-comp-bio/funnel/events"
 	"github.com/ohsu-comp-bio/funnel/logger"
 	"github.com/ohsu-comp-bio/funnel/storage"
@@ -19,18 +22,46 @@ import (
 
 // Run runs the "worker run" command.
 func Run(ctx context.Context, conf config.Config, log *logger.Logger, taskID string) error {
-	w, err := NewWorker(ctx, conf, log)
+	w, spools, err := newWorker(ctx, conf, log)
 	if err != nil {
 		return err
 	}
-	return w.Run(ctx, taskID)
+	runerr := w.Run(ctx, taskID)
+
+	// Wait for spooled events to be written before exiting.
+	flushctx, cancel := context.WithTimeout(context.Background(), time.Duration(conf.Worker.EventSpool.FlushTimeout))
+	defer cancel()
+	for _, s := range spools {
+		if err := s.Flush(flushctx); err != nil {
+			log.Error("Couldn't write spooled events", "error", err)
+		}
+		s.Close()
+	}
+	return runerr
 }
 
 // NewWorker returns a new Funnel worker based on the given config.
 func NewWorker(ctx context.Context, conf config.Config, log *logger.Logger) (*worker.DefaultWorker, error) {
+	w, spools, err := newWorker(ctx, conf, log)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		for _, s := range spools {
+			s.Close()
+		}
+	}()
+	return w, nil
+}
+
+// newWorker returns a new Funnel worker, and the spools buffering
+// its events, if the event spool is enabled.
+func newWorker(ctx context.Context, conf config.Config, log *logger.Logger) (*worker.DefaultWorker, []*events.Spool, error) {
 	log.Debug("NewWorker", "config", conf)
 
 	var err error
+	var spools []*events.Spool
 	var db tes.ReadOnlyServer
 	var reader worker.TaskReader
 	var writer events.Writer
@@ -61,14 +92,38 @@ func NewWorker(ctx context.Context, conf config.Config, log *logger.Logger) (*wo
 			writer, err = events.NewKafkaWriter(ctx, conf.Kafka)
 		case "pubsub":
 			writer, err = events.NewPubSubWriter(ctx, conf.PubSub)
+		case "webhook":
+			// Webhooks are sent by the server, which receives the worker's events.
+			continue
 		case "mongodb":
 			writer, err = mongodb.NewMongoDB(conf.MongoDB)
+		case "postgres":
+			writer, err = sqldb.NewPostgres(conf.Postgres)
+		case "sqlite":
+			writer, err = events.NewRPCWriter(ctx, conf.Server)
 		default:
 			err = fmt.Errorf("unknown event writer: %s", e)
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error occurred while initializing the %s event writer: %v", e, err)
+			return nil, nil, fmt.Errorf("error occurred while initializing the %s event writer: %v", e, err)
+		}
+
+		// Spool events which can't be written, except for log events,
+		// which can't fail.
+		if conf.Worker.EventSpool.Enabled && e != "log" {
+			dir := conf.Worker.EventSpool.Dir
+			if dir == "" {
+				dir = filepath.Join(conf.Worker.WorkDir, "event-spool")
+			}
+			var spool *events.Spool
+			spool, err = events.NewSpool(ctx, writer, dir, e, time.Duration(conf.Worker.EventSpool.Rate), log.Sub(e+"-spool"))
+			if err != nil {
+				return nil, nil, fmt.Errorf("error occurred while initializing the %s event spool: %v", e, err)
+			}
+			spools = append(spools, spool)
+			writer = spool
 		}
+
 		if writer != nil {
 			writers = append(writers, writer)
 		}
@@ -86,15 +141,19 @@ func NewWorker(ctx context.Context, conf config.Config, log *logger.Logger) (*wo
 		db, err = elastic.NewElastic(conf.Elastic)
 	case "mongodb":
 		db, err = mongodb.NewMongoDB(conf.MongoDB)
+	case "postgres":
+		db, err = sqldb.NewPostgres(conf.Postgres)
 	case "boltdb":
 		reader, err = worker.NewRPCTaskReader(ctx, conf.Server)
 	case "badger":
 		reader, err = worker.NewRPCTaskReader(ctx, conf.Server)
+	case "sqlite":
+		reader, err = worker.NewRPCTaskReader(ctx, conf.Server)
 	default:
 		err = fmt.Errorf("unknown database: '%s'", conf.Database)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to instantiate database client: %v", err)
+		return nil, nil, fmt.Errorf("failed to instantiate database client: %v", err)
 	}
 	if reader == nil {
 		reader = worker.NewGenericTaskReader(db.GetTask)
@@ -102,16 +161,17 @@ func NewWorker(ctx context.Context, conf config.Config, log *logger.Logger) (*wo
 
 	store, err := storage.NewMux(conf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to instantiate Storage backend: %v", err)
+		return nil, nil, fmt.Errorf("failed to instantiate Storage backend: %v", err)
 	}
 	store.AttachLogger(log)
 
 	w := &worker.DefaultWorker{
-		Conf:        conf.Worker,
-		Store:       store,
-		TaskReader:  reader,
-		EventWriter: writer,
+		Conf:            conf.Worker,
+		Store:           store,
+		TaskReader:      reader,
+		EventWriter:     writer,
+		CustomResources: conf.Node.Resources.Custom,
 	}
 
-	return w, nil
+	return w, spools, nil
 }
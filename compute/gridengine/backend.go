@@ -24,6 +24,7 @@ func NewBackend(conf config.Config, reader tes.ReadOnlyServer, writer events.Wri
 		// grid engine backend doesnt support state reconciliation
 		MapStates:     nil,
 		ReconcileRate: 0,
+		AllowedTags:   conf.GridEngine.AllowedTags,
 	}
 }
 
@@ -8,6 +8,9 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
@@ -36,6 +39,9 @@ type HPCBackend struct {
 	// and system logs to report errors reported by the backend.
 	MapStates     func([]string) ([]*HPCTaskState, error)
 	ReconcileRate time.Duration
+	// AllowedTags lists the task tags, namespaced by Name (e.g. "slurm.partition"),
+	// which may be passed through to the submit template.
+	AllowedTags []string
 }
 
 // WriteEvent writes an event to the compute backend.
@@ -59,6 +65,15 @@ func (b *HPCBackend) Submit(task *tes.Task) error {
 
 	submitPath, err := b.setupTemplatedHPCSubmit(task)
 	if err != nil {
+		b.Event.WriteEvent(ctx, events.NewState(task.Id, tes.SystemError))
+		b.Event.WriteEvent(
+			ctx,
+			events.NewSystemLog(
+				task.Id, 0, 0, "error",
+				"error creating "+b.Name+" submit file",
+				map[string]string{"error": err.Error()},
+			),
+		)
 		return err
 	}
 
@@ -216,6 +231,11 @@ func (b *HPCBackend) Reconcile(ctx context.Context) {
 func (b *HPCBackend) setupTemplatedHPCSubmit(task *tes.Task) (string, error) {
 	var err error
 
+	tags, gpus, err := b.templateTags(task)
+	if err != nil {
+		return "", err
+	}
+
 	// TODO document that these working dirs need manual cleanup
 	workdir := path.Join(b.Conf.Worker.WorkDir, task.Id)
 	workdir, _ = filepath.Abs(workdir)
@@ -231,6 +251,7 @@ func (b *HPCBackend) setupTemplatedHPCSubmit(task *tes.Task) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	defer f.Close()
 
 	submitTpl, err := template.New(submitName).Parse(b.Template)
 	if err != nil {
@@ -255,15 +276,73 @@ func (b *HPCBackend) setupTemplatedHPCSubmit(task *tes.Task) (string, error) {
 		"RamGb":   res.GetRamGb(),
 		"DiskGb":  res.GetDiskGb(),
 		"Zone":    zone,
+		// Values below are set from namespaced task tags, e.g. "slurm.partition".
+		"Partition": tags["partition"],
+		"Queue":     tags["queue"],
+		"Account":   tags["account"],
+		"Qos":       tags["qos"],
+		"Walltime":  tags["walltime"],
+		"Gpus":      gpus,
+		"Tags":      tags,
 	})
 	if err != nil {
 		return "", err
 	}
-	f.Close()
 
 	return submitPath, nil
 }
 
+// tagValuePattern restricts tag values passed to the submit template, so that
+// a tag can't inject extra lines or shell syntax into the submit file.
+var tagValuePattern = regexp.MustCompile(`^[A-Za-z0-9_.,:=@+/-]*$`)
+
+// ValidateTask returns an error if the task has a tag namespaced with the
+// backend name which isn't allowed, or has an invalid value. This is called
+// when the task is created, so that it is rejected instead of failing
+// at submit time.
+func (b *HPCBackend) ValidateTask(task *tes.Task) error {
+	_, _, err := b.templateTags(task)
+	return err
+}
+
+// templateTags collects the task tags namespaced with the backend name
+// (e.g. "slurm.account") and returns them with the prefix removed,
+// along with the value of the "gpus" tag.
+// An error is returned if a tag is not in AllowedTags or has an invalid value.
+func (b *HPCBackend) templateTags(task *tes.Task) (map[string]string, int, error) {
+	allowed := map[string]bool{}
+	for _, k := range b.AllowedTags {
+		allowed[k] = true
+	}
+
+	prefix := b.Name + "."
+	tags := map[string]string{}
+	for k, v := range task.GetTags() {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		key := strings.TrimPrefix(k, prefix)
+		if !allowed[key] {
+			return nil, 0, fmt.Errorf("tag %s is not allowed by the %s backend config; allowed tags are: %s",
+				k, b.Name, strings.Join(b.AllowedTags, ", "))
+		}
+		if !tagValuePattern.MatchString(v) {
+			return nil, 0, fmt.Errorf("invalid tag %s: value %q contains unsupported characters", k, v)
+		}
+		tags[key] = v
+	}
+
+	var gpus int
+	if v, ok := tags["gpus"]; ok {
+		var err error
+		gpus, err = strconv.Atoi(v)
+		if err != nil || gpus < 0 {
+			return nil, 0, fmt.Errorf("invalid tag %s.gpus: %q is not a non-negative integer", b.Name, v)
+		}
+	}
+	return tags, gpus, nil
+}
+
 func getBackendTaskID(task *tes.Task, backend string) string {
 	logs := task.GetLogs()
 	if len(logs) > 0 {
@@ -89,3 +89,121 @@ funnel worker run --taskID test-taskid
 		t.Fatal("Unexpected content")
 	}
 }
+
+func TestSetupTemplatedHPCSubmitTags(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "funnel-test-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := config.DefaultConfig()
+	conf.Worker.WorkDir = tmp
+
+	tpl := `#TEST --partition {{.Partition}}
+#TEST --account {{.Account}}
+#TEST --time {{.Walltime}}
+#TEST --gpus {{.Gpus}}
+#TEST --constraint {{index .Tags "constraint"}}
+`
+
+	b := HPCBackend{
+		Name:        "test",
+		SubmitCmd:   "qsub",
+		Template:    tpl,
+		Conf:        conf,
+		AllowedTags: []string{"partition", "account", "walltime", "gpus", "constraint"},
+	}
+
+	task := &tes.Task{
+		Id: "test-taskid",
+		Tags: map[string]string{
+			"test.partition":  "gpu",
+			"test.account":    "lab_123",
+			"test.walltime":   "01:30:00",
+			"test.gpus":       "2",
+			"test.constraint": "v100",
+			"other.qos":       "ignored",
+		},
+	}
+
+	sf, err := b.setupTemplatedHPCSubmit(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, rerr := ioutil.ReadFile(sf)
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+
+	expected := `#TEST --partition gpu
+#TEST --account lab_123
+#TEST --time 01:30:00
+#TEST --gpus 2
+#TEST --constraint v100
+`
+
+	if string(actual) != expected {
+		t.Log("Expected", "", expected)
+		t.Log("Actual", "", string(actual))
+		t.Fatal("Unexpected content")
+	}
+
+	task.Tags["test.qos"] = "high"
+	_, err = b.setupTemplatedHPCSubmit(task)
+	if err == nil {
+		t.Error("expected error for tag not in AllowedTags")
+	}
+	delete(task.Tags, "test.qos")
+
+	task.Tags["test.account"] = "lab\n#TEST --injected"
+	_, err = b.setupTemplatedHPCSubmit(task)
+	if err == nil {
+		t.Error("expected error for invalid tag value")
+	}
+	task.Tags["test.account"] = "lab_123"
+
+	task.Tags["test.gpus"] = "two"
+	_, err = b.setupTemplatedHPCSubmit(task)
+	if err == nil {
+		t.Error("expected error for invalid gpus tag")
+	}
+}
+
+func TestHPCBackendValidateTask(t *testing.T) {
+	conf := config.DefaultConfig()
+	b := HPCBackend{
+		Name:        "slurm",
+		Conf:        conf,
+		AllowedTags: conf.Slurm.AllowedTags,
+	}
+
+	valid := []map[string]string{
+		nil,
+		{"slurm.partition": "gpu", "slurm.gpus": "1"},
+		// Tags of other backends are ignored.
+		{"pbs.constraint": "v100"},
+	}
+	for _, tags := range valid {
+		if err := b.ValidateTask(&tes.Task{Tags: tags}); err != nil {
+			t.Error("unexpected error", tags, err)
+		}
+	}
+
+	invalid := []map[string]string{
+		{"slurm.constraint": "v100"},
+		{"slurm.account": "lab; rm -rf /"},
+		{"slurm.gpus": "-1"},
+	}
+	for _, tags := range invalid {
+		if err := b.ValidateTask(&tes.Task{Tags: tags}); err == nil {
+			t.Error("expected error", tags)
+		}
+	}
+
+	// Each backend has its own list of allowed tags.
+	conf.Slurm.AllowedTags[0] = "constraint"
+	if conf.PBS.AllowedTags[0] == "constraint" {
+		t.Error("expected backends not to share allowed tags")
+	}
+}
@@ -29,6 +29,7 @@ func NewBackend(ctx context.Context, conf config.Config, reader tes.ReadOnlyServ
 		ExtractID:     extractID,
 		MapStates:     mapStates,
 		ReconcileRate: time.Duration(conf.HTCondor.ReconcileRate),
+		AllowedTags:   conf.HTCondor.AllowedTags,
 	}
 
 	if !conf.HTCondor.DisableReconciler {
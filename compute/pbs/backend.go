@@ -27,6 +27,7 @@ func NewBackend(ctx context.Context, conf config.Config, reader tes.ReadOnlyServ
 		ExtractID:     extractID,
 		MapStates:     mapStates,
 		ReconcileRate: time.Duration(conf.PBS.ReconcileRate),
+		AllowedTags:   conf.PBS.AllowedTags,
 	}
 
 	if !conf.PBS.DisableReconciler {
@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// nodeUpdateRetries is the number of times a node update is attempted
+// when it conflicts with a concurrent update, e.g. a node sync.
+const nodeUpdateRetries = 5
+
+// NodeAdmin implements the NodeAdminService. Changes are saved to the node
+// database and picked up by the node on its next sync.
+type NodeAdmin struct {
+	Nodes SchedulerServiceServer
+	Event events.Writer
+	Log   *logger.Logger
+	// Tasks is used to look up the current attempt of evicted tasks.
+	// If nil, the first attempt is used.
+	Tasks tes.ReadOnlyServer
+	// Sessions is used to push task cancels to nodes as soon as tasks are evicted.
+	// If nil, nodes pick up evictions on their next sync.
+	Sessions *NodeSessions
+}
+
+// DrainNode stops the scheduler from assigning tasks to the node.
+// The node shuts down once its tasks are done.
+func (a *NodeAdmin) DrainNode(ctx context.Context, req *DrainNodeRequest) (*Node, error) {
+	return a.update(ctx, req.Id, func(n *Node) error {
+		switch n.State {
+		case NodeState_DEAD, NodeState_GONE:
+			return grpc.Errorf(codes.FailedPrecondition, "node %s is %s", n.Id, n.State)
+		}
+		n.State = NodeState_DRAIN
+		return nil
+	})
+}
+
+// CordonNode stops the scheduler from assigning tasks to the node.
+// Tasks already assigned to the node keep running.
+func (a *NodeAdmin) CordonNode(ctx context.Context, req *CordonNodeRequest) (*Node, error) {
+	return a.update(ctx, req.Id, func(n *Node) error {
+		switch n.State {
+		case NodeState_ALIVE, NodeState_CORDON, NodeState_QUARANTINE:
+		default:
+			return grpc.Errorf(codes.FailedPrecondition, "node %s is %s", n.Id, n.State)
+		}
+		n.State = NodeState_CORDON
+		return nil
+	})
+}
+
+// UncordonNode allows the scheduler to assign tasks to a cordoned
+// or quarantined node again.
+func (a *NodeAdmin) UncordonNode(ctx context.Context, req *UncordonNodeRequest) (*Node, error) {
+	return a.update(ctx, req.Id, func(n *Node) error {
+		switch n.State {
+		case NodeState_ALIVE, NodeState_CORDON, NodeState_QUARANTINE:
+		default:
+			return grpc.Errorf(codes.FailedPrecondition, "node %s is %s", n.Id, n.State)
+		}
+		n.State = NodeState_ALIVE
+		return nil
+	})
+}
+
+// LabelNode adds, updates or removes node metadata labels.
+//
+// Labels from the node's config are added back by the node on its next sync,
+// so they can't be removed this way.
+func (a *NodeAdmin) LabelNode(ctx context.Context, req *LabelNodeRequest) (*Node, error) {
+	for k := range req.Labels {
+		if k == "" {
+			return nil, grpc.Errorf(codes.InvalidArgument, "empty label key")
+		}
+	}
+	return a.update(ctx, req.Id, func(n *Node) error {
+		meta := map[string]string{}
+		for k, v := range n.Metadata {
+			meta[k] = v
+		}
+		for k, v := range req.Labels {
+			meta[k] = v
+		}
+		for _, k := range req.Remove {
+			delete(meta, k)
+		}
+		n.Metadata = meta
+		return nil
+	})
+}
+
+// EvictNode removes tasks from the node. The node stops the workers of
+// the evicted tasks on its next sync, and the tasks are marked as SYSTEM_ERROR.
+// If no task IDs are given, all the tasks assigned to the node are evicted.
+func (a *NodeAdmin) EvictNode(ctx context.Context, req *EvictNodeRequest) (*Node, error) {
+	var evicted []string
+	n, err := a.update(ctx, req.Id, func(n *Node) error {
+		assigned := map[string]bool{}
+		for _, id := range n.TaskIds {
+			assigned[id] = true
+		}
+
+		evict := map[string]bool{}
+		for _, id := range req.TaskIds {
+			if !assigned[id] {
+				return grpc.Errorf(codes.InvalidArgument, "task %s is not assigned to node %s", id, n.Id)
+			}
+			evict[id] = true
+		}
+
+		evicted = nil
+		var remaining []string
+		for _, id := range n.TaskIds {
+			if len(req.TaskIds) == 0 || evict[id] {
+				evicted = append(evicted, id)
+			} else {
+				remaining = append(remaining, id)
+			}
+		}
+		n.TaskIds = remaining
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range evicted {
+		a.Log.Info("Evicted task from node", "taskID", id, "nodeID", n.Id)
+		a.Sessions.Cancel(n.Id, id)
+
+		// Re-queued tasks run as later attempts.
+		var attempt uint32
+		if a.Tasks != nil {
+			task, err := a.Tasks.GetTask(ctx, &tes.GetTaskRequest{Id: id, View: tes.TaskView_BASIC})
+			if err != nil {
+				a.Log.Error("Error getting evicted task", "error", err, "taskID", id)
+			} else {
+				attempt = task.Attempt()
+			}
+		}
+
+		err := a.Event.WriteEvent(ctx, events.NewState(id, tes.State_SYSTEM_ERROR))
+		if err != nil {
+			a.Log.Error("Error marking evicted task as SYSTEM_ERROR", "error", err, "taskID", id)
+		}
+		err = a.Event.WriteEvent(ctx, events.NewSystemLog(id, attempt, 0, "info",
+			"Task evicted from node", map[string]string{
+				"nodeID": n.Id,
+			}))
+		if err != nil {
+			a.Log.Error("Error writing eviction system log", "error", err, "taskID", id)
+		}
+	}
+	return n, nil
+}
+
+// update gets the node, modifies it with "fn" and saves it, retrying if
+// the node was changed concurrently.
+func (a *NodeAdmin) update(ctx context.Context, id string, fn func(*Node) error) (*Node, error) {
+	if id == "" {
+		return nil, grpc.Errorf(codes.InvalidArgument, "missing node ID")
+	}
+
+	var err error
+	for i := 0; i < nodeUpdateRetries; i++ {
+		var n *Node
+		n, err = a.Nodes.GetNode(ctx, &GetNodeRequest{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			return nil, grpc.Errorf(codes.NotFound, "not found: nodeID: %s", id)
+		}
+
+		err = fn(n)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = a.Nodes.PutNode(ctx, n)
+		if err == nil {
+			a.Log.Info("Updated node", "nodeID", n.Id, "state", n.State)
+			return n, nil
+		}
+		a.Log.Debug("Error updating node, retrying", "nodeID", id, "error", err)
+	}
+	return nil, fmt.Errorf("updating node %s: %v", id, err)
+}
@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type eventRecorder []*events.Event
+
+func (r *eventRecorder) WriteEvent(ctx context.Context, ev *events.Event) error {
+	*r = append(*r, ev)
+	return nil
+}
+
+func TestNodeAdminState(t *testing.T) {
+	ctx := context.Background()
+	nodes := memNodes{"node-1": idleNode("node-1")}
+	a := &NodeAdmin{Nodes: nodes, Event: &eventRecorder{}}
+
+	n, err := a.CordonNode(ctx, &CordonNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.State != NodeState_CORDON || nodes["node-1"].State != NodeState_CORDON {
+		t.Errorf("expected node state CORDON, got %s", nodes["node-1"].State)
+	}
+
+	_, err = a.UncordonNode(ctx, &UncordonNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes["node-1"].State != NodeState_ALIVE {
+		t.Errorf("expected node state ALIVE, got %s", nodes["node-1"].State)
+	}
+
+	_, err = a.DrainNode(ctx, &DrainNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes["node-1"].State != NodeState_DRAIN {
+		t.Errorf("expected node state DRAIN, got %s", nodes["node-1"].State)
+	}
+
+	// A draining node can't be uncordoned.
+	_, err = a.UncordonNode(ctx, &UncordonNodeRequest{Id: "node-1"})
+	if s, _ := status.FromError(err); s.Code() != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition error, got %v", err)
+	}
+
+	_, err = a.DrainNode(ctx, &DrainNodeRequest{Id: "node-2"})
+	if s, _ := status.FromError(err); s.Code() != codes.NotFound {
+		t.Errorf("expected NotFound error, got %v", err)
+	}
+}
+
+func TestNodeAdminLabel(t *testing.T) {
+	ctx := context.Background()
+	node := idleNode("node-1")
+	node.Metadata = map[string]string{"pool": "cpu", "zone": "a"}
+	nodes := memNodes{"node-1": node}
+	a := &NodeAdmin{Nodes: nodes, Event: &eventRecorder{}}
+
+	_, err := a.LabelNode(ctx, &LabelNodeRequest{
+		Id:     "node-1",
+		Labels: map[string]string{"pool": "gpu", "gpu-type": "v100"},
+		Remove: []string{"zone"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"pool": "gpu", "gpu-type": "v100"}
+	if !reflect.DeepEqual(nodes["node-1"].Metadata, expected) {
+		t.Errorf("expected metadata %v, got %v", expected, nodes["node-1"].Metadata)
+	}
+}
+
+func TestNodeAdminEvict(t *testing.T) {
+	ctx := context.Background()
+	node := idleNode("node-1")
+	node.TaskIds = []string{"task-1", "task-2", "task-3"}
+	nodes := memNodes{"node-1": node}
+	rec := &eventRecorder{}
+	// task-2 was re-queued once, so it's on its second attempt.
+	tasks := memTasks{"task-2": {Id: "task-2", State: tes.Running, Logs: []*tes.TaskLog{{}, {}}}}
+	a := &NodeAdmin{Nodes: nodes, Event: rec, Tasks: tasks}
+
+	_, err := a.EvictNode(ctx, &EvictNodeRequest{Id: "node-1", TaskIds: []string{"task-4"}})
+	if s, _ := status.FromError(err); s.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+
+	_, err = a.EvictNode(ctx, &EvictNodeRequest{Id: "node-1", TaskIds: []string{"task-2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"task-1", "task-3"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected tasks %v, got %v", expected, nodes["node-1"].TaskIds)
+	}
+
+	var failed []string
+	for _, ev := range *rec {
+		if ev.Type == events.Type_TASK_STATE && ev.GetState() == tes.State_SYSTEM_ERROR {
+			failed = append(failed, ev.Id)
+		}
+	}
+	if !reflect.DeepEqual(failed, []string{"task-2"}) {
+		t.Errorf("expected task-2 to be marked SYSTEM_ERROR, got %v", failed)
+	}
+	for _, ev := range *rec {
+		if ev.Type == events.Type_SYSTEM_LOG && ev.Attempt != 1 {
+			t.Errorf("expected the eviction to be logged on the current attempt, got %d", ev.Attempt)
+		}
+	}
+
+	// Evict all remaining tasks.
+	_, err = a.EvictNode(ctx, &EvictNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes["node-1"].TaskIds) != 0 {
+		t.Errorf("expected no tasks, got %v", nodes["node-1"].TaskIds)
+	}
+}
@@ -0,0 +1,235 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// NodeProvider describes the interface the Autoscaler uses to start and stop
+// nodes, e.g. by creating VMs in a cloud provider.
+type NodeProvider interface {
+	// StartNodes requests that "count" new nodes be started. The new nodes are
+	// expected to run "funnel node run" and register with the server.
+	StartNodes(ctx context.Context, count int) error
+	// StopNode stops a node which has been drained by the autoscaler.
+	StopNode(ctx context.Context, node *Node) error
+}
+
+// NewNodeProvider returns the NodeProvider named by conf.Provider.
+func NewNodeProvider(conf config.Autoscaler, log *logger.Logger) (NodeProvider, error) {
+	switch conf.Provider {
+	case "command":
+		return &CommandProvider{Conf: conf.Command, Log: log}, nil
+	default:
+		return nil, fmt.Errorf("unknown autoscaler provider: '%s'", conf.Provider)
+	}
+}
+
+// Autoscaler watches the task queue and node pool, starting nodes when queued
+// tasks can't be scheduled and stopping nodes which have been idle.
+//
+// Nodes are stopped by first setting their state to DRAIN, which prevents new tasks
+// from being assigned. Once a drained node has no tasks left, it is stopped via
+// the NodeProvider and removed from the database.
+type Autoscaler struct {
+	Conf     config.Scheduler
+	Log      *logger.Logger
+	Nodes    SchedulerServiceServer
+	Queue    TaskQueue
+	Provider NodeProvider
+
+	// start times of nodes requested from the provider which haven't joined yet.
+	pending []time.Time
+	// IDs of nodes seen in the previous iteration.
+	known map[string]bool
+	// time at which each node was last seen running a task.
+	idleSince map[string]time.Time
+	// nodes drained by the autoscaler, by ID.
+	draining map[string]*Node
+}
+
+// Run starts the autoscaling loop. This blocks.
+func (a *Autoscaler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(a.Conf.Autoscaler.Rate))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := a.Scale(ctx)
+			if err != nil {
+				a.Log.Error("Error autoscaling nodes", "error", err)
+			}
+		}
+	}
+}
+
+// Scale does a single autoscaling iteration.
+func (a *Autoscaler) Scale(ctx context.Context) error {
+	if a.known == nil {
+		a.known = map[string]bool{}
+		a.idleSince = map[string]time.Time{}
+		a.draining = map[string]*Node{}
+	}
+	conf := a.Conf.Autoscaler
+	now := time.Now()
+
+	resp, err := a.Nodes.ListNodes(ctx, &ListNodesRequest{})
+	if err != nil {
+		return err
+	}
+	nodes := resp.Nodes
+
+	// Newly registered nodes fulfill the oldest pending requests.
+	// Pending requests which have timed out are forgotten.
+	known := map[string]bool{}
+	for _, n := range nodes {
+		known[n.Id] = true
+		if !a.known[n.Id] && len(a.pending) > 0 {
+			a.pending = a.pending[1:]
+		}
+	}
+	a.known = known
+
+	var pending []time.Time
+	for _, t := range a.pending {
+		if now.Sub(t) < time.Duration(conf.StartTimeout) {
+			pending = append(pending, t)
+		}
+	}
+	a.pending = pending
+
+	// Stop drained nodes which have finished their tasks. A drained node process
+	// exits once its tasks are done, so its record may already have been removed
+	// by the scheduler.
+	var active []*Node
+	for _, n := range nodes {
+		if _, ok := a.draining[n.Id]; ok {
+			a.draining[n.Id] = n
+			if len(n.TaskIds) == 0 && (n.State == NodeState_DRAIN || n.State == NodeState_GONE) {
+				a.stopNode(ctx, n, true)
+			}
+			continue
+		}
+		if n.State == NodeState_DEAD || n.State == NodeState_GONE || n.State == NodeState_DRAIN {
+			continue
+		}
+		active = append(active, n)
+	}
+	for id, n := range a.draining {
+		if !known[id] {
+			a.stopNode(ctx, n, false)
+		}
+	}
+
+	// Count queued tasks which don't fit on any active node.
+	unschedulable := 0
+	queued, _ := a.Queue.ReadQueue(a.Conf.ScheduleChunk, "")
+	for _, task := range queued {
+		if task != nil && !fitsAny(task, active) {
+			unschedulable++
+		}
+	}
+
+	// Scale up. Each pending node is expected to take one unschedulable task.
+	count := len(active) + len(a.pending)
+	need := unschedulable - len(a.pending)
+	if min := conf.MinNodes - count; min > need {
+		need = min
+	}
+	if conf.MaxNodes > 0 && count+need > conf.MaxNodes {
+		need = conf.MaxNodes - count
+	}
+	if conf.MaxScaleUp > 0 && need > conf.MaxScaleUp {
+		need = conf.MaxScaleUp
+	}
+	if need > 0 {
+		a.Log.Info("Starting nodes",
+			"count", need,
+			"unschedulable", unschedulable,
+			"pending", len(a.pending),
+			"active", len(active),
+		)
+		err := a.Provider.StartNodes(ctx, need)
+		if err != nil {
+			return fmt.Errorf("starting nodes: %v", err)
+		}
+		for i := 0; i < need; i++ {
+			a.pending = append(a.pending, now)
+		}
+		return nil
+	}
+
+	// Scale down. Only drain nodes when there is no unschedulable work.
+	if unschedulable > 0 {
+		return nil
+	}
+	remaining := len(active)
+	for _, n := range active {
+		if len(n.TaskIds) > 0 || n.State != NodeState_ALIVE {
+			a.idleSince[n.Id] = now
+			continue
+		}
+		since, ok := a.idleSince[n.Id]
+		if !ok {
+			a.idleSince[n.Id] = now
+			continue
+		}
+		if now.Sub(since) < time.Duration(conf.IdleTimeout) || remaining <= conf.MinNodes {
+			continue
+		}
+
+		a.Log.Info("Draining idle node", "nodeID", n.Id, "idle", now.Sub(since))
+		n.State = NodeState_DRAIN
+		_, err := a.Nodes.PutNode(ctx, n)
+		if err != nil {
+			a.Log.Error("Error draining node", "nodeID", n.Id, "error", err)
+			continue
+		}
+		a.draining[n.Id] = n
+		delete(a.idleSince, n.Id)
+		remaining--
+	}
+	for id := range a.idleSince {
+		if !known[id] {
+			delete(a.idleSince, id)
+		}
+	}
+	return nil
+}
+
+// stopNode stops a drained node via the provider and, optionally,
+// deletes the node record from the database.
+func (a *Autoscaler) stopNode(ctx context.Context, n *Node, del bool) {
+	a.Log.Info("Stopping drained node", "nodeID", n.Id)
+	err := a.Provider.StopNode(ctx, n)
+	if err != nil {
+		a.Log.Error("Error stopping node", "nodeID", n.Id, "error", err)
+		return
+	}
+	delete(a.draining, n.Id)
+	if !del {
+		return
+	}
+	_, err = a.Nodes.DeleteNode(ctx, n)
+	if err != nil {
+		a.Log.Error("Error deleting stopped node", "nodeID", n.Id, "error", err)
+	}
+}
+
+// fitsAny returns true if the task fits on at least one of the nodes.
+func fitsAny(task *tes.Task, nodes []*Node) bool {
+	for _, n := range nodes {
+		if Match(n, task, DefaultPredicates) {
+			return true
+		}
+	}
+	return false
+}
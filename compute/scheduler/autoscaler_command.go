@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
+)
+
+// CommandProvider is a NodeProvider which starts and stops nodes by running
+// user-defined shell commands, so that autoscaling works with any cloud or VM pool.
+//
+// The start command is run with FUNNEL_NODE_COUNT set in the environment.
+// The stop command is run with FUNNEL_NODE_ID, FUNNEL_NODE_HOSTNAME and
+// FUNNEL_NODE_ZONE set, along with the node metadata as FUNNEL_NODE_META_<key>.
+type CommandProvider struct {
+	Conf config.AutoscaleCommand
+	Log  *logger.Logger
+}
+
+// StartNodes runs the configured start command.
+func (c *CommandProvider) StartNodes(ctx context.Context, count int) error {
+	return c.run(ctx, c.Conf.Start, []string{
+		"FUNNEL_NODE_COUNT=" + strconv.Itoa(count),
+	})
+}
+
+// StopNode runs the configured stop command.
+func (c *CommandProvider) StopNode(ctx context.Context, node *Node) error {
+	env := []string{
+		"FUNNEL_NODE_ID=" + node.Id,
+		"FUNNEL_NODE_HOSTNAME=" + node.Hostname,
+		"FUNNEL_NODE_ZONE=" + node.Zone,
+	}
+	for k, v := range node.Metadata {
+		env = append(env, "FUNNEL_NODE_META_"+k+"="+v)
+	}
+	return c.run(ctx, c.Conf.Stop, env)
+}
+
+func (c *CommandProvider) run(ctx context.Context, command string, env []string) error {
+	if command == "" {
+		return fmt.Errorf("autoscaler command is not configured")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	c.Log.Debug("Ran autoscaler command",
+		"command", command,
+		"env", env,
+		"stdout", stdout.String(),
+		"stderr", stderr.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("command %q failed: %v: %s", command, err, stderr.String())
+	}
+	return nil
+}
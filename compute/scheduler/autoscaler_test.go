@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// memNodes is a simple in-memory SchedulerServiceServer.
+type memNodes map[string]*Node
+
+func (m memNodes) PutNode(ctx context.Context, n *Node) (*PutNodeResponse, error) {
+	m[n.Id] = n
+	return &PutNodeResponse{}, nil
+}
+
+func (m memNodes) DeleteNode(ctx context.Context, n *Node) (*DeleteNodeResponse, error) {
+	delete(m, n.Id)
+	return &DeleteNodeResponse{}, nil
+}
+
+func (m memNodes) ListNodes(ctx context.Context, req *ListNodesRequest) (*ListNodesResponse, error) {
+	resp := &ListNodesResponse{}
+	for _, n := range m {
+		resp.Nodes = append(resp.Nodes, n)
+	}
+	return resp, nil
+}
+
+func (m memNodes) GetNode(ctx context.Context, req *GetNodeRequest) (*Node, error) {
+	return m[req.Id], nil
+}
+
+type memQueue []*tes.Task
+
+func (q memQueue) ReadQueue(count int, after string) ([]*tes.Task, string) {
+	start := 0
+	if after != "" {
+		for i, t := range q {
+			if t.Id == after {
+				start = i + 1
+			}
+		}
+	}
+	end := start + count
+	if end > len(q) {
+		end = len(q)
+	}
+	if start == end {
+		return nil, ""
+	}
+	return q[start:end], q[end-1].Id
+}
+
+type testProvider struct {
+	started int
+	stopped []string
+}
+
+func (p *testProvider) StartNodes(ctx context.Context, count int) error {
+	p.started += count
+	return nil
+}
+
+func (p *testProvider) StopNode(ctx context.Context, n *Node) error {
+	p.stopped = append(p.stopped, n.Id)
+	return nil
+}
+
+func newTestAutoscaler(nodes memNodes, queue memQueue) (*Autoscaler, *testProvider) {
+	conf := config.DefaultConfig().Scheduler
+	conf.Autoscaler.MaxNodes = 3
+	conf.Autoscaler.IdleTimeout = 0
+	p := &testProvider{}
+	return &Autoscaler{
+		Conf:     conf,
+		Log:      logger.NewLogger("test-autoscaler", logger.DebugConfig()),
+		Nodes:    nodes,
+		Queue:    queue,
+		Provider: p,
+	}, p
+}
+
+func idleNode(id string) *Node {
+	res := &Resources{Cpus: 1, RamGb: 1, DiskGb: 10}
+	return &Node{Id: id, State: NodeState_ALIVE, Resources: res, Available: res}
+}
+
+func TestAutoscalerScaleUp(t *testing.T) {
+	ctx := context.Background()
+	queue := memQueue{
+		{Id: "task-1", Resources: &tes.Resources{CpuCores: 1}},
+		{Id: "task-2", Resources: &tes.Resources{CpuCores: 1}},
+		{Id: "task-3", Resources: &tes.Resources{CpuCores: 1}},
+		{Id: "task-4", Resources: &tes.Resources{CpuCores: 1}},
+		{Id: "task-5", Resources: &tes.Resources{CpuCores: 1}},
+	}
+	nodes := memNodes{}
+	a, p := newTestAutoscaler(nodes, queue)
+
+	if err := a.Scale(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Limited by MaxNodes.
+	if p.started != 3 {
+		t.Errorf("expected 3 nodes started, got %d", p.started)
+	}
+
+	// Pending nodes count toward MaxNodes.
+	if err := a.Scale(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if p.started != 3 {
+		t.Errorf("expected no more nodes started, got %d", p.started)
+	}
+
+	// Pending requests are forgotten after the start timeout.
+	a.Conf.Autoscaler.StartTimeout = 0
+	if err := a.Scale(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if p.started != 6 {
+		t.Errorf("expected 3 more nodes started, got %d", p.started)
+	}
+}
+
+func TestAutoscalerNoScaleUpWhenTasksFit(t *testing.T) {
+	ctx := context.Background()
+	queue := memQueue{
+		{Id: "task-1", Resources: &tes.Resources{CpuCores: 1}},
+	}
+	nodes := memNodes{"node-1": idleNode("node-1")}
+	a, p := newTestAutoscaler(nodes, queue)
+
+	if err := a.Scale(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if p.started != 0 {
+		t.Errorf("expected no nodes started, got %d", p.started)
+	}
+	if nodes["node-1"].State != NodeState_ALIVE {
+		t.Error("expected node to stay alive while the queue has work")
+	}
+}
+
+func TestAutoscalerScaleDown(t *testing.T) {
+	ctx := context.Background()
+	nodes := memNodes{
+		"node-1": idleNode("node-1"),
+		"node-2": idleNode("node-2"),
+	}
+	nodes["node-2"].TaskIds = []string{"task-1"}
+	a, p := newTestAutoscaler(nodes, memQueue{})
+	a.Conf.Autoscaler.MinNodes = 0
+
+	// First iteration only records idle times.
+	if err := a.Scale(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Scale(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if nodes["node-1"].State != NodeState_DRAIN {
+		t.Error("expected idle node to be drained")
+	}
+	if nodes["node-2"].State != NodeState_ALIVE {
+		t.Error("expected busy node to stay alive")
+	}
+
+	// The drained node finishes and is stopped.
+	if err := a.Scale(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.stopped) != 1 || p.stopped[0] != "node-1" {
+		t.Errorf("expected node-1 to be stopped, got %v", p.stopped)
+	}
+	if _, ok := nodes["node-1"]; ok {
+		t.Error("expected stopped node to be deleted")
+	}
+}
+
+func TestAutoscalerStopsRemovedNode(t *testing.T) {
+	ctx := context.Background()
+	nodes := memNodes{"node-1": idleNode("node-1")}
+	a, p := newTestAutoscaler(nodes, memQueue{})
+
+	a.Scale(ctx)
+	a.Scale(ctx)
+	if nodes["node-1"].State != NodeState_DRAIN {
+		t.Fatal("expected idle node to be drained")
+	}
+
+	// The node process exits and the scheduler removes the node record
+	// before the autoscaler sees it again.
+	delete(nodes, "node-1")
+	a.Scale(ctx)
+	if len(p.stopped) != 1 || p.stopped[0] != "node-1" {
+		t.Errorf("expected node-1 to be stopped, got %v", p.stopped)
+	}
+}
+
+func TestAutoscalerMinNodes(t *testing.T) {
+	ctx := context.Background()
+	nodes := memNodes{"node-1": idleNode("node-1")}
+	a, p := newTestAutoscaler(nodes, memQueue{})
+	a.Conf.Autoscaler.MinNodes = 2
+
+	a.Scale(ctx)
+	if p.started != 1 {
+		t.Errorf("expected 1 node started to reach MinNodes, got %d", p.started)
+	}
+
+	a.Conf.Autoscaler.StartTimeout = config.Duration(time.Minute)
+	a.Scale(ctx)
+	a.Scale(ctx)
+	if nodes["node-1"].State != NodeState_ALIVE {
+		t.Error("expected node to stay alive at MinNodes")
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	ctx := context.Background()
+	tmp, err := ioutil.TempDir("", "funnel-test-autoscaler-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := tmp + "/out"
+	c := &CommandProvider{
+		Conf: config.AutoscaleCommand{
+			Start: "echo start $FUNNEL_NODE_COUNT >> " + out,
+			Stop:  "echo stop $FUNNEL_NODE_ID $FUNNEL_NODE_META_pool >> " + out,
+		},
+		Log: logger.NewLogger("test-autoscaler", logger.DebugConfig()),
+	}
+
+	if err := c.StartNodes(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StopNode(ctx, &Node{Id: "node-1", Metadata: map[string]string{"pool": "gpu"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "start 2\nstop node-1 gpu\n" {
+		t.Errorf("unexpected command output: %q", string(b))
+	}
+
+	c.Conf.Start = "exit 1"
+	if err := c.StartNodes(ctx, 1); err == nil {
+		t.Error("expected error from failing command")
+	}
+}
@@ -0,0 +1,288 @@
+package scheduler
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+)
+
+// assignedTask is what the scheduler remembers about a task assigned to a node.
+type assignedTask struct {
+	node      string
+	group     string
+	name      string
+	resources *tes.Resources
+	custom    map[string]float64
+	// expected runtime of the task.
+	runtime time.Duration
+	// time the task was assigned. This is zero if the task was assigned
+	// before the scheduler started, in which case it isn't used for
+	// runtime history.
+	assigned time.Time
+}
+
+// end returns the expected end time of the task.
+func (a *assignedTask) end(now time.Time) time.Time {
+	if a.assigned.IsZero() {
+		return now.Add(a.runtime)
+	}
+	end := a.assigned.Add(a.runtime)
+	// The task is overdue, expect it to end any time now.
+	if end.Before(now) {
+		return now
+	}
+	return end
+}
+
+// runtimeHistory tracks the average runtime of tasks by name.
+type runtimeHistory struct {
+	count int
+	mean  time.Duration
+}
+
+// ExpectedRuntime returns how long the task is expected to run, read from the tag
+// named by conf.RuntimeTag as a duration (e.g. "1h30m") or a number of seconds.
+// Zero is returned if the tag is missing or invalid.
+func ExpectedRuntime(t *tes.Task, conf config.Scheduler) time.Duration {
+	if conf.RuntimeTag == "" {
+		return 0
+	}
+	v := t.GetTags()[conf.RuntimeTag]
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	return 0
+}
+
+// expectedRuntime returns how long the task is expected to run, based on
+// the task tags, then the average runtime of tasks with the same name,
+// then the configured default.
+func (s *Scheduler) expectedRuntime(t *tes.Task) time.Duration {
+	if d := ExpectedRuntime(t, s.Conf); d > 0 {
+		return d
+	}
+	if h, ok := s.history[t.GetName()]; ok && t.GetName() != "" {
+		return h.mean
+	}
+	return time.Duration(s.Conf.DefaultRuntime)
+}
+
+// trackTask records a task assigned to a node.
+func (s *Scheduler) trackTask(t *tes.Task, nodeID string, assigned time.Time) {
+	if s.assigned == nil {
+		s.assigned = map[string]*assignedTask{}
+	}
+	custom, _ := tes.CustomResources(t)
+	s.assigned[t.Id] = &assignedTask{
+		node:      nodeID,
+		group:     TaskGroup(t, s.Conf),
+		name:      t.GetName(),
+		resources: t.GetResources(),
+		custom:    custom,
+		runtime:   s.expectedRuntime(t),
+		assigned:  assigned,
+	}
+}
+
+// syncAssigned updates the tasks tracked by the scheduler from the tasks
+// assigned to the nodes, and returns the number of assigned tasks by
+// fair-share group.
+//
+// Unknown tasks, e.g. those assigned before the scheduler started, are looked up
+// with s.Tasks, if set. Tasks which are no longer assigned to a node have finished,
+// and their runtime is added to the runtime history, and their outcome to the
+// node's recent outcomes used for quarantine.
+func (s *Scheduler) syncAssigned(ctx context.Context, nodes []*Node) map[string]int {
+	if s.assigned == nil {
+		s.assigned = map[string]*assignedTask{}
+	}
+	if s.history == nil {
+		s.history = map[string]*runtimeHistory{}
+	}
+
+	usage := map[string]int{}
+	active := map[string]bool{}
+	for _, n := range nodes {
+		for _, id := range n.TaskIds {
+			active[id] = true
+			a, ok := s.assigned[id]
+			if !ok {
+				if s.Tasks == nil {
+					// Without task details, count the task in the "" group.
+					if s.Conf.FairShareTag == "" {
+						usage[""]++
+					}
+					continue
+				}
+				task, err := s.Tasks.GetTask(ctx, &tes.GetTaskRequest{Id: id, View: tes.TaskView_BASIC})
+				if err != nil {
+					continue
+				}
+				s.trackTask(task, n.Id, time.Time{})
+				a = s.assigned[id]
+			}
+			usage[a.group]++
+		}
+	}
+
+	now := time.Now()
+	for id, a := range s.assigned {
+		if active[id] {
+			continue
+		}
+		delete(s.assigned, id)
+		s.recordOutcome(ctx, a.node, id)
+		if a.assigned.IsZero() || a.name == "" {
+			continue
+		}
+		h, ok := s.history[a.name]
+		if !ok {
+			h = &runtimeHistory{}
+			s.history[a.name] = h
+		}
+		h.count++
+		h.mean += (now.Sub(a.assigned) - h.mean) / time.Duration(h.count)
+	}
+	return usage
+}
+
+// reservation holds a node for a task which doesn't currently fit any node,
+// so that smaller tasks don't keep taking the resources it needs.
+type reservation struct {
+	task *tes.Task
+	node *Node
+	// expected time at which enough resources will be free to start the task.
+	start time.Time
+	// expected free resources on the node at the start time.
+	free *Resources
+}
+
+// reserve creates a reservation for the task on the node where it is expected
+// to start the soonest. Nil is returned if the task doesn't fit any node,
+// even when the node is empty.
+func (s *Scheduler) reserve(t *tes.Task, nodes []*Node, now time.Time) *reservation {
+	var best *reservation
+
+	for _, n := range nodes {
+		// Check whether the task would fit the node if it was empty.
+		empty := *n
+		empty.Available = n.Resources
+		if !Match(&empty, t, s.Policy.Predicates) {
+			continue
+		}
+
+		// Free resources as the tasks on the node are expected to end,
+		// until the task fits.
+		var running []*assignedTask
+		for _, id := range n.TaskIds {
+			if a, ok := s.assigned[id]; ok {
+				running = append(running, a)
+			}
+		}
+		sort.Slice(running, func(i, j int) bool {
+			return running[i].end(now).Before(running[j].end(now))
+		})
+
+		start := now
+		free := copyResources(n.Available)
+		for _, a := range running {
+			if resourcesFit(t, free) {
+				break
+			}
+			start = a.end(now)
+			free = addResources(free, a, n.Resources)
+		}
+		if !resourcesFit(t, free) {
+			// Tasks on the node are unknown to the scheduler.
+			continue
+		}
+
+		if best == nil || start.Before(best.start) {
+			best = &reservation{task: t, node: n, start: start, free: free}
+		}
+	}
+	return best
+}
+
+// allows returns true if a task, expected to run for the given duration,
+// can be backfilled onto the reserved node without delaying the reserved task.
+// If so, the reservation is updated to account for the task.
+func (r *reservation) allows(t *tes.Task, runtime time.Duration, now time.Time) bool {
+	// The task is expected to finish before the reserved task starts.
+	if runtime > 0 && !now.Add(runtime).After(r.start) {
+		return true
+	}
+	// The task leaves enough resources for the reserved task.
+	free := SubtractResources(t, r.free)
+	if resourcesFit(t, r.free) && resourcesFit(r.task, free) {
+		r.free = free
+		return true
+	}
+	return false
+}
+
+// resourcesFit returns true if the resources requested by the task fit in "r".
+func resourcesFit(t *tes.Task, r *Resources) bool {
+	req := t.GetResources()
+	if r.GetCpus() < req.GetCpuCores() ||
+		r.GetRamGb() < req.GetRamGb() ||
+		r.GetDiskGb() < req.GetDiskGb() {
+		return false
+	}
+	custom, _ := tes.CustomResources(t)
+	for name, amount := range custom {
+		if r.GetCustom()[name] < amount {
+			return false
+		}
+	}
+	return true
+}
+
+// addResources adds the resources of the assigned task "a" to "r", up to the node total.
+func addResources(r *Resources, a *assignedTask, total *Resources) *Resources {
+	req := a.resources
+	out := &Resources{
+		Cpus:   r.GetCpus() + req.GetCpuCores(),
+		RamGb:  r.GetRamGb() + req.GetRamGb(),
+		DiskGb: r.GetDiskGb() + req.GetDiskGb(),
+		Custom: copyCustom(r.GetCustom()),
+	}
+	for name, amount := range a.custom {
+		if _, ok := total.GetCustom()[name]; !ok {
+			continue
+		}
+		if out.Custom == nil {
+			out.Custom = map[string]float64{}
+		}
+		out.Custom[name] += amount
+		if out.Custom[name] > total.GetCustom()[name] {
+			out.Custom[name] = total.GetCustom()[name]
+		}
+	}
+	if out.Cpus > total.GetCpus() {
+		out.Cpus = total.GetCpus()
+	}
+	if out.RamGb > total.GetRamGb() {
+		out.RamGb = total.GetRamGb()
+	}
+	if out.DiskGb > total.GetDiskGb() {
+		out.DiskGb = total.GetDiskGb()
+	}
+	return out
+}
+
+func copyResources(r *Resources) *Resources {
+	return &Resources{
+		Cpus:   r.GetCpus(),
+		RamGb:  r.GetRamGb(),
+		DiskGb: r.GetDiskGb(),
+		Custom: copyCustom(r.GetCustom()),
+	}
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+func TestExpectedRuntime(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	s := &Scheduler{Conf: conf}
+
+	tagged := func(v string) *tes.Task {
+		return &tes.Task{Tags: map[string]string{"expected-runtime": v}}
+	}
+	if d := s.expectedRuntime(tagged("1h30m")); d != 90*time.Minute {
+		t.Error("unexpected runtime from duration tag", d)
+	}
+	if d := s.expectedRuntime(tagged("60")); d != time.Minute {
+		t.Error("unexpected runtime from seconds tag", d)
+	}
+	if d := s.expectedRuntime(tagged("bad")); d != time.Hour {
+		t.Error("expected default runtime", d)
+	}
+
+	// Runtime history is learned from tasks which leave the nodes.
+	start := time.Now().Add(-10 * time.Minute)
+	s.trackTask(&tes.Task{Id: "t1", Name: "align"}, "node-1", start)
+	s.trackTask(&tes.Task{Id: "t2", Name: "align"}, "node-1", start.Add(-10*time.Minute))
+	s.syncAssigned(context.Background(), nil)
+
+	d := s.expectedRuntime(&tes.Task{Name: "align"})
+	if d < 14*time.Minute || d > 16*time.Minute {
+		t.Error("expected average runtime of about 15m", d)
+	}
+}
+
+// newBackfillScheduler returns a scheduler with one 8 CPU node running
+// a 6 CPU task, which is expected to end in 1 hour.
+func newBackfillScheduler(queue memQueue) (*Scheduler, memNodes) {
+	conf := config.DefaultConfig().Scheduler
+	conf.Reservations = true
+
+	nodes := memNodes{
+		"node-1": {
+			Id:        "node-1",
+			State:     NodeState_ALIVE,
+			Resources: &Resources{Cpus: 8, RamGb: 100, DiskGb: 100},
+			Available: &Resources{Cpus: 2, RamGb: 100, DiskGb: 100},
+			TaskIds:   []string{"running"},
+		},
+	}
+	s := &Scheduler{
+		Conf:  conf,
+		Nodes: nodes,
+		Queue: queue,
+		Event: events.Noop{},
+	}
+	s.trackTask(&tes.Task{
+		Id:        "running",
+		Resources: &tes.Resources{CpuCores: 6},
+		Tags:      map[string]string{"expected-runtime": "1h"},
+	}, "node-1", time.Now())
+	return s, nodes
+}
+
+func cpuTask(id string, cpus uint32, runtime string) *tes.Task {
+	return &tes.Task{
+		Id:        id,
+		Resources: &tes.Resources{CpuCores: cpus},
+		Tags:      map[string]string{"expected-runtime": runtime},
+	}
+}
+
+func TestBackfillShortTask(t *testing.T) {
+	s, nodes := newBackfillScheduler(memQueue{
+		cpuTask("large", 8, "1h"),
+		cpuTask("long", 1, "2h"),
+		cpuTask("short", 1, "10m"),
+	})
+
+	err := s.Schedule(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The long task would delay the large task, but the short one won't.
+	expected := []string{"running", "short"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected %v assigned, got %v", expected, nodes["node-1"].TaskIds)
+	}
+}
+
+func TestBackfillWithoutReservations(t *testing.T) {
+	s, nodes := newBackfillScheduler(memQueue{
+		cpuTask("large", 8, "1h"),
+		cpuTask("long", 1, "2h"),
+	})
+	s.Conf.Reservations = false
+
+	err := s.Schedule(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"running", "long"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected %v assigned, got %v", expected, nodes["node-1"].TaskIds)
+	}
+}
+
+func TestBackfillFreeResources(t *testing.T) {
+	s, nodes := newBackfillScheduler(memQueue{
+		cpuTask("medium", 4, "1h"),
+		cpuTask("long-1", 2, "2h"),
+		cpuTask("long-2", 2, "2h"),
+		cpuTask("long-3", 1, "2h"),
+	})
+
+	err := s.Schedule(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After the running task ends there are 8 CPUs free, so 4 CPUs of long tasks
+	// can run alongside the medium task, but no more.
+	expected := []string{"running", "long-1", "long-2"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected %v assigned, got %v", expected, nodes["node-1"].TaskIds)
+	}
+}
+
+func TestReserveImpossibleTask(t *testing.T) {
+	s, nodes := newBackfillScheduler(memQueue{
+		cpuTask("huge", 16, "1h"),
+		cpuTask("long", 1, "2h"),
+	})
+
+	err := s.Schedule(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The huge task can never fit, so it doesn't reserve the node.
+	expected := []string{"running", "long"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected %v assigned, got %v", expected, nodes["node-1"].TaskIds)
+	}
+}
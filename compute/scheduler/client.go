@@ -9,16 +9,18 @@ import (
 	"google.golang.org/grpc"
 )
 
-// Client is a client for the scheduler and event gRPC services.
+// Client is a client for the scheduler, node session and event gRPC services.
 type Client interface {
 	events.EventServiceClient
 	SchedulerServiceClient
+	NodeSessionServiceClient
 	Close()
 }
 
 type client struct {
 	events.EventServiceClient
 	SchedulerServiceClient
+	NodeSessionServiceClient
 	conn *grpc.ClientConn
 }
 
@@ -32,7 +34,8 @@ func NewClient(ctx context.Context, conf config.Server) (Client, error) {
 	}
 	e := events.NewEventServiceClient(conn)
 	s := NewSchedulerServiceClient(conn)
-	return &client{e, s, conn}, nil
+	n := NewNodeSessionServiceClient(conn)
+	return &client{e, s, n, conn}, nil
 }
 
 // Close closes the client connection.
@@ -1,6 +1,9 @@
 package scheduler
 
 import (
+	"fmt"
+
+	"github.com/ohsu-comp-bio/funnel/config"
 	"github.com/ohsu-comp-bio/funnel/tes"
 )
 
@@ -8,26 +11,110 @@ import (
 // the whether a task fits a node.
 var DefaultPredicates = []Predicate{
 	ResourcesFit,
+	PreemptibleFit,
+	CustomResourcesFit,
 	ZonesFit,
 	NotDead,
 	Alive,
+	Healthy,
 }
 
+// Scheduling policies
+const (
+	BinPack     = "binpack"
+	Spread      = "spread"
+	LeastLoaded = "least-loaded"
+)
+
 // DefaultScheduleAlgorithm implements a simple scheduling algorithm
 // that is (currently) common across a few scheduler backends.
 // Given a task, list of nodes, and weights, it returns the best Offer or nil.
 func DefaultScheduleAlgorithm(j *tes.Task, nodes []*Node, weights map[string]float32) *Offer {
+	if weights == nil {
+		weights = map[string]float32{CPU: 1, RAM: 1, DISK: 1, TASKS: 1, PREEMPTIBLE: 1}
+	}
+	p := &Policy{
+		Name:       BinPack,
+		Predicates: DefaultPredicates,
+		Score:      DefaultScores,
+		Weights:    weights,
+	}
+	offer, _ := p.Schedule(j, nodes)
+	return offer
+}
+
+// Policy describes how the scheduler picks a node for a task:
+// nodes are filtered by the Predicates, then ranked by the weighted Score.
+type Policy struct {
+	Name       string
+	Predicates []Predicate
+	Score      ScoreFunc
+	Weights    map[string]float32
+}
+
+// NewPolicy returns the scheduling policy described by the config.
+func NewPolicy(conf config.Scheduler) (*Policy, error) {
+	// If no weights are configured, weight every score equally.
+	w := conf.Weights
+	if w == (config.SchedulerWeights{}) {
+		w = config.SchedulerWeights{CPU: 1, RAM: 1, Disk: 1, Tasks: 1, Preemptible: 1}
+	}
 
+	p := &Policy{
+		Name: conf.Policy,
+		Weights: map[string]float32{
+			CPU:         w.CPU,
+			RAM:         w.RAM,
+			DISK:        w.Disk,
+			TASKS:       w.Tasks,
+			PREEMPTIBLE: w.Preemptible,
+		},
+	}
+
+	switch conf.Policy {
+	case BinPack, "":
+		p.Name = BinPack
+		p.Score = BinPackScores
+	case Spread:
+		p.Score = SpreadScores
+	case LeastLoaded:
+		p.Score = LeastLoadedScores
+	default:
+		return nil, fmt.Errorf("unknown scheduler policy: '%s'", conf.Policy)
+	}
+
+	p.Predicates = append(p.Predicates, DefaultPredicates...)
+	for _, pred := range conf.Predicates {
+		if pred.TaskTag == "" {
+			return nil, fmt.Errorf("scheduler predicate is missing TaskTag")
+		}
+		key := pred.NodeMetadata
+		if key == "" {
+			key = pred.TaskTag
+		}
+		p.Predicates = append(p.Predicates, TagMatchesMetadata(pred.TaskTag, key, pred.Exclusive))
+	}
+	return p, nil
+}
+
+// Schedule returns the best offer for the task, or nil if no node fits.
+// It also returns the reasons each rejected node didn't fit, keyed by node ID.
+func (p *Policy) Schedule(j *tes.Task, nodes []*Node) (*Offer, map[string][]string) {
 	offers := []*Offer{}
+	rejected := map[string][]string{}
 	for _, n := range nodes {
 		// Filter out nodes that don't match the task request.
 		// Checks CPU, RAM, disk space, etc.
-		if !Match(n, j, DefaultPredicates) {
+		if errs := MatchErrors(n, j, p.Predicates); len(errs) > 0 {
+			rejected[n.Id] = errs
 			continue
 		}
 
-		sc := DefaultScores(n, j)
-		sc = sc.Weighted(weights)
+		sc := p.Score(n, j)
+		for k, v := range PreemptibleScores(n, j) {
+			sc[k] = v
+		}
+		sc = sc.Weighted(p.Weights)
 
 		offer := NewOffer(n, j, sc)
 		offers = append(offers, offer)
@@ -35,9 +122,9 @@ func DefaultScheduleAlgorithm(j *tes.Task, nodes []*Node, weights map[string]flo
 
 	// No matching nodes were found.
 	if len(offers) == 0 {
-		return nil
+		return nil, rejected
 	}
 
 	SortByAverageScore(offers)
-	return offers[0]
+	return offers[0], rejected
 }
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"time"
+
+	psdisk "github.com/shirou/gopsutil/disk"
+)
+
+// checkHealth runs the node's health checks and returns the errors of the
+// checks which failed. The checks run at most once every HealthCheck.Rate,
+// otherwise the previous result is returned.
+func (n *NodeProcess) checkHealth(ctx context.Context) []string {
+	conf := n.conf.Node.HealthCheck
+	if !n.healthChecked.IsZero() && time.Since(n.healthChecked) < time.Duration(conf.Rate) {
+		return n.healthErrors
+	}
+	n.healthChecked = time.Now()
+
+	var errs []string
+	if conf.Command != "" {
+		out, err := n.runHealthCheck(ctx, "sh", "-c", conf.Command)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("health check command failed: %s: %s", err, out))
+		}
+	}
+
+	if conf.MinFreeDiskGb > 0 {
+		usage, err := psdisk.Usage(n.conf.Worker.WorkDir)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("error checking free disk: %s", err))
+		} else if free := float64(usage.Free) / math.Pow(1000, 3); free < conf.MinFreeDiskGb {
+			errs = append(errs, fmt.Sprintf(
+				"free disk %.1f GB is below the minimum %.1f GB", free, conf.MinFreeDiskGb))
+		}
+	}
+
+	if conf.Docker {
+		out, err := n.runHealthCheck(ctx, "docker", "info")
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("docker daemon isn't responding: %s: %s", err, out))
+		}
+	}
+
+	switch {
+	case len(errs) > 0:
+		n.log.Error("Node is unhealthy", "errors", errs)
+	case len(n.healthErrors) > 0:
+		n.log.Info("Node is healthy again")
+	}
+	n.healthErrors = errs
+	return errs
+}
+
+// runHealthCheck runs a health check command with the configured timeout,
+// and returns the end of its output.
+func (n *NodeProcess) runHealthCheck(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(n.conf.Node.HealthCheck.Timeout))
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", time.Duration(n.conf.Node.HealthCheck.Timeout))
+	}
+
+	const max = 200
+	s := strings.TrimSpace(string(out))
+	if len(s) > max {
+		s = s[len(s)-max:]
+	}
+	return s, err
+}
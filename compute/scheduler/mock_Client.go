@@ -106,6 +106,66 @@ func (_m *MockClient) ListNodes(ctx context.Context, in *ListNodesRequest, opts
 	return r0, r1
 }
 
+// ListTaskEvents provides a mock function with given fields: ctx, in, opts
+func (_m *MockClient) ListTaskEvents(ctx context.Context, in *events.ListTaskEventsRequest, opts ...grpc.CallOption) (*events.ListTaskEventsResponse, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *events.ListTaskEventsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *events.ListTaskEventsRequest, ...grpc.CallOption) *events.ListTaskEventsResponse); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*events.ListTaskEventsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *events.ListTaskEventsRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NodeSession provides a mock function with given fields: ctx, opts
+func (_m *MockClient) NodeSession(ctx context.Context, opts ...grpc.CallOption) (NodeSessionService_NodeSessionClient, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 NodeSessionService_NodeSessionClient
+	if rf, ok := ret.Get(0).(func(context.Context, ...grpc.CallOption) NodeSessionService_NodeSessionClient); ok {
+		r0 = rf(ctx, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(NodeSessionService_NodeSessionClient)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PutNode provides a mock function with given fields: ctx, in, opts
 func (_m *MockClient) PutNode(ctx context.Context, in *Node, opts ...grpc.CallOption) (*PutNodeResponse, error) {
 	_va := make([]interface{}, len(opts))
@@ -61,18 +61,33 @@ type NodeProcess struct {
 	timeout   util.IdleTimeout
 	state     NodeState
 	drained   chan struct{}
+	// true while the node is shutting down, in which case it doesn't start new tasks.
+	stopping bool
+
+	// time of the last health check, and its errors.
+	healthChecked time.Time
+	healthErrors  []string
+	// time of the last preemption notice check, and of the notice.
+	preemptionChecked time.Time
+	preempted         time.Time
 }
 
 // Run runs a node with the given config. This is responsible for communication
 // with the server and starting task workers
 func (n *NodeProcess) Run(ctx context.Context) {
+	// Workers run with "wctx", which isn't canceled until the shutdown
+	// grace period is over, so that running tasks get a chance to finish.
+	wctx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	n.log.Info("Starting node")
 	n.state = NodeState_ALIVE
 	n.checkConnection(ctx)
-	n.sync(ctx)
+	n.sync(wctx)
+	go n.stream(ctx, wctx)
 
 	ticker := time.NewTicker(time.Duration(n.conf.Node.UpdateRate))
 	defer ticker.Stop()
@@ -87,8 +102,10 @@ func (n *NodeProcess) Run(ctx context.Context) {
 
 		case <-ctx.Done():
 			n.timeout.Stop()
+			n.shutdown(stopWorkers)
 
 			// The node gets 10 seconds to do a final sync with the scheduler.
+			// Tasks which are still assigned to the node are reported as abandoned.
 			stopCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 			defer cancel()
 
@@ -96,19 +113,42 @@ func (n *NodeProcess) Run(ctx context.Context) {
 			n.sync(stopCtx)
 			// close grpc client connection
 			n.client.Close()
-
-			// The workers get 10 seconds to finish up.
-			n.workers.Wait(time.Second * 10)
 			return
 
 		case <-ticker.C:
-			n.sync(ctx)
+			n.sync(wctx)
 			n.checkIdleTimer()
 			n.checkDrain()
 		}
 	}
 }
 
+// shutdown gives running tasks config.Node.ShutdownGracePeriod to finish.
+// Meanwhile, the node drains, so that it isn't assigned more tasks.
+// Then the remaining workers are stopped, and get 10 seconds to clean up.
+func (n *NodeProcess) shutdown(stopWorkers context.CancelFunc) {
+	n.stopping = true
+	defer stopWorkers()
+
+	grace := time.Duration(n.conf.Node.ShutdownGracePeriod)
+	if n.workers.Count() == 0 || grace <= 0 {
+		return
+	}
+
+	n.log.Info("Node shutting down, waiting for tasks to finish", "gracePeriod", grace)
+	syncCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	n.state = NodeState_DRAIN
+	n.sync(syncCtx)
+
+	if n.workers.Wait(grace) == nil {
+		return
+	}
+	n.log.Info("Shutdown grace period is over, stopping tasks", "count", n.workers.Count())
+	stopWorkers()
+	n.workers.Wait(time.Second * 10)
+}
+
 func (n *NodeProcess) checkConnection(ctx context.Context) {
 	_, err := n.client.GetNode(ctx, &GetNodeRequest{Id: n.conf.Node.ID})
 
@@ -129,7 +169,14 @@ func (n *NodeProcess) checkConnection(ctx context.Context) {
 func (n *NodeProcess) sync(ctx context.Context) {
 	var r *Node
 	var err error
+	var created bool
+
+	// The health and preemption checks may run commands, so they run before
+	// the node is read, to keep the node's read-modify-write short.
+	preempted := n.checkPreemption(ctx)
+	healthErrors := n.checkHealth(ctx)
 
+	start := time.Now()
 	r, err = n.client.GetNode(ctx, &GetNodeRequest{Id: n.conf.Node.ID})
 	if err != nil {
 		// If its a 404 error create a new node
@@ -140,17 +187,63 @@ func (n *NodeProcess) sync(ctx context.Context) {
 		}
 		n.log.Info("Starting initial node sync")
 		r = &Node{Id: n.conf.Node.ID}
+		created = true
 	}
 
-	// Start task workers. runSet will track task IDs
-	// to ensure there's only one worker per ID, so it's ok
-	// to call this multiple times with the same task ID.
-	for _, id := range r.TaskIds {
-		if n.workers.Add(id) {
-			go n.runTask(ctx, id)
+	// The server may ask the node to drain, e.g. when the autoscaler is
+	// removing the node from the pool, or to stop accepting tasks (cordon,
+	// quarantine). A draining node never goes back to accepting tasks.
+	schedulable := n.state == NodeState_ALIVE || n.state == NodeState_CORDON ||
+		n.state == NodeState_QUARANTINE
+	switch {
+	case !schedulable || r.GetState() == n.state:
+	case r.GetState() == NodeState_DRAIN:
+		n.log.Info("Server requested node drain")
+		n.state = NodeState_DRAIN
+	case r.GetState() == NodeState_CORDON:
+		n.log.Info("Server requested node cordon")
+		n.state = NodeState_CORDON
+	case r.GetState() == NodeState_QUARANTINE:
+		n.log.Info("Server quarantined node")
+		n.state = NodeState_QUARANTINE
+	case r.GetState() == NodeState_ALIVE:
+		n.log.Info("Server requested node uncordon")
+		n.state = NodeState_ALIVE
+	}
+
+	// A node which is about to be preempted stops accepting tasks.
+	if preempted && schedulable {
+		n.state = NodeState_DRAIN
+	}
+
+	// Stop workers for tasks which were evicted from the node. Workers started
+	// after the request, e.g. from a pushed assignment, may not be in the response.
+	if !created {
+		assigned := map[string]bool{}
+		for _, id := range r.TaskIds {
+			assigned[id] = true
+		}
+		for _, id := range n.workers.AddedBefore(start) {
+			if !assigned[id] {
+				n.log.Info("Task evicted from node, stopping worker", "taskID", id)
+				n.workers.Cancel(id)
+			}
+		}
+	}
+
+	// A node which is shutting down doesn't start new tasks.
+	// They're reported as abandoned instead, and re-queued by the server.
+	if !n.stopping {
+		for _, id := range r.TaskIds {
+			n.startTask(ctx, id)
 		}
 	}
 
+	var abandoned []string
+	if n.state == NodeState_GONE {
+		abandoned = r.TaskIds
+	}
+
 	// Node data has been updated. Send back to server for database update.
 	var derr error
 	n.resources, derr = detectResources(n.conf.Node, n.conf.Worker.WorkDir)
@@ -166,22 +259,93 @@ func (n *NodeProcess) sync(ctx context.Context) {
 	for k, v := range r.GetMetadata() {
 		meta[k] = v
 	}
+	if !n.preempted.IsZero() {
+		meta[PreemptionNoticeKey] = n.preempted.Format(time.RFC3339)
+	}
 
 	_, err = n.client.PutNode(context.Background(), &Node{
-		Id:        n.conf.Node.ID,
-		Resources: &n.resources,
-		State:     n.state,
-		Version:   r.GetVersion(),
-		Metadata:  meta,
-		Hostname:  hostname(),
-		TaskIds:   r.TaskIds,
+		Id:               n.conf.Node.ID,
+		Resources:        &n.resources,
+		State:            n.state,
+		Preemptible:      n.conf.Node.Preemptible,
+		Version:          r.GetVersion(),
+		Metadata:         meta,
+		Hostname:         hostname(),
+		TaskIds:          r.TaskIds,
+		HealthErrors:     healthErrors,
+		AbandonedTaskIds: abandoned,
 	})
 	if err != nil {
 		n.log.Error("Couldn't save node update. Recovering.", err)
 	}
 }
 
-func (n *NodeProcess) runTask(ctx context.Context, id string) {
+// stream opens a session with the server, over which the server pushes task
+// assignments and cancels. If the session fails, it is reopened after
+// Node.UpdateRate. Meanwhile, sync acts as a fallback. Assigned tasks are
+// started with "wctx".
+func (n *NodeProcess) stream(ctx, wctx context.Context) {
+	for {
+		err := n.session(ctx, wctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if s, _ := status.FromError(err); s.Code() == codes.Unimplemented {
+			n.log.Info("Server doesn't support node sessions, falling back to polling")
+			return
+		}
+		n.log.Debug("Node session closed", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(n.conf.Node.UpdateRate)):
+		}
+	}
+}
+
+func (n *NodeProcess) session(ctx, wctx context.Context) error {
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s, err := n.client.NodeSession(sctx)
+	if err != nil {
+		return err
+	}
+	err = s.Send(&NodeSessionRequest{Id: n.conf.Node.ID})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := s.Recv()
+		if err != nil {
+			return err
+		}
+		for _, id := range resp.Cancel {
+			n.log.Info("Server requested task stop", "taskID", id)
+			n.workers.Cancel(id)
+		}
+		for _, id := range resp.Assign {
+			n.startTask(wctx, id)
+		}
+	}
+}
+
+// startTask starts a task worker. runSet will track task IDs
+// to ensure there's only one worker per ID, so it's ok
+// to call this multiple times with the same task ID.
+func (n *NodeProcess) startTask(ctx context.Context, id string) {
+	if n.workers.Add(id) {
+		tctx, cancel := context.WithCancel(ctx)
+		n.workers.SetCancel(id, cancel)
+		go n.runTask(ctx, tctx, id)
+	}
+}
+
+// runTask runs the worker for the task with "tctx", which is canceled if the task
+// is evicted or canceled, then removes the task from the node.
+func (n *NodeProcess) runTask(ctx, tctx context.Context, id string) {
 	log := n.log.WithFields("ns", "worker", "taskID", id)
 	log.Info("Running task")
 
@@ -233,7 +397,7 @@ func (n *NodeProcess) runTask(ctx context.Context, id string) {
 		}
 	}()
 
-	err := n.workerRun(ctx, id)
+	err := n.workerRun(tctx, id)
 	if err != nil {
 		log.Error("error running task", err)
 		return
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -175,3 +176,256 @@ func TestFinishedTaskRunsetCount(t *testing.T) {
 		t.Fatalf("Unexpected worker count: %d", n.workers.Count())
 	}
 }
+
+// Test that a node adopts the DRAIN state when requested by the server.
+func TestNodeServerDrain(t *testing.T) {
+	conf := config.DefaultConfig()
+	n := newTestNode(conf, t)
+
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{State: NodeState_DRAIN}, nil)
+	n.sync(context.Background())
+
+	if n.state != NodeState_DRAIN {
+		t.Errorf("expected node state DRAIN, got %s", n.state)
+	}
+}
+
+// Test that a node adopts the CORDON state when requested by the server,
+// and goes back to ALIVE when uncordoned.
+func TestNodeServerCordon(t *testing.T) {
+	conf := config.DefaultConfig()
+	n := newTestNode(conf, t)
+
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{State: NodeState_CORDON}, nil).Once()
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{State: NodeState_ALIVE}, nil)
+
+	n.sync(context.Background())
+	if n.state != NodeState_CORDON {
+		t.Errorf("expected node state CORDON, got %s", n.state)
+	}
+
+	n.sync(context.Background())
+	if n.state != NodeState_ALIVE {
+		t.Errorf("expected node state ALIVE, got %s", n.state)
+	}
+
+	// A draining node doesn't go back to ALIVE.
+	n.Drain()
+	n.sync(context.Background())
+	if n.state != NodeState_DRAIN {
+		t.Errorf("expected node state DRAIN, got %s", n.state)
+	}
+}
+
+// Test that a node stops the worker of a task evicted by the server.
+func TestNodeEvictTask(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.Node.UpdateRate = config.Duration(time.Millisecond * 2)
+	n := newTestNode(conf, t)
+
+	canceled := make(chan struct{})
+	n.workerRun = func(ctx context.Context, id string) error {
+		<-ctx.Done()
+		close(canceled)
+		return nil
+	}
+
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{TaskIds: []string{"task-1"}}, nil).Once()
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{}, nil)
+
+	n.sync(context.Background())
+	n.sync(context.Background())
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the evicted task's worker to be canceled")
+	}
+}
+
+// Test that a node starts and stops workers for tasks pushed by the server.
+func TestNodeSessionPushedTasks(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.Node.UpdateRate = config.Duration(time.Hour)
+	n := newTestNode(conf, t)
+
+	started := make(chan string, 1)
+	canceled := make(chan string, 1)
+	n.workerRun = func(ctx context.Context, id string) error {
+		started <- id
+		<-ctx.Done()
+		canceled <- id
+		return nil
+	}
+
+	stream := &testClientStream{recv: make(chan *NodeSessionResponse, 2)}
+	n.Client.ExpectedCalls = nil
+	n.Client.On("NodeSession", mock.Anything).Return(stream, nil)
+
+	go n.session(context.Background(), context.Background())
+
+	stream.recv <- &NodeSessionResponse{Assign: []string{"task-1"}}
+	select {
+	case id := <-started:
+		if id != "task-1" {
+			t.Errorf("unexpected task started: %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the pushed task to start")
+	}
+
+	stream.recv <- &NodeSessionResponse{Cancel: []string{"task-1"}}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the pushed cancel to stop the worker")
+	}
+	close(stream.recv)
+}
+
+// Test that failed health checks are reported to the server.
+func TestNodeHealthCheck(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.Node.HealthCheck.Command = "echo disk on fire; exit 1"
+	n := newTestNode(conf, t)
+
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{}, nil)
+	n.sync(context.Background())
+
+	var reported *Node
+	for _, c := range n.Client.Calls {
+		if c.Method == "PutNode" {
+			reported = c.Arguments.Get(1).(*Node)
+		}
+	}
+	if reported == nil {
+		t.Fatal("expected PutNode call")
+	}
+	if len(reported.HealthErrors) != 1 || !strings.Contains(reported.HealthErrors[0], "disk on fire") {
+		t.Errorf("unexpected health errors: %v", reported.HealthErrors)
+	}
+
+	// The result is reused until the next check.
+	n.conf.Node.HealthCheck.Command = "true"
+	if len(n.checkHealth(context.Background())) != 1 {
+		t.Error("expected previous health check result")
+	}
+	n.healthChecked = time.Time{}
+	if len(n.checkHealth(context.Background())) != 0 {
+		t.Error("expected node to be healthy")
+	}
+}
+
+func TestNodeShutdownGracePeriod(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.Node.UpdateRate = config.Duration(time.Millisecond * 2)
+	conf.Node.ShutdownGracePeriod = config.Duration(time.Second)
+	n := newTestNode(conf, t)
+
+	started := make(chan struct{})
+	var stopped error
+	n.workerRun = func(ctx context.Context, id string) error {
+		close(started)
+		time.Sleep(time.Millisecond * 50)
+		stopped = ctx.Err()
+		return nil
+	}
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{TaskIds: []string{"task-1"}}, nil)
+
+	stop := n.Start()
+	<-started
+	cleanup := timeLimit(t, time.Second)
+	defer cleanup()
+	stop()
+	n.Wait()
+
+	if stopped != nil {
+		t.Error("expected the task to finish during the grace period")
+	}
+	var drained bool
+	for _, c := range n.Client.Calls {
+		if c.Method == "PutNode" && c.Arguments.Get(1).(*Node).State == NodeState_DRAIN {
+			drained = true
+		}
+	}
+	if !drained {
+		t.Error("expected the node to drain during the grace period")
+	}
+}
+
+func TestNodeShutdownAbandonsTasks(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.Node.UpdateRate = config.Duration(time.Millisecond * 2)
+	conf.Node.ShutdownGracePeriod = config.Duration(time.Millisecond * 20)
+	n := newTestNode(conf, t)
+
+	started := make(chan struct{})
+	n.workerRun = func(ctx context.Context, id string) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{TaskIds: []string{"task-1"}}, nil)
+
+	stop := n.Start()
+	<-started
+	cleanup := timeLimit(t, time.Second)
+	defer cleanup()
+	stop()
+	n.Wait()
+
+	var gone *Node
+	for _, c := range n.Client.Calls {
+		if c.Method == "PutNode" && c.Arguments.Get(1).(*Node).State == NodeState_GONE {
+			gone = c.Arguments.Get(1).(*Node)
+		}
+	}
+	if gone == nil {
+		t.Fatal("expected a final sync with state GONE")
+	}
+	if len(gone.AbandonedTaskIds) != 1 || gone.AbandonedTaskIds[0] != "task-1" {
+		t.Errorf("expected task-1 to be abandoned, got %v", gone.AbandonedTaskIds)
+	}
+}
+
+func TestNodePreemptionNotice(t *testing.T) {
+	conf := config.DefaultConfig()
+	conf.Node.Preemptible = true
+	conf.Node.PreemptionNotice.Command = "exit 1"
+	n := newTestNode(conf, t)
+
+	n.Client.On("GetNode", mock.Anything, mock.Anything, mock.Anything).
+		Return(&Node{}, nil)
+	n.sync(context.Background())
+	if n.state != NodeState_ALIVE {
+		t.Fatalf("unexpected state without a preemption notice: %s", n.state)
+	}
+
+	n.conf.Node.PreemptionNotice.Command = "true"
+	n.preemptionChecked = time.Time{}
+	n.sync(context.Background())
+
+	var reported *Node
+	for _, c := range n.Client.Calls {
+		if c.Method == "PutNode" {
+			reported = c.Arguments.Get(1).(*Node)
+		}
+	}
+	if reported.State != NodeState_DRAIN {
+		t.Errorf("expected node to drain after a preemption notice, got %s", reported.State)
+	}
+	if !reported.Preemptible {
+		t.Error("expected node to report it's preemptible")
+	}
+	if reported.Metadata[PreemptionNoticeKey] == "" {
+		t.Error("expected preemption notice in node metadata")
+	}
+}
@@ -32,15 +32,15 @@ func UpdateNode(ctx context.Context, cli tes.ReadOnlyServer, node, existing *Nod
 	// Update last ping time.
 	node.LastPing = time.Now().UnixNano()
 
-	// Merge metadata
-	meta := map[string]string{}
-	for k, v := range existing.Metadata {
-		meta[k] = v
-	}
-	for k, v := range node.Metadata {
-		meta[k] = v
+	// Keep the existing metadata if none was given. Otherwise the given
+	// metadata replaces it, so that labels can be removed.
+	if node.Metadata == nil {
+		meta := map[string]string{}
+		for k, v := range existing.GetMetadata() {
+			meta[k] = v
+		}
+		node.Metadata = meta
 	}
-	node.Metadata = meta
 	return nil
 }
 
@@ -51,6 +51,7 @@ func SubtractResources(t *tes.Task, in *Resources) *Resources {
 		Cpus:   in.GetCpus(),
 		RamGb:  in.GetRamGb(),
 		DiskGb: in.GetDiskGb(),
+		Custom: copyCustom(in.GetCustom()),
 	}
 	tres := t.GetResources()
 
@@ -76,6 +77,29 @@ func SubtractResources(t *tes.Task, in *Resources) *Resources {
 	if out.DiskGb < 0.0 {
 		out.DiskGb = 0.0
 	}
+
+	// Invalid custom resource requests are rejected by the CustomResourcesFit predicate.
+	custom, _ := tes.CustomResources(t)
+	for name, amount := range custom {
+		if _, ok := out.Custom[name]; !ok {
+			continue
+		}
+		out.Custom[name] -= amount
+		if out.Custom[name] < 0.0 {
+			out.Custom[name] = 0.0
+		}
+	}
+	return out
+}
+
+func copyCustom(in map[string]float64) map[string]float64 {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]float64, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
 	return out
 }
 
@@ -86,6 +110,7 @@ func AvailableResources(tasks []*tes.Task, res *Resources) *Resources {
 		Cpus:   res.GetCpus(),
 		RamGb:  res.GetRamGb(),
 		DiskGb: res.GetDiskGb(),
+		Custom: copyCustom(res.GetCustom()),
 	}
 	for _, t := range tasks {
 		a = SubtractResources(t, a)
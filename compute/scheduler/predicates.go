@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ohsu-comp-bio/funnel/tes"
 )
@@ -14,8 +15,6 @@ func ResourcesFit(t *tes.Task, n *Node) error {
 	req := t.GetResources()
 
 	switch {
-	case n.GetPreemptible() && !req.GetPreemptible():
-		return fmt.Errorf("Fail preemptible")
 	case n.GetAvailable().GetCpus() <= 0:
 		return fmt.Errorf("Fail zero cpus available")
 	case n.GetAvailable().GetRamGb() <= 0.0:
@@ -44,6 +43,34 @@ func ResourcesFit(t *tes.Task, n *Node) error {
 	return nil
 }
 
+// PreemptibleFit determines whether a task may run on a node which can be
+// preempted, e.g. a spot VM. Only tasks which allow preemption run on them.
+func PreemptibleFit(t *tes.Task, n *Node) error {
+	if n.GetPreemptible() && !t.GetResources().GetPreemptible() {
+		return fmt.Errorf("Fail preemptible")
+	}
+	return nil
+}
+
+// CustomResourcesFit determines whether the custom resources requested by
+// a task, e.g. GPUs, fit a node's available custom resources.
+func CustomResourcesFit(t *tes.Task, n *Node) error {
+	req, err := tes.CustomResources(t)
+	if err != nil {
+		return fmt.Errorf("Fail custom resources, %s", err)
+	}
+	for name, amount := range req {
+		avail := n.GetAvailable().GetCustom()[name]
+		if avail < amount {
+			return fmt.Errorf(
+				"Fail %s, requested %f, available %f",
+				name, amount, avail,
+			)
+		}
+	}
+	return nil
+}
+
 // ZonesFit determines whether a task's zones fit a node.
 func ZonesFit(t *tes.Task, n *Node) error {
 	if n.Zone == "" {
@@ -80,6 +107,14 @@ func Alive(j *tes.Task, n *Node) error {
 	return nil
 }
 
+// Healthy returns true if the node's health checks passed.
+func Healthy(j *tes.Task, n *Node) error {
+	if len(n.HealthErrors) > 0 {
+		return fmt.Errorf("Fail unhealthy: %s", strings.Join(n.HealthErrors, "; "))
+	}
+	return nil
+}
+
 // NodeHasTag returns a predicate function which returns true
 // if the node has the given tag (key in Metadata field).
 func NodeHasTag(tag string) Predicate {
@@ -91,6 +126,26 @@ func NodeHasTag(tag string) Predicate {
 	}
 }
 
+// TagMatchesMetadata returns a predicate function which checks that, if the task
+// has the given tag, the node has the metadata key with the same value.
+// If exclusive is true, nodes with the metadata key only accept tasks with
+// a matching tag, which is useful for dedicating nodes (e.g. GPU nodes) to some tasks.
+func TagMatchesMetadata(tag, key string, exclusive bool) Predicate {
+	return func(j *tes.Task, n *Node) error {
+		want, hasTag := j.GetTags()[tag]
+		got, hasKey := n.GetMetadata()[key]
+		switch {
+		case hasTag && !hasKey:
+			return fmt.Errorf("Fail metadata, node is missing %s", key)
+		case hasTag && want != got:
+			return fmt.Errorf("Fail metadata, task tag %s=%s, node %s=%s", tag, want, key, got)
+		case !hasTag && hasKey && exclusive:
+			return fmt.Errorf("Fail metadata, node %s is reserved for tasks tagged %s", key, tag)
+		}
+		return nil
+	}
+}
+
 // TODO should have a predicate which understands authorization
 //      - storage
 //      - other auth resources?
@@ -108,3 +163,15 @@ func Match(node *Node, task *tes.Task, predicates []Predicate) bool {
 	}
 	return true
 }
+
+// MatchErrors returns the error messages of all the predicates
+// which reject the task for the node.
+func MatchErrors(node *Node, task *tes.Task, predicates []Predicate) []string {
+	var errs []string
+	for _, pred := range predicates {
+		if err := pred(task, node); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return errs
+}
@@ -15,6 +15,50 @@ func TestResourcesFitEmptyTask(t *testing.T) {
 	testEmptyTask(t, ResourcesFit, "ResourcesFit")
 }
 
+func TestCustomResourcesFitEmptyTask(t *testing.T) {
+	testEmptyTask(t, CustomResourcesFit, "CustomResourcesFit")
+}
+
+func TestCustomResourcesFit(t *testing.T) {
+	j := &tes.Task{
+		Tags: map[string]string{"resource.nvidia.com/gpu": "2"},
+	}
+	res := &Resources{
+		Cpus:   1,
+		RamGb:  1.0,
+		DiskGb: 1.0,
+		Custom: map[string]float64{"nvidia.com/gpu": 2},
+	}
+	w := &Node{Id: "test-node", Resources: res, Available: res}
+
+	if err := CustomResourcesFit(j, w); err != nil {
+		t.Error("Expected custom resources to fit", err)
+	}
+
+	// The task's GPUs are subtracted from the available resources.
+	w.Available = AvailableResources([]*tes.Task{j}, res)
+	if w.Available.Custom["nvidia.com/gpu"] != 0 {
+		t.Errorf("Expected no GPUs available, got %v", w.Available.Custom)
+	}
+	if res.Custom["nvidia.com/gpu"] != 2 {
+		t.Error("Expected node resources to be unchanged")
+	}
+	if CustomResourcesFit(j, w) == nil {
+		t.Error("Expected custom resources NOT to fit")
+	}
+
+	// Nodes without the resource don't fit.
+	w.Available = &Resources{Cpus: 1, RamGb: 1.0, DiskGb: 1.0}
+	if CustomResourcesFit(j, w) == nil {
+		t.Error("Expected custom resources NOT to fit")
+	}
+
+	j.Tags["resource.nvidia.com/gpu"] = "bad"
+	if CustomResourcesFit(j, w) == nil {
+		t.Error("Expected invalid custom resource request NOT to fit")
+	}
+}
+
 func TestCpuResourcesFit(t *testing.T) {
 	j := &tes.Task{
 		Resources: &tes.Resources{
@@ -67,3 +111,65 @@ func testEmptyTask(t *testing.T, p Predicate, name string) {
 	w := &Node{}
 	p(j, w)
 }
+
+func TestTagMatchesMetadata(t *testing.T) {
+	pred := TagMatchesMetadata("gpu", "gpu-type", false)
+	exclusive := TagMatchesMetadata("gpu", "gpu-type", true)
+
+	tagged := &tes.Task{Tags: map[string]string{"gpu": "v100"}}
+	untagged := &tes.Task{}
+	gpuNode := &Node{Metadata: map[string]string{"gpu-type": "v100"}}
+	otherNode := &Node{Metadata: map[string]string{"gpu-type": "k80"}}
+	plainNode := &Node{}
+
+	if err := pred(tagged, gpuNode); err != nil {
+		t.Error("expected tagged task to fit matching node", err)
+	}
+	if pred(tagged, otherNode) == nil {
+		t.Error("expected tagged task to not fit node with different value")
+	}
+	if pred(tagged, plainNode) == nil {
+		t.Error("expected tagged task to not fit node without metadata")
+	}
+	if err := pred(untagged, gpuNode); err != nil {
+		t.Error("expected untagged task to fit non-exclusive node", err)
+	}
+	if exclusive(untagged, gpuNode) == nil {
+		t.Error("expected untagged task to not fit exclusive node")
+	}
+	if err := exclusive(untagged, plainNode); err != nil {
+		t.Error("expected untagged task to fit node without metadata", err)
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	j := &tes.Task{Resources: &tes.Resources{CpuCores: 2}}
+	n := &Node{
+		State:     NodeState_DEAD,
+		Available: &Resources{Cpus: 1, RamGb: 1, DiskGb: 1},
+	}
+	errs := MatchErrors(n, j, DefaultPredicates)
+	if len(errs) != 3 {
+		t.Errorf("expected 3 predicate errors, got %v", errs)
+	}
+}
+
+func TestPreemptibleFit(t *testing.T) {
+	j := &tes.Task{Resources: &tes.Resources{}}
+	w := &Node{Id: "test-node", Preemptible: true}
+
+	if PreemptibleFit(j, w) == nil {
+		t.Error("Expected a task which doesn't allow preemption NOT to fit a preemptible node")
+	}
+
+	j.Resources.Preemptible = true
+	if err := PreemptibleFit(j, w); err != nil {
+		t.Errorf("Expected task to fit: %s", err)
+	}
+
+	j.Resources.Preemptible = false
+	w.Preemptible = false
+	if err := PreemptibleFit(j, w); err != nil {
+		t.Errorf("Expected task to fit: %s", err)
+	}
+}
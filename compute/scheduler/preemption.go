@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// PreemptionNoticeKey is the node metadata key under which a node reports
+// the time it received a preemption notice.
+const PreemptionNoticeKey = "preemption-notice"
+
+// checkPreemption runs the node's preemption notice check, at most once every
+// PreemptionNotice.Rate, and returns true once the node received a notice.
+// A notice can't be taken back, so the check stops running after the first one.
+func (n *NodeProcess) checkPreemption(ctx context.Context) bool {
+	conf := n.conf.Node.PreemptionNotice
+	switch {
+	case !n.preempted.IsZero():
+		return true
+	case conf.Command == "":
+		return false
+	case !n.preemptionChecked.IsZero() && time.Since(n.preemptionChecked) < time.Duration(conf.Rate):
+		return false
+	}
+	n.preemptionChecked = time.Now()
+
+	// The check command uses the health check timeout.
+	if _, err := n.runHealthCheck(ctx, "sh", "-c", conf.Command); err != nil {
+		return false
+	}
+	n.log.Info("Node received a preemption notice, no longer accepting tasks")
+	n.preempted = time.Now()
+	return true
+}
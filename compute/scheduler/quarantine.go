@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+)
+
+// recordOutcome records whether a task which left the node failed with
+// a system error. Outcomes are only recorded if quarantine is enabled
+// and s.Tasks is set.
+func (s *Scheduler) recordOutcome(ctx context.Context, nodeID, taskID string) {
+	window := s.Conf.Quarantine.Window
+	if window <= 0 || s.Tasks == nil || nodeID == "" {
+		return
+	}
+	task, err := s.Tasks.GetTask(ctx, &tes.GetTaskRequest{Id: taskID, View: tes.TaskView_MINIMAL})
+	if err != nil || !tes.TerminalState(task.GetState()) {
+		return
+	}
+
+	if s.outcomes == nil {
+		s.outcomes = map[string][]bool{}
+	}
+	out := append(s.outcomes[nodeID], task.GetState() == tes.State_SYSTEM_ERROR)
+	if len(out) > window {
+		out = out[len(out)-window:]
+	}
+	s.outcomes[nodeID] = out
+}
+
+// checkQuarantine quarantines nodes whose recent task failure rate reached
+// config.Quarantine.FailureRate, and releases quarantined nodes after
+// config.Quarantine.Duration.
+func (s *Scheduler) checkQuarantine(ctx context.Context, nodes []*Node, now time.Time) {
+	conf := s.Conf.Quarantine
+	if conf.Window <= 0 {
+		return
+	}
+	if s.quarantined == nil {
+		s.quarantined = map[string]time.Time{}
+	}
+
+	known := map[string]bool{}
+	for _, n := range nodes {
+		known[n.Id] = true
+
+		switch n.State {
+		case NodeState_ALIVE:
+			delete(s.quarantined, n.Id)
+			out := s.outcomes[n.Id]
+			if len(out) < conf.Window {
+				continue
+			}
+			failed := 0
+			for _, f := range out {
+				if f {
+					failed++
+				}
+			}
+			rate := float64(failed) / float64(len(out))
+			if rate < conf.FailureRate {
+				continue
+			}
+
+			s.Log.Error("Quarantining node",
+				"nodeID", n.Id,
+				"failureRate", rate,
+				"window", len(out),
+			)
+			n.State = NodeState_QUARANTINE
+			if _, err := s.Nodes.PutNode(ctx, n); err != nil {
+				s.Log.Error("Error quarantining node", "nodeID", n.Id, "error", err)
+				n.State = NodeState_ALIVE
+				continue
+			}
+			s.quarantined[n.Id] = now
+			delete(s.outcomes, n.Id)
+
+		case NodeState_QUARANTINE:
+			since, ok := s.quarantined[n.Id]
+			if !ok || conf.Duration <= 0 || now.Sub(since) < time.Duration(conf.Duration) {
+				continue
+			}
+			s.Log.Info("Releasing node from quarantine", "nodeID", n.Id)
+			n.State = NodeState_ALIVE
+			if _, err := s.Nodes.PutNode(ctx, n); err != nil {
+				s.Log.Error("Error releasing node from quarantine", "nodeID", n.Id, "error", err)
+				n.State = NodeState_QUARANTINE
+				continue
+			}
+			delete(s.quarantined, n.Id)
+
+		default:
+			// The node was uncordoned, drained, etc.
+			delete(s.quarantined, n.Id)
+		}
+	}
+
+	for id := range s.outcomes {
+		if !known[id] {
+			delete(s.outcomes, id)
+		}
+	}
+	for id := range s.quarantined {
+		if !known[id] {
+			delete(s.quarantined, id)
+		}
+	}
+}
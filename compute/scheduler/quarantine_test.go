@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// memTasks is a simple in-memory tes.ReadOnlyServer.
+type memTasks map[string]*tes.Task
+
+func (m memTasks) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.Task, error) {
+	t, ok := m[req.Id]
+	if !ok {
+		return nil, tes.ErrNotFound
+	}
+	return t, nil
+}
+
+func (m memTasks) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
+	resp := &tes.ListTasksResponse{}
+	for _, t := range m {
+		resp.Tasks = append(resp.Tasks, t)
+	}
+	return resp, nil
+}
+
+func TestQuarantine(t *testing.T) {
+	ctx := context.Background()
+	conf := config.DefaultConfig().Scheduler
+	conf.Quarantine = config.Quarantine{
+		Window:      3,
+		FailureRate: 0.6,
+		Duration:    config.Duration(time.Minute),
+	}
+
+	tasks := memTasks{
+		"t1": {Id: "t1", State: tes.State_COMPLETE},
+		"t2": {Id: "t2", State: tes.State_SYSTEM_ERROR},
+		"t3": {Id: "t3", State: tes.State_COMPLETE},
+		"t4": {Id: "t4", State: tes.State_SYSTEM_ERROR},
+	}
+	nodes := memNodes{"node-1": idleNode("node-1")}
+	s := &Scheduler{
+		Conf:  conf,
+		Nodes: nodes,
+		Queue: memQueue{},
+		Event: events.Noop{},
+		Tasks: tasks,
+	}
+	list := func() []*Node {
+		resp, _ := nodes.ListNodes(ctx, &ListNodesRequest{})
+		return resp.Nodes
+	}
+
+	// Each task runs on the node, then leaves it.
+	now := time.Now()
+	for i, id := range []string{"t1", "t2", "t3"} {
+		s.trackTask(tasks[id], "node-1", now)
+		s.syncAssigned(ctx, list())
+		s.checkQuarantine(ctx, list(), now)
+		if nodes["node-1"].State != NodeState_ALIVE {
+			t.Fatalf("unexpected quarantine after %d tasks", i+1)
+		}
+	}
+
+	// 2 of the last 3 tasks failed.
+	s.trackTask(tasks["t4"], "node-1", now)
+	s.syncAssigned(ctx, list())
+	s.checkQuarantine(ctx, list(), now)
+	if nodes["node-1"].State != NodeState_QUARANTINE {
+		t.Fatalf("expected node to be quarantined, got %s", nodes["node-1"].State)
+	}
+
+	// Quarantined nodes aren't assigned tasks.
+	if Match(nodes["node-1"], &tes.Task{}, DefaultPredicates) {
+		t.Error("expected quarantined node NOT to match")
+	}
+
+	s.checkQuarantine(ctx, list(), now.Add(30*time.Second))
+	if nodes["node-1"].State != NodeState_QUARANTINE {
+		t.Fatal("expected node to stay quarantined")
+	}
+	s.checkQuarantine(ctx, list(), now.Add(2*time.Minute))
+	if nodes["node-1"].State != NodeState_ALIVE {
+		t.Fatalf("expected node to be released, got %s", nodes["node-1"].State)
+	}
+}
+
+func TestHealthyPredicate(t *testing.T) {
+	n := idleNode("node-1")
+	if Healthy(&tes.Task{}, n) != nil {
+		t.Error("expected healthy node")
+	}
+	n.HealthErrors = []string{"docker daemon isn't responding"}
+	if Healthy(&tes.Task{}, n) == nil {
+		t.Error("expected unhealthy node")
+	}
+}
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// TaskPriority returns the priority of the task, read from the tag named
+// by conf.PriorityTag. Tasks without a valid priority tag have priority 0.
+func TaskPriority(t *tes.Task, conf config.Scheduler) int {
+	if conf.PriorityTag == "" {
+		return 0
+	}
+	p, err := strconv.Atoi(t.GetTags()[conf.PriorityTag])
+	if err != nil {
+		return 0
+	}
+	return p
+}
+
+// TaskGroup returns the fair-share group of the task, read from the tag named
+// by conf.FairShareTag. Tasks without the tag belong to the "" group.
+func TaskGroup(t *tes.Task, conf config.Scheduler) string {
+	if conf.FairShareTag == "" {
+		return ""
+	}
+	return t.GetTags()[conf.FairShareTag]
+}
+
+// GroupQuota returns the maximum number of concurrent tasks for the group.
+// Zero means no limit.
+func GroupQuota(group string, conf config.Scheduler) int {
+	for _, q := range conf.GroupQuotas {
+		if q.Group == group {
+			return q.MaxTasks
+		}
+	}
+	return conf.GroupQuota
+}
+
+// OrderQueue orders queued tasks for scheduling. Tasks with a higher priority
+// come first. Tasks with the same priority are interleaved across fair-share groups,
+// picking next from the group with the fewest running tasks (given by "usage")
+// plus tasks already picked. Within a group, tasks keep their queue order.
+func OrderQueue(tasks []*tes.Task, conf config.Scheduler, usage map[string]int) []*tes.Task {
+	// Split the queue by priority, preserving queue order.
+	byPriority := map[int][]*tes.Task{}
+	var priorities []int
+	for _, t := range tasks {
+		p := TaskPriority(t, conf)
+		if _, ok := byPriority[p]; !ok {
+			priorities = append(priorities, p)
+		}
+		byPriority[p] = append(byPriority[p], t)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+
+	picked := map[string]int{}
+	for g, n := range usage {
+		picked[g] = n
+	}
+
+	out := make([]*tes.Task, 0, len(tasks))
+	for _, p := range priorities {
+		// Split by group, remembering the order in which groups first appear
+		// so that ties go to the group with the oldest task.
+		groups := map[string][]*tes.Task{}
+		var names []string
+		for _, t := range byPriority[p] {
+			g := TaskGroup(t, conf)
+			if _, ok := groups[g]; !ok {
+				names = append(names, g)
+			}
+			groups[g] = append(groups[g], t)
+		}
+
+		for len(names) > 0 {
+			// Find the least used group.
+			next := 0
+			for i, g := range names {
+				if picked[g] < picked[names[next]] {
+					next = i
+				}
+			}
+			g := names[next]
+			out = append(out, groups[g][0])
+			picked[g]++
+			groups[g] = groups[g][1:]
+			if len(groups[g]) == 0 {
+				names = append(names[:next], names[next+1:]...)
+			}
+		}
+	}
+	return out
+}
+
+// ReadOrderedQueue pages through the whole queue, conf.QueueWindow tasks at
+// a time, and returns the tasks ordered by OrderQueue. No more than
+// conf.ScheduleChunk tasks are scheduled in an iteration, so only the oldest
+// ScheduleChunk tasks of each priority and group are kept. This way, a group
+// with many queued tasks doesn't hide the tasks of other groups, or of a higher
+// priority, which were queued after them.
+func ReadOrderedQueue(q TaskQueue, conf config.Scheduler, usage map[string]int) []*tes.Task {
+	page := conf.QueueWindow
+	if page < conf.ScheduleChunk {
+		page = conf.ScheduleChunk
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	type key struct {
+		priority int
+		group    string
+	}
+	counts := map[key]int{}
+	var tasks []*tes.Task
+
+	after := ""
+	for {
+		queued, last := q.ReadQueue(page, after)
+		for _, t := range queued {
+			if t == nil {
+				continue
+			}
+			k := key{TaskPriority(t, conf), TaskGroup(t, conf)}
+			if counts[k] < conf.ScheduleChunk {
+				counts[k]++
+				tasks = append(tasks, t)
+			}
+		}
+		// A page may hold fewer tasks than were scanned, so paging
+		// only stops at the end of the queue.
+		if last == "" {
+			break
+		}
+		after = last
+	}
+	return OrderQueue(tasks, conf, usage)
+}
@@ -0,0 +1,270 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+func queuedTask(id, owner, priority string) *tes.Task {
+	tags := map[string]string{"owner": owner}
+	if priority != "" {
+		tags["priority"] = priority
+	}
+	return &tes.Task{Id: id, Tags: tags}
+}
+
+func taskIDs(tasks []*tes.Task) []string {
+	var ids []string
+	for _, t := range tasks {
+		ids = append(ids, t.Id)
+	}
+	return ids
+}
+
+func TestOrderQueuePriority(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	tasks := []*tes.Task{
+		queuedTask("t1", "a", ""),
+		queuedTask("t2", "a", "10"),
+		queuedTask("t3", "a", "-1"),
+		queuedTask("t4", "a", "bad"),
+		queuedTask("t5", "a", "10"),
+	}
+
+	ids := taskIDs(OrderQueue(tasks, conf, nil))
+	expected := []string{"t2", "t5", "t1", "t4", "t3"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestOrderQueueFairShare(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	conf.FairShareTag = "owner"
+	tasks := []*tes.Task{
+		queuedTask("a1", "a", ""),
+		queuedTask("a2", "a", ""),
+		queuedTask("a3", "a", ""),
+		queuedTask("b1", "b", ""),
+		queuedTask("c1", "c", ""),
+		queuedTask("b2", "b", ""),
+	}
+
+	ids := taskIDs(OrderQueue(tasks, conf, nil))
+	expected := []string{"a1", "b1", "c1", "a2", "b2", "a3"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+
+	// Group "a" already has running tasks, so it goes last.
+	ids = taskIDs(OrderQueue(tasks, conf, map[string]int{"a": 2}))
+	expected = []string{"b1", "c1", "b2", "a1", "a2", "a3"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestGroupQuota(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	conf.GroupQuota = 5
+	conf.GroupQuotas = []config.GroupQuota{{Group: "a", MaxTasks: 1}}
+
+	if GroupQuota("a", conf) != 1 {
+		t.Error("expected group override")
+	}
+	if GroupQuota("b", conf) != 5 {
+		t.Error("expected default group quota")
+	}
+}
+
+func TestScheduleGroupQuota(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	conf.FairShareTag = "owner"
+	conf.GroupQuotas = []config.GroupQuota{{Group: "a", MaxTasks: 1}}
+
+	res := &Resources{Cpus: 10, RamGb: 10, DiskGb: 10}
+	nodes := memNodes{
+		"node-1": {Id: "node-1", State: NodeState_ALIVE, Resources: res, Available: res},
+	}
+	queue := memQueue{
+		queuedTask("a1", "a", ""),
+		queuedTask("a2", "a", ""),
+		queuedTask("b1", "b", ""),
+	}
+	s := &Scheduler{
+		Conf:  conf,
+		Nodes: nodes,
+		Queue: queue,
+		Event: events.Noop{},
+	}
+
+	err := s.Schedule(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a1", "b1"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected %v assigned, got %v", expected, nodes["node-1"].TaskIds)
+	}
+}
+
+// skippingQueue is a queue which skips the "skipped" tasks, as if they failed
+// to be read, and returns nil for the "missing" tasks.
+type skippingQueue struct {
+	memQueue
+	skipped map[string]bool
+	missing map[string]bool
+}
+
+func (q skippingQueue) ReadQueue(count int, after string) ([]*tes.Task, string) {
+	page, last := q.memQueue.ReadQueue(count, after)
+	var tasks []*tes.Task
+	for _, t := range page {
+		switch {
+		case q.skipped[t.Id]:
+		case q.missing[t.Id]:
+			tasks = append(tasks, nil)
+		default:
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, last
+}
+
+func TestReadOrderedQueueSkippedTasks(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	conf.FairShareTag = "owner"
+	conf.QueueWindow = 3
+	conf.ScheduleChunk = 3
+
+	// The first page is short a task, and the second page ends with nil.
+	queue := skippingQueue{
+		memQueue: memQueue{
+			queuedTask("a1", "a", ""),
+			queuedTask("a2", "a", ""),
+			queuedTask("a3", "a", ""),
+			queuedTask("a4", "a", ""),
+			queuedTask("a5", "a", ""),
+			queuedTask("a6", "a", ""),
+			queuedTask("b1", "b", ""),
+		},
+		skipped: map[string]bool{"a2": true},
+		missing: map[string]bool{"a6": true},
+	}
+
+	ids := taskIDs(ReadOrderedQueue(queue, conf, nil))
+	expected := []string{"a1", "b1", "a3", "a4"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestScheduleBeyondQueueWindow(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	conf.FairShareTag = "owner"
+	conf.PriorityTag = "priority"
+	conf.QueueWindow = 5
+	conf.ScheduleChunk = 2
+
+	// Group "a" queued many more tasks than the window,
+	// before "b" queued one task and "c" queued an urgent one.
+	var queue memQueue
+	for i := 0; i < 20; i++ {
+		queue = append(queue, queuedTask(fmt.Sprintf("a%02d", i), "a", ""))
+	}
+	queue = append(queue, queuedTask("b1", "b", ""), queuedTask("c1", "c", "10"))
+
+	ids := taskIDs(ReadOrderedQueue(queue, conf, nil))
+	expected := []string{"c1", "a00", "b1", "a01"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+
+	res := &Resources{Cpus: 10, RamGb: 10, DiskGb: 10}
+	nodes := memNodes{
+		"node-1": {Id: "node-1", State: NodeState_ALIVE, Resources: res, Available: res},
+	}
+	s := &Scheduler{
+		Conf:  conf,
+		Nodes: nodes,
+		Queue: queue,
+		Event: events.Noop{},
+	}
+	if err := s.Schedule(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"c1", "a00"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected %v assigned, got %v", expected, nodes["node-1"].TaskIds)
+	}
+
+	// Group "a" is at its quota, so "b" gets the next slot.
+	conf.GroupQuotas = []config.GroupQuota{{Group: "a", MaxTasks: 1}}
+	s.Conf = conf
+	s.Queue = append(queue[1:20:20], queuedTask("b1", "b", ""))
+	if err := s.Schedule(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"c1", "a00", "b1"}
+	if !reflect.DeepEqual(nodes["node-1"].TaskIds, expected) {
+		t.Errorf("expected %v assigned, got %v", expected, nodes["node-1"].TaskIds)
+	}
+}
+
+func TestRequeueAbandonedTasks(t *testing.T) {
+	gone := &Node{
+		Id:               "node-1",
+		State:            NodeState_GONE,
+		TaskIds:          []string{"t1", "t2", "t3"},
+		AbandonedTaskIds: []string{"t1", "t3"},
+	}
+	rec := &eventRecorder{}
+	s := &Scheduler{
+		Conf:  config.DefaultConfig().Scheduler,
+		Nodes: memNodes{"node-1": gone},
+		Event: rec,
+		Tasks: memTasks{
+			"t1": {Id: "t1", State: tes.State_RUNNING, Logs: []*tes.TaskLog{{}}},
+			"t2": {Id: "t2", State: tes.State_RUNNING},
+			// t3 finished during the node's shutdown.
+			"t3": {Id: "t3", State: tes.State_COMPLETE},
+		},
+	}
+
+	if err := s.CheckNodes(); err != nil {
+		t.Fatal(err)
+	}
+
+	states := map[string]tes.State{}
+	var attempt uint32
+	for _, ev := range *rec {
+		switch ev.Type {
+		case events.Type_TASK_STATE:
+			states[ev.Id] = ev.GetState()
+		case events.Type_SYSTEM_LOG:
+			if ev.Id == "t1" {
+				attempt = ev.Attempt
+			}
+		}
+	}
+	if states["t1"] != tes.State_QUEUED {
+		t.Errorf("expected abandoned task to be re-queued, got %s", states["t1"])
+	}
+	if attempt != 1 {
+		t.Errorf("expected the re-queued task to start attempt 1, got %d", attempt)
+	}
+	if _, ok := states["t3"]; ok {
+		t.Errorf("expected finished task not to be re-queued, got %s", states["t3"])
+	}
+	if states["t2"] != tes.State_SYSTEM_ERROR {
+		t.Errorf("expected task on gone node to fail, got %s", states["t2"])
+	}
+}
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -10,7 +11,7 @@ var errRunSetWaitTimedOut = errors.New("runSet.Wait() timed out")
 
 func newRunSet() *runSet {
 	return &runSet{
-		runners: make(map[string]struct{}),
+		runners: make(map[string]*runner),
 	}
 }
 
@@ -19,7 +20,12 @@ func newRunSet() *runSet {
 type runSet struct {
 	wg      sync.WaitGroup
 	mtx     sync.Mutex
-	runners map[string]struct{}
+	runners map[string]*runner
+}
+
+type runner struct {
+	cancel context.CancelFunc
+	added  time.Time
 }
 
 // Add tries to add an ID to the set and returns true if it was added,
@@ -30,7 +36,7 @@ func (r *runSet) Add(id string) bool {
 
 	// Only add the ID if it doesn't already exist.
 	if _, ok := r.runners[id]; !ok {
-		r.runners[id] = struct{}{}
+		r.runners[id] = &runner{added: time.Now()}
 		r.wg.Add(1)
 		return true
 	}
@@ -42,12 +48,47 @@ func (r *runSet) Remove(id string) {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	// Only remove if the ID exists in the set.
-	if _, ok := r.runners[id]; ok {
+	if x, ok := r.runners[id]; ok {
+		if x.cancel != nil {
+			x.cancel()
+		}
 		r.wg.Done()
 		delete(r.runners, id)
 	}
 }
 
+// SetCancel sets the function used to cancel the runner with the given ID.
+func (r *runSet) SetCancel(id string, cancel context.CancelFunc) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if x, ok := r.runners[id]; ok {
+		x.cancel = cancel
+	}
+}
+
+// Cancel cancels the runner with the given ID, if it has a cancel function.
+// The ID stays in the set until the runner calls Remove.
+func (r *runSet) Cancel(id string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if x, ok := r.runners[id]; ok && x.cancel != nil {
+		x.cancel()
+	}
+}
+
+// AddedBefore returns the IDs in the set which were added before the given time.
+func (r *runSet) AddedBefore(t time.Time) []string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	var ids []string
+	for id, x := range r.runners {
+		if x.added.Before(t) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // Wait for all runners to exit.
 func (r *runSet) Wait(timeout time.Duration) error {
 	done := make(chan struct{})
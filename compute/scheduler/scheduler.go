@@ -14,7 +14,15 @@ import (
 
 // TaskQueue describes the interface the scheduler uses to find tasks that need scheduling.
 type TaskQueue interface {
-	ReadQueue(count int) []*tes.Task
+	// ReadQueue returns up to "count" queued tasks, oldest first,
+	// starting after the task with ID "after". An empty "after" reads
+	// from the start of the queue.
+	//
+	// Up to "count" entries of the queue are scanned, and tasks which can't be
+	// read are skipped, so fewer tasks may be returned before the end of the
+	// queue. "last" is the ID of the last entry scanned, where the next page
+	// starts. It's empty once the end of the queue is reached.
+	ReadQueue(count int, after string) (tasks []*tes.Task, last string)
 }
 
 // Scheduler handles scheduling tasks to nodes and support many backends.
@@ -24,6 +32,24 @@ type Scheduler struct {
 	Nodes SchedulerServiceServer
 	Queue TaskQueue
 	Event events.Writer
+	// Policy used to pick a node for a task.
+	// If nil, the policy is created from Conf.
+	Policy *Policy
+	// Tasks is used to look up tasks which were assigned to nodes before
+	// the scheduler started. If nil, only tasks assigned by this scheduler are tracked.
+	Tasks tes.ReadOnlyServer
+	// Sessions is used to push task assignments to nodes as soon as they're made.
+	// If nil, nodes pick up assignments on their next sync.
+	Sessions *NodeSessions
+
+	// tasks assigned to nodes, by task ID.
+	assigned map[string]*assignedTask
+	// runtime history by task name.
+	history map[string]*runtimeHistory
+	// recent task outcomes by node ID, true if the task failed with a system error.
+	outcomes map[string][]bool
+	// time nodes were quarantined, by node ID.
+	quarantined map[string]time.Time
 }
 
 // Run starts the scheduling loop. This blocks.
@@ -62,7 +88,15 @@ func (s *Scheduler) CheckNodes() error {
 		var err error
 
 		if node.State == NodeState_GONE {
+			abandoned := map[string]bool{}
+			for _, tid := range node.AbandonedTaskIds {
+				abandoned[tid] = true
+			}
 			for _, tid := range node.TaskIds {
+				if abandoned[tid] {
+					s.requeue(ctx, tid, node.Id)
+					continue
+				}
 				s.Event.WriteEvent(ctx, events.NewState(tid, tes.State_SYSTEM_ERROR))
 				s.Event.WriteEvent(ctx, events.NewSystemLog(tid, 0, 0, "info",
 					"Cleaning up Task assigned to dead/gone node", map[string]string{
@@ -82,25 +116,107 @@ func (s *Scheduler) CheckNodes() error {
 	return nil
 }
 
+// requeue puts a task which was abandoned by a node, e.g. when the node
+// shut down, back in the queue. The task runs again as a new attempt.
+// Tasks which finished while the node was shutting down are left alone.
+func (s *Scheduler) requeue(ctx context.Context, taskID, nodeID string) {
+	var attempt uint32
+	if s.Tasks != nil {
+		task, err := s.Tasks.GetTask(ctx, &tes.GetTaskRequest{Id: taskID, View: tes.TaskView_BASIC})
+		if err == nil {
+			if tes.TerminalState(task.GetState()) {
+				s.Log.Debug("Abandoned task already finished, not re-queueing",
+					"taskID", taskID, "nodeID", nodeID, "state", task.GetState())
+				return
+			}
+			attempt = uint32(len(task.GetLogs()))
+		}
+	}
+
+	err := s.Event.WriteEvent(ctx, events.NewState(taskID, tes.State_QUEUED))
+	if err != nil {
+		s.Log.Error("Error re-queueing task", "error", err, "taskID", taskID, "nodeID", nodeID)
+		return
+	}
+	s.Log.Info("Re-queued task abandoned by node", "taskID", taskID, "nodeID", nodeID, "attempt", attempt)
+	// The first log of the new attempt.
+	s.Event.WriteEvent(ctx, events.NewSystemLog(taskID, attempt, 0, "info",
+		"Re-queued task abandoned by node", map[string]string{
+			"nodeID": nodeID,
+		}))
+}
+
 // Schedule does a scheduling iteration. It checks the health of nodes
-// in the database, gets a chunk of tasks from the queue (configurable by config.ScheduleChunk),
-// and calls the given scheduler backend. If the backend returns a valid offer, the
-// task is assigned to the offered node.
+// in the database, reads the queue (see ReadOrderedQueue),
+// orders it by priority and fair-share, and tries to schedule a chunk of the tasks
+// (configurable by config.ScheduleChunk). Tasks in groups which have reached their
+// quota are skipped. If the backend returns a valid offer, the task is assigned to
+// the offered node.
+//
+// If config.Reservations is enabled, the first task which doesn't fit any node
+// reserves the node where it is expected to start the soonest. Other tasks are
+// only assigned to that node if they won't delay the reserved task.
 func (s *Scheduler) Schedule(ctx context.Context) error {
 	err := s.CheckNodes()
 	if err != nil {
 		s.Log.Error("Error checking nodes", err)
 	}
 
-	for _, task := range s.Queue.ReadQueue(s.Conf.ScheduleChunk) {
+	var nodes []*Node
+	resp, err := s.Nodes.ListNodes(ctx, &ListNodesRequest{})
+	if err == nil {
+		nodes = resp.Nodes
+	}
+	usage := s.syncAssigned(ctx, nodes)
+	s.checkQuarantine(ctx, nodes, time.Now())
+
+	var res *reservation
+	attempted := 0
+	for _, task := range ReadOrderedQueue(s.Queue, s.Conf, usage) {
+		if attempted >= s.Conf.ScheduleChunk {
+			break
+		}
+
+		group := TaskGroup(task, s.Conf)
+		if quota := GroupQuota(group, s.Conf); quota > 0 && usage[group] >= quota {
+			s.Log.Debug("Group quota reached, skipping task",
+				"taskID", task.Id,
+				"group", group,
+				"quota", quota,
+			)
+			continue
+		}
+		attempted++
+
 		offer := s.GetOffer(task)
+		if offer != nil && res != nil && offer.Node.Id == res.node.Id &&
+			!res.allows(task, s.expectedRuntime(task), time.Now()) {
+			// The task would delay the reserved task, so try the other nodes.
+			offer = s.getOffer(task, res.node.Id)
+		}
+
+		if offer == nil && res == nil && s.Conf.Reservations {
+			// Nodes may have changed since the start of this iteration.
+			if resp, err := s.Nodes.ListNodes(ctx, &ListNodesRequest{}); err == nil {
+				nodes = resp.Nodes
+			}
+			res = s.reserve(task, nodes, time.Now())
+			if res != nil {
+				s.Log.Debug("Reserving node for task",
+					"taskID", task.Id,
+					"nodeID", res.node.Id,
+					"expectedStart", res.start,
+				)
+			}
+		}
+
 		if offer != nil {
 			s.Log.Info("Assigning task to node",
 				"taskID", task.Id,
 				"nodeID", offer.Node.Id,
 				"node", offer.Node,
 			)
-			s.Event.WriteEvent(ctx, events.NewSystemLog(task.Id, 0, 0, "info",
+			s.Event.WriteEvent(ctx, events.NewSystemLog(task.Id, task.Attempt(), 0, "info",
 				"Assigning task to node", map[string]string{
 					"nodeID": offer.Node.Id,
 				}))
@@ -116,13 +232,16 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 					"taskID", task.Id,
 					"nodeID", offer.Node.Id,
 				)
-				s.Event.WriteEvent(ctx, events.NewSystemLog(task.Id, 0, 0, "error",
+				s.Event.WriteEvent(ctx, events.NewSystemLog(task.Id, task.Attempt(), 0, "error",
 					"Error in AssignTask", map[string]string{
 						"error":  err.Error(),
 						"nodeID": offer.Node.Id,
 					}))
 				continue
 			}
+			usage[group]++
+			s.trackTask(task, offer.Node.Id, time.Now())
+			s.Sessions.Assign(offer.Node.Id, task.Id)
 
 			err = s.Event.WriteEvent(ctx, events.NewState(task.Id, tes.State_INITIALIZING))
 			if err != nil {
@@ -141,11 +260,47 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 
 // GetOffer returns an offer based on available funnel nodes.
 func (s *Scheduler) GetOffer(j *tes.Task) *Offer {
+	return s.getOffer(j, "")
+}
+
+// getOffer returns an offer based on available funnel nodes,
+// excluding the node with the given ID.
+func (s *Scheduler) getOffer(j *tes.Task, exclude string) *Offer {
 	// Get the nodes from the funnel server
 	nodes := []*Node{}
 	resp, err := s.Nodes.ListNodes(context.Background(), &ListNodesRequest{})
 	if err == nil {
-		nodes = resp.Nodes
+		for _, n := range resp.Nodes {
+			if n.Id != exclude {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+
+	if s.Policy == nil {
+		p, err := NewPolicy(s.Conf)
+		if err != nil {
+			s.Log.Error("Invalid scheduler policy, using default", "error", err)
+			p, _ = NewPolicy(config.Scheduler{})
+		}
+		s.Policy = p
+	}
+
+	offer, rejected := s.Policy.Schedule(j, nodes)
+	if offer != nil {
+		s.Log.Debug("Schedule decision",
+			"taskID", j.Id,
+			"policy", s.Policy.Name,
+			"nodeID", offer.Node.Id,
+			"scores", offer.Scores,
+			"rejected", rejected,
+		)
+	} else {
+		s.Log.Debug("Schedule decision",
+			"taskID", j.Id,
+			"policy", s.Policy.Name,
+			"rejected", rejected,
+		)
 	}
-	return DefaultScheduleAlgorithm(j, nodes, nil)
+	return offer
 }
@@ -6,15 +6,22 @@ import (
 	"github.com/ohsu-comp-bio/funnel/tes"
 )
 
-// Scores describe how well a task fits a node.
+// Scores describe how well a task fits a node. Higher scores are better.
 type Scores map[string]float32
 
 // Scores keys
 const (
-	CPU = "cpu"
-	RAM = "ram"
+	CPU   = "cpu"
+	RAM   = "ram"
+	DISK  = "disk"
+	TASKS = "tasks"
+	// PREEMPTIBLE is added to the scores of every policy.
+	PREEMPTIBLE = "preemptible"
 )
 
+// ScoreFunc returns the scores of a node for the given task.
+type ScoreFunc func(*Node, *tes.Task) Scores
+
 // Average returns the average of the scores.
 func (s Scores) Average() float32 {
 	var tot float32
@@ -34,29 +41,77 @@ func (s Scores) Weighted(w map[string]float32) Scores {
 	return out
 }
 
-// DefaultScores returns a default set of scores.
+// DefaultScores returns a default set of scores, which is BinPackScores.
 func DefaultScores(w *Node, t *tes.Task) Scores {
-	req := t.GetResources()
-	tot := w.GetResources()
-	avail := w.GetAvailable()
-	s := Scores{}
+	return BinPackScores(w, t)
+}
 
-	s[CPU] = float32(avail.GetCpus()+req.GetCpuCores()) / float32(tot.GetCpus())
-	s[RAM] = float32(avail.GetRamGb() + req.GetRamGb()/tot.GetRamGb())
+// BinPackScores prefers the nodes which will have the least free resources
+// after the task is assigned, keeping other nodes free for large tasks.
+func BinPackScores(w *Node, t *tes.Task) Scores {
+	s := Scores{}
+	for k, v := range freeFractions(w, t) {
+		s[k] = 1 - v
+	}
 	return s
 }
 
-// SortByAverageScore sorts the given offers by their average score.
-// This modifies the offers list in place.
+// LeastLoadedScores prefers the nodes which will have the most free resources
+// after the task is assigned.
+func LeastLoadedScores(w *Node, t *tes.Task) Scores {
+	return freeFractions(w, t)
+}
+
+// SpreadScores prefers the nodes with the fewest assigned tasks.
+func SpreadScores(w *Node, t *tes.Task) Scores {
+	return Scores{
+		TASKS: 1 / float32(1+len(w.GetTaskIds())),
+	}
+}
+
+// PreemptibleScores prefers preemptible nodes for tasks which allow preemption,
+// keeping the other nodes free for tasks which don't.
+func PreemptibleScores(w *Node, t *tes.Task) Scores {
+	if t.GetResources().GetPreemptible() && !w.GetPreemptible() {
+		return Scores{PREEMPTIBLE: 0}
+	}
+	return Scores{PREEMPTIBLE: 1}
+}
+
+// freeFractions returns the fraction of each of the node's resources
+// which will be free after the task is assigned, between 0.0 and 1.0.
+func freeFractions(w *Node, t *tes.Task) Scores {
+	tot := w.GetResources()
+	avail := SubtractResources(t, w.GetAvailable())
+	return Scores{
+		CPU:  fraction(float64(avail.GetCpus()), float64(tot.GetCpus())),
+		RAM:  fraction(avail.GetRamGb(), tot.GetRamGb()),
+		DISK: fraction(avail.GetDiskGb(), tot.GetDiskGb()),
+	}
+}
+
+func fraction(a, b float64) float32 {
+	if b <= 0 {
+		return 0
+	}
+	f := float32(a / b)
+	if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// SortByAverageScore sorts the given offers by their average score,
+// highest (best) first. This modifies the offers list in place.
 func SortByAverageScore(offers []*Offer) {
 	// Pre-calculate the averages scores so that we're not re-calculating
 	// many times during sort
-	averages := make([]float32, len(offers))
+	averages := make([]float32, 0, len(offers))
 	for _, o := range offers {
 		averages = append(averages, o.Scores.Average())
 	}
 	s := sorter{offers, averages}
-	sort.Sort(s)
+	sort.Stable(s)
 }
 
 // sorter is a helper which implements Go's sort.Interface
@@ -74,5 +129,5 @@ func (s sorter) Swap(i, j int) {
 	s.averages[i], s.averages[j] = s.averages[j], s.averages[i]
 }
 func (s sorter) Less(i, j int) bool {
-	return s.averages[i] < s.averages[j]
+	return s.averages[i] > s.averages[j]
 }
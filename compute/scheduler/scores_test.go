@@ -4,6 +4,7 @@ import (
 	"runtime/debug"
 	"testing"
 
+	"github.com/ohsu-comp-bio/funnel/config"
 	"github.com/ohsu-comp-bio/funnel/tes"
 )
 
@@ -18,3 +19,141 @@ func TestDefaultScoresEmptyTask(t *testing.T) {
 	w := &Node{}
 	DefaultScores(w, j)
 }
+
+func testPolicyNodes() []*Node {
+	full := &Resources{Cpus: 4, RamGb: 4, DiskGb: 4}
+	return []*Node{
+		{
+			Id:        "empty",
+			State:     NodeState_ALIVE,
+			Resources: full,
+			Available: full,
+		},
+		{
+			Id:        "half",
+			State:     NodeState_ALIVE,
+			Resources: full,
+			Available: &Resources{Cpus: 2, RamGb: 2, DiskGb: 2},
+			TaskIds:   []string{"task-1"},
+		},
+		{
+			Id:        "busy",
+			State:     NodeState_ALIVE,
+			Resources: full,
+			Available: &Resources{Cpus: 3, RamGb: 3, DiskGb: 3},
+			TaskIds:   []string{"task-2", "task-3"},
+		},
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	j := &tes.Task{Resources: &tes.Resources{CpuCores: 1, RamGb: 1, DiskGb: 1}}
+
+	expected := map[string]string{
+		BinPack:     "half",
+		LeastLoaded: "empty",
+		Spread:      "empty",
+	}
+	for name, nodeID := range expected {
+		conf := config.DefaultConfig().Scheduler
+		conf.Policy = name
+		p, err := NewPolicy(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o, _ := p.Schedule(j, testPolicyNodes())
+		if o == nil || o.Node.Id != nodeID {
+			t.Errorf("policy %s: expected node %s, got %v", name, nodeID, o)
+		}
+	}
+
+	conf := config.DefaultConfig().Scheduler
+	conf.Policy = "unknown"
+	if _, err := NewPolicy(conf); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestPolicyWeights(t *testing.T) {
+	j := &tes.Task{Resources: &tes.Resources{CpuCores: 1, RamGb: 1, DiskGb: 1}}
+	nodes := []*Node{
+		{
+			Id:        "cpu-free",
+			State:     NodeState_ALIVE,
+			Resources: &Resources{Cpus: 8, RamGb: 4, DiskGb: 4},
+			Available: &Resources{Cpus: 8, RamGb: 2, DiskGb: 2},
+		},
+		{
+			Id:        "ram-free",
+			State:     NodeState_ALIVE,
+			Resources: &Resources{Cpus: 8, RamGb: 4, DiskGb: 4},
+			Available: &Resources{Cpus: 2, RamGb: 4, DiskGb: 4},
+		},
+	}
+
+	conf := config.DefaultConfig().Scheduler
+	conf.Policy = LeastLoaded
+	conf.Weights = config.SchedulerWeights{CPU: 1}
+	p, _ := NewPolicy(conf)
+	if o, _ := p.Schedule(j, nodes); o == nil || o.Node.Id != "cpu-free" {
+		t.Errorf("expected cpu-free node, got %v", o)
+	}
+
+	conf.Weights = config.SchedulerWeights{RAM: 1}
+	p, _ = NewPolicy(conf)
+	if o, _ := p.Schedule(j, nodes); o == nil || o.Node.Id != "ram-free" {
+		t.Errorf("expected ram-free node, got %v", o)
+	}
+}
+
+func TestPolicyRejected(t *testing.T) {
+	j := &tes.Task{
+		Resources: &tes.Resources{CpuCores: 1},
+		Tags:      map[string]string{"pool": "gpu"},
+	}
+	conf := config.DefaultConfig().Scheduler
+	conf.Predicates = []config.SchedulerPredicate{{TaskTag: "pool"}}
+	p, err := NewPolicy(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := testPolicyNodes()
+	nodes[1].Metadata = map[string]string{"pool": "gpu"}
+
+	o, rejected := p.Schedule(j, nodes)
+	if o == nil || o.Node.Id != "half" {
+		t.Errorf("expected node with matching metadata, got %v", o)
+	}
+	if len(rejected["empty"]) != 1 || len(rejected["busy"]) != 1 {
+		t.Errorf("expected rejected predicates for other nodes, got %v", rejected)
+	}
+}
+
+func TestPreemptibleScores(t *testing.T) {
+	conf := config.DefaultConfig().Scheduler
+	conf.Policy = LeastLoaded
+	p, err := NewPolicy(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := testPolicyNodes()
+	nodes[2].Preemptible = true
+
+	// Preemptible tasks prefer preemptible nodes.
+	j := &tes.Task{Resources: &tes.Resources{CpuCores: 1, RamGb: 1, DiskGb: 1, Preemptible: true}}
+	o, _ := p.Schedule(j, nodes)
+	if o == nil || o.Node.Id != "busy" {
+		t.Errorf("expected node busy, got %v", o)
+	}
+
+	// Other tasks don't run on preemptible nodes.
+	j.Resources.Preemptible = false
+	o, rejected := p.Schedule(j, nodes)
+	if o == nil || o.Node.Id != "empty" {
+		t.Errorf("expected node empty, got %v", o)
+	}
+	if len(rejected["busy"]) == 0 {
+		t.Error("expected preemptible node to be rejected")
+	}
+}
@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// sessionBuffer is the number of updates buffered per node session.
+// Updates are dropped when the buffer is full, in which case the node
+// picks up the change on its next sync.
+const sessionBuffer = 100
+
+// NodeSessions implements the NodeSessionService. It tracks the sessions
+// opened by nodes, and pushes task assignments and cancels to them.
+//
+// NodeSessions is also an events.Writer, which pushes a cancel to the node
+// running a task when the task is canceled.
+//
+// A nil *NodeSessions is valid, and pushes nothing.
+type NodeSessions struct {
+	Log *logger.Logger
+
+	mtx      sync.Mutex
+	sessions map[string]*nodeSession
+	// node ID by task ID, for tasks assigned through Assign.
+	tasks map[string]string
+}
+
+type nodeSession struct {
+	updates chan *NodeSessionResponse
+}
+
+// NodeSession handles a session opened by a node. The first message from
+// the node must contain its ID. The session stays open until the node
+// closes it or the context is canceled.
+func (s *NodeSessions) NodeSession(stream NodeSessionService_NodeSessionServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if req.Id == "" {
+		return grpc.Errorf(codes.InvalidArgument, "missing node ID")
+	}
+
+	sess := &nodeSession{updates: make(chan *NodeSessionResponse, sessionBuffer)}
+	s.mtx.Lock()
+	if s.sessions == nil {
+		s.sessions = map[string]*nodeSession{}
+	}
+	s.sessions[req.Id] = sess
+	s.mtx.Unlock()
+	s.Log.Debug("Node session opened", "nodeID", req.Id)
+
+	defer func() {
+		s.mtx.Lock()
+		// The node may have opened a new session in the meantime.
+		if s.sessions[req.Id] == sess {
+			delete(s.sessions, req.Id)
+		}
+		s.mtx.Unlock()
+		s.Log.Debug("Node session closed", "nodeID", req.Id)
+	}()
+
+	// Detect when the node closes the session.
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-closed:
+			return nil
+		case u := <-sess.updates:
+			if err := stream.Send(u); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Assign pushes a task assignment to the node. False is returned if the node
+// doesn't have an open session, or the update couldn't be queued.
+func (s *NodeSessions) Assign(nodeID, taskID string) bool {
+	if s == nil {
+		return false
+	}
+	s.mtx.Lock()
+	if s.tasks == nil {
+		s.tasks = map[string]string{}
+	}
+	s.tasks[taskID] = nodeID
+	s.mtx.Unlock()
+	return s.push(nodeID, &NodeSessionResponse{Assign: []string{taskID}})
+}
+
+// Cancel pushes a task cancel to the node, which stops the task's worker.
+// False is returned if the node doesn't have an open session, or the update
+// couldn't be queued.
+func (s *NodeSessions) Cancel(nodeID, taskID string) bool {
+	if s == nil {
+		return false
+	}
+	s.mtx.Lock()
+	delete(s.tasks, taskID)
+	s.mtx.Unlock()
+	return s.push(nodeID, &NodeSessionResponse{Cancel: []string{taskID}})
+}
+
+// WriteEvent pushes a cancel to the node running the task when the task
+// is canceled. Tasks which reach another terminal state are forgotten.
+func (s *NodeSessions) WriteEvent(ctx context.Context, ev *events.Event) error {
+	if s == nil || ev.Type != events.Type_TASK_STATE || !tes.TerminalState(ev.GetState()) {
+		return nil
+	}
+
+	s.mtx.Lock()
+	nodeID, ok := s.tasks[ev.Id]
+	delete(s.tasks, ev.Id)
+	s.mtx.Unlock()
+
+	if ok && ev.GetState() == tes.State_CANCELED {
+		s.Cancel(nodeID, ev.Id)
+	}
+	return nil
+}
+
+func (s *NodeSessions) push(nodeID string, u *NodeSessionResponse) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sess, ok := s.sessions[nodeID]
+	if !ok {
+		return false
+	}
+	select {
+	case sess.updates <- u:
+		return true
+	default:
+		s.Log.Debug("Node session buffer full, dropping update", "nodeID", nodeID)
+		return false
+	}
+}
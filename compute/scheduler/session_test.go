@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"google.golang.org/grpc"
+)
+
+// testServerStream is a NodeSessionService_NodeSessionServer backed by channels.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv chan *NodeSessionRequest
+	sent chan *NodeSessionResponse
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testServerStream) Send(resp *NodeSessionResponse) error {
+	s.sent <- resp
+	return nil
+}
+
+func (s *testServerStream) Recv() (*NodeSessionRequest, error) {
+	req, ok := <-s.recv
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+// testClientStream is a NodeSessionService_NodeSessionClient backed by channels.
+type testClientStream struct {
+	grpc.ClientStream
+	recv chan *NodeSessionResponse
+}
+
+func (s *testClientStream) Send(req *NodeSessionRequest) error {
+	return nil
+}
+
+func (s *testClientStream) Recv() (*NodeSessionResponse, error) {
+	resp, ok := <-s.recv
+	if !ok {
+		return nil, io.EOF
+	}
+	return resp, nil
+}
+
+func openTestSession(t *testing.T, s *NodeSessions, nodeID string) (*testServerStream, chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &testServerStream{
+		ctx:  ctx,
+		recv: make(chan *NodeSessionRequest, 1),
+		sent: make(chan *NodeSessionResponse, 10),
+	}
+	stream.recv <- &NodeSessionRequest{Id: nodeID}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.NodeSession(stream)
+		cancel()
+	}()
+
+	// Wait for the session to be registered.
+	for i := 0; i < 100; i++ {
+		s.mtx.Lock()
+		_, ok := s.sessions[nodeID]
+		s.mtx.Unlock()
+		if ok {
+			return stream, done
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("session wasn't opened")
+	return nil, nil
+}
+
+func nextUpdate(t *testing.T, stream *testServerStream) *NodeSessionResponse {
+	select {
+	case u := <-stream.sent:
+		return u
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for node session update")
+	}
+	return nil
+}
+
+func TestNodeSessionPush(t *testing.T) {
+	s := &NodeSessions{}
+	stream, done := openTestSession(t, s, "node-1")
+
+	if !s.Assign("node-1", "task-1") {
+		t.Fatal("expected assignment to be pushed")
+	}
+	if s.Assign("node-2", "task-2") {
+		t.Error("expected no push to a node without a session")
+	}
+	u := nextUpdate(t, stream)
+	if !reflect.DeepEqual(u.Assign, []string{"task-1"}) {
+		t.Errorf("unexpected update: %v", u)
+	}
+
+	// Canceling the task pushes a cancel to the node.
+	s.WriteEvent(context.Background(), events.NewState("task-1", tes.State_CANCELED))
+	u = nextUpdate(t, stream)
+	if !reflect.DeepEqual(u.Cancel, []string{"task-1"}) {
+		t.Errorf("unexpected update: %v", u)
+	}
+
+	// The session ends when the node closes it.
+	close(stream.recv)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected session to end")
+	}
+	if s.Assign("node-1", "task-3") {
+		t.Error("expected no push after the session ended")
+	}
+}
+
+func TestNodeSessionNil(t *testing.T) {
+	var s *NodeSessions
+	if s.Assign("node-1", "task-1") || s.Cancel("node-1", "task-1") {
+		t.Error("expected nil sessions to push nothing")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/ohsu-comp-bio/funnel/logger"
 	"github.com/ohsu-comp-bio/funnel/util"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // testNode wraps Node with some testing helpers.
@@ -43,6 +45,8 @@ func newTestNode(conf config.Config, t *testing.T) testNode {
 		Return(nil, nil)
 	s.On("PutNode", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(nil, nil)
+	s.On("NodeSession", mock.Anything).
+		Return(nil, status.Error(codes.Unimplemented, "node sessions are not supported"))
 	s.On("Close").Return(nil)
 
 	return testNode{
@@ -29,6 +29,12 @@ func detectResources(conf config.Node, workdir string) (Resources, error) {
 		RamGb:  conf.Resources.RamGb,
 		DiskGb: conf.Resources.DiskGb,
 	}
+	for _, c := range conf.Resources.Custom {
+		if res.Custom == nil {
+			res.Custom = map[string]float64{}
+		}
+		res.Custom[c.Name] = c.Amount
+	}
 
 	cpuinfo, err := pscpu.Info()
 	if err != nil {
@@ -30,6 +30,7 @@ func NewBackend(ctx context.Context, conf config.Config, reader tes.ReadOnlyServ
 		ExtractID:     extractID,
 		MapStates:     mapStates,
 		ReconcileRate: time.Duration(conf.Slurm.ReconcileRate),
+		AllowedTags:   conf.Slurm.AllowedTags,
 	}
 
 	if !conf.Slurm.DisableReconciler {
@@ -43,22 +43,63 @@ func DefaultConfig() Config {
 		Scheduler: Scheduler{
 			ScheduleRate:    Duration(time.Second),
 			ScheduleChunk:   10,
+			QueueWindow:     1000,
+			PriorityTag:     "priority",
+			RuntimeTag:      "expected-runtime",
+			DefaultRuntime:  Duration(time.Hour),
 			NodePingTimeout: Duration(time.Minute),
 			NodeInitTimeout: Duration(time.Minute * 5),
 			NodeDeadTimeout: Duration(time.Minute * 5),
+			Policy:          "binpack",
+			Weights: SchedulerWeights{
+				CPU:         1,
+				RAM:         1,
+				Disk:        1,
+				Tasks:       1,
+				Preemptible: 1,
+			},
+			Autoscaler: Autoscaler{
+				Rate:         Duration(time.Second * 30),
+				IdleTimeout:  Duration(time.Minute * 10),
+				StartTimeout: Duration(time.Minute * 10),
+			},
+			Quarantine: Quarantine{
+				FailureRate: 0.5,
+				Duration:    Duration(time.Minute * 30),
+			},
 		},
 		Node: Node{
-			Timeout:    -1,
-			UpdateRate: Duration(time.Second * 5),
-			Metadata:   map[string]string{},
+			Timeout:             -1,
+			UpdateRate:          Duration(time.Second * 5),
+			ShutdownGracePeriod: Duration(time.Second * 10),
+			Metadata:            map[string]string{},
+			HealthCheck: NodeHealthCheck{
+				Rate:    Duration(time.Second * 30),
+				Timeout: Duration(time.Second * 10),
+			},
+			PreemptionNotice: NodePreemptionNotice{
+				Rate: Duration(time.Second * 5),
+			},
 		},
 		Worker: Worker{
 			WorkDir:       workDir,
 			PollingRate:   Duration(time.Second * 5),
 			LogUpdateRate: Duration(time.Second * 5),
 			LogTailSize:   10000,
+			DiskQuota: WorkerDiskQuota{
+				Rate: Duration(time.Second * 10),
+			},
+			EventSpool: WorkerEventSpool{
+				Enabled:      true,
+				Rate:         Duration(time.Second * 5),
+				FlushTimeout: Duration(time.Minute),
+			},
 		},
 		Logger: logger.DefaultConfig(),
+		Retention: Retention{
+			Rate:      Duration(time.Hour),
+			BatchSize: 1000,
+		},
 		// databases / event handlers
 		BoltDB: BoltDB{
 			Path: path.Join(workDir, "funnel.db"),
@@ -78,8 +119,27 @@ func DefaultConfig() Config {
 			Timeout:  Duration(time.Minute * 5),
 			Database: "funnel",
 		},
+		Postgres: Postgres{
+			URL:    "postgres://localhost:5432/funnel?sslmode=disable",
+			Schema: "public",
+		},
+		SQLite: SQLite{
+			Path: path.Join(workDir, "funnel.sqlite.db"),
+		},
 		Kafka: Kafka{
-			Topic: "funnel",
+			Topic:           "funnel",
+			Group:           "funnel-server",
+			DeadLetterTopic: "funnel-dead-letter",
+		},
+		PubSub: PubSub{
+			Topic:           "funnel",
+			Subscription:    "funnel-server",
+			DeadLetterTopic: "funnel-dead-letter",
+		},
+		Webhook: Webhook{
+			Timeout:   Duration(time.Second * 10),
+			MaxTries:  10,
+			QueueSize: 1000,
 		},
 		// storage
 		LocalStorage: LocalStorage{
@@ -102,23 +162,33 @@ func DefaultConfig() Config {
 	// compute
 	reconcile := Duration(time.Minute * 10)
 
+	// Each backend gets its own copy of the allowed tags,
+	// so that changing one backend's list doesn't change the others.
+	hpcTags := func() []string {
+		return []string{"partition", "queue", "account", "qos", "walltime", "gpus"}
+	}
+
 	htcondorTemplate, _ := intern.Asset("config/htcondor-template.txt")
 	c.HTCondor.Template = string(htcondorTemplate)
 	c.HTCondor.ReconcileRate = reconcile
 	c.HTCondor.DisableReconciler = true
+	c.HTCondor.AllowedTags = hpcTags()
 
 	slurmTemplate, _ := intern.Asset("config/slurm-template.txt")
 	c.Slurm.Template = string(slurmTemplate)
 	c.Slurm.ReconcileRate = reconcile
 	c.Slurm.DisableReconciler = true
+	c.Slurm.AllowedTags = hpcTags()
 
 	pbsTemplate, _ := intern.Asset("config/pbs-template.txt")
 	c.PBS.Template = string(pbsTemplate)
 	c.PBS.ReconcileRate = reconcile
 	c.PBS.DisableReconciler = true
+	c.PBS.AllowedTags = hpcTags()
 
 	geTemplate, _ := intern.Asset("config/gridengine-template.txt")
 	c.GridEngine.Template = string(geTemplate)
+	c.GridEngine.AllowedTags = hpcTags()
 
 	c.AWSBatch.JobDefinition = "funnel-job-def"
 	c.AWSBatch.JobQueue = "funnel-job-queue"
@@ -72,7 +72,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _configGridengineTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\xcd\x4a\xc4\x30\x14\x85\xf7\x79\x8a\x6b\xc7\x59\x26\xed\x0b\xb8\xb2\x30\xb8\x71\x21\x82\x4b\x69\xc9\x0d\x13\x32\xf9\xe1\x26\x51\x30\xe4\xdd\xa5\x69\x11\x0a\x75\x76\x97\xc3\x77\x3e\xb8\xe7\xf4\xd0\xcf\xda\xf5\xf3\x14\xaf\xec\xf4\x08\xfc\x15\x4a\x11\xef\x53\x34\x2f\xb2\xd6\x96\xf8\x25\xf9\xf0\x64\x46\x4d\xb5\xf6\x2a\x3b\x87\x37\x1e\x93\xf4\x39\x35\x00\xff\x03\x90\x88\x95\xa2\x15\x38\x04\xf1\x1c\x72\x84\x01\x78\xad\xac\x94\x40\xda\x25\x05\xdd\x52\x0f\x08\x36\x68\x38\xcb\x6e\x85\x1a\xc0\x01\x9d\x6c\xd7\x56\x7f\x9b\xec\x65\x86\x41\x1c\x19\x6e\x70\xfd\xfc\xb2\x68\x9f\xce\x62\x50\x97\x6e\x83\x8f\x3d\xa3\x8e\xe6\xae\x48\x45\xfd\x83\x7f\xa6\x15\xdf\xa9\xd8\xfa\x20\x7c\x7b\x32\x48\x40\xd9\x01\xe7\x69\x59\x6c\xdc\x6d\xf7\x1b\x00\x00\xff\xff\xcf\x92\x30\x7f\x5a\x01\x00\x00")
+var _configGridengineTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x7d\x91\xcd\x8a\xc2\x30\x14\x46\xf7\x7d\x8a\x3b\xd5\x2e\x93\xf6\x05\x5c\x88\x05\x99\xcd\x80\x22\xb8\x94\xd6\xde\x60\x68\x93\xd6\xfc\x28\x18\xfa\xee\xda\xb4\x0a\x62\x9c\x5d\x48\xce\x39\x04\xbe\xd9\x4f\x5a\x72\x99\x96\x85\x3e\x45\xb3\x39\x90\x3f\x70\x8e\xee\x0a\x5d\xff\x56\x7d\xef\x6f\xda\xe1\x66\xdf\xaa\x3a\xe7\xaa\xef\x53\x66\xa5\xc4\x86\x68\x53\xb5\xd6\x78\x00\xbf\x01\xa8\x54\xe4\x1c\x67\x20\x11\xe8\xaa\xb3\x1a\x32\x20\x8f\xaa\x73\x9d\xe2\xd2\x30\x88\x07\xbd\x43\x10\x1d\x87\xa4\x8a\x47\xc8\x03\x04\x50\x56\xfe\x34\xe9\xdb\x42\xac\x4b\xc8\x68\xa8\xd0\xc0\xe9\x70\x11\x28\x16\x09\xcd\xd8\x3a\x9e\xe0\x70\x27\xe7\xba\xfe\x37\xc4\x34\xbf\xe1\xab\x34\xe2\x9f\x29\xba\xb1\x68\x31\x90\x38\x43\xa2\xe3\xe9\x39\xa0\x2d\x8f\xc7\xd6\x4a\x13\x10\x97\xa3\x38\x01\x01\x75\x5f\x34\x8d\xe1\x02\xbf\xfc\x5b\x99\x85\x0f\x3c\xb1\xb7\x42\x34\x8e\x02\xd7\xc7\x4a\xa8\x40\x59\x09\x84\x98\x61\xe5\xfc\x6d\xef\x3b\x2f\xaf\xab\x58\x0e\x02\x00\x00")
 
 func configGridengineTemplateTxtBytes() ([]byte, error) {
 	return bindataRead(
@@ -87,12 +87,12 @@ func configGridengineTemplateTxt() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "config/gridengine-template.txt", size: 346, mode: os.FileMode(420), modTime: time.Unix(1527178560, 0)}
+	info := bindataFileInfo{name: "config/gridengine-template.txt", size: 526, mode: os.FileMode(420), modTime: time.Unix(1792428587, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _configPbsTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\xd0\xc1\x4a\xc4\x30\x10\xc6\xf1\x7b\x9f\x62\xec\xb2\xc7\xb4\xf5\x2a\xf4\xa2\x05\xf1\x22\xa2\x82\xe7\x86\x4c\x34\xa4\x9d\x84\x49\x82\x87\x90\x77\x97\xdd\x04\x64\xc1\xf5\x16\x86\x3f\x3f\xc8\x77\xb8\x19\xa5\xa1\x51\xae\xe1\xab\x3b\xbc\xdc\xbf\x81\x78\x86\x9c\x87\xf7\x35\xd8\x27\x55\x4a\xbb\xb9\xd3\xed\xc3\xb1\x5d\x0c\x97\x32\xea\x44\x84\x9b\x08\x51\xb9\x14\x5b\x82\xd7\x12\x64\xee\x72\x36\x1a\x08\x61\x78\xf0\x29\xc0\x04\xa2\x94\x2e\x67\xcf\x86\xa2\x86\xbe\x02\x1b\x90\x53\x18\xe6\xdb\x3b\xef\x69\x3e\xaa\xbe\xd6\xe7\x52\x00\x92\x3a\xbf\x9a\xf3\xba\xee\x8f\x12\xa6\xe1\x1a\xb5\xe3\x3e\x1f\x87\x49\x7f\xca\xbe\xc5\x7f\x3b\x8b\x09\xf6\x5f\x48\x9b\x0d\x7f\xa5\x9a\x5f\x50\x5d\xfd\x29\x7c\x3b\xb6\xc8\xc0\x89\x40\x88\x78\x9a\x6f\xb9\x18\xf2\x27\x00\x00\xff\xff\x94\x91\x42\x0b\x69\x01\x00\x00")
+var _configPbsTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x7d\x92\xcb\x6a\xc3\x30\x10\x45\xf7\xfe\x8a\xa9\x83\x97\x92\xdd\x6d\xc1\x8b\xb4\x86\xd2\x4d\xe8\x0b\xb2\xb6\xe3\x51\x2a\x2c\x4b\x8e\x1e\x64\x21\xfc\xef\x4d\x2c\x95\x12\x2a\x77\x27\x46\xe7\x1e\x06\xee\x6c\xee\xca\x8e\xcb\xb2\x6b\xcd\x57\xb6\x79\x7d\xfc\x00\xb2\x03\xef\xe9\x67\x6b\x86\x97\x7e\x9e\xe3\x4c\x5d\x67\x7b\xa5\x87\x86\xeb\x79\x2e\x99\x93\x12\x05\x31\xb6\x57\xce\x46\x04\xd7\x10\xd4\x3a\xf3\x9e\x33\x90\x08\xf4\x69\x72\x06\x2a\x20\x17\xb3\xf7\x93\xe6\xd2\x32\xc8\x83\x40\x80\x54\x3d\x9a\xfa\xfe\x61\x9a\x64\x5d\xf4\x79\xa0\x17\x92\x00\xca\x7e\x79\x45\xcf\x7b\x3b\x3e\x77\x50\xd1\x35\xd5\x88\x63\x5d\xd0\x8a\x1d\xbb\x3c\xc2\x69\x4f\xc3\xcd\xf0\xaf\x88\x71\x81\xbf\xa6\x80\xff\x55\xd1\x37\x87\x0e\x93\x8a\x13\x14\x26\x8f\x40\x22\xb8\x3d\x1c\x94\x93\x36\x19\xdd\x86\x68\x44\x12\xe1\x7d\x2b\x84\xe5\x23\xae\xec\x7e\x8e\xdf\xf5\xa2\xf9\x81\x6f\x3c\x59\xe8\x09\xce\x97\xe2\x50\x83\x76\x12\x08\xb1\xd7\xf2\x9b\x9b\x33\xf8\x06\x9d\x53\x0a\xe3\x27\x02\x00\x00")
 
 func configPbsTemplateTxtBytes() ([]byte, error) {
 	return bindataRead(
@@ -107,12 +107,12 @@ func configPbsTemplateTxt() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "config/pbs-template.txt", size: 361, mode: os.FileMode(420), modTime: time.Unix(1527178525, 0)}
+	info := bindataFileInfo{name: "config/pbs-template.txt", size: 551, mode: os.FileMode(420), modTime: time.Unix(1792428587, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _configSlurmTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x90\xc1\x6a\xc3\x30\x0c\x86\xef\x79\x0a\x2d\xa5\x47\x27\xd9\x23\xac\x0d\x74\xbb\x6e\x85\x9d\x9d\x45\x61\x9e\x67\xd9\x48\x36\x3b\x18\xbf\xfb\x48\x53\x68\x0a\x0b\xbb\x19\xeb\xfb\x3f\xa4\x7f\xf7\xd0\x0e\x86\xda\x41\xcb\x67\xb5\x7b\x3b\x3c\x9d\x8f\xcf\xa0\xd4\x97\x1f\x14\x69\x87\x90\x73\x73\xd6\x62\x5f\xc6\x52\x56\x63\x8a\x5a\xac\xc0\xe3\xea\x0b\x99\x3d\xcf\xf8\xbb\x67\xdb\x1b\x2e\xa5\x9d\x12\x11\x7e\x2b\x89\x23\x32\xaf\x50\x9f\x62\x48\x71\x8b\xf5\x29\x56\x39\x9b\x09\x08\xa1\x39\x86\x24\xd0\x81\x2a\xa5\xca\x39\xb0\xa1\x38\x41\x7d\x33\x7d\x84\x24\x2a\x20\xab\x79\x1f\xd8\x8f\xf5\x92\xb8\xd0\x0a\x90\xc6\xcb\xeb\xea\x7a\xd5\xee\x34\x40\xd7\x6c\xeb\x1c\x3a\xd8\x37\xdd\x74\x3a\xd4\x57\xfc\x6f\x53\x6f\xc4\xfe\xa3\x8a\x2e\xdc\x54\x0b\x7f\xe7\xaa\x96\x83\xe1\xc7\xb3\x45\x06\x4e\x34\x87\xe6\xa6\xfb\xbb\xce\x7f\x03\x00\x00\xff\xff\x71\x9e\x5b\xbd\x9f\x01\x00\x00")
+var _configSlurmTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x85\x92\xcb\x4e\x84\x30\x14\x86\xf7\x3c\xc5\x91\x09\xcb\x02\x6e\xdd\xcd\x25\x41\x77\x5e\x26\x99\x75\x81\x32\x56\xa0\xad\xbd\xc4\x45\xc3\xbb\x5b\x0a\x3a\xa8\x74\xdc\x91\xfe\xdf\xf9\x4a\x7a\xfe\xcd\x4d\x56\x52\x96\x95\x58\xbd\x46\x9b\x97\xdd\xf6\xb8\xbf\x07\x84\xde\x78\x89\x18\xee\x09\x58\x9b\x1e\xb1\x6a\x1f\xea\x61\x58\xc4\x4c\xbb\x33\x05\xb7\x8b\x23\x22\x25\x97\x23\x7e\xe2\xb2\x3d\x50\x39\x0c\x59\x63\x18\x23\x1d\x52\xba\x76\xe1\x02\xe5\x46\x0b\xa3\x43\xac\x4b\x23\x6b\x69\x03\x8c\x40\xba\x17\x46\x41\x0e\xc8\xdd\x6e\xad\x90\x94\xe9\x06\xe2\x8b\xa9\x72\x31\x12\x44\xa2\xf1\x7f\x20\xa9\xe3\x69\xc2\xd3\x08\x08\xab\xfd\xd7\xec\x7a\xc6\x7d\x51\x42\x9e\x86\x75\x3d\xe9\x21\x49\xf3\xa6\xd8\xc5\x33\xbe\x6e\x3a\x50\xd5\xfe\xa3\xd2\xbd\xb8\xa8\x26\xfe\xaf\x2b\x7d\xc4\x52\x53\x4d\x39\x0b\x7a\xc4\x37\x91\xa8\x78\x31\xb0\x22\xdb\x56\x15\x37\x4c\x07\x55\x78\xce\xbd\x68\x86\x57\x34\x4f\x5c\x05\x15\xef\x2e\xf3\xe3\x0e\x5a\x19\x3d\xe1\xae\xd3\xd4\xb5\x26\xf8\x2a\x63\xe8\x05\x5f\xe8\xfa\x03\x17\xd7\xd7\x7e\x96\x44\xc1\x59\x98\x3b\xbf\xf1\xe2\xf7\xc6\xa3\xa9\x4c\xf0\xe1\xda\x45\x24\x48\xc3\xc6\xab\xc7\x16\x1f\x7e\xf4\xf9\x13\xb9\xd0\x50\xf8\xfb\x02\x00\x00")
 
 func configSlurmTemplateTxtBytes() ([]byte, error) {
 	return bindataRead(
@@ -127,12 +127,12 @@ func configSlurmTemplateTxt() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "config/slurm-template.txt", size: 415, mode: os.FileMode(420), modTime: time.Unix(1527178517, 0)}
+	info := bindataFileInfo{name: "config/slurm-template.txt", size: 763, mode: os.FileMode(420), modTime: time.Unix(1792428587, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _configDefaultConfigYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x5a\x5f\x53\x1b\xbb\x92\x7f\x9f\x4f\xd1\x8b\x73\x6b\x93\x2a\xdb\x98\x9b\xca\xad\x3d\xae\xe2\x01\x0c\x87\xb0\x21\x84\xc5\xce\x66\xf7\x89\xd2\x8c\xda\x1e\x1d\x6b\xa4\x39\x92\x06\xe3\xb0\xf9\xee\x5b\xdd\xd2\x8c\x6d\x20\x7f\xee\xbd\xa4\x2a\x0f\x37\x4f\x58\xd3\x6a\xb5\xba\xfb\xd7\xff\x94\x1e\xcc\x4a\x04\x23\x2a\x04\x3b\x87\x50\x22\x88\x22\xa8\x5b\x04\x8f\xee\x16\x1d\x48\x11\x44\x2e\x3c\x42\x2e\x8a\x25\x1a\x99\xf5\xe0\xe8\x56\x28\x2d\x72\xdd\xad\xf9\x31\xe4\x56\x07\x99\xf7\x21\x17\x72\x81\xae\xcf\xdb\x7c\xb0\x0e\xfb\x20\xd7\x46\x54\x96\x3e\xa2\x16\x3e\xa8\xa2\x0f\x95\x35\x0b\x2b\xf3\xec\x24\x31\x6f\xf7\x67\xd9\x57\xc5\x29\x6c\x55\x37\xe1\x7b\x62\x68\x5b\x08\xdd\x87\x32\x14\xd6\x48\xeb\xfa\xe0\x75\xe3\xaa\x3e\xd4\xb9\xef\xc3\xc2\x29\x89\x66\xa1\x0c\xf6\xa1\x12\xa6\x21\x4a\xb1\xf2\x83\x5c\x84\xa2\xcc\x26\xf1\x80\xc4\xe3\x1b\x92\xe0\x2d\x9a\x00\x2b\xa7\x02\xba\xf6\xe8\x97\xfe\xd5\xf0\xab\x22\x2d\xfa\xff\x98\x7a\xfa\xb0\x14\xf3\xa5\xc8\x4e\xe9\xc0\x4f\x7c\x9e\x1f\x43\x06\x30\x68\xd5\x45\x7f\x6a\xbb\xc8\xb2\x0b\xbb\x58\xa0\x1b\x67\x00\x3d\xa0\xbf\x95\x59\x80\xc6\x5b\xd4\x7e\x0c\x12\xf3\x66\xd1\x07\x65\xe6\xb6\x0f\xe8\x9c\x75\x19\xc0\x05\x7d\x1c\xf3\x22\x6f\x62\xf6\xc4\xcb\x43\xb0\x10\x4a\xe5\xa1\x16\xa1\x1c\xc2\xf9\x1c\xb0\xaa\xc3\xba\x1f\x3f\x0a\x87\x7c\xf5\x80\x86\x08\x7d\x90\xe8\xdc\x30\x03\xf8\xd0\x84\xba\x09\xbf\x2b\x8d\x63\xd8\xdb\xcb\xb2\x29\xbb\x4f\x94\xe8\xad\xf5\x61\x5b\x91\xbf\x37\xc6\xa0\x4e\x1e\x46\x9b\x89\xe0\x52\x54\xad\xf2\x4b\xeb\x43\xc6\x3b\xaf\xac\x0b\xd0\x78\x94\x30\xb7\x0e\xde\xce\x66\x57\xe4\x08\x55\x63\x54\x21\x82\xb2\x06\x84\x91\xcc\x72\x85\x39\x48\xe1\xcb\xdc\x0a\x27\x99\xe5\x6c\x76\x45\xbb\xc7\xf0\x1f\xa3\xd1\xe8\x29\x6e\xd7\x57\x93\x5d\x66\xb4\xed\xfa\x6a\x12\x77\xfd\x36\xfa\x2d\xed\xba\xc6\x3f\x1b\xe5\xc8\xa4\x5e\x15\x20\x9a\x50\xa2\x09\xed\xf9\xc4\x88\xce\x4f\x68\x39\xba\x3a\xf7\xd0\x78\x52\xbf\x80\x5a\x78\xbf\xb2\x51\x9c\x1e\x29\x92\x8e\x26\xd7\x5b\x22\xf8\xc6\x21\x29\xb0\x76\xb6\x46\xa7\xd7\xe0\xd0\x07\xa7\x8a\x00\xa2\x28\xd0\x27\x2b\x90\xdb\x9b\xb9\x5a\xc0\x5c\x69\x64\x2e\x2f\x71\xb8\x18\x42\x51\x56\x56\xc2\xdf\x46\x23\x98\xb3\x2a\x87\x91\x6c\xb8\xae\xf4\x2b\x26\xfb\xe8\xd1\x8d\xd3\xc7\x78\xf5\x24\xcb\x18\x44\x5e\x1c\xfc\xf5\x75\xbc\xda\xb9\x29\x74\x23\x11\x04\xec\x4d\x44\x51\xe2\x60\x62\x4d\x70\x56\x8f\xc1\xd8\x01\xfb\xe7\x5e\x54\x7a\x89\x42\xa2\x03\x65\xe0\x0c\xc3\xfe\x85\xf2\x81\x04\xae\xad\xf1\xe8\x99\x13\x5f\x25\x22\xa3\x10\x45\x49\x0a\xc8\xd7\xa0\x4c\x40\x57\xa1\x54\xc2\xad\x59\x45\xaa\x40\x4f\xea\x38\x51\x9e\x60\x42\xbc\xf9\xe0\x31\x04\xd7\x60\xd2\x37\xd9\x45\x2b\x66\x65\x8d\xc1\x82\x15\x1d\x54\x85\xb6\x09\xc9\x46\x13\xfe\x3e\x8b\x6b\x63\xf8\xdb\xc8\xc7\xbd\x04\xd9\x4a\xdc\xa9\xaa\xa9\xc0\x34\x55\x8e\x8e\x7d\x4e\x55\xe8\x21\x94\x22\x80\x00\x87\x7f\x36\xe8\x03\xac\x94\xd6\x90\x23\x38\x0c\x4e\x25\x97\x98\x0b\xa5\x1b\x17\x45\xec\x01\xb1\x87\x1c\xc3\x0a\xd1\x24\x32\x0f\x73\xab\xb5\x5d\x79\x10\x06\xf0\xae\xb6\x86\x7c\x41\x68\xc6\xbb\x9d\xcf\xc1\x07\xe1\x02\x9b\x3f\xc0\x1b\xf0\x48\x71\x28\x6a\xa8\xa9\x49\x49\x07\x50\x29\xd3\x04\xdc\xbe\xc6\x7b\x71\x77\x1d\xb9\x8f\xe1\x60\x94\xb5\xa1\xc7\x17\x25\xca\x46\x93\xda\xfd\xc6\x6b\xc9\x29\xde\x73\xf0\x7a\x18\x12\x87\x90\x4d\xdb\x2d\x2d\xee\x56\x60\xe7\x09\xaa\xae\x31\x20\xb6\x99\x06\x74\x9d\xdb\xb7\x1b\xaf\x05\x45\xc0\x03\xdf\x6d\xaf\x84\x59\x43\x10\x7e\xc9\x1e\xd9\xee\x26\x47\xb0\x06\x9f\xe6\x31\x29\x1b\xb3\xe4\x9b\xb4\x4c\xb4\x35\x0b\xda\xbe\x12\x2a\x74\x0a\x6d\x6a\x29\x02\x7a\xc8\x71\x6e\x1d\x59\xcd\x2d\x23\x6c\x8c\x95\x08\x12\x05\xe3\xe6\xd2\x4a\xbc\x52\x66\xd1\x99\xfa\xa0\x7a\x9a\x2d\xe9\x26\xed\xe5\xb8\x24\x5c\xe8\x3f\xe4\x4d\xba\x7b\xc4\xfd\xdc\xa8\x8d\x23\xbd\xa9\xb2\x8c\x16\xc7\x2d\x64\x53\xec\x4b\x9c\xcf\x4f\x3a\xb7\x11\x4d\xb0\x95\xa0\x38\xa0\xf5\x1a\x16\x68\x48\x13\xc8\x5c\xcf\x4f\x62\x08\x4c\x2c\xba\x53\x4b\x41\xb7\x45\x03\x4a\x6a\x64\x81\xe9\x06\x48\x36\x15\x86\xc9\x92\x93\xf7\x41\x25\xff\xf4\x65\x13\x40\xda\x95\x89\x1e\x39\x38\x80\x0a\x85\x21\x5f\x46\x87\xe4\x17\xc6\x76\xc8\x80\x51\xfb\x31\x2e\x80\xaa\x18\x7a\x01\xf5\x1a\xc4\x3c\x60\x74\x9e\xb9\x72\x3e\xb0\x49\x89\x67\x77\xf3\xc1\x41\x82\xd0\x11\x6b\x25\x1e\xbf\x7b\xc9\xe0\xd6\xa4\x5b\x89\x01\x8b\x00\x2b\x82\x93\x43\x6f\x1b\x57\x60\x4c\x0c\xa2\x4b\x7f\xc1\x82\x0a\x43\x60\x86\x27\x38\x57\x86\xd4\x7f\xdd\x11\xab\x78\x5d\x3e\x28\x86\xae\x26\xfa\x11\xd8\x5b\x74\x94\xa6\x7d\xcc\x41\x39\x96\xe2\x56\x59\x4e\x12\xdd\x76\x32\x0e\x31\x9e\x5c\x7d\xf4\x9b\x33\x87\xed\x6a\xdd\xf8\x31\x70\xec\xe6\x68\x72\xf4\x7e\x43\xd3\xe7\x08\x76\xdc\x92\x5e\x8b\xea\x2c\x1f\xc3\x68\xd8\x51\x9f\x28\xbf\x04\x5f\x8b\x02\xbf\xba\x89\x48\xb6\x76\xf5\xe0\x77\x36\xe4\x6a\xc0\xf9\x16\x42\x43\x77\x1d\x3e\xc6\x9f\x5f\x9b\x02\x56\x2a\x94\x4f\xa7\xc0\x8f\x0c\x87\x88\xbf\x37\x3e\xcb\x3e\x59\xb7\x6c\x71\x4c\x59\xd5\x43\xe1\x90\x3c\x0c\x64\xe3\x48\x9b\xb5\xb3\x94\x28\xe8\xcf\xd6\x27\xdb\xc4\xcc\xea\x55\x1e\xa4\x72\x58\x04\xeb\xd6\x74\x00\x31\x3c\x51\x6e\x0c\xc3\xfd\x98\x17\x06\x2b\xeb\x96\x03\xa9\xdc\xdf\x75\x8d\xda\x6a\xcd\xbe\x5b\x08\x53\xd0\x0d\xd4\xc2\x08\x4d\x01\xe3\xca\x6a\xad\xcc\x62\x73\x85\xbf\x47\x39\x68\x24\x15\x13\xb6\x09\xfb\xe8\x1c\xbb\x27\x15\x1c\x5d\x94\x48\xe9\xf0\x91\xda\x7a\x30\xc5\x10\x22\xb8\x15\x93\x8d\xa2\x3a\x1c\xfa\x46\x87\xe4\x69\x9e\xdc\x1e\xb5\x24\x87\x22\xda\xc8\x55\x52\x3c\x54\x66\xa1\x23\xf0\x98\xdb\x06\x27\x78\x87\x45\x13\xac\x03\xbc\x53\x81\x33\xc2\x85\x5d\x3c\xb4\x12\x6d\x79\x2f\xee\x20\x5f\x27\x21\x39\x69\xb2\x7e\xb6\x6e\x93\xdc\xbd\xbd\x54\xe2\x35\x13\x4a\x4f\xd5\x67\x8a\xb7\xa3\xd1\x68\x04\x3d\x38\x18\xc1\xbb\xe3\xc8\xf4\xd2\xba\x2a\x82\x8e\x8a\x1b\xf6\x05\x90\xa8\x91\x8e\x51\xc1\xf3\x12\xdd\xa4\x33\x71\x92\x3c\x4a\xdd\x29\x79\x46\x4a\xb1\x35\x43\x4b\xc6\x9c\x9b\x12\xe1\x36\xb2\x2e\x50\xdc\x62\xe7\x1f\x73\xa1\x3d\x66\x59\x6f\xf0\xbc\xff\xb2\x1e\xb4\xe5\x3e\x65\x4f\xb9\x6f\x1d\x70\x65\x0b\xa9\xb4\xdd\x7f\x2b\x8c\xd4\xe8\xfc\xf3\x1f\x9d\x1d\x5b\x1d\x4e\x8e\xc7\xa9\x14\x22\x0c\x46\x7f\xea\xba\x9b\x54\x60\xd1\xb7\x27\x10\x92\x7e\x0f\xa9\x43\x39\xe1\x7a\xbd\x65\x76\x2c\x3c\x72\x69\x1b\x2c\xe5\x68\xb6\x7c\x5b\xd1\x43\x60\x7d\x53\x94\xa5\x3f\x5a\xd2\x9d\xba\xec\xe8\xd3\x14\x1c\x2e\x94\x35\x1c\xe1\xe8\x0f\x4e\x1e\xed\xb7\xa3\x58\x0d\x2e\x71\x0d\xe7\x27\x19\xc0\x3b\x5c\xef\x7c\x9f\x62\xe1\x30\xb4\x64\xef\x70\x4d\x89\x98\xd7\x62\x0e\x3a\x8d\x3d\x45\xba\xb9\xc3\xb9\xba\xdb\x16\x55\x19\x89\x77\xe8\xe1\x25\xf9\x66\x3f\xb6\x36\xbe\xcf\xe9\xca\x53\x25\x79\x4e\xdf\xe3\xb6\x1d\xb1\x3f\x5e\x5f\xb4\xc5\x7c\xea\x5a\x3c\x0a\x57\x94\xdb\x01\xed\xfa\x62\x0c\x65\x08\xf5\x78\x7f\xbf\xab\xea\xc7\xbf\xfd\x95\x8a\xf1\x1e\x9c\x59\x4b\xb8\x9b\x68\xdb\x48\xf6\x8b\x08\x1c\x86\x48\x6b\x94\x61\xd6\x7d\x20\xf9\xaf\x9c\xfd\x03\x8b\xd0\x5d\xbf\xb5\xa3\x28\x0a\xdb\x50\xb1\xe8\x50\xc6\x6a\xcc\xb3\x39\x23\x02\x3e\xb0\xf3\x0b\xcd\x9d\x4c\x6d\xbd\x57\x1c\xd2\xb7\x89\x9f\x4e\xe9\x52\xf9\x82\xb2\x11\x4a\xe6\x33\x77\xb6\x8a\xf7\x35\xb7\xca\x59\x53\xa1\xe1\x62\x74\xb2\x61\xd4\x35\x3f\x00\xd9\x7b\x6a\xe1\x5a\x27\x39\x92\xd2\x79\x28\x2d\x05\x20\x6e\x24\xa5\x74\xe8\x3d\x57\x93\x6d\xfb\x80\x32\xe9\x8e\x43\x0d\xef\x88\x79\x6e\xb0\xd5\x13\x71\xfe\x69\x5d\x56\xf9\x5d\x17\x66\x37\xe4\x3a\x91\xb2\x8c\x32\x90\x64\xd8\x0a\x4b\x31\x7a\xd2\x0e\x2e\xc2\xbb\xee\x7b\xcb\xb2\xb3\xb6\x78\x48\xa2\x56\xac\xdb\x54\x44\x3f\xa8\xb6\x52\xd3\x43\xc5\x25\x37\x03\x12\x56\x25\x9a\xa8\x2e\xae\x32\xda\xfa\x9d\xea\x3a\x23\x81\xfb\x25\x2a\x9f\xa9\x18\xa6\xbe\x87\x73\x7e\x57\x0e\x78\x4a\x67\xd6\x90\xa5\x62\x4f\xb1\x11\xe5\x33\x3a\xdb\x8f\x0d\x91\xd0\x1a\x2a\xb1\x86\x5c\xdb\x62\x49\x82\x20\xc9\x40\x52\xd1\x31\x51\xb0\x4d\xdf\xd0\x36\x27\x39\x02\x7a\xc2\xa3\xf2\x65\xac\xd2\xb6\x6b\xbe\xb6\x65\x62\x15\x92\xa4\x6d\xbb\xc4\x0d\xb2\x8b\x86\xdf\xf1\xaf\x64\x37\x65\x14\x97\xff\xbb\x5d\x21\xf3\x93\x54\x20\x5b\xb3\x6b\x23\x49\xb5\x10\x4a\xea\x8d\x68\xfd\x64\x13\x7e\x50\xb3\x54\xad\x14\xc9\x8f\x36\x6d\x1b\x81\xf9\x9d\x98\x2f\xc5\x98\x11\xce\x9e\xd2\x3a\x08\x93\xce\x6c\xad\x8a\xce\x94\x3f\x23\x7c\xa7\x09\x09\x1c\xa7\xd9\xc6\x4f\x88\xd3\x6f\x67\x13\x1e\xdc\x44\xdc\xcc\x1a\x67\x80\xba\x2a\x0e\x0b\x3e\x88\x40\xed\x5a\x61\x4d\xa1\x34\xba\x21\x7c\x2a\xd1\x00\x1a\x0a\xae\xb2\xdf\xd6\x06\x9b\x96\x1e\xfd\xa6\xde\x7a\x7b\x35\x61\x96\x9b\xfe\x27\x58\x98\x2b\x23\xdb\xe6\x86\x9b\x43\x87\xe0\x43\x53\x2c\xc9\x23\x05\xfc\xd9\x60\x43\xb0\xe4\x73\xa9\x10\x70\xce\x3a\xaa\x1a\x8c\x4c\x1d\x5d\xaa\x4d\xda\xdc\x1e\x29\x29\x1e\x39\x49\x75\xc5\x7a\xab\xd5\xbd\xee\xe4\x4e\xbd\x6e\x1c\x2d\xa4\x45\xaa\x28\xc8\xcf\xcb\x4d\x51\x54\x3e\x9a\x79\xf1\x6f\xe1\xd0\xc7\x83\x18\x37\xf1\xd2\xff\xee\xbb\xb9\x58\xf2\xf7\x50\x5a\x4f\xca\xaa\xad\x0b\x1b\x7f\xdb\x10\xed\x9c\x3c\x86\xd7\x23\x02\xc1\x0c\xab\x5a\xf3\xef\xff\x63\xcf\x6a\x8c\x22\x37\x43\x38\x84\x5b\x61\x94\xd6\x82\x97\x17\x18\xd0\xdc\xc2\x21\xcc\xe2\x3d\x20\x55\x1d\x5c\xf9\x1f\xc2\xfd\xfd\xf0\xb4\xfb\xfd\xe5\x0b\x13\x08\xb7\x68\x28\x62\x7a\x38\x6c\xab\x19\xea\x47\x07\x83\x34\xdf\xb8\xbf\x1f\x4e\xf8\xaf\x2f\x5f\x60\x30\x20\x65\x0e\x94\xa4\xd5\x99\xf0\xcb\x73\x99\xb8\x50\x41\xc8\xfc\x53\xad\xf2\xe5\xcb\x7e\x9c\xf3\x0d\x38\x71\x0d\xb4\x5d\x44\x71\xc8\x50\x0f\x29\x53\x4a\x8f\x13\x2b\x26\xb3\x3c\xb2\xfa\x3a\x9d\x6d\x02\xd3\xf9\xd2\x36\x5a\xde\x04\x27\x8c\x9f\xa3\xbb\x99\x73\x39\x7e\x08\xff\x7b\x3a\xe5\xef\x14\xf4\x6e\x82\xdd\x10\x74\x8c\x3f\x5c\xde\x9c\xfe\xcf\xf9\xec\xe6\xc3\xf5\xcd\xe9\x7f\x9f\x4f\x66\x4c\x7e\x7f\xaf\xe6\x60\x10\x86\xd4\xa9\xc0\x08\x06\xe9\x76\xf7\xf7\xb5\x53\x26\xcc\x61\x2f\x8d\x28\x6e\x0a\x22\x38\x84\xbf\xc8\xbd\x48\xdc\x11\x0e\x00\x8d\xec\x7e\x25\x76\xdc\xcd\x50\x5b\xf2\x0d\x8e\x15\x56\x54\x2b\x1e\xc2\x5f\x86\xa3\x39\x9c\x1d\xef\xa5\x6d\xdf\xe6\x1c\x5b\x9e\xef\xb0\x96\xd4\x3a\x6d\x33\x8e\xbb\x1e\x71\xe6\x9f\x0c\xad\x2c\xbb\x3a\x9e\xfe\x0b\xe9\xbf\x02\xd2\x7b\xff\x96\x2b\xb3\x9f\x0b\x5f\xc6\x9f\x57\xc7\x53\x18\x5c\x3e\x02\x60\x5c\xb7\xdf\x03\x4c\x24\xc3\xef\xe1\xef\xfb\x40\x88\x8c\x74\xac\x47\x0f\x0f\xc6\x75\x6d\x0e\x9f\x01\x0d\x2d\xdb\x0a\xab\x43\xf2\xd7\x45\xfe\x0c\x38\x68\x99\x52\x74\xd8\x70\xfd\x16\x08\x1e\x04\xca\x1f\x0c\x8c\xe7\x27\x3b\x66\xc9\xce\x9c\x92\xa7\xfc\xaa\x31\xfe\x01\xc3\xbe\x78\xd2\xac\x2f\x7e\xc4\xa8\x2f\x7e\xc0\xa4\x44\xd4\x99\xeb\x47\x8d\xfc\x02\x06\x35\x42\x55\xab\xe7\x88\x74\x51\x82\xf2\xe6\xb6\x35\xee\xd9\x73\xd8\x36\x31\x9d\x7b\xf5\x19\x3b\xae\x3f\xdf\xb6\x53\xdd\xb8\xea\x5f\x11\xf2\xd7\x88\x90\xfb\xbb\x48\x9a\x1e\x1f\xcd\x26\x6f\x61\x30\xf8\xc3\xe6\x03\x6e\x1c\x1e\xc1\xaa\x23\x31\x51\xb1\x07\x0f\x96\x63\x9d\xf2\x3d\x48\x75\xe4\xa9\xac\xf8\x0e\x4e\x7f\x00\x70\x1d\x47\x2a\x30\x06\x35\x3a\x76\xbe\x67\x41\x5f\xc7\xba\xc2\x8a\x6b\x81\x67\xa9\x31\x36\x6c\x43\x55\x6f\xd8\xfe\x7c\x00\xf2\x90\xe5\x58\x84\xa2\x04\x89\xbe\x70\x2a\x4f\x3e\xbe\x3b\xb5\x6e\xdb\xc1\xa3\x4f\x53\x88\xd4\x0f\xdf\x66\xb2\x96\xcf\xb3\xa2\xb9\x3b\xaf\x75\xf5\x87\x28\x36\xdc\x1c\xf3\x6b\x48\x04\xeb\x06\xa8\xbf\x3c\x48\xb7\x2f\xf7\x24\x44\x7b\xf0\x9f\x36\x8f\xaf\x0b\x6c\x85\x42\x18\xee\xf3\x55\x28\x91\x1f\x83\xf8\xc9\x39\x59\xa6\x12\x9f\xad\xe9\xde\x10\xe0\x92\xbe\xbd\x3c\xba\xbe\x7c\x45\x57\xde\xe1\x33\x86\xbd\x84\x2a\x42\xb6\xc4\xf9\x5e\x7b\xd6\x7f\x51\x10\xfc\xe7\x8e\x61\x16\xbb\x27\x70\x68\xdd\x7b\x30\x0d\x6c\xa7\x6b\xbe\xc6\x42\xcd\x15\x4a\xf8\xc3\xe6\x31\x0a\xc7\x37\x6f\x9b\xde\x03\x98\x8a\xbe\xc9\x8d\x22\xd4\xa3\x61\xe2\x66\x6c\xb8\x3d\x1c\xfc\x09\x33\x82\x69\xb0\x4e\x2c\xf0\x27\x8c\x06\x7a\xff\xc4\x00\xef\x6b\xe3\xbb\xac\x07\x17\xb6\x10\xb1\x68\x03\xbf\xf6\x01\xab\x61\xc6\x4b\xe9\x22\x11\xae\x9f\x4a\x15\x50\x2b\xcf\x83\x30\x1e\xc7\x6d\x0d\xde\x6b\x11\x4a\x0f\xab\x52\x15\x65\x8b\x54\xe5\x41\x68\x6d\x57\x28\xd3\x80\x12\x7d\x1c\xeb\xc5\xc5\x13\xb5\x99\xdd\x0c\xf7\x49\x8a\xb7\xb3\xd9\x55\x3a\xb1\x7b\xed\x0d\x96\x5f\xfe\xb4\x15\x12\xea\x26\xd7\xaa\x80\xd8\x79\xa6\xa1\xd2\x0a\x73\xb8\x55\x02\x04\x9c\x9d\xce\xda\x67\xed\x61\xb6\xc5\x6a\xbc\x33\xd1\xa3\x20\x55\x86\x50\xbf\xf4\xaf\xb6\x77\xf8\x9d\x61\xd8\xeb\x91\xcf\xb2\xe8\xc5\xd3\xd7\xe3\x4d\x34\x90\xed\xbb\xc0\x33\xbf\xb2\x3f\x78\xfb\x7e\xae\xb1\x77\x0f\x26\x29\x40\x23\xcf\x3e\x49\xaf\xed\x7f\xc9\x61\x19\xa6\xaf\xa1\x76\xf6\x56\x49\x74\x1e\x7c\x53\x94\x20\x3c\xbc\x57\x46\xd9\xf6\x6d\x62\x82\x75\x99\xf5\xe0\x0c\x0d\x3a\x55\x90\x32\x7a\x6c\xb0\x8d\x42\x38\x2a\xd2\x22\xc0\xa9\x91\xb5\x55\x26\x9e\x1e\x97\x5a\x91\xe3\xaf\x6d\xe1\xe2\xec\x7b\xcb\x46\x4f\xe9\xf8\xd7\x9d\x6e\x67\xd3\x95\x9a\x87\xa7\xe5\xfe\xe8\xd1\x5d\x7e\x65\x7c\x09\x70\xd4\x84\x92\x9f\x05\xe2\xc0\x12\x8d\x30\x61\x8b\x3a\x2e\xa4\xd7\xf3\x36\x80\x6d\x7d\xef\xc1\x9b\xd1\x08\xde\x1f\x93\x5c\x65\x63\x96\x53\xf5\x19\x8f\xd7\x81\x5c\xe7\xcd\x28\xfd\xcb\xfe\x3f\x00\x00\xff\xff\xb1\x1e\xc3\x7c\xd0\x26\x00\x00")
+var _configDefaultConfigYaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xed\x5c\x6d\x73\xdb\x46\x92\xfe\xce\x5f\x31\x47\xe7\x2a\x76\x15\x49\x49\xf6\x3a\xb7\xe1\x9d\xee\x8a\xa2\x68\x59\x1b\xbd\x45\xa4\xd7\xd9\xbb\x4a\xa9\x40\x60\x48\x62\x05\x60\x60\x0c\x20\x99\xf1\xea\xbf\x5f\xbf\xcd\x00\x10\x29\xcb\x49\x94\xbb\x4d\x55\xf4\x49\x04\x66\x7a\x7a\x7a\xba\x9f\x7e\x99\x26\x9f\xa9\xd9\x4a\xab\x2c\x48\xb5\x32\x0b\x55\xc2\xff\x41\x58\xc6\x37\x5a\x59\x5d\xdc\xe8\x42\x45\x41\x19\xcc\x03\xab\xd5\x3c\x08\xaf\x75\x16\x75\x9e\xa9\xd1\x4d\x10\x27\xc1\x3c\xf1\xcf\xec\x50\xcd\x4d\x52\x46\xf3\x1e\x3c\x89\x96\xba\xe8\xd1\x34\x5b\x9a\x42\xc3\xbf\x6b\xa0\x6e\xf0\xa5\x4e\xe0\x59\x1c\xf6\x54\x6a\xb2\x25\x3d\xc9\x8d\x2d\x97\x85\xb6\x3d\x65\x3f\x24\x71\xa9\x3b\x87\xb2\x9c\xa3\xd8\x81\xf5\x1e\x60\x30\x34\x69\x5e\x95\x8f\x31\x96\x98\x30\x48\x7a\x6a\x55\x86\x26\x8b\x0c\x70\x66\x93\xaa\x48\x61\xe5\x39\x2c\xba\x2c\xe2\x48\x67\xcb\x38\x03\x36\xd3\x20\xab\x70\x64\x70\x6b\xfb\xf3\xa0\x0c\x57\x9d\x31\x2f\x20\x34\x3e\xc3\x89\xbe\xd1\x59\xa9\x6e\x0b\xd8\x40\xe1\x96\x7e\x6e\x5f\x0c\x1e\x64\x69\xd9\x7b\x2a\x81\xf5\xd4\x75\xb0\xb8\x0e\xe0\x45\x35\xb7\x15\x0c\xb8\xd5\xf3\x95\x31\xd7\x9d\x09\xf2\xf4\x9e\x58\x82\x25\x3b\x4a\xf5\x9d\x44\xf1\x5f\x60\x61\xdb\x7e\x78\x23\x85\x0e\xa2\xf6\x46\x68\xa0\x28\x04\xc8\xd1\x56\xa9\xb6\x0a\xf8\xbd\xe6\x19\x16\x48\xc1\xfa\x49\x6c\x57\x3a\x52\xa5\x41\x5a\xb5\xc6\x58\xf5\x5c\x0f\x96\x03\x35\x5f\xab\x5b\x53\x5c\x03\x3f\x2f\x54\x9c\xf1\x28\xaf\x5e\x0f\xc9\xaa\xb5\x3b\xde\xd4\x25\xb1\x07\xef\xfe\xe7\xc7\x4e\xe7\xc4\x2c\x41\x7c\x43\xd8\xd4\x33\x85\xff\xc7\xd9\x52\x25\xc0\x54\x02\xef\x23\x3d\xaf\x40\xd2\x71\xb6\x30\x20\xca\xa2\x30\x05\x0c\x3b\xc1\x97\x43\x7a\x48\x93\x48\x44\x28\x0f\xcb\x8c\xc7\x56\xe5\x41\xb9\x1a\xa8\xe3\x85\xd2\x69\x5e\xae\x7b\xfc\x32\x28\x34\x9d\x70\xa9\x33\x1c\x68\x4b\xe0\xa1\x18\x00\x89\xf3\xaa\x04\x2d\x79\x13\x27\xa0\x28\xdd\x6e\xa7\x33\x25\x31\x31\x47\x6f\xe1\xb4\x9a\xf2\x7d\x53\x65\x99\x4e\x44\x92\x38\x19\x07\x9c\xc1\x00\xd1\xb1\x15\x7c\xec\xd0\xcc\x0b\x53\x94\xaa\xb2\x20\xce\x85\x29\xd4\xdb\xd9\xec\x02\xf5\x3d\xad\xb2\x38\x0c\xca\xd8\x64\x2a\xc8\x22\x22\x09\xe7\x0d\x42\xb4\xab\xb9\x09\x8a\x88\x48\xc2\x58\x9c\x3d\x54\x7f\xde\xdd\xdd\xdd\x46\xed\xf2\x62\xdc\x26\x86\xd3\xe0\x21\xcf\xfa\x76\xf7\x5b\x99\x75\xa9\x3f\x54\x71\x81\xa7\x61\xe3\x50\x05\x15\x2c\x97\x95\x6e\x7d\x24\x54\xd6\x5a\x31\xba\x38\xb6\xb0\x02\x8a\x3f\x00\x01\x5a\x0b\x47\x4d\xec\x3c\x43\x41\xe2\xd2\x68\x61\xd7\x30\xbe\x02\x8a\x20\xc0\xbc\x30\xb9\x2e\x92\x35\x68\x9b\x2d\x8b\x38\x2c\xc1\x98\x42\x6d\xe5\x14\xd0\xba\xb3\x45\xbc\x54\x0b\x90\x2b\x51\x61\x1d\x0a\x57\x60\x18\xea\x9b\xdd\x5d\xb5\x20\x51\x0e\x78\xd8\x60\x9d\x26\x2f\x68\xd8\x3b\xe0\x67\x28\x2f\x79\xeb\xc2\xcb\x50\x05\xf3\x70\xef\xe5\x2b\xde\xda\x71\x16\x26\x55\x04\x06\xac\xba\xe3\x20\x5c\xe9\xfe\xd8\x64\x65\x61\x40\x31\x32\xd3\x27\x33\xec\xb2\xd0\x57\x6c\x0b\x71\xa6\x8e\x74\xb9\x73\x12\x5b\x34\x0f\x9b\x83\x0d\x68\x4b\x94\x68\x2b\x6c\x37\x21\x50\x42\x01\x80\xa2\x83\x7e\xeb\x22\xd5\x51\x1c\x14\x6b\x12\x51\x0c\x7b\x43\x71\x1c\xc6\x16\x35\x1c\x69\xd3\xc2\x43\x55\x16\x95\x16\x79\xe3\xb9\x24\x31\x91\x32\xb0\x81\x90\x04\x5d\xc6\xa9\x36\x55\x29\x67\x34\xa6\xf7\x33\x7e\x36\x04\x49\x58\x9e\x8b\x06\x9a\x06\x1f\xe3\xb4\x4a\x55\x56\xa5\x73\xe0\x19\x75\x2e\x26\x43\x5d\x05\x20\x5d\xe0\xfb\x43\x05\xb2\x56\xb7\x71\x92\xa8\xb9\x86\xcf\x20\x77\x51\x89\x05\x58\x1e\x1c\x8c\xe5\x13\x43\xf2\x30\xa2\xbc\xd5\xa0\xec\x3c\xcc\xc2\xb0\x24\x31\xb7\x60\x08\x99\xd2\x1f\x41\x00\xa8\x0b\x41\x42\xa6\x6a\x16\x0b\x30\x88\xa0\x28\xe9\xf8\x4b\xf5\x1a\xb6\x8c\x70\xcb\x12\xaa\x72\x14\xd2\x9e\x4a\xe3\x0c\xd0\xb4\xb9\x8d\xd3\xe0\xe3\x25\x53\x1f\xaa\x3d\x50\x3a\x41\x24\xe7\x79\x74\xa2\x4b\xc1\x19\xab\x6e\x57\x71\xb8\x82\x2d\x02\x30\xd3\x5e\x4a\x5c\x1f\xc4\x93\x9b\x24\x0e\xd7\x03\x35\xf9\x98\x83\xae\x46\x32\x1a\xac\x15\x88\x05\x05\x1c\xc8\x0d\x3c\xbc\x8d\xcb\x15\x6a\x55\x5c\x28\x30\x70\x38\xde\xb5\x0a\xac\xfa\xcb\xf4\xfc\x4c\x25\x00\xfe\x80\xa6\x4c\xbe\xcb\x8a\xa3\x82\x08\x98\x25\xcd\x24\x4d\x40\x40\x04\xd1\x5c\xba\x45\xd9\xb8\x47\x82\x80\xb4\xb0\x55\xf1\x42\xc5\x25\x33\xa8\x51\x4a\x6b\xcf\xda\x7b\x58\x1d\x4e\x8b\x3f\xc7\xb8\x1a\x33\x99\x69\xdc\x26\xcf\x67\xc1\x9f\x67\x60\x10\xfc\x12\xd6\x0f\x14\x2a\x51\x9c\x81\x94\x41\xba\x80\x52\xcf\xc7\xe7\xa7\x17\x27\x93\xd9\xa4\xa7\x26\x3f\x4c\xc6\xef\x66\xe7\x97\x57\x93\xcb\xcb\xf3\xcb\x9e\x9a\xfe\x6d\x3a\x9b\x9c\xca\x27\xa2\x35\x1e\x9d\x8d\x27\x27\x93\xc3\x17\x6e\x05\x35\x41\x38\x53\x53\x24\x65\x55\xaa\x83\x0c\xd8\x04\x55\x68\x2f\x02\xdc\xa1\xa5\x11\x89\xbe\x82\x13\x1a\x2d\x41\x49\xff\xed\xe5\xee\xea\xde\xa3\xbd\x6f\xfe\xcc\x8f\x94\x90\x04\x40\x76\xfc\xfd\x28\x2f\x66\xc1\xd2\x0e\xe5\x7f\x85\x26\xff\x77\x50\xea\xa1\xb2\x61\x41\x0e\x56\x01\x30\xb1\x48\x08\xcc\x71\xdc\x14\x24\x1e\x2c\xc1\x2f\x00\xc7\x21\x1d\xd4\xed\x4a\x03\x62\xe8\xfb\xc7\xeb\x0f\x97\xf9\x55\x5d\xfb\x6a\xb8\xb3\x33\xaf\xc0\x71\x94\x3b\xf2\xae\xcb\xdb\xe0\x7d\xf3\x86\xeb\xf9\xac\x5e\xac\x1b\x78\x3a\x73\x4d\xda\x2b\x54\x71\xe6\x88\xff\x7f\x77\x79\x42\xc8\xce\x80\x7e\x0b\x76\x25\x3e\x20\x01\x3f\x4b\xd6\xd3\x62\x8e\x0c\x35\xc0\xb0\x61\x8f\xe5\x73\xba\x69\x96\xc4\x04\x50\xe0\x89\x78\xd6\x60\x4f\x0a\x7d\xb5\xc7\xe3\x03\x94\xd0\x34\xfe\x09\xe9\x10\x8e\x8b\x6d\x80\x76\x45\x55\x82\x90\x64\x6b\x44\x47\xc0\x3c\xa5\xf8\xe5\x7e\x54\x34\x50\x9d\xa9\x9b\x32\xdc\xdc\x42\x51\xa1\x9a\x35\x88\x36\x59\x70\x13\x65\x37\xd6\x4f\x4f\x51\xbb\xfd\x26\xdc\xec\xad\xdb\x70\x34\xc6\xab\x2a\xbb\x26\x2b\x6f\x11\x01\x44\xaa\xfc\xa9\x22\x3f\x60\x69\x8a\xd0\x0a\xa1\x6b\x70\x6f\xcb\x39\x68\x06\xc2\x59\x61\xaa\x25\xd9\x33\x11\xbb\x5d\x19\x58\x9c\x28\xf5\xc8\x2d\x02\xda\x43\x7c\x80\xd6\x08\x48\x9c\x17\xb1\x01\xb7\xbd\xa6\x37\x00\x73\x45\xdf\xae\xf0\xf8\xe7\x1a\x04\xa7\xdb\xfc\x31\xa0\x7b\x0d\x71\x0b\x93\x32\x7c\x8f\xf4\xdf\xc7\x10\x43\xde\xca\x89\x10\x52\x22\x02\x94\xc1\x52\x01\x0f\x11\xea\x0f\x9e\x04\xc1\x82\x5b\x17\x59\x22\x77\x00\x61\xca\x80\xc6\x5b\x86\xa3\x00\xa0\x68\x09\xba\x4d\x74\x6a\x2e\x9b\xeb\x82\xf7\x2b\x6c\xc9\x52\x88\xf4\x22\xa8\x92\xb2\x1e\x09\xe7\xbf\x8b\x8c\x5d\xc8\x03\xb0\xb5\xa1\x7f\xdb\x66\x8e\xf4\x04\xa4\xbb\x04\xc1\xe5\xb2\x41\x41\x7d\x27\x0e\x59\x13\xb6\xe0\xec\xc9\xdc\x66\xba\x60\xad\x87\xa1\x5d\x31\xde\xae\xdb\x83\x44\x34\x16\xa3\x9b\x16\xf7\xe4\xfa\x12\x1d\x20\xee\x06\x61\x61\xc0\xa5\xd3\xba\x96\x61\x69\x11\xdc\xc0\x58\x91\x14\xbf\xf0\xf0\xac\x16\xfa\x16\x3d\x14\x68\x65\x46\x43\xc8\x9e\xc4\x7e\x23\xf6\x9c\xb6\xc1\x35\x6e\xff\x0d\x7c\x9a\xe2\x07\xda\xbf\x98\xe9\xa6\xc9\x81\x3b\x0a\xab\xa2\x40\xdf\xca\xfb\x87\xe0\x83\x97\x1f\xa8\x5d\xc1\x86\x0c\x4c\x3a\x4e\x63\x72\xb5\x47\xf8\xea\xfb\xca\x94\xc1\x50\xf1\x51\x5f\xe8\xa2\xcf\x02\x34\x00\xdb\x98\x38\x90\x10\xea\x81\x2d\xe0\xa4\xc7\x10\xd5\x05\xf3\x7e\x20\x00\x08\x4c\x91\xe8\x48\x7d\x5a\x4b\xd4\xf0\x77\xa9\xc9\xf9\x81\x72\x64\x06\xc2\x13\x67\xdb\xa4\x07\x4d\x63\x11\x7f\x15\x19\x6d\xb3\xaf\x4b\x78\x5f\x92\xc7\xc1\x49\x2c\x66\x6b\xd8\xed\x27\x41\xb1\xd4\xb5\x4e\xe3\x60\xf4\xd4\x78\x38\x60\x1c\x36\x05\x07\x80\x02\x02\x1f\x38\x50\xe7\xb0\x54\xd1\xd0\x7f\x03\xde\x88\x88\x41\x08\x15\x2f\x33\x1f\xda\xa3\x63\xd4\x44\x82\x98\x8c\x49\x13\x30\xb8\x47\xea\x00\xab\xc1\xba\x3d\x0a\x29\x12\x2a\xd2\x03\x82\x06\xd8\xf1\x22\x48\xac\x7e\xd8\x88\x00\x1a\x41\xdd\x60\x36\x28\x03\x62\x81\x53\x38\x24\xd6\x43\xef\x1d\xa8\xa8\x62\x9c\x69\xc4\x87\xdd\xbd\xd5\xab\xdd\xb4\xfb\x02\x55\x36\x68\x1c\xbf\xc4\x23\xb5\x2f\x66\x52\xa0\xec\x94\xc6\xc1\x81\xa2\xd3\x91\xa5\x58\xe7\x1d\x4c\x7b\xe5\x24\x55\xa7\x68\x5e\xa0\x97\x26\x67\xea\x90\x2d\xf3\x92\x27\xd3\x46\xf9\x5f\xd2\x48\xb7\x8f\x7e\x93\xf8\x64\xdb\xe6\xfc\x6a\xc8\x5f\xe0\x5f\xa1\x60\x8c\x8f\x5b\x28\x86\x6c\x2d\xe8\xfd\x0c\x82\x6a\x62\x50\x7c\x46\xdd\x06\x88\x7e\x12\xc1\x55\x79\x44\x2e\x5f\x20\x2f\x0d\x8a\x6b\x8e\xd3\xe9\xf8\x22\x80\x5c\xa4\x7a\x06\x1f\x2e\xe0\xb9\x8f\x2d\xf7\xd2\xed\x64\x17\x2c\x5b\x9c\x4b\x89\x10\xc4\x7d\xbd\xfb\xb4\x51\x5e\x1b\xd4\x8f\xb3\xb8\x8e\x5c\x5f\xa7\x1d\x4f\x1e\x47\xb2\xca\x15\x41\x76\x8d\x3e\x19\xe5\x5a\x43\x12\xba\x03\x90\x8e\x8b\x24\xe6\x71\x96\x83\x77\x43\xb4\xd3\x0b\x38\x5f\x9e\xee\xcf\x09\xb0\x07\x8c\x65\x51\x68\x52\x41\x53\x15\x21\x12\x5f\x60\x6e\xee\xd1\x19\x4e\xd0\x29\xf5\x40\xa8\xda\x1c\x9d\xcf\x43\x44\x05\x99\x6a\x4b\x70\xae\x1e\xa7\xd2\x8a\xfd\xc4\x40\xaa\xf0\x20\x81\xd4\xfc\x5c\xa6\x28\x36\x5a\x0f\xdd\x76\x39\x5b\xd5\xe0\x33\x4a\x18\x97\xe7\x49\x5c\x1b\x24\x89\xda\x86\x06\x23\x78\xc8\x68\x2e\xde\x75\x7b\xaa\x7b\x39\x3a\xed\x92\xd7\xeb\x42\xd2\x71\xdd\x25\xf9\x92\x27\x98\xb3\x55\x0b\x5d\x1a\xd2\xdc\x01\x4c\x25\x9c\xea\xfa\x10\x03\x91\x82\xa4\x03\xc4\x2f\x60\x0b\x80\xc7\x31\x40\xb1\x1f\xc0\xe4\xd6\x58\x0c\x28\x5c\xd8\xdb\x93\xcc\x08\x45\x91\xd7\x73\x44\x2c\x04\x6c\x8d\xb8\x3e\xc0\xdc\xc2\x8f\xe3\xd0\x41\xf6\x8a\xa7\xae\x14\xec\x09\x34\x92\xfe\x85\x7d\xb9\x7f\x71\x63\xee\x7f\x87\xad\xf4\xa1\xc1\x25\x3f\x82\x70\x3d\x8a\x62\x24\x0d\x31\x52\x51\xa1\x1f\xa1\x50\xdd\xb9\x19\x34\x33\x8a\x40\x48\x94\xa9\x2e\x03\xac\x4c\xb4\x20\x1d\x17\x20\x93\x5e\xe6\x55\xbf\x5c\xe7\x5a\xb9\xbf\x67\xa2\xa0\x48\x64\x09\xf2\x72\x03\xf6\x6f\x00\xe9\x07\x03\xa7\x26\x68\x03\xa7\x42\xb9\x4d\xe5\x99\x82\x51\x08\xb7\x68\xf5\xe0\x56\xb2\xa6\xf6\x38\x66\xee\x91\x15\xa2\x93\x8f\x90\xc9\x5a\x08\x53\x39\x93\x54\xaa\xc1\x55\x83\x88\x5f\xcd\xad\xe2\x62\xae\x36\xc7\x1c\x50\x40\xde\x1a\x4a\x44\xff\x63\x9d\x5e\x06\x55\x69\x6c\x18\xa0\xbf\x20\xa3\xb7\x8e\x3e\x1a\x6b\x2b\x90\x8b\x8c\x73\x4a\x54\xa8\x68\xfa\x25\x54\xb6\xa8\x08\x62\xcc\x3d\xe0\x5f\xc0\xb4\xbc\xa6\x83\x9a\xb0\x02\x30\x06\x34\x01\x92\x71\x94\x10\x3f\x23\xbf\x2e\xab\x02\xb8\xe2\xc2\xdc\xc4\x98\x96\xbb\xc0\x86\xf8\xf1\x04\x99\xde\x46\xdc\x50\xb6\xf6\x30\x10\x5a\x75\xd1\x29\x17\xaa\xb0\xed\x2e\x96\x47\x80\x5c\x77\x20\xda\xc4\x6f\x24\xbe\xd8\x88\xa2\x01\xa8\xc0\x8e\xbc\x1d\x93\x2c\x88\x1b\x52\xa6\xdc\x98\x84\xe9\x70\x20\xfd\x6a\xd7\x0a\x91\xd3\x38\xa3\x30\x05\x87\x6e\x26\xef\xb2\x8b\x5d\x0c\x1c\xce\x48\x42\x9b\x61\x8a\x42\x1a\xf4\x92\xc3\x14\xe5\x07\xbb\xcf\xdb\x82\x21\x96\xb7\x97\xdb\x46\xdc\xbe\x35\x22\x22\xd2\x53\x94\xdd\xbb\xbc\x26\xee\xbd\x83\x78\x84\xb4\xb2\xe8\x78\xe8\xf0\x9c\x53\x00\x3d\x00\xa0\xa0\x53\xc7\x98\x50\x4e\x29\x67\x94\x53\xea\x18\x86\xd6\x4e\x67\x37\xbd\x4f\xb9\xed\x77\x88\x65\x17\x7a\x50\xc6\xb0\x04\xcf\x88\xe5\x4a\x5e\xac\xae\x43\x64\x06\xe3\x19\x5e\x63\x8a\x4f\x37\x16\x19\xf3\x29\xb3\x5a\x51\xf8\x55\x65\xb5\x58\x44\xfe\x6f\xde\x9d\x9d\x4d\x4e\xae\xce\xce\x0f\x27\x57\xe3\xf3\x77\x67\x33\xdc\x8c\xd5\x24\x36\x0a\x54\xb2\x9b\xb8\x30\x59\x0a\xa1\xe5\x40\x08\xd1\x6a\x5e\x59\x5a\x84\x41\x3b\x03\x2f\x0a\x5c\xa1\xbd\xc0\xf1\x61\xaf\xf5\xf9\xed\xf9\x74\x76\x36\x3a\x9d\xf4\x3c\xa5\xe6\xdb\xff\x3e\x3f\x9b\x90\x3c\x9b\x0f\x4f\x27\xb3\xd1\xd5\x7f\x5c\xeb\xf5\x7f\x72\x32\xf1\x08\xa7\x26\xe7\xb2\xa5\x24\xdf\xb9\xb8\x20\x1f\x7e\x3b\x4c\x44\xfb\x34\x16\xbd\x57\x58\x07\xd1\x58\x36\x72\xe5\x15\xbb\x86\x63\x48\xb9\xce\x2a\x9e\xf1\xfb\x2a\x00\x87\x5e\xfa\xbd\x8a\x8f\xd7\xe8\x6d\xdc\x34\x57\x72\xa1\xf3\xac\x32\xf0\x61\x80\x1e\x5d\xce\xb8\xdc\x6c\x67\xf7\x67\x5e\x83\x5b\x5c\xe4\xe2\x6d\x3d\x1c\xb8\x1c\x98\x1c\x37\x57\xb6\x20\xb2\x28\xb5\xb3\xf9\xdd\x1a\x14\x3e\xf8\x45\xf8\xa5\xcb\xf0\x9c\x7e\xbf\x29\x02\x2e\xc3\x49\xf8\xd9\x5a\x98\x11\xab\x29\x84\xa0\x25\x86\x9e\x10\x81\x38\x9c\x87\x7a\x5f\x1d\x37\x57\x16\x3b\x78\xc3\x9c\x32\x48\xec\x0e\x5e\x3f\x60\x61\xa0\x9c\xeb\xf6\x6c\x6f\xae\x18\x10\x26\xad\x45\x9c\x40\xdd\x1a\x87\x12\x32\x23\x0a\x41\x00\x86\x48\x31\x74\x45\x5a\xa9\x76\xcb\x32\xc7\x87\xbe\x50\x88\xa0\x09\xce\x12\x7c\x42\x02\xbe\x63\xa9\x33\xc4\x09\xa6\x78\x7c\x58\x6b\xcf\xf1\xa2\x5e\x7a\x15\xd8\x1a\xc4\xc9\x72\x71\x0b\x14\xec\x04\xac\x8c\x52\xd6\xec\x21\x3a\xd0\x42\x76\x05\x61\x2f\xc8\x3e\x13\x7f\xbb\xe7\x2a\x36\x54\xff\x89\x09\x8c\x5c\x2d\xd4\xef\x58\x1e\xa8\x38\xa5\x62\x6b\xa9\x81\xc1\x3a\xaa\xe2\xb4\xc9\xa5\x1f\xde\xfc\xfb\x7b\x52\x34\x1d\x91\x4b\xe6\xe5\xdb\x9b\x2c\x21\x8e\x01\x45\x8a\x74\x09\x71\x3a\x1c\x5e\x50\x36\xc3\x36\xac\x3e\x79\xb7\x01\xc3\x00\x20\x15\x11\x84\xc8\x3c\x26\xd3\xb9\xf4\x83\xc5\xf6\x68\x21\x2e\x56\xcb\x11\x34\xd2\x48\xba\x75\x98\x6b\x70\x7d\x90\x47\x4b\xa6\xc4\xd3\x9d\xe6\x43\xf8\x63\xeb\x35\x9d\x1e\x8f\xf3\x8a\x70\x5e\x3e\x42\x60\x54\x8f\xe9\x51\xcd\xfa\xc0\x0d\xbd\x0c\xd2\xa3\x39\xaa\x95\x1f\x8d\xb1\x13\x84\x75\x41\xa8\x1f\x9c\x84\x43\xee\xcd\x1a\x03\xbe\x9b\xb4\x16\x86\x54\x0b\x8e\x90\x3f\x3c\xe5\x18\xcc\xc3\x22\x6c\xce\xe8\x0a\x48\x52\x4e\x96\xa3\x53\xc2\x67\x52\x42\x70\x05\x69\x90\x4f\x2a\x81\x32\x04\x61\xae\xfe\xe0\x96\x18\x00\x6a\xc1\xd1\x0e\xc0\xa8\x77\x20\x4c\xd9\xdf\xeb\x7a\xf6\x4c\x78\xad\x8b\x51\x21\xb7\x33\x41\x14\xb1\xf9\x77\x23\x7a\x81\x51\x4e\x77\x23\xd2\x6c\x2c\xea\x77\xe1\x08\x76\x3f\x7d\x1a\x8c\x52\x03\x66\x74\x77\x47\xc1\x6d\xa1\xf3\x04\x04\x44\x11\x30\x4f\xa0\xc9\xe8\xc5\x68\xd8\xa0\x25\x15\x77\x58\x78\xc1\xc6\x97\x3a\x6d\xd6\xfd\x6b\x88\x69\x68\xfa\x50\xbd\x6c\x3c\xab\xb7\x03\x71\x57\xb7\xdf\x87\x19\x16\xe3\xf8\x26\x53\x3f\x6e\x2c\xe1\x25\x0e\xca\x0b\x67\xb8\x65\x8d\xd7\xac\xec\x6f\xc8\x08\x6f\xfb\x74\x3b\xa6\xca\x0a\xf5\x74\xb0\x59\x11\xb4\xeb\x2c\xac\xb3\x96\x8d\x0b\xab\x77\x94\x4b\x32\x46\xbd\xb6\x9d\x76\x86\xd8\xaa\xdb\xd0\x99\x2c\x21\x30\x25\xb2\x68\x17\x76\xc5\xb1\x62\x13\xa0\x24\x12\xe0\xe0\xd0\x5d\x13\x4e\x8f\x8f\x66\x93\xcb\x53\x4e\xe2\xeb\xa4\x00\x26\x81\x7d\x6a\x5f\x45\x93\x12\x08\xc7\x9a\x44\x3e\xe6\xeb\x12\x8e\x34\x29\x18\x20\xea\xe4\x23\x0b\xdd\x97\x20\x55\x0e\x53\xae\x19\xa8\x94\x90\xe9\x5b\x40\xe9\x12\x17\xa2\xaa\x25\x60\x11\x42\xd1\x11\xa0\xbf\xbe\xd0\x45\x6c\x22\x8c\x1a\xdc\x7e\x75\x90\x80\x7c\x28\xea\xb3\x14\x4b\x0b\x45\xf6\xe7\x23\xc8\xb8\xb3\x15\x8d\x59\xbb\x7d\x22\x8f\x9b\xb9\x23\x13\x1a\x23\x9d\xe1\xb6\xb8\x92\xc2\x74\xa0\xbb\x6a\x2e\xb8\x3d\x94\xf4\x67\xa0\x83\x50\x46\x42\x48\xc9\x91\x7e\x1d\x86\xa1\xab\x12\x02\x8d\x60\xc8\xd1\x90\x70\x88\xe6\xb0\x6f\x06\x91\xf6\xc3\x2e\x6c\x09\xf6\x91\xf5\x7f\xd2\x05\x96\xac\x63\x2a\x21\x95\x15\xbb\x7f\x8e\xab\x69\xc1\x41\x2b\xa8\xaa\x63\x65\x17\xe6\x52\xf6\x1b\xd5\xb0\x23\xd0\xc8\x97\xc2\x5f\x5b\xfa\xa7\x2e\xfc\xdf\xc3\xa2\xdd\x76\x20\xdf\x58\x10\xc8\xbf\x01\xca\x1e\xac\xdc\x6e\x24\x28\x0f\xd8\xd6\x05\x13\xa2\x40\xa7\x26\x93\x3b\xbc\x48\x64\xc1\x96\xe7\x4a\x52\x5c\xed\xbb\x9f\xb2\x0a\x2c\xc1\xb4\x52\xfd\xf5\xd4\xf6\x48\xba\xf3\x86\x8a\x71\x05\xce\x95\x80\x28\x65\x5a\x7f\x2d\x55\xb4\x7b\x67\xff\x99\xa4\xb7\x95\xb8\x36\xf8\xc1\xf3\x6d\x14\x55\x10\x4e\xe5\x62\x2f\x2e\x41\x74\xc1\x9c\x8a\x5a\x06\x39\x12\x82\x18\x18\xbc\x47\x73\x0b\xc4\x6e\x40\x0d\xb9\x02\x0b\xb0\xc0\xb5\x2b\x17\x50\x60\x0e\x86\xb7\xaf\x79\xd9\xb0\x5e\x32\x99\xdc\x14\xa5\x95\xa1\x4c\x03\x5c\x7a\x69\x7d\x4e\xda\x64\xd9\x40\x22\x82\x63\x86\x0f\xa7\x48\xae\x74\x44\x66\xdc\xd0\xe3\xd7\x5f\xa2\x82\x1b\xea\xe7\xe3\x03\xde\x07\x86\x66\x78\x75\xe3\x82\xae\x6d\xc8\x51\x87\x39\xb5\x30\xa5\x86\x5a\x73\xc8\x83\xc1\x87\xd1\x89\xc3\xec\xa3\xf1\xc4\x6d\x2a\xac\x8a\x44\xf5\xed\x42\xf5\xdf\xaa\xae\x4b\xe6\xfb\x6f\x12\xac\x6a\x0f\xd5\x91\x31\x4b\xac\x8b\xac\xca\x32\x1f\xee\xec\x78\x29\x2d\xe9\xf9\x80\x4a\xe3\x59\x90\xec\x48\x80\xea\xe6\xef\xdc\xec\xed\x40\x46\x5c\x06\x59\xa8\x77\xfc\xf1\xa9\x7f\xa8\x25\x9c\x80\xea\x7f\x50\xb3\xcb\x77\x93\x0d\xe3\xea\xbc\x27\xc3\xe1\xf8\x0d\xfb\x0f\xac\x0a\x0b\x8d\x91\x19\x56\x46\xf1\x24\x21\xa3\xc5\x2b\x75\xfc\xd7\xc5\x72\xae\x85\x81\x6c\x0f\xb3\x32\x67\x6e\x54\x71\x01\x82\x87\x31\xec\x63\xb0\xc3\x51\x79\x1f\x4d\xb2\x0f\x63\x7e\x96\x0b\xc9\x0d\xac\x85\x82\x0c\x71\x43\xe0\x3d\x40\xf5\x41\x93\xb9\xa4\x85\xf5\xbc\xb6\xfb\xf8\x52\xc7\xa4\x29\x71\x8c\x40\xd1\x77\x20\x70\xe2\x54\x3b\x31\x4b\x5f\xde\x94\x5a\xd8\x86\xcb\x82\xbc\x46\x97\xac\xd9\xb8\x63\x18\xb6\xcb\xe2\x00\x10\xc0\x1b\x15\x86\x21\x8b\xe1\xa2\x4e\x22\x2b\x17\x81\x4c\x35\xc2\x88\x1e\x3e\x26\xba\xe1\x58\x7c\x7c\xa9\x3f\xea\x10\xc2\xc6\x82\xb4\x92\xb0\xe4\xc4\x2c\xb7\x79\x48\xc8\x9c\xc1\x47\x94\x2e\xe9\x46\x34\x46\xf9\x34\x76\x23\x58\xe8\x36\x25\xb4\x66\x00\xb0\xf5\x1d\xe0\x2e\x50\xda\xdb\x55\xdf\x1d\x30\xd1\x33\x53\xa4\x1c\xac\x7a\x10\xf5\x77\xe6\x68\xa3\xf8\x08\x77\x52\x5f\xa5\x32\xe7\xcc\xb5\x17\xf2\x0c\x85\x62\xd8\x4c\x1a\x10\x1b\x94\xad\x88\xf4\x04\x2f\x74\xbc\x7e\x34\x80\xe9\x04\xeb\x03\x6c\x85\x16\x38\xc5\x3c\x89\x5c\x10\xee\xe4\xeb\x6d\x4c\xb8\xc6\x1f\xef\x07\x88\x4c\x1d\x59\x89\x2f\x25\x49\x3c\xf7\x81\xf0\x80\xe1\x5d\x5a\x91\xe8\x25\x7b\x9f\x56\xda\xc5\x51\x04\xa6\x5e\x1c\x69\x00\x56\xe8\x8f\xa1\xd6\x11\x33\xf8\x01\x2f\x5b\x06\x62\x2b\xb5\x25\xd8\x38\xd2\xae\xe7\xa4\xc4\xcc\xbc\xe0\x70\x04\xce\x86\x5e\x35\x6e\x1e\x60\x47\x37\x26\xc1\xf6\xa7\x9e\x8b\x6d\x43\x8c\xb2\x38\xb4\x45\x1e\xf9\xce\x88\x8c\x75\x92\xa1\x30\xa3\xfa\xaa\xe3\x33\x85\x23\x27\x3b\x77\x96\x2d\x99\x35\xa1\xd2\x47\x21\x72\x0b\x40\x7a\xc9\x55\x1a\x5b\x5f\x23\xd0\x35\xa8\x9c\x04\x53\x8d\x0b\xaf\xae\xc0\x3b\x65\xca\x2e\x18\xf3\xd7\x9e\x1e\x0b\xb1\x68\x80\x9c\x74\x25\xbf\xea\xd2\x15\xa0\x9b\xdf\xf7\x4f\x31\x5e\xdf\x5e\x2d\xf2\xed\x11\x12\x75\x53\x59\x13\x5c\x94\x34\xa5\xb4\x0e\x2f\x6b\x18\x12\x9e\x1d\x25\x6b\x58\x6c\x75\x21\x0a\x45\x28\x13\x19\xd3\x7c\x4a\x0b\x1d\x54\x0b\x2c\x32\x73\x27\x9a\x62\x2d\xbe\xf6\x9b\x5b\x23\x0a\x7d\x5d\x36\xb0\x4f\x3c\x39\x30\x96\xe8\x46\x20\xe8\x6e\x3a\x7d\xc3\x23\xa5\xcf\x05\x8a\x90\x53\x23\xf1\x88\x72\xbd\x95\x52\xd9\x0c\x6f\x9a\x61\x49\x74\x8c\xe8\x86\xf1\xd2\x7d\x20\x17\x79\x38\x10\x84\x2c\x6c\x85\x41\x81\x06\x88\x09\x31\xa0\xbb\x01\x36\xc2\xb5\xba\xd6\xe0\x88\xac\x69\x46\xa3\x51\x81\xce\x38\xaa\xf2\x84\x4b\xb0\x48\x8d\xba\xde\xa6\x58\x46\xbc\xa7\x57\xd4\x3a\xe4\x52\xb5\x86\x7d\x51\x27\x22\x86\x2a\x26\xa1\x06\x2a\x84\x9d\x81\x53\x18\xc2\xa0\x2e\xb1\xd5\xa7\x21\x5d\xdc\x89\x98\xb6\xc4\x43\xf1\x83\xa5\x4e\x11\x00\x4d\xf4\xbb\xdb\xea\xca\x7d\x44\xea\x2a\x3c\x02\x51\x94\x95\x61\x41\x8f\x6f\x01\xda\x94\x24\x84\x91\x93\x12\x4a\x75\xfc\xea\x71\x56\xa9\x37\x49\x65\x57\xcd\xbb\xaa\xce\xb3\xfe\xd3\xfe\x75\x40\xaa\xa2\x0b\x64\x50\x3b\xc0\x2d\x1d\x85\x92\xb6\xca\x9d\xb7\xf0\x34\x81\x7f\x9e\x7e\xe9\xce\x81\x49\xca\xc3\x83\xa1\xb4\xb0\x61\x36\xd6\xee\x97\x74\x8d\x71\xf8\x6e\x8b\xbf\x96\xcf\x03\x6c\xa0\x3d\xa4\x76\x52\x47\xec\x00\x26\xd3\x25\x26\x10\xac\x2c\xfb\x21\xd7\x70\x0a\x16\x8b\xe8\x4f\xe6\x07\xff\xb8\xa1\xad\x7e\xba\xd1\xfb\x29\xd5\x5c\xe9\xda\xf5\x92\xfe\xf1\xd7\xee\xf8\x6e\xc4\x5d\x7c\xa0\xda\xea\xf8\x10\x9e\x7e\xa7\xd7\xad\xf7\x53\x0d\x41\x4a\xe9\x86\xc1\x5b\x4c\xb7\xe8\x19\x07\x35\x13\x6e\x79\x1d\xba\x28\x7c\x11\x7f\x6c\xb2\x1a\x67\x11\x80\x85\x55\xcf\xf9\x1e\x98\xb5\xa6\xc7\x21\x3a\x76\x00\x1e\xe3\x7b\x9e\xd6\x62\xfb\xdd\xe5\x89\x6f\x72\xe5\x15\xac\xc6\x5e\x9f\x66\x6a\x8b\x7d\x3e\x12\xb9\xf9\x6e\xcc\xe1\xb7\x2f\xb9\xf9\x86\x43\x3b\x35\x4e\x4c\x15\x91\x5e\xb0\x1b\x67\xd8\xf4\x4d\xac\xfe\xc5\x90\x02\x63\xe9\x77\x92\xed\xbb\x73\x84\x50\x1b\x7d\x06\x46\x6b\x11\x77\xd1\x59\x3a\x4e\xe9\x01\xcb\xf9\x96\x8a\x82\xd5\xdc\x40\xf8\x46\xe8\xd3\x1c\xbc\xbd\x30\x07\xb0\x17\x62\x4d\x49\xee\xe3\x16\x85\x49\xb7\x95\x7d\xc7\x35\x21\xdf\xb4\xaa\x54\xe7\x14\x3b\x8c\x9d\x92\x8c\xa2\xa8\xb0\x74\x1d\x2f\xd7\x26\xf0\x19\x8e\xcb\x5d\xdc\x11\x5c\x81\xcd\xb2\xec\xc8\x20\x69\x06\xc3\x53\xbf\xd1\xcb\x4a\xd0\xd4\x00\xd4\x96\x0a\x93\x1a\x56\xae\x12\x0c\x20\x24\x3c\x34\x82\x24\x46\x0e\x97\x69\xd4\xcd\xe1\x8d\x93\x9d\xb9\x12\xa0\xb0\x4a\x55\x0f\xef\x67\xee\x5d\x1e\x30\xc8\x12\x8e\x51\x02\x48\x6e\x82\xc5\x45\xb5\x42\xd7\x77\x49\xf7\x07\x91\xa2\x3e\x57\xca\xd2\xb0\xed\x23\x97\xfb\x11\x5f\xd4\xb3\x18\x5c\xe3\x7d\xc9\xf1\x82\x7d\x68\xcd\x0a\x26\x2b\x9c\x64\xe0\xe1\x70\xb2\x08\x62\xa1\x04\x83\xda\xfe\x90\x2b\x5c\x86\x19\xab\xfb\x3d\x5d\x53\x29\x1c\x2f\xc4\x43\x81\x34\x55\xb7\xea\x97\xaf\x53\xdf\xea\x4a\x22\x44\x4e\x5d\x9b\x2b\x35\x36\x17\x7c\xf0\x2d\xfd\x92\x73\x83\x54\x9c\xda\x36\xdb\xdd\xbc\x44\x0f\xcb\xc4\xb8\xe3\xd6\x19\x45\x58\xd1\xac\xa3\xb2\xc3\x1a\x7e\x20\x56\x26\xab\x11\x2e\x44\x8f\xea\x76\x5b\x34\xe6\x0b\xe9\x56\x1f\xde\xb7\xc1\xda\x62\xc4\xee\x5c\x5f\x7b\xcb\xf6\x5e\xff\xe9\xd5\x4b\x41\xb2\xff\xb2\x36\x01\x88\xd2\xfb\x12\xa2\x72\x5c\x0f\xf1\x53\x1a\xb8\x8b\x43\xaf\xad\x92\x01\x30\x98\xc1\xe1\xa0\x67\x76\x99\x11\x77\x69\xba\x38\x07\xfc\x89\x2d\x5d\x9b\x5a\x1a\x0c\xb9\x8b\x3d\xec\x74\xa6\xdf\x9f\x00\xc4\xff\x6a\xf8\xe5\x1e\x7d\x42\xe1\xef\xb0\x91\x7d\x48\x60\x47\x46\xe3\x6c\x85\xa4\x36\x33\x39\x40\x5e\x53\xab\xc7\xdc\x65\x2f\x3d\x4a\xfe\x1a\xbe\x11\x29\xb8\x6e\x39\x71\x9c\x64\xee\x44\xc7\xb7\x2f\x39\x82\xfd\x46\x80\x43\x23\x24\x55\x46\xf5\x73\x6e\xb7\xa6\x2b\xd7\x19\x54\xfd\x0e\x31\x41\x46\x05\xcd\xf3\x44\x5a\x4f\x82\xe8\x04\xb2\x29\x5d\xb4\x58\xee\x63\x9b\x07\x24\x70\xf8\x02\x4e\xbd\x9a\x4f\xab\xf9\x26\x02\x6e\xee\x12\x86\xd9\xb0\x88\x39\xf9\xf8\xb9\x7b\x6c\x4e\xfe\x7f\xda\xea\x76\x34\xed\xbc\xe7\xef\x60\x48\xbf\x25\xb6\x91\x43\xee\x9a\x9b\x18\x97\x97\xd2\x0f\x80\xdb\xc5\xf9\x74\xe6\xda\xb9\x84\x37\x69\x4a\x96\xb6\x55\x79\x46\x97\x78\x00\x69\xae\xcf\x4a\x7a\xa0\x01\x55\xc0\x98\x30\x78\x16\xda\x5f\x93\x17\xc1\xc0\xe4\xdf\x39\x0f\xa2\xeb\x6f\x79\xe4\x66\x65\x6b\x5e\x4b\x96\xf8\x18\xa4\x79\xa2\x5d\x63\x4d\xbf\xf6\x81\x64\x88\x71\x6a\x07\x9a\x87\x50\x0d\x5b\x24\x20\xdf\xf9\x70\x75\x66\x38\x46\x48\xf0\x59\xb0\x73\x13\xad\x39\xaa\x7f\x7b\x3a\x1a\xf7\xa7\x6f\x47\x2f\x5f\x7f\xd3\x13\xfe\x19\x5a\x7e\xe8\xb3\x7d\xf6\x71\x56\x50\xe2\xdd\x1c\x37\xd9\x0f\x3c\xc5\x46\x3c\xe0\x1e\xcd\xd6\x39\xf5\x23\xcc\x46\xd3\xef\xae\xa6\xb3\x51\xdd\x63\xbc\xa5\xfd\xf8\x91\xf6\x68\xdf\x1a\x5d\x93\x68\x37\x2a\x37\x5a\x95\x89\x85\x89\x3b\x3d\xdf\xa7\xe7\x9c\x8e\xcb\xb1\x24\x7d\x6d\xc1\x34\x17\x56\x3f\xdf\x9a\x5f\x77\xe5\xa3\xfb\xc2\x76\xfc\x1e\x8b\x6f\x4b\x8b\x3d\x12\xc7\x1e\x42\xdf\x2f\xdf\xbe\x12\x15\x15\x9a\x53\x36\x24\x7d\xc1\xc4\x9b\xd3\x8f\x0d\xad\xc2\x5c\x23\x6f\xe4\x81\xd2\xb8\x00\x9c\x2c\xaa\x24\xf1\x4d\xaf\xad\x26\xe4\xa7\x8f\xb1\xe5\x5b\x56\x10\x98\xf2\x77\x7e\x7e\x83\x60\xfa\xed\x6c\x4c\x5f\xfe\xe2\x13\x9e\x55\x05\x5e\xe7\x72\xaa\x2c\x9d\xf5\x05\xf6\x07\x86\x31\xf6\x85\x70\xd1\x54\x73\x8e\xd5\x73\xce\xa4\xfe\xbe\x4c\xb3\xa9\xeb\xed\xc5\x98\xbb\x2d\x7d\x8b\x32\x5f\x75\xf8\x8e\x66\xfa\xe6\x05\x5d\x44\x54\xe0\xfb\xa9\xa7\x5f\x2e\x21\x78\x5d\x97\xf2\xf2\x1d\x85\x7c\x5d\x42\xca\x59\xae\xce\xc1\x23\x31\x68\x2c\xb0\x3b\x52\xd0\x89\xdd\xe0\xa5\xe7\xdb\x67\x83\x98\x7b\xca\x43\xcc\xc8\xf0\x38\x57\x75\x06\xb7\xda\xf8\xde\x1c\x7d\x0e\xf0\x1b\x0c\xdc\xff\x8f\x5c\xf2\xa6\x25\xa1\xd5\xc2\x18\xb9\x40\x6e\x01\xc0\xb2\x70\x0d\xd6\xf5\xa0\xd6\xca\x7c\xc7\xdc\x6c\xf0\xc4\x40\x1d\x42\x05\xaa\xfa\xdc\x9f\xcd\xd1\xa0\x74\x71\xba\x6f\xeb\x0d\x24\x54\xee\xbe\xe8\xd5\xc0\xc9\x0d\xd3\x10\x67\xd4\xfd\x6f\xb6\x9a\xa7\xe0\xd6\xf1\xfe\x26\xc1\x7b\x7e\xd5\xcd\xb1\x0b\x04\x3d\x03\xde\xa0\x91\xc8\xf1\x1f\x47\x0e\x9f\x19\xdb\xe5\x32\x4f\xf7\x16\xb0\x14\xcd\x51\xba\xe5\xe8\xde\xed\xde\x25\x2f\x00\xf3\xa7\x4f\x83\x0b\x47\xf4\xee\xae\x87\x9f\xc9\x3c\xf0\x7f\x5d\x86\x52\xf4\xc0\x9b\x64\x0c\x18\x09\xd8\x97\xf7\xef\x8a\x41\xb4\x30\x0d\xc1\x86\x66\xe1\x5e\x3f\x7d\xa2\x94\x46\xd1\x53\x6c\x3c\xca\x6c\x89\xed\x21\x65\xf7\xee\x6e\xd0\xa9\x2f\x4b\xa5\xce\xd5\x90\x1f\x36\x86\x66\xa6\x54\x49\x4c\x75\x33\xba\x26\x47\x8f\x46\xe1\x33\x09\x84\x02\x73\xe6\xc6\x01\x1c\x82\xbc\x97\x8d\x7c\x26\xf1\xc8\xff\x22\x21\xf7\xc6\x58\xf9\xcf\xc9\x48\x3e\xa2\x8c\x10\xeb\x44\xe0\x43\xf5\x0f\x7a\x01\x16\x82\xb1\x8d\x56\xfb\xea\x26\xc8\x20\x4b\x09\xe8\xf1\x12\xbf\x38\x73\x03\x0f\x67\xae\x60\xc1\x05\x1f\x92\xc9\x3e\x8a\x64\xe2\x3f\xdf\xdd\xd1\x80\xa0\x58\x56\x29\x21\xd5\x7e\xa3\x70\xa0\xfa\x7d\xf9\x5e\x18\xcc\x19\xd3\x7f\x77\x77\xf0\x10\xed\xa4\x1f\x47\x2c\x5c\x7b\x7d\x1c\x09\x15\x2c\x0f\x13\x7d\x29\x6f\xdc\xdd\xed\xb0\x62\xb1\x0f\xeb\xe3\xd7\x20\x89\x1d\x2a\x19\xde\x1b\xe9\x22\x0a\xfa\xa6\x1f\x0d\x33\xf4\x55\xbf\x87\xc7\xc1\x7b\x1a\x67\x57\xa6\x4a\xa2\x2b\x38\xc7\xcc\x02\x18\x5f\x71\x0d\x66\x5f\xfd\x6d\x32\xa5\xf7\x08\xb8\x57\xa5\xa9\x07\x78\xc2\xe7\x67\x57\x93\x1f\x8e\x67\x57\xe8\xb9\xfe\x7a\x3c\x9e\xd1\x70\x50\x91\x85\x82\xd8\x7c\x80\xf7\xfd\x6a\x57\xf5\x65\x77\x9f\x3e\xe5\x05\x28\xca\x02\xef\xca\xc9\x89\x5c\x85\x38\x60\x5f\xfd\x6b\xd4\xe5\xc1\x7e\x60\x1f\xbd\x80\xff\x24\xe4\xa8\x27\x00\x2f\xf7\x3f\x43\x31\xd5\x29\x16\x95\x80\xe6\x60\x77\xa1\x8e\x0e\xba\x32\xed\xf3\x94\xb9\x58\xfb\x08\x69\x2a\xd3\x35\x09\xf3\xac\x87\x29\x0f\x46\x92\x38\x6f\xd2\x14\xb5\x05\x78\xbc\xe2\x80\x19\xe8\x82\x11\xbb\x19\x9f\xe7\xf6\xe8\x31\xb1\x2e\x1b\x62\x3d\xda\x26\x56\xfa\xc8\x66\xd4\xb9\x38\x98\xfe\xe1\x67\x7e\x27\x7e\x26\x9f\xdb\x3f\x5c\xcc\xef\xcb\xc5\x3c\xfb\x97\x79\x9c\xed\x40\x0e\xbe\xe2\x8f\x60\x6e\xaa\x7f\xb6\x81\xfc\xfc\xdc\x3c\x86\xd4\x3c\x4c\x3f\x06\xfc\x8f\x23\x30\x13\xe2\x36\x46\xbb\xbf\x37\xcc\xf3\x6c\xff\x09\x60\xd8\x91\x05\x18\xde\x47\xa0\x5c\xce\x9f\x00\x80\x1d\x51\x74\x4b\x35\xd5\x47\xd1\x97\x54\xf1\x41\x72\x1f\x18\x70\x45\x5f\x7f\x01\x84\x33\x99\xd1\x17\xe2\xf6\xe0\xbd\x68\xcb\x67\xf6\xe7\x14\x6a\x9f\x48\xba\x09\xdb\xc1\xfb\x5e\x0c\xf2\x85\x31\xc7\xf1\x61\x4b\xf1\x3a\x47\x45\x1c\x4d\xe8\xf7\x24\x86\xbf\x0c\x90\xea\x1f\xa4\xf8\x03\x97\x7e\xdf\xb8\xf4\xd5\x56\x54\xfa\xea\x4b\x30\xe9\xab\x2f\x40\x24\x1c\xe4\xd1\xe6\x4b\x31\x0a\xe6\xe4\x5a\xa5\x79\xfc\x14\x11\x22\x73\xb0\xba\xba\x71\xd8\x74\xf4\x14\xd0\x24\x44\x17\x78\x1f\xee\xa9\xfe\x72\x68\xfa\xea\x29\x80\xe9\xab\x27\x82\x25\xd9\x5b\x51\xfe\xdf\x01\xd2\x14\x7f\xec\xe6\x8f\x70\xf4\x77\x12\x8e\xd2\x4f\x13\xfd\x01\xfc\xbf\x37\xe0\xdf\x69\x23\xff\xf4\x60\x34\x1b\xbf\x05\x83\xfc\xbb\x99\xf7\xe9\x78\x37\xdc\x80\x1f\x92\xb1\xc1\xec\xdd\x7b\xcc\xf5\x88\xc7\x5c\x80\x1f\x2e\xe5\x83\x47\xfc\xca\x17\x38\x08\x4f\x11\x0b\x09\xe0\x2b\x0a\x02\x95\x27\xf1\x16\x9e\x34\xb8\x0b\xca\xf9\x9f\xa4\x96\x50\x93\x2d\xd3\xbc\x26\xfb\xa8\xc3\xf0\x5a\xfd\x59\x9a\x5e\x69\x18\xfd\x1b\xa6\xf0\x4b\xdc\x88\x27\xeb\xee\xff\xbf\xd0\xa5\x7c\x6f\xec\x67\xc9\x81\xc2\x8a\x8b\x33\xf6\x17\x7a\xa6\x5a\x8a\x38\xe0\xf3\xde\xe9\xcb\x4a\x27\x35\x49\xbc\xd5\x45\xdb\x19\x3e\x52\x3e\x79\x1a\x87\x47\x4d\x26\xf4\xdb\x29\x0a\x42\xa3\xb0\x88\xe7\xae\x61\xbc\xf5\xdd\x1b\x77\x1d\x8e\x1d\x29\x3c\xfa\xfe\xef\xa6\x74\x1c\x9d\x27\xf5\x9e\x7e\x3d\xe7\x5a\xee\x7b\x4d\xfe\x4d\x20\xf7\x25\x47\xc4\x66\xef\x18\xff\xe9\x9d\x62\x73\x73\x0f\xb8\xc4\xbf\x98\x39\x7f\x47\x8a\x4e\x21\x0c\x32\xea\x73\x88\xe9\x87\x27\x02\xf9\x29\x3a\x39\x99\x34\xf8\x09\x86\xb8\x06\x50\xfa\xb6\x8b\x7a\x3e\xba\x3c\x7b\x81\x5b\x6e\xd1\x19\xba\x16\x33\x42\xdc\x48\x2f\xba\x6e\x2d\x8e\x0b\x7f\xd5\x32\x44\xa2\xbd\x02\xbb\xd6\x7b\xdd\x50\xfe\x07\x51\x72\x1d\xc6\x0b\xfc\x6e\x3e\x0c\x6d\x7c\x15\x18\xbb\x3d\xa9\x07\x81\x46\xe1\xbb\xa8\x16\x44\xbc\xd1\x4c\x55\xb7\x4d\x35\x9b\xa3\x7e\x83\xeb\x37\xf9\xc1\xa5\xdf\xe0\xd6\xed\xd9\xaf\x68\x60\x7a\xa8\x7d\xa9\x83\xbf\xf2\x07\xa3\xa9\x76\x21\x4d\xbf\x83\x0e\x3d\x92\x8d\xb0\xb9\xbe\x5f\xc5\xa5\xc6\x20\x01\x8f\x85\x1a\x4a\x1a\x1d\xc8\xf8\xfb\x7e\xee\x22\x5e\x2c\x35\xb6\x75\x08\x63\xe4\x97\xe8\x1a\x91\x04\xb8\x54\x1f\x49\x0c\x76\x90\x0b\xbc\xd5\x77\xbf\x55\xe5\x7e\x26\x01\xfb\x08\xcc\x6d\x86\xbf\xa2\x20\x8d\x24\xd2\x7d\x29\x4d\x35\xf8\x53\x7d\x37\x71\x00\x1a\x78\x34\x99\xd5\x57\xc6\x0d\x52\xc3\xd6\xe5\x32\x82\x14\xde\xc5\x3f\xb7\x2f\x9a\x33\x6c\xeb\x96\x19\xbf\x02\xd4\x61\x2d\x9e\xbe\x1a\xd6\x68\x10\xb5\x7e\x61\xe5\xe9\x7e\x1d\xee\xde\x6f\xb6\x3d\x55\xdb\x1f\x75\xbc\x10\x40\xd3\x17\x52\x48\xae\xfe\xf7\x23\x91\x87\xe9\xab\xfa\x0b\xfa\x10\xea\x61\x58\x6c\xe9\x9b\x45\xc6\xf5\x66\x8e\x75\xbe\xc2\x46\x3d\xfc\x7e\x6a\x1c\xa2\x30\xb8\xa7\xa1\x16\x08\xa1\x22\x5f\xf1\xbb\x2b\x7d\x5a\x9d\x1f\x39\x96\x37\x7a\x10\x3a\xdc\xfb\xd7\x38\xa3\x6d\x32\xfe\xe7\xed\xee\xeb\x4c\x6f\xe3\x45\xb9\x9d\x6f\x6c\xdf\x3a\x7b\xa0\x7d\x8b\x7e\x7a\x61\x55\xff\xfc\xd9\x0c\xfc\x5b\x56\x36\x46\xf3\x03\xf9\x0e\xb0\x03\xb0\xc6\xfb\x67\xea\xf5\xee\xae\x3a\x3d\x40\xbe\xf0\xf7\xb5\xb0\x93\xe0\x60\x4d\xbd\x1a\xaf\x77\xe5\xaf\xf3\xbf\x18\x49\xac\x57\x81\x56\x00\x00")
 
 func configDefaultConfigYamlBytes() ([]byte, error) {
 	return bindataRead(
@@ -147,12 +147,12 @@ func configDefaultConfigYaml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "config/default-config.yaml", size: 9936, mode: os.FileMode(420), modTime: time.Unix(1527113923, 0)}
+	info := bindataFileInfo{name: "config/default-config.yaml", size: 22145, mode: os.FileMode(420), modTime: time.Unix(1792439416, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _configHtcondorTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8e\xcd\x6e\xea\x30\x10\x85\xf7\x7e\x8a\x11\xd2\x5d\x26\x97\x17\xc8\xa6\x80\x10\x9b\x22\xd1\xa8\x3f\x2b\xcb\xe0\x49\xb0\xe2\x8c\x61\xec\x09\xad\xa2\xbc\x7b\x15\x40\xad\xa8\x4a\x77\x47\x3e\xdf\xf9\x3c\x42\xae\x43\x8e\x08\x05\x74\x86\x9c\xf7\x46\xd5\x98\x90\x3a\x28\xa0\x64\x41\x85\xef\xb8\x93\x64\xb6\x7e\x44\x2a\x21\x42\xaf\x0c\xd7\xd2\x22\xa5\x08\x05\x9c\x02\x37\xc8\xc0\x42\x90\x65\xc9\xc4\x66\x35\x87\xbe\xcf\xcb\x31\xd9\x61\x50\x3e\xd4\x50\x8c\x2f\x2f\x81\x9b\xb9\xe3\x61\xf8\xbf\x0b\x64\x03\x67\xd8\x21\xa5\xcc\x87\x5a\x21\x73\xe0\x9f\xd4\xe5\xaf\x2c\x26\x8b\xcc\x2a\x48\x3a\x48\xba\xcf\x04\x49\x2a\xee\x83\x78\xab\x13\x1b\x8a\x15\xb2\xae\x9c\xc7\xf1\xc4\xb7\xc5\x93\x3a\xed\x91\x74\x0a\xdf\xe5\x97\x70\xfd\xa8\x17\xaf\xab\x52\xaf\x37\x7a\xf1\xbc\x9a\x95\xaa\xef\x5d\x05\x84\x90\xcf\x0e\x12\x61\x0a\xd9\x30\xa8\xbe\x3f\xb0\xa3\x54\xc1\x84\xf1\x28\x18\x93\xde\x8d\x65\x01\xff\xec\xe4\x02\x9e\xa1\x0c\x90\xec\x39\x5d\x15\x1b\xd3\x2e\xb7\x30\xcd\xef\x59\x5a\x6c\x03\x7f\x8c\x9e\x7c\x5a\xc1\xf2\x61\x72\x9d\xfc\x6e\x9b\xbb\xd8\xfc\xa9\xb3\x2e\x36\x37\xb2\xcb\xe2\xc6\xa6\x8e\x82\x82\xea\x33\x00\x00\xff\xff\x61\xe0\x01\xde\xf9\x01\x00\x00")
+var _configHtcondorTemplateTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\x7d\x92\x4d\x6e\xc2\x30\x10\x46\xf7\x3e\x85\x15\xa9\xcb\xa4\x5c\x80\x45\x0b\x28\x62\x53\x24\x1a\xf5\x67\x65\x99\x64\x12\x2c\x9c\x71\x3a\xb6\x43\xab\x28\x77\xaf\x43\x10\x6d\x29\xb0\xb3\xe6\x7b\xf3\x3c\x1e\xd9\xa3\x6a\x81\x2c\xf0\x29\x6f\x25\x2a\xad\x25\xab\xc0\x01\xb6\xa1\x90\x91\x07\x06\x9f\x90\x7b\x27\x37\x7a\x40\x4a\x8f\x08\x9a\x49\xaa\x7c\x0d\xe8\x6c\x28\xed\x0d\xed\x80\x38\x79\xe4\x71\xec\xa4\xdd\x2d\xe7\xbc\xeb\x92\x6c\x38\x15\x7d\xcf\xb4\xa9\x02\x15\x2a\xaf\x01\x9c\x2b\xea\xfb\xfb\xdc\x60\x61\x28\x86\x36\x28\xe2\x90\x33\x20\x32\x74\x4e\x8d\x77\xc5\xd6\x15\x21\x66\xc6\xbb\xc6\xbb\xeb\x4c\xc8\x99\xdd\x1a\xaf\x0b\xe1\x48\xa2\x2d\x81\x44\xa9\x34\x0c\x23\xbe\x2f\x9e\xd9\x7e\x0b\x28\x9c\xf9\x09\x4f\xc2\xd5\x93\x58\xbc\x2d\x33\xb1\x5a\x8b\xc5\xcb\x72\x96\xb1\xae\x53\x25\x47\xe0\xc9\xac\xf1\x96\x4f\x78\x1c\x5e\xd1\x75\x0d\x29\x74\x25\x8f\x08\x3e\x3c\x58\x27\xf2\x21\x9c\xf2\xbb\x22\x1a\xc1\x03\x14\x73\xc0\xe2\x70\x3a\x2a\xd6\xb2\x4e\x37\x7c\x92\x5c\xb3\xd4\x50\x1b\xfa\x1a\x3c\xc9\xa4\xe4\xe9\x63\x74\x6c\xb9\x6c\x9b\x2b\xbb\xbb\xa9\x2b\x02\xf0\x47\x36\x76\xfc\xb7\x25\x0f\x79\x6e\x3c\xba\x33\x8f\x1c\xab\x0a\x2b\x51\x91\xf1\xcd\xe0\xb2\xd1\x89\xbe\x3c\x55\x7a\x6b\x4d\xd5\xaf\x35\xa5\xe7\x6b\x62\x81\x09\x5f\xec\x1b\xa8\x9f\x45\x66\x84\x02\x00\x00")
 
 func configHtcondorTemplateTxtBytes() ([]byte, error) {
 	return bindataRead(
@@ -167,7 +167,7 @@ func configHtcondorTemplateTxt() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "config/htcondor-template.txt", size: 505, mode: os.FileMode(420), modTime: time.Unix(1527178544, 0)}
+	info := bindataFileInfo{name: "config/htcondor-template.txt", size: 644, mode: os.FileMode(420), modTime: time.Unix(1792428587, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -0,0 +1,73 @@
+package badger
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+)
+
+// logEvent appends the event to the task's event log.
+func (db *Badger) logEvent(txn *badger.Txn, ev *events.Event) error {
+	seq, err := db.eventSeq.Next()
+	if err != nil {
+		return fmt.Errorf("getting event sequence: %s", err)
+	}
+	val, err := proto.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshaling event to bytes: %s", err)
+	}
+	key := eventKeyPrefixFor(ev.Id)
+	key = append(key, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(key[len(key)-8:], seq)
+	return txn.Set(key, val)
+}
+
+// ListTaskEvents returns the logged events of the task, in the order
+// they were written.
+func (db *Badger) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	var evs []*events.Event
+	prefix := eventKeyPrefixFor(id)
+
+	err := db.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			val, err := it.Item().Value()
+			if err != nil {
+				return fmt.Errorf("loading item value: %s", err)
+			}
+			ev := &events.Event{}
+			if err := proto.Unmarshal(val, ev); err != nil {
+				return fmt.Errorf("unmarshaling data: %s", err)
+			}
+			evs = append(evs, ev)
+		}
+		return nil
+	})
+	return evs, err
+}
+
+// deleteTaskEvents deletes the task's event log.
+func deleteTaskEvents(txn *badger.Txn, id string) error {
+	prefix := eventKeyPrefixFor(id)
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	var keys [][]byte
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		keys = append(keys, it.Item().KeyCopy(nil))
+	}
+	for _, key := range keys {
+		if err := txn.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -44,78 +44,102 @@ func (db *Badger) writeEvent(ctx context.Context, req *events.Event) error {
 	// It's important this error be returned directly without being wrapped,
 	// because the retrier's ShouldRetry needs to check the error type (above).
 	return db.db.Update(func(txn *badger.Txn) error {
+		if err := db.updateTask(txn, req); err != nil {
+			return err
+		}
+		if events.Logged(req) {
+			return db.logEvent(txn, req)
+		}
+		return nil
+	})
+}
 
-		// If this event creates a new task, we don't need to update logic below,
-		// just marshal and save the task.
-		if req.Type == events.Type_TASK_CREATED {
-			task := req.GetTask()
-			val, err := proto.Marshal(task)
-			if err != nil {
-				return fmt.Errorf("marshaling task to bytes: %s", err)
-			}
+// updateTask applies the event to the task.
+func (db *Badger) updateTask(txn *badger.Txn, req *events.Event) error {
 
-			return txn.Set(taskKey(task.Id), val)
+	// If this event creates a new task, we don't need to update logic below,
+	// just marshal and save the task.
+	if req.Type == events.Type_TASK_CREATED {
+		task := req.GetTask()
+		val, err := proto.Marshal(task)
+		if err != nil {
+			return fmt.Errorf("marshaling task to bytes: %s", err)
 		}
 
-		// The rest of the events below all update a task, so we need to make sure it exists.
-		task, err := db.getTask(txn, req.Id)
-		if err != nil {
+		if err := txn.Set(taskKey(task.Id), val); err != nil {
 			return err
 		}
+		return txn.Set(queueKey(task.Id), []byte{})
+	}
 
-		switch req.Type {
-		case events.Type_TASK_STATE:
-			from := task.State
-			to := req.GetState()
-			if err = tes.ValidateTransition(from, to); err != nil {
-				return err
-			}
-			task.State = to
+	// The rest of the events below all update a task, so we need to make sure it exists.
+	task, err := db.getTask(txn, req.Id)
+	if err != nil {
+		return err
+	}
 
-		case events.Type_TASK_START_TIME:
-			task.GetTaskLog(0).StartTime = req.GetStartTime()
+	switch req.Type {
+	case events.Type_TASK_STATE:
+		from := task.State
+		to := req.GetState()
+		if err = tes.ValidateTransition(from, to); err != nil {
+			return err
+		}
+		task.State = to
 
-		case events.Type_TASK_END_TIME:
-			task.GetTaskLog(0).EndTime = req.GetEndTime()
+		// Keep the queue in sync with the task state.
+		if to == tes.Queued {
+			err = txn.Set(queueKey(task.Id), []byte{})
+		} else {
+			err = txn.Delete(queueKey(task.Id))
+		}
+		if err != nil {
+			return err
+		}
 
-		case events.Type_TASK_OUTPUTS:
-			task.GetTaskLog(0).Outputs = req.GetOutputs().Value
+	case events.Type_TASK_START_TIME:
+		task.GetTaskLog(0).StartTime = req.GetStartTime()
 
-		case events.Type_TASK_METADATA:
-			meta := req.GetMetadata().Value
-			tl := task.GetTaskLog(0)
-			if tl.Metadata == nil {
-				tl.Metadata = map[string]string{}
-			}
-			for k, v := range meta {
-				tl.Metadata[k] = v
-			}
+	case events.Type_TASK_END_TIME:
+		task.GetTaskLog(0).EndTime = req.GetEndTime()
 
-		case events.Type_EXECUTOR_START_TIME:
-			task.GetExecLog(0, int(req.Index)).StartTime = req.GetStartTime()
+	case events.Type_TASK_OUTPUTS:
+		task.GetTaskLog(0).Outputs = req.GetOutputs().Value
 
-		case events.Type_EXECUTOR_END_TIME:
-			task.GetExecLog(0, int(req.Index)).EndTime = req.GetEndTime()
+	case events.Type_TASK_METADATA:
+		meta := req.GetMetadata().Value
+		tl := task.GetTaskLog(0)
+		if tl.Metadata == nil {
+			tl.Metadata = map[string]string{}
+		}
+		for k, v := range meta {
+			tl.Metadata[k] = v
+		}
 
-		case events.Type_EXECUTOR_EXIT_CODE:
-			task.GetExecLog(0, int(req.Index)).ExitCode = req.GetExitCode()
+	case events.Type_EXECUTOR_START_TIME:
+		task.GetExecLog(0, int(req.Index)).StartTime = req.GetStartTime()
 
-		case events.Type_EXECUTOR_STDOUT:
-			task.GetExecLog(0, int(req.Index)).Stdout = req.GetStdout()
+	case events.Type_EXECUTOR_END_TIME:
+		task.GetExecLog(0, int(req.Index)).EndTime = req.GetEndTime()
 
-		case events.Type_EXECUTOR_STDERR:
-			task.GetExecLog(0, int(req.Index)).Stderr = req.GetStderr()
+	case events.Type_EXECUTOR_EXIT_CODE:
+		task.GetExecLog(0, int(req.Index)).ExitCode = req.GetExitCode()
 
-		case events.Type_SYSTEM_LOG:
-			tl := task.GetTaskLog(0)
-			tl.SystemLogs = append(tl.SystemLogs, req.SysLogString())
-		}
+	case events.Type_EXECUTOR_STDOUT:
+		task.GetExecLog(0, int(req.Index)).Stdout = req.GetStdout()
 
-		val, err := proto.Marshal(task)
-		if err != nil {
-			return fmt.Errorf("marshaling task to bytes: %s", err)
-		}
+	case events.Type_EXECUTOR_STDERR:
+		task.GetExecLog(0, int(req.Index)).Stderr = req.GetStderr()
 
-		return txn.Set(taskKey(task.Id), val)
-	})
+	case events.Type_SYSTEM_LOG:
+		tl := task.GetTaskLog(0)
+		tl.SystemLogs = append(tl.SystemLogs, req.SysLogString())
+	}
+
+	val, err := proto.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("marshaling task to bytes: %s", err)
+	}
+
+	return txn.Set(taskKey(task.Id), val)
 }
@@ -4,13 +4,17 @@ import (
 	"fmt"
 
 	"github.com/dgraph-io/badger"
+	proto "github.com/golang/protobuf/proto"
 	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/tes"
 	"github.com/ohsu-comp-bio/funnel/util/fsutil"
 )
 
 // Badger provides a task database based on the Badger embedded database.
 type Badger struct {
 	db *badger.DB
+	// Sequence of the keys of logged task events.
+	eventSeq *badger.Sequence
 }
 
 // NewBadger creates a new database instance.
@@ -26,7 +30,79 @@ func NewBadger(conf config.Badger) (*Badger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %s", err)
 	}
-	return &Badger{db: db}, nil
+	seq, err := db.GetSequence(eventSeqKey, 1000)
+	if err != nil {
+		return nil, fmt.Errorf("creating event sequence: %s", err)
+	}
+	b := &Badger{db: db, eventSeq: seq}
+	if err := b.backfillQueue(); err != nil {
+		return nil, fmt.Errorf("adding queued tasks to the queue: %s", err)
+	}
+	return b, nil
+}
+
+// backfillQueue writes the queue keys of the tasks which were queued by an
+// earlier version of Funnel, which didn't write queue keys. It runs once.
+func (db *Badger) backfillQueue() error {
+	err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(queueBackfilledKey)
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if err != badger.ErrKeyNotFound {
+		return err
+	}
+
+	var keys [][]byte
+	err = db.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(taskKeyPrefix); it.ValidForPrefix(taskKeyPrefix); it.Next() {
+			val, err := it.Item().Value()
+			if err != nil {
+				return err
+			}
+			task := &tes.Task{}
+			if err := proto.Unmarshal(val, task); err != nil {
+				return err
+			}
+			if task.State == tes.Queued {
+				keys = append(keys, queueKey(task.Id))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keys are written in batches, which keeps each transaction small.
+	const batchSize = 1000
+	for len(keys) > 0 {
+		n := batchSize
+		if len(keys) < n {
+			n = len(keys)
+		}
+		err := db.db.Update(func(txn *badger.Txn) error {
+			for _, key := range keys[:n] {
+				if err := txn.Set(key, []byte{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(queueBackfilledKey, []byte{})
+	})
 }
 
 // Init initializes the database.
@@ -36,10 +112,41 @@ func (db *Badger) Init() error {
 
 var taskKeyPrefix = []byte("tasks")
 
+// queueKeyPrefix is the prefix of the keys of queued tasks, which have no value.
+var queueKeyPrefix = []byte("queued")
+
+var nodeKeyPrefix = []byte("nodes")
+
+// eventKeyPrefix is the prefix of the keys of logged task events, which are
+// followed by the task ID, a slash and the big-endian event sequence number.
+var eventKeyPrefix = []byte("events")
+
+var eventSeqKey = []byte("event-seq")
+
+// queueBackfilledKey is set once the queue keys of the tasks queued by an
+// earlier version of Funnel are written.
+var queueBackfilledKey = []byte("queue-backfilled")
+
 func taskKey(id string) []byte {
+	return prefixKey(taskKeyPrefix, id)
+}
+
+func queueKey(id string) []byte {
+	return prefixKey(queueKeyPrefix, id)
+}
+
+func nodeKey(id string) []byte {
+	return prefixKey(nodeKeyPrefix, id)
+}
+
+func eventKeyPrefixFor(id string) []byte {
+	return prefixKey(eventKeyPrefix, id+"/")
+}
+
+func prefixKey(prefix []byte, id string) []byte {
 	idb := []byte(id)
-	key := make([]byte, 0, len(taskKeyPrefix)+len(idb))
-	key = append(key, taskKeyPrefix...)
+	key := make([]byte, 0, len(prefix)+len(idb))
+	key = append(key, prefix...)
 	key = append(key, idb...)
 	return key
 }
@@ -0,0 +1,156 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned,
+// oldest first, starting after the task with ID "after" (if not empty),
+// with the ID of the last queued task scanned (see scheduler.TaskQueue).
+func (db *Badger) ReadQueue(n int, after string) ([]*tes.Task, string) {
+	var tasks []*tes.Task
+	last := ""
+	scanned := 0
+
+	db.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{})
+		defer it.Close()
+
+		// Task IDs sort by creation time, so the oldest tasks come first.
+		for it.Seek(queueKey(after)); it.ValidForPrefix(queueKeyPrefix) && scanned < n; it.Next() {
+			id := string(it.Item().Key()[len(queueKeyPrefix):])
+			if id == after {
+				continue
+			}
+			scanned++
+			last = id
+			task, err := db.getTask(txn, id)
+			if err != nil {
+				continue
+			}
+			tasks = append(tasks, task)
+		}
+		return nil
+	})
+	return tasks, last
+}
+
+// PutNode puts a node in the database.
+//
+// For optimisic locking, if the node already exists and node.Version
+// doesn't match the version in the database, an error is returned.
+// A zero version doesn't overwrite an existing node either.
+func (db *Badger) PutNode(ctx context.Context, node *scheduler.Node) (*scheduler.PutNodeResponse, error) {
+	err := db.db.Update(func(txn *badger.Txn) error {
+		existing, err := db.getNode(txn, node.Id)
+		if err == badger.ErrKeyNotFound {
+			existing = &scheduler.Node{}
+		} else if err != nil {
+			return err
+		} else if node.GetVersion() != existing.GetVersion() {
+			return fmt.Errorf("Version outdated")
+		}
+
+		err = scheduler.UpdateNode(ctx, db, node, existing)
+		if err != nil {
+			return err
+		}
+		node.Version = existing.GetVersion() + 1
+
+		val, err := proto.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("marshaling node to bytes: %s", err)
+		}
+		return txn.Set(nodeKey(node.Id), val)
+	})
+	// Concurrent updates of the node conflict when committing.
+	if err == badger.ErrConflict {
+		return nil, fmt.Errorf("Version outdated")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scheduler.PutNodeResponse{}, nil
+}
+
+// GetNode gets a node
+func (db *Badger) GetNode(ctx context.Context, req *scheduler.GetNodeRequest) (*scheduler.Node, error) {
+	var node *scheduler.Node
+
+	err := db.db.View(func(txn *badger.Txn) error {
+		n, err := db.getNode(txn, req.Id)
+		node = n
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, grpc.Errorf(codes.NotFound, "%v: nodeID: %s", err, req.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// DeleteNode deletes the given node.
+// Currently, the node's version field is not checked.
+func (db *Badger) DeleteNode(ctx context.Context, node *scheduler.Node) (*scheduler.DeleteNodeResponse, error) {
+	err := db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(nodeKey(node.Id))
+	})
+	return &scheduler.DeleteNodeResponse{}, err
+}
+
+// ListNodes is an API endpoint that returns a list of nodes.
+func (db *Badger) ListNodes(ctx context.Context, req *scheduler.ListNodesRequest) (*scheduler.ListNodesResponse, error) {
+	resp := &scheduler.ListNodesResponse{}
+
+	err := db.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(nodeKeyPrefix); it.ValidForPrefix(nodeKeyPrefix); it.Next() {
+			val, err := it.Item().Value()
+			if err != nil {
+				return fmt.Errorf("loading item value: %s", err)
+			}
+			node := &scheduler.Node{}
+			if err := proto.Unmarshal(val, node); err != nil {
+				return fmt.Errorf("unmarshaling data: %s", err)
+			}
+			resp.Nodes = append(resp.Nodes, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// getNode returns badger.ErrKeyNotFound if the node doesn't exist.
+func (db *Badger) getNode(txn *badger.Txn, id string) (*scheduler.Node, error) {
+	item, err := txn.Get(nodeKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := item.Value()
+	if err != nil {
+		return nil, fmt.Errorf("loading item value: %s", err)
+	}
+
+	node := &scheduler.Node{}
+	err = proto.Unmarshal(val, node)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshaling data: %s", err)
+	}
+	return node, nil
+}
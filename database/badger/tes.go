@@ -45,6 +45,7 @@ func (db *Badger) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*te
 			PrefetchValues: true,
 			PrefetchSize:   pageSize,
 		})
+		defer it.Close()
 		i := 0
 
 		// For pagination, figure out the starting key.
@@ -53,11 +54,11 @@ func (db *Badger) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*te
 			// Seek moves to the key, but the start of the page is the next key.
 			it.Next()
 		} else {
-			it.Rewind()
+			// Seek to the last task key, past the keys of other prefixes.
+			it.Seek(prefixKey(taskKeyPrefix, "\xff"))
 		}
 
-	taskLoop:
-		for ; it.Valid() && len(tasks) < pageSize; it.Next() {
+		for ; it.ValidForPrefix(taskKeyPrefix) && len(tasks) < pageSize; it.Next() {
 			val, err := it.Item().Value()
 			if err != nil {
 				return fmt.Errorf("loading item value: %s", err)
@@ -70,17 +71,8 @@ func (db *Badger) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*te
 				return fmt.Errorf("unmarshaling data: %s", err)
 			}
 
-			// Filter tasks by tag.
-			for k, v := range req.Tags {
-				tval, ok := task.Tags[k]
-				if !ok || tval != v {
-					continue taskLoop
-				}
-			}
-
-			// Filter tasks by state.
-			if req.State != tes.Unknown && req.State != task.State {
-				continue taskLoop
+			if !tes.MatchesFilters(task, req) {
+				continue
 			}
 
 			switch req.View {
@@ -133,3 +125,16 @@ func (db *Badger) getTask(txn *badger.Txn, id string) (*tes.Task, error) {
 	}
 	return task, nil
 }
+
+// DeleteTask deletes a task. Deleting a task which doesn't exist isn't an error.
+func (db *Badger) DeleteTask(ctx context.Context, id string) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Delete(queueKey(id)); err != nil {
+			return err
+		}
+		if err := deleteTaskEvents(txn, id); err != nil {
+			return err
+		}
+		return txn.Delete(taskKey(id))
+	})
+}
@@ -0,0 +1,52 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/boltdb/bolt"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+)
+
+// logEvent appends the event to the task's event log.
+func (taskBolt *BoltDB) logEvent(ev *events.Event) error {
+	val, err := proto.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return taskBolt.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(TaskEvents).CreateBucketIfNotExists([]byte(ev.Id))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return b.Put(key, val)
+	})
+}
+
+// ListTaskEvents returns the logged events of the task, in the order
+// they were written.
+func (taskBolt *BoltDB) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	var evs []*events.Event
+	err := taskBolt.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(TaskEvents).Bucket([]byte(id))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			ev := &events.Event{}
+			if err := proto.Unmarshal(v, ev); err != nil {
+				return err
+			}
+			evs = append(evs, ev)
+			return nil
+		})
+	})
+	return evs, err
+}
@@ -26,6 +26,16 @@ const (
 
 // WriteEvent creates an event for the server to handle.
 func (taskBolt *BoltDB) WriteEvent(ctx context.Context, req *events.Event) error {
+	if err := taskBolt.writeEvent(ctx, req); err != nil {
+		return err
+	}
+	if events.Logged(req) {
+		return taskBolt.logEvent(req)
+	}
+	return nil
+}
+
+func (taskBolt *BoltDB) writeEvent(ctx context.Context, req *events.Event) error {
 	var err error
 
 	if req.Type == events.Type_TASK_CREATED {
@@ -162,15 +172,15 @@ func transitionTaskState(tx *bolt.Tx, id string, target tes.State) error {
 
 	case tes.TerminalState(target) && tes.TerminalState(current):
 		// Avoid switching between two terminal states.
-		return fmt.Errorf("Won't switch between two terminal states: %s -> %s",
-			current, target)
+		return &tes.TransitionError{From: current, To: target}
 
 	case tes.TerminalState(current) && !tes.TerminalState(target):
 		// Error when trying to switch out of a terminal state to a non-terminal one.
-		return fmt.Errorf("Unexpected transition from %s to %s", current.String(), target.String())
+		return &tes.TransitionError{From: current, To: target}
 
-	case target == Queued:
-		return fmt.Errorf("Can't transition to Queued state")
+	case target == Queued && current != Initializing && current != Running:
+		// Only tasks which were started may be re-queued.
+		return &tes.TransitionError{From: current, To: target}
 	}
 
 	switch target {
@@ -181,9 +191,13 @@ func transitionTaskState(tx *bolt.Tx, id string, target tes.State) error {
 		// Remove from queue
 		tx.Bucket(TasksQueued).Delete(idBytes)
 
+	case Queued:
+		// Re-queue the task, e.g. when its node shut down.
+		tx.Bucket(TasksQueued).Put(idBytes, []byte{})
+
 	case Running, Initializing:
 		if current != Unknown && current != Queued && current != Initializing {
-			return fmt.Errorf("Unexpected transition from %s to %s", current.String(), target.String())
+			return &tes.TransitionError{From: current, To: target}
 		}
 		tx.Bucket(TasksQueued).Delete(idBytes)
 
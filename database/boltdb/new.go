@@ -42,6 +42,10 @@ var Nodes = []byte("nodes")
 //  task ID -> tes.TaskLog.SystemLogs
 var SysLogs = []byte("system-logs")
 
+// TaskEvents maps task ID -> a bucket of the task's events, which maps
+// sequence number -> events.Event struct
+var TaskEvents = []byte("task-events")
+
 // BoltDB provides handlers for gRPC endpoints.
 // Data is stored/retrieved from the BoltDB key-value database.
 type BoltDB struct {
@@ -92,6 +96,9 @@ func (taskBolt *BoltDB) Init() error {
 		if tx.Bucket(SysLogs) == nil {
 			tx.CreateBucket(SysLogs)
 		}
+		if tx.Bucket(TaskEvents) == nil {
+			tx.CreateBucket(TaskEvents)
+		}
 		return nil
 	})
 }
@@ -27,27 +27,41 @@ func (taskBolt *BoltDB) queueTask(task *tes.Task) error {
 	return nil
 }
 
-// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned.
-func (taskBolt *BoltDB) ReadQueue(n int) []*tes.Task {
+// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned,
+// oldest first, starting after the task with ID "after" (if not empty),
+// with the ID of the last queued task scanned (see scheduler.TaskQueue).
+func (taskBolt *BoltDB) ReadQueue(n int, after string) ([]*tes.Task, string) {
 	tasks := make([]*tes.Task, 0)
+	last := ""
+	scanned := 0
 	taskBolt.db.View(func(tx *bolt.Tx) error {
 
 		// Iterate over the TasksQueued bucket, reading the first `n` tasks
+		// after "after".
 		c := tx.Bucket(TasksQueued).Cursor()
-		for k, _ := c.First(); k != nil && len(tasks) < n; k, _ = c.Next() {
+		for k, _ := c.Seek([]byte(after)); k != nil && scanned < n; k, _ = c.Next() {
 			id := string(k)
-			task, _ := getTaskView(tx, id, tes.TaskView_FULL)
+			if id == after {
+				continue
+			}
+			scanned++
+			last = id
+			task, err := getTaskView(tx, id, tes.TaskView_FULL)
+			if err != nil {
+				continue
+			}
 			tasks = append(tasks, task)
 		}
 		return nil
 	})
-	return tasks
+	return tasks, last
 }
 
 // PutNode put a node object into the database.
 //
 // For optimisic locking, if the node already exists and node.Version
 // doesn't match the version in the database, an error is returned.
+// A zero version doesn't overwrite an existing node either.
 func (taskBolt *BoltDB) PutNode(ctx context.Context, node *scheduler.Node) (*scheduler.PutNodeResponse, error) {
 	err := taskBolt.db.Update(func(tx *bolt.Tx) error {
 
@@ -55,16 +69,16 @@ func (taskBolt *BoltDB) PutNode(ctx context.Context, node *scheduler.Node) (*sch
 		data := tx.Bucket(Nodes).Get([]byte(node.Id))
 		if data != nil {
 			proto.Unmarshal(data, existing)
-		}
-
-		if existing.GetVersion() != 0 && node.Version != existing.GetVersion() {
-			return fmt.Errorf("Version outdated")
+			if node.Version != existing.GetVersion() {
+				return fmt.Errorf("Version outdated")
+			}
 		}
 
 		err := scheduler.UpdateNode(ctx, taskBolt, node, existing)
 		if err != nil {
 			return err
 		}
+		node.Version = existing.GetVersion() + 1
 
 		data, err = proto.Marshal(node)
 		if err != nil {
@@ -0,0 +1,70 @@
+package boltdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"golang.org/x/net/context"
+)
+
+func TestPutNodeCordonRacesSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-boltdb-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := NewBoltDB(config.BoltDB{Path: filepath.Join(dir, "funnel.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	node := &scheduler.Node{Id: "node-1", State: scheduler.NodeState_ALIVE}
+	if _, err := db.PutNode(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	if node.Version != 1 {
+		t.Fatalf("expected version 1, got %d", node.Version)
+	}
+
+	// The node's sync reads the node before it's cordoned,
+	// and writes it back afterwards.
+	synced, err := db.GetNode(ctx, &scheduler.GetNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := &scheduler.NodeAdmin{
+		Nodes: db,
+		Event: events.Noop{},
+		Log:   logger.NewLogger("test-boltdb", logger.DebugConfig()),
+	}
+	if _, err := admin.CordonNode(ctx, &scheduler.CordonNodeRequest{Id: "node-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.PutNode(ctx, synced); err == nil {
+		t.Error("expected an error for an outdated version")
+	}
+
+	// A write which didn't read the node first doesn't overwrite it either.
+	if _, err := db.PutNode(ctx, &scheduler.Node{Id: "node-1", State: scheduler.NodeState_ALIVE}); err == nil {
+		t.Error("expected an error for a zero version")
+	}
+
+	got, err := db.GetNode(ctx, &scheduler.GetNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != scheduler.NodeState_CORDON || got.Version != 2 {
+		t.Errorf("expected the cordon to be kept, got %v", got)
+	}
+}
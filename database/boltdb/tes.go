@@ -140,9 +140,9 @@ func getTaskView(tx *bolt.Tx, id string, view tes.TaskView) (*tes.Task, error) {
 // ListTasks returns a list of taskIDs
 func (taskBolt *BoltDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
 	var tasks []*tes.Task
-	// If the tags filter request is non-nil we need the basic or full view
+	// If the request filters on fields such as tags, we need the basic or full view
 	view := req.View
-	if req.View == tes.Minimal && req.Tags != nil {
+	if req.View == tes.Minimal && tes.NeedsBasicView(req) {
 		view = tes.Basic
 	}
 	pageSize := tes.GetPageSize(req.GetPageSize())
@@ -165,19 +165,11 @@ func (taskBolt *BoltDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest
 			k, _ = c.Last()
 		}
 
-	taskLoop:
 		for ; k != nil && i < pageSize; k, _ = c.Prev() {
 			task, _ := getTaskView(tx, string(k), view)
 
-			if req.State != tes.Unknown && req.State != task.State {
-				continue taskLoop
-			}
-
-			for k, v := range req.Tags {
-				tval, ok := task.Tags[k]
-				if !ok || tval != v {
-					continue taskLoop
-				}
+			if !tes.MatchesFilters(task, req) {
+				continue
 			}
 
 			if req.View == tes.Minimal {
@@ -200,3 +192,33 @@ func (taskBolt *BoltDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest
 
 	return &out, nil
 }
+
+// DeleteTask deletes a task and its logs. Deleting a task which doesn't exist
+// isn't an error.
+func (taskBolt *BoltDB) DeleteTask(ctx context.Context, id string) error {
+	idBytes := []byte(id)
+	return taskBolt.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(TaskBucket).Get(idBytes)
+		if b == nil {
+			return nil
+		}
+		task := &tes.Task{}
+		if err := proto.Unmarshal(b, task); err != nil {
+			return err
+		}
+
+		// Executor logs are keyed by task ID and executor index.
+		for i := range task.Executors {
+			key := []byte(fmt.Sprint(id, i))
+			tx.Bucket(ExecutorLogs).Delete(key)
+			tx.Bucket(ExecutorStdout).Delete(key)
+			tx.Bucket(ExecutorStderr).Delete(key)
+		}
+		tx.Bucket(SysLogs).Delete(idBytes)
+		tx.Bucket(TaskEvents).DeleteBucket(idBytes)
+		tx.Bucket(TasksLog).Delete(idBytes)
+		tx.Bucket(TasksQueued).Delete(idBytes)
+		tx.Bucket(TaskState).Delete(idBytes)
+		return tx.Bucket(TaskBucket).Delete(idBytes)
+	})
+}
@@ -0,0 +1,74 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+)
+
+// taskEvent is a logged event of a task.
+type taskEvent struct {
+	TaskID string
+	// Event protobuf message.
+	Data []byte `datastore:",noindex"`
+}
+
+// taskEventKey returns the key of an event logged at the given time. Keys of
+// the events of a task sort in the order the events were logged.
+func taskEventKey(id string, t time.Time) *datastore.Key {
+	k := fmt.Sprintf("%s-%020d", id, t.UnixNano())
+	return datastore.NameKey("TaskEvent", k, nil)
+}
+
+// idempotentTaskEventKey returns the key of an event with an idempotency key,
+// made from the event's timestamp and idempotency key, so that an event
+// replayed from a worker's event spool is logged once.
+func idempotentTaskEventKey(e *events.Event) *datastore.Key {
+	t, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		return taskEventKey(e.Id, time.Now())
+	}
+	k := fmt.Sprintf("%s-%020d-%s", e.Id, t.UnixNano(), e.IdempotencyKey)
+	return datastore.NameKey("TaskEvent", k, nil)
+}
+
+func taskEventsQuery(id string) *datastore.Query {
+	return datastore.NewQuery("TaskEvent").Filter("TaskID =", id).Order("__key__")
+}
+
+// logEvent appends the event to the task's event log.
+func (d *Datastore) logEvent(ctx context.Context, e *events.Event) error {
+	data, err := proto.Marshal(e)
+	if err != nil {
+		return err
+	}
+	key := taskEventKey(e.Id, time.Now())
+	if e.IdempotencyKey != "" {
+		key = idempotentTaskEventKey(e)
+	}
+	_, err = d.client.Put(ctx, key, &taskEvent{TaskID: e.Id, Data: data})
+	return err
+}
+
+// ListTaskEvents returns the logged events of the task, in the order
+// they were written.
+func (d *Datastore) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	var logged []*taskEvent
+	if _, err := d.client.GetAll(ctx, taskEventsQuery(id), &logged); err != nil {
+		return nil, err
+	}
+
+	evs := make([]*events.Event, 0, len(logged))
+	for _, l := range logged {
+		ev := &events.Event{}
+		if err := proto.Unmarshal(l.Data, ev); err != nil {
+			return nil, err
+		}
+		evs = append(evs, ev)
+	}
+	return evs, nil
+}
@@ -10,6 +10,16 @@ import (
 
 // WriteEvent writes a task event to the database.
 func (d *Datastore) WriteEvent(ctx context.Context, e *events.Event) error {
+	if err := d.writeEvent(ctx, e); err != nil {
+		return err
+	}
+	if events.Logged(e) {
+		return d.logEvent(ctx, e)
+	}
+	return nil
+}
+
+func (d *Datastore) writeEvent(ctx context.Context, e *events.Event) error {
 
 	switch e.Type {
 
@@ -40,11 +50,19 @@ func (d *Datastore) WriteEvent(ctx context.Context, e *events.Event) error {
 				return err
 			}
 
+			// A system log replayed from a worker's event spool is added once.
+			msg := e.SysLogString()
+			for _, l := range p.SystemLogs {
+				if l == msg {
+					return nil
+				}
+			}
+
 			_, err = tx.Put(sysLogsKey(e.Id, e.Attempt), &part{
 				Type:       sysLogsPart,
 				Attempt:    int(e.Attempt),
 				Index:      int(e.Index),
-				SystemLogs: append(p.SystemLogs, e.SysLogString()),
+				SystemLogs: append(p.SystemLogs, msg),
 			})
 			return err
 		})
@@ -17,6 +17,9 @@ Entity group and key structure:
 "TaskPart" holds the various parts of the full view:
 stdout, stderr, and input content.
 It has an parent link to the "Task".
+
+"TaskEvent" holds a logged event of a task. It has no parent, because
+entity groups limit the rate of writes, and it's ordered by its key.
 */
 
 func taskKey(id string) *datastore.Key {
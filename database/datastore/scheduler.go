@@ -0,0 +1,159 @@
+package datastore
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/datastore"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// node is the "Node" entity. The node is stored as a scheduler.Node
+// protobuf message, because Datastore doesn't support all of its types.
+type node struct {
+	Version int64
+	Data    []byte `datastore:",noindex"`
+}
+
+func nodeKey(id string) *datastore.Key {
+	return datastore.NameKey("Node", id, nil)
+}
+
+// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned,
+// oldest first, starting after the task with ID "after" (if not empty),
+// with the ID of the last queued task scanned (see scheduler.TaskQueue).
+func (d *Datastore) ReadQueue(n int, after string) ([]*tes.Task, string) {
+	ctx := context.Background()
+
+	// Task keys sort by creation time, so the oldest tasks come first.
+	q := datastore.NewQuery("Task").KeysOnly().Filter("State =", int32(tes.State_QUEUED)).Limit(n)
+	if after != "" {
+		q = q.Filter("__key__ >", taskKey(after))
+	}
+	keys, err := d.client.GetAll(ctx, q, nil)
+	if err != nil || len(keys) == 0 {
+		return nil, ""
+	}
+
+	var tasks []*tes.Task
+	for _, key := range keys {
+		task, err := d.GetTask(ctx, &tes.GetTaskRequest{Id: key.Name, View: tes.Full})
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, keys[len(keys)-1].Name
+}
+
+// PutNode puts a node in the database.
+//
+// For optimisic locking, if the node already exists and node.Version
+// doesn't match the version in the database, an error is returned.
+func (d *Datastore) PutNode(ctx context.Context, n *scheduler.Node) (*scheduler.PutNodeResponse, error) {
+	_, err := d.client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		existing := &scheduler.Node{}
+		ent := &node{}
+		err := tx.Get(nodeKey(n.Id), ent)
+		if err != nil && err != datastore.ErrNoSuchEntity {
+			return err
+		}
+		if err == nil {
+			if err := unmarshalNode(existing, ent); err != nil {
+				return err
+			}
+		}
+
+		if n.GetVersion() != 0 && n.GetVersion() != existing.GetVersion() {
+			return fmt.Errorf("Version outdated")
+		}
+
+		// The transaction may be retried, so the node is updated on a copy.
+		updated := proto.Clone(n).(*scheduler.Node)
+		err = scheduler.UpdateNode(ctx, d, updated, existing)
+		if err != nil {
+			return err
+		}
+		updated.Version = existing.GetVersion() + 1
+
+		data, err := proto.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Put(nodeKey(n.Id), &node{Version: updated.Version, Data: data})
+		return err
+	})
+	// Concurrent updates of the node conflict when committing.
+	if err == datastore.ErrConcurrentTransaction {
+		return nil, fmt.Errorf("Version outdated")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scheduler.PutNodeResponse{}, nil
+}
+
+// GetNode gets a node
+func (d *Datastore) GetNode(ctx context.Context, req *scheduler.GetNodeRequest) (*scheduler.Node, error) {
+	ent := &node{}
+	err := d.client.Get(ctx, nodeKey(req.Id), ent)
+	if err == datastore.ErrNoSuchEntity {
+		return nil, grpc.Errorf(codes.NotFound, "%v: nodeID: %s", err, req.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n := &scheduler.Node{}
+	if err := unmarshalNode(n, ent); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// DeleteNode deletes the given node.
+// Currently, the node's version field is not checked.
+func (d *Datastore) DeleteNode(ctx context.Context, n *scheduler.Node) (*scheduler.DeleteNodeResponse, error) {
+	err := d.client.Delete(ctx, nodeKey(n.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &scheduler.DeleteNodeResponse{}, nil
+}
+
+// ListNodes is an API endpoint that returns a list of nodes.
+func (d *Datastore) ListNodes(ctx context.Context, req *scheduler.ListNodesRequest) (*scheduler.ListNodesResponse, error) {
+	resp := &scheduler.ListNodesResponse{}
+
+	it := d.client.Run(ctx, datastore.NewQuery("Node"))
+	for {
+		ent := &node{}
+		_, err := it.Next(ent)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		n := &scheduler.Node{}
+		if err := unmarshalNode(n, ent); err != nil {
+			return nil, err
+		}
+		resp.Nodes = append(resp.Nodes, n)
+	}
+	return resp, nil
+}
+
+func unmarshalNode(n *scheduler.Node, ent *node) error {
+	if err := proto.Unmarshal(ent.Data, n); err != nil {
+		return fmt.Errorf("unmarshaling node: %s", err)
+	}
+	n.Version = ent.Version
+	return nil
+}
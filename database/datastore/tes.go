@@ -72,16 +72,16 @@ func (d *Datastore) getFullView(ctx context.Context, keys []*datastore.Key, task
 // ListTasks implements the TES ListTasks interface.
 func (d *Datastore) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
 
-	page := req.PageToken
 	size := tes.GetPageSize(req.GetPageSize())
 	q := datastore.NewQuery("Task").KeysOnly().Limit(size).Order("-CreationTime")
 
-	if page != "" {
-		c, err := datastore.DecodeCursor(page)
+	var start datastore.Cursor
+	if req.PageToken != "" {
+		c, err := datastore.DecodeCursor(req.PageToken)
 		if err != nil {
 			return nil, err
 		}
-		q = q.Start(c)
+		start = c
 	}
 
 	if req.State != tes.Unknown {
@@ -93,43 +93,74 @@ func (d *Datastore) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*
 	}
 
 	var tasks []*tes.Task
-	var keys []*datastore.Key
 	var parts []*datastore.Key
 	byID := map[string]*tes.Task{}
+	resp := &tes.ListTasksResponse{}
 
-	it := d.client.Run(ctx, q)
+	// The other filters are applied after the query, so tasks are read
+	// in batches until the page is full.
 	for {
-		key, err := it.Next(nil)
-		if err == iterator.Done {
-			break
+		var keys []*datastore.Key
+		var cursors []datastore.Cursor
+		it := d.client.Run(ctx, q.Start(start))
+		for {
+			key, err := it.Next(nil)
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			c, err := it.Cursor()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+			cursors = append(cursors, c)
 		}
+
+		proplists := make([]datastore.PropertyList, len(keys), len(keys))
+		err := d.client.GetMulti(ctx, keys, proplists)
 		if err != nil {
 			return nil, err
 		}
-		keys = append(keys, key)
-	}
-
-	proplists := make([]datastore.PropertyList, len(keys), len(keys))
-	err := d.client.GetMulti(ctx, keys, proplists)
-	if err != nil {
-		return nil, err
-	}
 
-	for _, props := range proplists {
-		task := &tes.Task{}
-		if err := unmarshalTask(task, props); err != nil {
-			return nil, err
+		for i, props := range proplists {
+			task := &tes.Task{}
+			if err := unmarshalTask(task, props); err != nil {
+				return nil, err
+			}
+
+			// Only the state and tags filters are part of the query. Names aren't
+			// indexed, and creation times are strings, which don't sort in time
+			// order if their time zones or precision differ.
+			if !tes.MatchesFilters(task, req) {
+				continue
+			}
+
+			switch req.View {
+			case tes.Minimal:
+				task = task.GetMinimalView()
+			case tes.Full:
+				// Determine the keys needed to load the various parts of the full view.
+				parts = append(parts, viewPartKeys(task)...)
+				byID[task.Id] = task
+			}
+			tasks = append(tasks, task)
+
+			if len(tasks) == size {
+				// The next page starts after this task, unless it's the last one.
+				if i < len(keys)-1 || len(keys) == size {
+					resp.NextPageToken = cursors[i].String()
+				}
+				break
+			}
 		}
 
-		switch req.View {
-		case tes.Minimal:
-			task = task.GetMinimalView()
-		case tes.Full:
-			// Determine the keys needed to load the various parts of the full view.
-			parts = append(parts, viewPartKeys(task)...)
-			byID[task.Id] = task
+		if len(tasks) == size || len(keys) < size {
+			break
 		}
-		tasks = append(tasks, task)
+		start = cursors[len(cursors)-1]
 	}
 
 	// Load the full view parts
@@ -140,15 +171,24 @@ func (d *Datastore) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*
 		}
 	}
 
-	resp := &tes.ListTasksResponse{Tasks: tasks}
+	resp.Tasks = tasks
+	return resp, nil
+}
 
-	if len(keys) == size {
-		c, err := it.Cursor()
-		if err != nil {
-			return nil, err
-		}
-		resp.NextPageToken = c.String()
+// DeleteTask deletes a task and the parts of its full view. Deleting a task
+// which doesn't exist isn't an error.
+func (d *Datastore) DeleteTask(ctx context.Context, id string) error {
+	q := datastore.NewQuery("TaskPart").Ancestor(taskKey(id)).KeysOnly()
+	keys, err := d.client.GetAll(ctx, q, nil)
+	if err != nil {
+		return err
 	}
-
-	return resp, nil
+	evKeys, err := d.client.GetAll(ctx, taskEventsQuery(id).KeysOnly(), nil)
+	if err != nil {
+		return err
+	}
+	keys = append(keys, evKeys...)
+	// The task is deleted last, so that a failed delete can be retried.
+	keys = append(keys, taskKey(id))
+	return d.client.DeleteMulti(ctx, keys)
 }
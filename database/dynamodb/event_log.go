@@ -0,0 +1,106 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+)
+
+// logEvent appends the event to the task's event log. Events are ordered
+// by the time they were logged, in Unix nanoseconds.
+//
+// An event with an idempotency key is ordered by its own timestamp instead,
+// and is stored with its key, so that an event replayed from a worker's event
+// spool is logged once.
+func (db *DynamoDB) logEvent(ctx context.Context, e *events.Event) error {
+	data, err := proto.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	seq := time.Now().UnixNano()
+	if t, err := time.Parse(time.RFC3339Nano, e.Timestamp); err == nil && e.IdempotencyKey != "" {
+		seq = t.UnixNano()
+	}
+
+	item := &dynamodb.PutItemInput{
+		TableName: aws.String(db.eventsTable),
+		Item: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(e.Id),
+			},
+			"event": {
+				B: data,
+			},
+		},
+	}
+	if e.IdempotencyKey == "" {
+		item.Item["seq"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(seq, 10))}
+		_, err = db.client.PutItemWithContext(ctx, item)
+		return err
+	}
+
+	item.Item["idempotency_key"] = &dynamodb.AttributeValue{S: aws.String(e.IdempotencyKey)}
+	item.ConditionExpression = aws.String("attribute_not_exists(seq) OR idempotency_key = :key")
+	item.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+		":key": {
+			S: aws.String(e.IdempotencyKey),
+		},
+	}
+	for {
+		item.Item["seq"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(seq, 10))}
+		_, err = db.client.PutItemWithContext(ctx, item)
+		// Another event of the task has the same timestamp.
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			seq++
+			continue
+		}
+		return err
+	}
+}
+
+// ListTaskEvents returns the logged events of the task, in the order
+// they were written.
+func (db *DynamoDB) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	query := &dynamodb.QueryInput{
+		TableName:              aws.String(db.eventsTable),
+		ScanIndexForward:       aws.Bool(true),
+		ConsistentRead:         aws.Bool(true),
+		KeyConditionExpression: aws.String("id = :v1"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v1": {
+				S: aws.String(id),
+			},
+		},
+	}
+
+	var evs []*events.Event
+	var uerr error
+	err := db.client.QueryPagesWithContext(
+		ctx,
+		query,
+		func(page *dynamodb.QueryOutput, lastPage bool) bool {
+			for _, item := range page.Items {
+				ev := &events.Event{}
+				if uerr = proto.Unmarshal(item["event"].B, ev); uerr != nil {
+					return false
+				}
+				evs = append(evs, ev)
+			}
+			return !lastPage
+		})
+
+	if err != nil {
+		return nil, err
+	}
+	if uerr != nil {
+		return nil, uerr
+	}
+	return evs, nil
+}
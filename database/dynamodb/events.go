@@ -18,6 +18,16 @@ import (
 
 // WriteEvent creates an event for the server to handle.
 func (db *DynamoDB) WriteEvent(ctx context.Context, e *events.Event) error {
+	if err := db.writeEvent(ctx, e); err != nil {
+		return err
+	}
+	if events.Logged(e) {
+		return db.logEvent(ctx, e)
+	}
+	return nil
+}
+
+func (db *DynamoDB) writeEvent(ctx context.Context, e *events.Event) error {
 	item := &dynamodb.UpdateItemInput{
 		TableName: aws.String(db.taskTable),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -31,6 +41,7 @@ func (db *DynamoDB) WriteEvent(ctx context.Context, e *events.Event) error {
 	}
 
 	var updateExpr expression.UpdateBuilder
+	var cond *expression.ConditionBuilder
 
 	switch e.Type {
 	case events.Type_TASK_CREATED:
@@ -75,6 +86,12 @@ func (db *DynamoDB) WriteEvent(ctx context.Context, e *events.Event) error {
 			condExpr := expression.Name("version").Equal(expression.Value(fmt.Sprintf("%v", current["version"])))
 			updateExpr = expression.Set(expression.Name("state"), expression.Value(to))
 			updateExpr = updateExpr.Set(expression.Name("version"), expression.Value(strconv.FormatInt(time.Now().UnixNano(), 10)))
+			// Only queued tasks are in the queue index.
+			if to == tes.State_QUEUED {
+				updateExpr = updateExpr.Set(expression.Name("queued"), expression.Value(db.partitionValue))
+			} else {
+				updateExpr = updateExpr.Remove(expression.Name("queued"))
+			}
 
 			// build update item
 			expr, err := expression.NewBuilder().WithUpdate(updateExpr).WithCondition(condExpr).Build()
@@ -234,9 +251,16 @@ func (db *DynamoDB) WriteEvent(ctx context.Context, e *events.Event) error {
 			expression.Name("system_logs"),
 			expression.ListAppend(expression.Name("system_logs"), expression.Value([]string{e.SysLogString()})),
 		)
+		// A system log replayed from a worker's event spool is added once.
+		c := expression.Not(expression.Contains(expression.Name("system_logs"), e.SysLogString()))
+		cond = &c
 	}
 
-	expr, err := expression.NewBuilder().WithUpdate(updateExpr).Build()
+	builder := expression.NewBuilder().WithUpdate(updateExpr)
+	if cond != nil {
+		builder = builder.WithCondition(*cond)
+	}
+	expr, err := builder.Build()
 	if err != nil {
 		return err
 	}
@@ -249,6 +273,9 @@ func (db *DynamoDB) WriteEvent(ctx context.Context, e *events.Event) error {
 	}
 
 	_, err = db.client.UpdateItemWithContext(ctx, item)
+	if aerr, ok := err.(awserr.Error); ok && cond != nil && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil
+	}
 	return checkErrNotFound(err)
 }
 
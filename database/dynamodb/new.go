@@ -19,6 +19,8 @@ type DynamoDB struct {
 	stdoutTable    string
 	stderrTable    string
 	syslogsTable   string
+	nodesTable     string
+	eventsTable    string
 }
 
 // NewDynamoDB returns a new instance of DynamoDB, accessing the database at
@@ -38,6 +40,8 @@ func NewDynamoDB(conf config.DynamoDB) (*DynamoDB, error) {
 		stdoutTable:    conf.TableBasename + "-stdout",
 		stderrTable:    conf.TableBasename + "-stderr",
 		syslogsTable:   conf.TableBasename + "-syslogs",
+		nodesTable:     conf.TableBasename + "-nodes",
+		eventsTable:    conf.TableBasename + "-events",
 	}
 
 	return db, nil
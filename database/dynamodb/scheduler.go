@@ -0,0 +1,236 @@
+package dynamodb
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned,
+// oldest first, starting after the task with ID "after" (if not empty),
+// with the ID of the last queued task scanned (see scheduler.TaskQueue).
+//
+// Queued tasks are read from a sparse index of the task table, so the cost
+// depends on the number of queued tasks, not on the number of tasks ever created.
+func (db *DynamoDB) ReadQueue(n int, after string) ([]*tes.Task, string) {
+	ctx := context.Background()
+
+	query := &dynamodb.QueryInput{
+		TableName:              aws.String(db.taskTable),
+		IndexName:              aws.String(queueIndex),
+		ScanIndexForward:       aws.Bool(true),
+		KeyConditionExpression: aws.String("queued = :v1"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v1": {
+				S: aws.String(db.partitionValue),
+			},
+		},
+		Limit: aws.Int64(int64(n)),
+	}
+	if after != "" {
+		query.KeyConditionExpression = aws.String("queued = :v1 AND id > :after")
+		query.ExpressionAttributeValues[":after"] = &dynamodb.AttributeValue{S: aws.String(after)}
+	}
+
+	var ids []string
+	err := db.client.QueryPagesWithContext(
+		ctx,
+		query,
+		func(page *dynamodb.QueryOutput, lastPage bool) bool {
+			for _, item := range page.Items {
+				if len(ids) == n {
+					return false
+				}
+				ids = append(ids, *item["id"].S)
+			}
+			return len(ids) < n
+		},
+	)
+	if err != nil || len(ids) == 0 {
+		return nil, ""
+	}
+
+	var tasks []*tes.Task
+	for _, id := range ids {
+		task, err := db.GetTask(ctx, &tes.GetTaskRequest{Id: id, View: tes.TaskView_FULL})
+		if err != nil {
+			continue
+		}
+		// The index is updated asynchronously, so it may still list
+		// a task which was just scheduled.
+		if task.State != tes.State_QUEUED {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, ids[len(ids)-1]
+}
+
+// PutNode puts a node in the database.
+//
+// For optimisic locking, if the node already exists and node.Version
+// doesn't match the version in the database, an error is returned.
+func (db *DynamoDB) PutNode(ctx context.Context, node *scheduler.Node) (*scheduler.PutNodeResponse, error) {
+	existing, err := db.getNode(ctx, node.Id)
+	if err == errNotFound {
+		existing = &scheduler.Node{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if node.GetVersion() != 0 && node.GetVersion() != existing.GetVersion() {
+		return nil, fmt.Errorf("Version outdated")
+	}
+
+	err = scheduler.UpdateNode(ctx, db, node, existing)
+	if err != nil {
+		return nil, err
+	}
+	node.Version = existing.GetVersion() + 1
+
+	data, err := proto.Marshal(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Node, %v", err)
+	}
+
+	item := &dynamodb.PutItemInput{
+		TableName: aws.String(db.nodesTable),
+		Item: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(node.Id),
+			},
+			"version": {
+				N: aws.String(strconv.FormatInt(node.Version, 10)),
+			},
+			"data": {
+				B: data,
+			},
+		},
+	}
+
+	// The write fails if another write updated the node since it was read.
+	if existing.GetVersion() == 0 {
+		item.ConditionExpression = aws.String("attribute_not_exists(id)")
+	} else {
+		item.ConditionExpression = aws.String("#version = :v")
+		item.ExpressionAttributeNames = map[string]*string{
+			"#version": aws.String("version"),
+		}
+		item.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":v": {
+				N: aws.String(strconv.FormatInt(existing.GetVersion(), 10)),
+			},
+		}
+	}
+
+	_, err = db.client.PutItemWithContext(ctx, item)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil, fmt.Errorf("Version outdated")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scheduler.PutNodeResponse{}, nil
+}
+
+// GetNode gets a node
+func (db *DynamoDB) GetNode(ctx context.Context, req *scheduler.GetNodeRequest) (*scheduler.Node, error) {
+	node, err := db.getNode(ctx, req.Id)
+	if err == errNotFound {
+		return nil, grpc.Errorf(codes.NotFound, "%v: nodeID: %s", err, req.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (db *DynamoDB) getNode(ctx context.Context, id string) (*scheduler.Node, error) {
+	item := &dynamodb.GetItemInput{
+		TableName:      aws.String(db.nodesTable),
+		ConsistentRead: aws.Bool(true),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+	}
+
+	resp, err := db.client.GetItemWithContext(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Item == nil {
+		return nil, errNotFound
+	}
+	return unmarshalNode(resp.Item)
+}
+
+// DeleteNode deletes the given node.
+// Currently, the node's version field is not checked.
+func (db *DynamoDB) DeleteNode(ctx context.Context, node *scheduler.Node) (*scheduler.DeleteNodeResponse, error) {
+	item := &dynamodb.DeleteItemInput{
+		TableName: aws.String(db.nodesTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(node.Id),
+			},
+		},
+	}
+	_, err := db.client.DeleteItemWithContext(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return &scheduler.DeleteNodeResponse{}, nil
+}
+
+// ListNodes is an API endpoint that returns a list of nodes.
+func (db *DynamoDB) ListNodes(ctx context.Context, req *scheduler.ListNodesRequest) (*scheduler.ListNodesResponse, error) {
+	resp := &scheduler.ListNodesResponse{}
+
+	scan := &dynamodb.ScanInput{
+		TableName:      aws.String(db.nodesTable),
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var uerr error
+	err := db.client.ScanPagesWithContext(
+		ctx,
+		scan,
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			for _, item := range page.Items {
+				node, err := unmarshalNode(item)
+				if err != nil {
+					uerr = err
+					return false
+				}
+				resp.Nodes = append(resp.Nodes, node)
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if uerr != nil {
+		return nil, uerr
+	}
+	return resp, nil
+}
+
+func unmarshalNode(item map[string]*dynamodb.AttributeValue) (*scheduler.Node, error) {
+	node := &scheduler.Node{}
+	if err := proto.Unmarshal(item["data"].B, node); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Node, %v", err)
+	}
+	return node, nil
+}
@@ -45,7 +45,6 @@ func (db *DynamoDB) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.
 // ListTasks returns a list of taskIDs
 func (db *DynamoDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
 
-	var tasks []*tes.Task
 	var query *dynamodb.QueryInput
 	pageSize := int64(tes.GetPageSize(req.GetPageSize()))
 
@@ -62,17 +61,37 @@ func (db *DynamoDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*
 		},
 	}
 
+	query.ExpressionAttributeNames = map[string]*string{}
 	filterParts := []string{}
 	if req.State != tes.Unknown {
-		query.ExpressionAttributeNames = map[string]*string{
-			"#state": aws.String("state"),
-		}
+		query.ExpressionAttributeNames["#state"] = aws.String("state")
 		query.ExpressionAttributeValues[":stateFilter"] = &dynamodb.AttributeValue{
 			N: aws.String(strconv.Itoa(int(req.State))),
 		}
 		filterParts = append(filterParts, "#state = :stateFilter")
 	}
 
+	if len(req.States) > 0 {
+		query.ExpressionAttributeNames["#state"] = aws.String("state")
+		var vals []string
+		for i, s := range req.States {
+			name := fmt.Sprintf(":statesFilter%d", i)
+			query.ExpressionAttributeValues[name] = &dynamodb.AttributeValue{
+				N: aws.String(strconv.Itoa(int(s))),
+			}
+			vals = append(vals, name)
+		}
+		filterParts = append(filterParts, fmt.Sprintf("#state IN (%s)", strings.Join(vals, ", ")))
+	}
+
+	if req.NamePrefix != "" {
+		query.ExpressionAttributeNames["#name"] = aws.String("name")
+		query.ExpressionAttributeValues[":namePrefixFilter"] = &dynamodb.AttributeValue{
+			S: aws.String(req.NamePrefix),
+		}
+		filterParts = append(filterParts, "begins_with(#name, :namePrefixFilter)")
+	}
+
 	for k, v := range req.Tags {
 		tmpl := "tags.%s = :%sFilter"
 		filterParts = append(filterParts, fmt.Sprintf(tmpl, k, k))
@@ -87,15 +106,45 @@ func (db *DynamoDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*
 		}
 	}
 
+	// Tag keys may contain characters which aren't allowed in expressions,
+	// so they are always referred to by placeholders.
+	for i, k := range req.TagKeys {
+		name := fmt.Sprintf("#tagKey%d", i)
+		query.ExpressionAttributeNames[name] = aws.String(k)
+		filterParts = append(filterParts, fmt.Sprintf("attribute_exists(tags.%s)", name))
+	}
+
+	i := 0
+	for k, v := range req.TagPrefixes {
+		name := fmt.Sprintf("#tagPrefix%d", i)
+		val := fmt.Sprintf(":tagPrefixFilter%d", i)
+		query.ExpressionAttributeNames[name] = aws.String(k)
+		query.ExpressionAttributeValues[val] = &dynamodb.AttributeValue{
+			S: aws.String(v),
+		}
+		filterParts = append(filterParts, fmt.Sprintf("begins_with(tags.%s, %s)", name, val))
+		i++
+	}
+
 	if len(filterParts) > 0 {
 		query.FilterExpression = aws.String(strings.Join(filterParts, " AND "))
 	}
 
+	// Creation times are strings, which can't be compared in filter
+	// expressions, so the time range is filtered after the query.
+	timeRange := req.CreatedAfter != "" || req.CreatedBefore != ""
+
 	if req.View == tes.TaskView_MINIMAL {
-		query.ExpressionAttributeNames = map[string]*string{
-			"#state": aws.String("state"),
+		query.ExpressionAttributeNames["#state"] = aws.String("state")
+		if timeRange {
+			query.ProjectionExpression = aws.String("id, #state, creation_time")
+		} else {
+			query.ProjectionExpression = aws.String("id, #state")
 		}
-		query.ProjectionExpression = aws.String("id, #state")
+	}
+
+	if len(query.ExpressionAttributeNames) == 0 {
+		query.ExpressionAttributeNames = nil
 	}
 
 	if req.PageToken != "" {
@@ -109,34 +158,62 @@ func (db *DynamoDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*
 		}
 	}
 
-	response, err := db.client.QueryWithContext(ctx, query)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if req.View == tes.TaskView_FULL {
-		for _, item := range response.Items {
-			// TODO handle errors
-			_ = db.getContent(ctx, item)
-			_ = db.getExecutorOutput(ctx, item, "stdout", db.stdoutTable)
-			_ = db.getExecutorOutput(ctx, item, "stderr", db.stderrTable)
-			_ = db.getSystemLogs(ctx, item)
+	// Filter expressions and the time range are applied after the query's
+	// limit, so tasks are read in batches until the page is full.
+	out := tes.ListTasksResponse{}
+	for {
+		response, err := db.client.QueryWithContext(ctx, query)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	err = dynamodbattribute.UnmarshalListOfMaps(response.Items, &tasks)
-	if err != nil {
-		return nil, fmt.Errorf("failed to DynamoDB unmarshal Tasks, %v", err)
-	}
+		for i, item := range response.Items {
+			task := &tes.Task{}
+			err = dynamodbattribute.UnmarshalMap(item, task)
+			if err != nil {
+				return nil, fmt.Errorf("failed to DynamoDB unmarshal Task, %v", err)
+			}
+			if !tes.MatchesCreationTime(task, req) {
+				continue
+			}
 
-	out := tes.ListTasksResponse{
-		Tasks: tasks,
-	}
+			switch req.View {
+			case tes.TaskView_MINIMAL:
+				task = task.GetMinimalView()
+			case tes.TaskView_FULL:
+				// TODO handle errors
+				_ = db.getContent(ctx, item)
+				_ = db.getExecutorOutput(ctx, item, "stdout", db.stdoutTable)
+				_ = db.getExecutorOutput(ctx, item, "stderr", db.stderrTable)
+				_ = db.getSystemLogs(ctx, item)
+				task = &tes.Task{}
+				err = dynamodbattribute.UnmarshalMap(item, task)
+				if err != nil {
+					return nil, fmt.Errorf("failed to DynamoDB unmarshal Task, %v", err)
+				}
+			}
+			out.Tasks = append(out.Tasks, task)
+
+			if int64(len(out.Tasks)) == pageSize {
+				// The next page starts after this task, unless it's the last one.
+				if i < len(response.Items)-1 || response.LastEvaluatedKey != nil {
+					out.NextPageToken = task.Id
+				}
+				return &out, nil
+			}
+		}
 
-	if response.LastEvaluatedKey != nil {
-		out.NextPageToken = *response.LastEvaluatedKey["id"].S
+		if response.LastEvaluatedKey == nil {
+			break
+		}
+		query.ExclusiveStartKey = response.LastEvaluatedKey
 	}
 
 	return &out, nil
 }
+
+// DeleteTask deletes a task and the parts of its full view. Deleting a task
+// which doesn't exist isn't an error.
+func (db *DynamoDB) DeleteTask(ctx context.Context, id string) error {
+	return db.deleteTask(ctx, id)
+}
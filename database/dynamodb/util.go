@@ -23,6 +23,132 @@ func checkCreateErr(err error) error {
 	return err
 }
 
+// queueIndex is a sparse index of the task table, which contains only the
+// queued tasks. Queued tasks have a "queued" attribute, which is removed when
+// the task leaves the queue.
+const queueIndex = "queue-index"
+
+func queueIndexDef() *dynamodb.GlobalSecondaryIndex {
+	return &dynamodb.GlobalSecondaryIndex{
+		IndexName: aws.String(queueIndex),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("queued"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		Projection: &dynamodb.Projection{
+			ProjectionType: aws.String("KEYS_ONLY"),
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+	}
+}
+
+// ensureQueueIndex adds the queue index to a task table created by an earlier
+// version of Funnel, and adds the tasks which are already queued to it.
+func (db *DynamoDB) ensureQueueIndex() error {
+	desc, err := db.client.DescribeTable(&dynamodb.DescribeTableInput{
+		TableName: aws.String(db.taskTable),
+	})
+	if err != nil {
+		return err
+	}
+	for _, idx := range desc.Table.GlobalSecondaryIndexes {
+		if aws.StringValue(idx.IndexName) == queueIndex {
+			return nil
+		}
+	}
+
+	gsi := queueIndexDef()
+	_, err = db.client.UpdateTable(&dynamodb.UpdateTableInput{
+		TableName: aws.String(db.taskTable),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("queued"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+			{
+				Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+					IndexName:             gsi.IndexName,
+					KeySchema:             gsi.KeySchema,
+					Projection:            gsi.Projection,
+					ProvisionedThroughput: gsi.ProvisionedThroughput,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating the queue index: %v", err)
+	}
+
+	// Queued tasks are read once, when the index is created.
+	query := &dynamodb.QueryInput{
+		TableName:              aws.String(db.taskTable),
+		KeyConditionExpression: aws.String(fmt.Sprintf("%s = :v1", db.partitionKey)),
+		FilterExpression:       aws.String("#state = :queued"),
+		ExpressionAttributeNames: map[string]*string{
+			"#state": aws.String("state"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v1": {
+				S: aws.String(db.partitionValue),
+			},
+			":queued": {
+				N: aws.String(strconv.Itoa(int(tes.State_QUEUED))),
+			},
+		},
+		ProjectionExpression: aws.String("id"),
+	}
+	var uerr error
+	err = db.client.QueryPages(query, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			_, uerr = db.client.UpdateItem(&dynamodb.UpdateItemInput{
+				TableName: aws.String(db.taskTable),
+				Key: map[string]*dynamodb.AttributeValue{
+					db.partitionKey: {
+						S: aws.String(db.partitionValue),
+					},
+					"id": item["id"],
+				},
+				UpdateExpression: aws.String("SET queued = :v1"),
+				// The task may have left the queue since it was read.
+				ConditionExpression: aws.String("#state = :queued"),
+				ExpressionAttributeNames: map[string]*string{
+					"#state": aws.String("state"),
+				},
+				ExpressionAttributeValues: query.ExpressionAttributeValues,
+			})
+			if aerr, ok := uerr.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+				uerr = nil
+			}
+			if uerr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = uerr
+	}
+	if err != nil {
+		return fmt.Errorf("adding queued tasks to the queue index: %v", err)
+	}
+	return nil
+}
+
 func (db *DynamoDB) createTables() error {
 	var table *dynamodb.CreateTableInput
 	var err error
@@ -38,6 +164,10 @@ func (db *DynamoDB) createTables() error {
 				AttributeName: aws.String("id"),
 				AttributeType: aws.String("S"),
 			},
+			{
+				AttributeName: aws.String("queued"),
+				AttributeType: aws.String("S"),
+			},
 		},
 		KeySchema: []*dynamodb.KeySchemaElement{
 			{
@@ -49,6 +179,7 @@ func (db *DynamoDB) createTables() error {
 				KeyType:       aws.String("RANGE"),
 			},
 		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{queueIndexDef()},
 		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(1),
 			WriteCapacityUnits: aws.Int64(1),
@@ -58,6 +189,9 @@ func (db *DynamoDB) createTables() error {
 	if checkCreateErr(err) != nil {
 		return err
 	}
+	if err := db.ensureQueueIndex(); err != nil {
+		return err
+	}
 
 	table = &dynamodb.CreateTableInput{
 		TableName: aws.String(db.contentTable),
@@ -186,6 +320,62 @@ func (db *DynamoDB) createTables() error {
 	if checkCreateErr(err) != nil {
 		return err
 	}
+
+	table = &dynamodb.CreateTableInput{
+		TableName: aws.String(db.nodesTable),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+	}
+	_, err = db.client.CreateTable(table)
+	if checkCreateErr(err) != nil {
+		return err
+	}
+
+	table = &dynamodb.CreateTableInput{
+		TableName: aws.String(db.eventsTable),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("seq"),
+				AttributeType: aws.String("N"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("seq"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1),
+			WriteCapacityUnits: aws.Int64(1),
+		},
+	}
+	_, err = db.client.CreateTable(table)
+	if checkCreateErr(err) != nil {
+		return err
+	}
 	return db.waitForTables()
 }
 
@@ -225,6 +415,12 @@ func (db *DynamoDB) waitForTables() error {
 	if err := db.tableIsAlive(ctx, db.syslogsTable); err != nil {
 		return err
 	}
+	if err := db.tableIsAlive(ctx, db.nodesTable); err != nil {
+		return err
+	}
+	if err := db.tableIsAlive(ctx, db.eventsTable); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -244,6 +440,13 @@ func (db *DynamoDB) createTask(ctx context.Context, task *tes.Task) error {
 		S: aws.String(strconv.FormatInt(time.Now().UnixNano(), 10)),
 	}
 
+	// Only queued tasks are in the queue index.
+	if task.State == tes.State_QUEUED {
+		av["queued"] = &dynamodb.AttributeValue{
+			S: aws.String(db.partitionValue),
+		}
+	}
+
 	// Add nil fields to make updates easier
 	av["logs"] = &dynamodb.AttributeValue{
 		L: []*dynamodb.AttributeValue{
@@ -307,10 +510,24 @@ func (db *DynamoDB) createTaskInputContent(ctx context.Context, task *tes.Task)
 }
 
 func (db *DynamoDB) deleteTask(ctx context.Context, id string) error {
-	var item *dynamodb.DeleteItemInput
-	var err error
+	// The parts of the full view are deleted first, so that a failed delete
+	// can be retried.
+	parts := []struct {
+		table, rangeKey string
+	}{
+		{db.contentTable, "index"},
+		{db.stdoutTable, "attempt_index"},
+		{db.stderrTable, "attempt_index"},
+		{db.syslogsTable, "attempt"},
+		{db.eventsTable, "seq"},
+	}
+	for _, p := range parts {
+		if err := db.deleteTaskItems(ctx, id, p.table, p.rangeKey); err != nil {
+			return err
+		}
+	}
 
-	item = &dynamodb.DeleteItemInput{
+	item := &dynamodb.DeleteItemInput{
 		TableName: aws.String(db.taskTable),
 		Key: map[string]*dynamodb.AttributeValue{
 			db.partitionKey: {
@@ -321,15 +538,15 @@ func (db *DynamoDB) deleteTask(ctx context.Context, id string) error {
 			},
 		},
 	}
-	_, err = db.client.DeleteItemWithContext(ctx, item)
-	if err != nil {
-		return err
-	}
+	_, err := db.client.DeleteItemWithContext(ctx, item)
+	return err
+}
 
+// deleteTaskItems deletes the items of the task from a table with the
+// "id" hash key and the given range key.
+func (db *DynamoDB) deleteTaskItems(ctx context.Context, id, table, rangeKey string) error {
 	query := &dynamodb.QueryInput{
-		TableName:              aws.String(db.contentTable),
-		Limit:                  aws.Int64(10),
-		ScanIndexForward:       aws.Bool(false),
+		TableName:              aws.String(table),
 		ConsistentRead:         aws.Bool(true),
 		KeyConditionExpression: aws.String("id = :v1"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
@@ -338,37 +555,36 @@ func (db *DynamoDB) deleteTask(ctx context.Context, id string) error {
 			},
 		},
 		ExpressionAttributeNames: map[string]*string{
-			"#index": aws.String("index"),
+			"#range": aws.String(rangeKey),
 		},
-		ProjectionExpression: aws.String("id, #index"),
+		ProjectionExpression: aws.String("id, #range"),
 	}
 
-	err = db.client.QueryPagesWithContext(
+	var derr error
+	err := db.client.QueryPagesWithContext(
 		ctx,
 		query,
 		func(page *dynamodb.QueryOutput, lastPage bool) bool {
 			for _, res := range page.Items {
-				item = &dynamodb.DeleteItemInput{
-					TableName: aws.String(db.contentTable),
+				item := &dynamodb.DeleteItemInput{
+					TableName: aws.String(table),
 					Key: map[string]*dynamodb.AttributeValue{
-						"id":    res["id"],
-						"index": res["index"],
+						"id":     res["id"],
+						rangeKey: res[rangeKey],
 					},
 				}
-				// TODO handle error
-				db.client.DeleteItem(item)
-			}
-			if page.LastEvaluatedKey == nil {
-				return false
+				_, derr = db.client.DeleteItemWithContext(ctx, item)
+				if derr != nil {
+					return false
+				}
 			}
-			return true
+			return !lastPage
 		})
 
 	if err != nil {
 		return err
 	}
-
-	return nil
+	return derr
 }
 
 func (db *DynamoDB) getMinimalView(ctx context.Context, id string) (*dynamodb.GetItemOutput, error) {
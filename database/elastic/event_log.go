@@ -0,0 +1,100 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/ohsu-comp-bio/funnel/events"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// loggedEvent is a document of the events index.
+type loggedEvent struct {
+	TaskID string `json:"taskId"`
+	// Unix time in nanoseconds at which the event was logged,
+	// which orders the events of a task.
+	Seq   int64           `json:"seq"`
+	Event json.RawMessage `json:"event"`
+}
+
+// logEvent appends the event to the task's event log.
+func (es *Elastic) logEvent(ctx context.Context, ev *events.Event) error {
+	mar := jsonpb.Marshaler{}
+	s, err := mar.MarshalToString(ev)
+	if err != nil {
+		return err
+	}
+
+	idx := es.client.Index().
+		Index(es.eventIndex).
+		Type("event").
+		BodyJson(&loggedEvent{TaskID: ev.Id, Seq: time.Now().UnixNano(), Event: json.RawMessage(s)})
+	if ev.IdempotencyKey != "" {
+		// An event replayed from a worker's event spool is logged once.
+		idx = idx.Id(ev.IdempotencyKey).OpType("create")
+	}
+	_, err = idx.Do(ctx)
+	if elastic.IsConflict(err) {
+		return nil
+	}
+	return err
+}
+
+// ListTaskEvents returns the logged events of the task, in the order
+// they were written.
+func (es *Elastic) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	// Make the recently logged events searchable.
+	if _, err := es.client.Refresh(es.eventIndex).Do(ctx); err != nil {
+		return nil, err
+	}
+
+	const pageSize = 1000
+	var evs []*events.Event
+	var after []interface{}
+	for {
+		q := es.client.Search().
+			Index(es.eventIndex).
+			Type("event").
+			Query(elastic.NewTermQuery("taskId", id)).
+			Sort("seq", true).
+			Size(pageSize)
+		if after != nil {
+			q = q.SearchAfter(after...)
+		}
+
+		res, err := q.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hit := range res.Hits.Hits {
+			doc := loggedEvent{}
+			if err := json.Unmarshal(*hit.Source, &doc); err != nil {
+				return nil, err
+			}
+			ev := &events.Event{}
+			if err := jsonpb.Unmarshal(bytes.NewReader(doc.Event), ev); err != nil {
+				return nil, err
+			}
+			evs = append(evs, ev)
+			after = hit.Sort
+		}
+
+		if len(res.Hits.Hits) < pageSize {
+			return evs, nil
+		}
+	}
+}
+
+// deleteTaskEvents deletes the task's event log.
+func (es *Elastic) deleteTaskEvents(ctx context.Context, id string) error {
+	_, err := es.client.DeleteByQuery(es.eventIndex).
+		Type("event").
+		Query(elastic.NewTermQuery("taskId", id)).
+		Refresh("true").
+		Do(ctx)
+	return err
+}
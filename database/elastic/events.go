@@ -28,7 +28,10 @@ if (params.field == "system_logs") {
   if (ctx._source.logs[params.attempt].system_logs == null) {
     ctx._source.logs[params.attempt].system_logs = new ArrayList();
   }
-  ctx._source.logs[params.attempt].system_logs.add(params.value)
+  // A system log replayed from a worker's event spool is added once.
+  if (!ctx._source.logs[params.attempt].system_logs.contains(params.value)) {
+    ctx._source.logs[params.attempt].system_logs.add(params.value)
+  }
 } else if (params.field == "metadata") {
   if (ctx._source.logs[params.attempt].metadata == null) {
     ctx._source.logs[params.attempt].metadata = new HashMap();
@@ -82,6 +85,16 @@ func execLogUpdate(attempt, index uint32, field string, value interface{}) *elas
 
 // WriteEvent writes a task update event.
 func (es *Elastic) WriteEvent(ctx context.Context, ev *events.Event) error {
+	if err := es.writeEvent(ctx, ev); err != nil {
+		return err
+	}
+	if events.Logged(ev) {
+		return es.logEvent(ctx, ev)
+	}
+	return nil
+}
+
+func (es *Elastic) writeEvent(ctx context.Context, ev *events.Event) error {
 	u := es.client.Update().
 		Index(es.taskIndex).
 		Type("task").
@@ -14,10 +14,11 @@ var basic = elastic.NewFetchSourceContext(true).
 
 // Elastic provides an elasticsearch database server backend.
 type Elastic struct {
-	client    *elastic.Client
-	conf      config.Elastic
-	taskIndex string
-	nodeIndex string
+	client     *elastic.Client
+	conf       config.Elastic
+	taskIndex  string
+	nodeIndex  string
+	eventIndex string
 }
 
 // NewElastic returns a new Elastic instance.
@@ -39,6 +40,7 @@ func NewElastic(conf config.Elastic) (*Elastic, error) {
 		conf,
 		conf.IndexPrefix + "-tasks",
 		conf.IndexPrefix + "-nodes",
+		conf.IndexPrefix + "-events",
 	}
 	return es, nil
 }
@@ -77,6 +79,9 @@ func (es *Elastic) Init() error {
           "state": {
             "type": "keyword"
           },
+          "creationTime": {
+            "type": "date"
+          },
           "inputs": {
             "type": "nested"
           },
@@ -98,5 +103,27 @@ func (es *Elastic) Init() error {
 	if err := es.initIndex(ctx, es.nodeIndex, ""); err != nil {
 		return err
 	}
+	// Events are stored, but not indexed, as JSON.
+	eventMappings := `{
+    "mappings": {
+      "event":{
+        "properties":{
+          "taskId": {
+            "type": "keyword"
+          },
+          "seq": {
+            "type": "long"
+          },
+          "event": {
+            "type": "object",
+            "enabled": false
+          }
+        }
+      }
+    }
+  }`
+	if err := es.initIndex(ctx, es.eventIndex, eventMappings); err != nil {
+		return err
+	}
 	return nil
 }
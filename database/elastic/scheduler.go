@@ -13,11 +13,16 @@ import (
 	elastic "gopkg.in/olivere/elastic.v5"
 )
 
-// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned.
-func (es *Elastic) ReadQueue(n int) []*tes.Task {
+// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned,
+// oldest first, starting after the task with ID "after" (if not empty),
+// with the ID of the last queued task scanned (see scheduler.TaskQueue).
+func (es *Elastic) ReadQueue(n int, after string) ([]*tes.Task, string) {
 	ctx := context.Background()
 
-	q := elastic.NewTermQuery("state", tes.State_QUEUED.String())
+	q := elastic.NewBoolQuery().Filter(elastic.NewTermQuery("state", tes.State_QUEUED.String()))
+	if after != "" {
+		q = q.Filter(elastic.NewRangeQuery("id").Gt(after))
+	}
 	res, err := es.client.Search().
 		Index(es.taskIndex).
 		Type("task").
@@ -27,11 +32,13 @@ func (es *Elastic) ReadQueue(n int) []*tes.Task {
 		Do(ctx)
 	if err != nil {
 		fmt.Println(err)
-		return nil
+		return nil, ""
 	}
 
 	var tasks []*tes.Task
+	last := ""
 	for _, hit := range res.Hits.Hits {
+		last = hit.Id
 		t := &tes.Task{}
 		err := jsonpb.Unmarshal(bytes.NewReader(*hit.Source), t)
 		if err != nil {
@@ -42,7 +49,7 @@ func (es *Elastic) ReadQueue(n int) []*tes.Task {
 		tasks = append(tasks, t)
 	}
 
-	return tasks
+	return tasks, last
 }
 
 // GetNode gets a node
@@ -0,0 +1,233 @@
+package elastic
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	elastic "gopkg.in/olivere/elastic.v5"
+)
+
+// taskDuration computes the run time or the queue time of a task, in seconds.
+// Task log times are written by update scripts, so both the camel case and
+// snake case names are read. Tasks without the times don't have a duration.
+var taskDuration = `
+def logs = params._source.logs;
+if (logs == null || logs.isEmpty()) {
+  return null;
+}
+
+def start;
+def end;
+if (params.duration == "run") {
+  def log = logs[logs.length - 1];
+  start = log.startTime != null ? log.startTime : log.start_time;
+  end = log.endTime != null ? log.endTime : log.end_time;
+} else {
+  def log = logs[0];
+  start = params._source.creationTime;
+  end = log.startTime != null ? log.startTime : log.start_time;
+}
+if (start == null || end == null) {
+  return null;
+}
+
+try {
+  return Duration.between(OffsetDateTime.parse(start), OffsetDateTime.parse(end)).toNanos() / 1e9;
+} catch (Exception e) {
+  return null;
+}
+`
+
+// statsGroupsSize is the maximum number of groups of each terms aggregation.
+const statsGroupsSize = 10000
+
+// statsLevel is a level of the nested aggregations which group the tasks.
+type statsLevel struct {
+	field string
+	// tag is the tag key, if the level groups the tasks by a tag.
+	tag string
+	// interval is the date histogram interval, if the level groups the tasks
+	// by creation time.
+	interval string
+}
+
+// GetTaskStats computes task stats with Elasticsearch aggregations.
+// Tasks are grouped by terms and date histogram aggregations, with a missing
+// aggregation for the tasks which don't have the field. Percentiles are
+// computed by percentiles aggregations, so they are approximate.
+func (es *Elastic) GetTaskStats(ctx context.Context, req *tes.GetTaskStatsRequest) (*tes.GetTaskStatsResponse, error) {
+	filterParts, err := listFilters(tes.StatsFilter(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var levels []statsLevel
+	switch req.GroupByTime {
+	case tes.TimeBucket_HOUR:
+		levels = append(levels, statsLevel{field: "creationTime", interval: "hour"})
+	case tes.TimeBucket_DAY:
+		levels = append(levels, statsLevel{field: "creationTime", interval: "day"})
+	case tes.TimeBucket_WEEK:
+		// Weeks start on Monday.
+		levels = append(levels, statsLevel{field: "creationTime", interval: "week"})
+	}
+	if req.GroupByState {
+		levels = append(levels, statsLevel{field: "state"})
+	}
+	for _, k := range req.GroupByTags {
+		levels = append(levels, statsLevel{field: fmt.Sprintf("tags.%s.keyword", k), tag: k})
+	}
+	percentiles := tes.StatsPercentiles(req)
+
+	s := es.client.Search(es.taskIndex).
+		Type("task").
+		Size(0)
+	if len(filterParts) > 0 {
+		s = s.Query(elastic.NewBoolQuery().Filter(filterParts...))
+	}
+	for name, agg := range statsAggs(levels, percentiles) {
+		s = s.Aggregation(name, agg)
+	}
+
+	res, err := s.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*tes.TaskStatsGroup
+	addStatsGroups(&groups, res.Aggregations, res.Hits.TotalHits, &tes.TaskStatsGroup{}, levels, percentiles)
+	return tes.NewTaskStatsResponse(req, groups), nil
+}
+
+// statsAggs returns the aggregations which group the tasks by the levels,
+// and compute the stats of each group.
+func statsAggs(levels []statsLevel, percentiles []float64) map[string]elastic.Aggregation {
+	if len(levels) == 0 {
+		aggs := map[string]elastic.Aggregation{}
+		for _, d := range []string{"run", "queue"} {
+			script := elastic.NewScript(taskDuration).
+				Lang("painless").
+				Param("duration", d)
+			aggs[d] = elastic.NewStatsAggregation().Script(script)
+			aggs[d+"_percentiles"] = elastic.NewPercentilesAggregation().
+				Script(script).
+				Percentiles(percentiles...)
+		}
+		return aggs
+	}
+
+	l := levels[0]
+	sub := statsAggs(levels[1:], percentiles)
+
+	missing := elastic.NewMissingAggregation().Field(l.field)
+	for name, agg := range sub {
+		missing = missing.SubAggregation(name, agg)
+	}
+	aggs := map[string]elastic.Aggregation{"missing": missing}
+
+	if l.interval != "" {
+		group := elastic.NewDateHistogramAggregation().
+			Field(l.field).
+			Interval(l.interval).
+			MinDocCount(1)
+		for name, agg := range sub {
+			group = group.SubAggregation(name, agg)
+		}
+		aggs["group"] = group
+	} else {
+		group := elastic.NewTermsAggregation().
+			Field(l.field).
+			Size(statsGroupsSize)
+		for name, agg := range sub {
+			group = group.SubAggregation(name, agg)
+		}
+		aggs["group"] = group
+	}
+	return aggs
+}
+
+// addStatsGroups adds the groups of the aggregations' buckets to "groups".
+// "g" holds the group fields of the enclosing buckets.
+func addStatsGroups(groups *[]*tes.TaskStatsGroup, aggs elastic.Aggregations, count int64, g *tes.TaskStatsGroup, levels []statsLevel, percentiles []float64) {
+	if count == 0 {
+		return
+	}
+	if len(levels) == 0 {
+		g.Count = count
+		g.RunTime = durationStats(aggs, "run", percentiles)
+		g.QueueTime = durationStats(aggs, "queue", percentiles)
+		*groups = append(*groups, g)
+		return
+	}
+
+	l := levels[0]
+	if m, ok := aggs.Missing("missing"); ok {
+		addStatsGroups(groups, m.Aggregations, m.DocCount, proto.Clone(g).(*tes.TaskStatsGroup), levels[1:], percentiles)
+	}
+
+	if l.interval != "" {
+		h, ok := aggs.DateHistogram("group")
+		if !ok {
+			return
+		}
+		for _, b := range h.Buckets {
+			bg := proto.Clone(g).(*tes.TaskStatsGroup)
+			bg.TimeBucket = time.Unix(0, int64(b.Key)*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+			addStatsGroups(groups, b.Aggregations, b.DocCount, bg, levels[1:], percentiles)
+		}
+		return
+	}
+
+	t, ok := aggs.Terms("group")
+	if !ok {
+		return
+	}
+	for _, b := range t.Buckets {
+		bg := proto.Clone(g).(*tes.TaskStatsGroup)
+		key := fmt.Sprint(b.Key)
+		if l.tag != "" {
+			if bg.Tags == nil {
+				bg.Tags = map[string]string{}
+			}
+			bg.Tags[l.tag] = key
+		} else {
+			bg.State = tes.State(tes.State_value[key])
+		}
+		addStatsGroups(groups, b.Aggregations, b.DocCount, bg, levels[1:], percentiles)
+	}
+}
+
+// durationStats returns the duration stats of the named aggregations.
+func durationStats(aggs elastic.Aggregations, name string, percentiles []float64) *tes.DurationStats {
+	st, ok := aggs.Stats(name)
+	if !ok || st.Count == 0 || st.Avg == nil || st.Min == nil || st.Max == nil {
+		return &tes.DurationStats{}
+	}
+	ds := &tes.DurationStats{
+		Count: st.Count,
+		Mean:  *st.Avg,
+		Min:   *st.Min,
+		Max:   *st.Max,
+	}
+
+	// The percentiles are keyed by their string representation in Java,
+	// e.g. "50.0", so the keys are compared as numbers.
+	pct, _ := aggs.Percentiles(name + "_percentiles")
+	for _, p := range percentiles {
+		var v float64
+		if pct != nil {
+			for k, kv := range pct.Values {
+				if f, err := strconv.ParseFloat(k, 64); err == nil && f == p {
+					v = kv
+					break
+				}
+			}
+		}
+		ds.Percentiles = append(ds.Percentiles, v)
+	}
+	return ds
+}
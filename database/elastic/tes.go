@@ -41,6 +41,10 @@ func (es *Elastic) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.T
 	return task, err
 }
 
+// esTimeFormat is the format of times in queries. Elasticsearch dates
+// have millisecond precision.
+const esTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
 // ListTasks lists tasks, duh.
 func (es *Elastic) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
 
@@ -53,13 +57,9 @@ func (es *Elastic) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*t
 		q = q.SearchAfter(req.PageToken)
 	}
 
-	filterParts := []elastic.Query{}
-	if req.State != tes.Unknown {
-		filterParts = append(filterParts, elastic.NewTermQuery("state", req.State.String()))
-	}
-
-	for k, v := range req.Tags {
-		filterParts = append(filterParts, elastic.NewMatchQuery(fmt.Sprintf("tags.%s.keyword", k), v))
+	filterParts, err := listFilters(req)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(filterParts) > 0 {
@@ -97,3 +97,69 @@ func (es *Elastic) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*t
 
 	return resp, nil
 }
+
+// listFilters returns the queries of the ListTasks request's filters.
+func listFilters(req *tes.ListTasksRequest) ([]elastic.Query, error) {
+	filterParts := []elastic.Query{}
+	if req.State != tes.Unknown {
+		filterParts = append(filterParts, elastic.NewTermQuery("state", req.State.String()))
+	}
+
+	if len(req.States) > 0 {
+		var states []interface{}
+		for _, s := range req.States {
+			states = append(states, s.String())
+		}
+		filterParts = append(filterParts, elastic.NewTermsQuery("state", states...))
+	}
+
+	if req.NamePrefix != "" {
+		filterParts = append(filterParts, elastic.NewPrefixQuery("name.keyword", req.NamePrefix))
+	}
+
+	after, before, err := tes.CreationTimeRange(req)
+	if err != nil {
+		return nil, err
+	}
+	if !after.IsZero() || !before.IsZero() {
+		r := elastic.NewRangeQuery("creationTime")
+		if !after.IsZero() {
+			r = r.Gte(after.UTC().Format(esTimeFormat))
+		}
+		if !before.IsZero() {
+			r = r.Lt(before.UTC().Format(esTimeFormat))
+		}
+		filterParts = append(filterParts, r)
+	}
+
+	for k, v := range req.Tags {
+		filterParts = append(filterParts, elastic.NewMatchQuery(fmt.Sprintf("tags.%s.keyword", k), v))
+	}
+
+	for _, k := range req.TagKeys {
+		filterParts = append(filterParts, elastic.NewExistsQuery(fmt.Sprintf("tags.%s", k)))
+	}
+
+	for k, v := range req.TagPrefixes {
+		filterParts = append(filterParts, elastic.NewPrefixQuery(fmt.Sprintf("tags.%s.keyword", k), v))
+	}
+
+	return filterParts, nil
+}
+
+// DeleteTask deletes a task. Deleting a task which doesn't exist isn't an error.
+func (es *Elastic) DeleteTask(ctx context.Context, id string) error {
+	if err := es.deleteTaskEvents(ctx, id); err != nil {
+		return err
+	}
+	_, err := es.client.Delete().
+		Index(es.taskIndex).
+		Type("task").
+		Id(id).
+		Refresh("true").
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
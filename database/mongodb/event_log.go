@@ -0,0 +1,55 @@
+package mongodb
+
+import (
+	"context"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// loggedEvent is a document of the events collection. Object IDs increase
+// with time, so the events of a task are sorted by ID.
+type loggedEvent struct {
+	ID     bson.ObjectId `bson:"_id"`
+	TaskID string        `bson:"taskid"`
+	// Idempotency key of the event, if it has one.
+	Key string `bson:"key,omitempty"`
+	// Event protobuf message.
+	Data []byte `bson:"data"`
+}
+
+// logEvent appends the event to the task's event log.
+func (db *MongoDB) logEvent(ev *events.Event) error {
+	data, err := proto.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	doc := &loggedEvent{ID: bson.NewObjectId(), TaskID: ev.Id, Key: ev.IdempotencyKey, Data: data}
+	if ev.IdempotencyKey == "" {
+		return db.events.Insert(doc)
+	}
+	// An event replayed from a worker's event spool is logged once.
+	_, err = db.events.Upsert(bson.M{"taskid": ev.Id, "key": ev.IdempotencyKey}, bson.M{"$setOnInsert": doc})
+	return err
+}
+
+// ListTaskEvents returns the logged events of the task, in the order
+// they were written.
+func (db *MongoDB) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	var docs []loggedEvent
+	err := db.events.Find(bson.M{"taskid": id}).Sort("_id").All(&docs)
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]*events.Event, 0, len(docs))
+	for _, doc := range docs {
+		ev := &events.Event{}
+		if err := proto.Unmarshal(doc.Data, ev); err != nil {
+			return nil, err
+		}
+		evs = append(evs, ev)
+	}
+	return evs, nil
+}
@@ -14,6 +14,16 @@ import (
 
 // WriteEvent creates an event for the server to handle.
 func (db *MongoDB) WriteEvent(ctx context.Context, req *events.Event) error {
+	if err := db.writeEvent(ctx, req); err != nil {
+		return err
+	}
+	if events.Logged(req) {
+		return db.logEvent(req)
+	}
+	return nil
+}
+
+func (db *MongoDB) writeEvent(ctx context.Context, req *events.Event) error {
 	update := bson.M{}
 	selector := bson.M{"id": req.Id}
 
@@ -125,12 +135,17 @@ func (db *MongoDB) WriteEvent(ctx context.Context, req *events.Event) error {
 		}
 
 	case events.Type_SYSTEM_LOG:
+		// A system log replayed from a worker's event spool is added once.
 		update = bson.M{
-			"$push": bson.M{
+			"$addToSet": bson.M{
 				fmt.Sprintf("logs.%v.systemlogs", req.Attempt): req.SysLogString(),
 			},
 		}
 	}
 
-	return db.tasks.Update(selector, update)
+	err := db.tasks.Update(selector, update)
+	if err == mgo.ErrNotFound {
+		return tes.ErrNotFound
+	}
+	return err
 }
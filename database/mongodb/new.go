@@ -10,11 +10,11 @@ import (
 
 // MongoDB provides an MongoDB database server backend.
 type MongoDB struct {
-	sess  *mgo.Session
-	conf  config.MongoDB
-	tasks *mgo.Collection
-	nodes *mgo.Collection
-	// events  *mgo.Collection
+	sess   *mgo.Session
+	conf   config.MongoDB
+	tasks  *mgo.Collection
+	nodes  *mgo.Collection
+	events *mgo.Collection
 }
 
 // NewMongoDB returns a new MongoDB instance.
@@ -33,10 +33,11 @@ func NewMongoDB(conf config.MongoDB) (*MongoDB, error) {
 		return nil, err
 	}
 	db := &MongoDB{
-		sess:  sess,
-		conf:  conf,
-		tasks: sess.DB(conf.Database).C("tasks"),
-		nodes: sess.DB(conf.Database).C("nodes"),
+		sess:   sess,
+		conf:   conf,
+		tasks:  sess.DB(conf.Database).C("tasks"),
+		nodes:  sess.DB(conf.Database).C("nodes"),
+		events: sess.DB(conf.Database).C("events"),
 	}
 	return db, nil
 }
@@ -49,12 +50,15 @@ func (db *MongoDB) Init() error {
 	}
 	var tasksFound bool
 	var nodesFound bool
+	var eventsFound bool
 	for _, n := range names {
 		switch n {
 		case "tasks":
 			tasksFound = true
 		case "nodes":
 			nodesFound = true
+		case "events":
+			eventsFound = true
 		}
 	}
 
@@ -76,6 +80,18 @@ func (db *MongoDB) Init() error {
 		}
 	}
 
+	// Indexes used by ListTasks filters. These are also added to existing
+	// collections.
+	for _, key := range [][]string{{"state", "-id"}, {"name"}} {
+		err = db.tasks.EnsureIndex(mgo.Index{
+			Key:        key,
+			Background: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	if !nodesFound {
 		err = db.nodes.Create(&mgo.CollectionInfo{})
 		if err != nil {
@@ -94,6 +110,31 @@ func (db *MongoDB) Init() error {
 		}
 	}
 
+	if !eventsFound {
+		err = db.events.Create(&mgo.CollectionInfo{})
+		if err != nil {
+			return fmt.Errorf("error creating events collection in database %s: %v", db.conf.Database, err)
+		}
+
+		err = db.events.EnsureIndex(mgo.Index{
+			Key:        []string{"taskid", "_id"},
+			Background: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Logged events are looked up by idempotency key. The index is ensured
+	// for existing events collections too.
+	err = db.events.EnsureIndex(mgo.Index{
+		Key:        []string{"taskid", "key"},
+		Background: true,
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
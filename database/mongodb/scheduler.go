@@ -12,15 +12,22 @@ import (
 	"gopkg.in/mgo.v2/bson"
 )
 
-// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned.
-func (db *MongoDB) ReadQueue(n int) []*tes.Task {
+// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned,
+// oldest first, starting after the task with ID "after" (if not empty),
+// with the ID of the last queued task scanned (see scheduler.TaskQueue).
+func (db *MongoDB) ReadQueue(n int, after string) ([]*tes.Task, string) {
 	var tasks []*tes.Task
-	err := db.tasks.Find(bson.M{"state": tes.State_QUEUED}).Sort("creationtime").Select(basicView).Limit(n).All(&tasks)
+	// Task IDs sort by creation time, so the oldest tasks come first.
+	q := bson.M{"state": tes.State_QUEUED, "id": bson.M{"$gt": after}}
+	err := db.tasks.Find(q).Sort("id").Select(basicView).Limit(n).All(&tasks)
 	if err != nil {
 		fmt.Println(err)
-		return nil
+		return nil, ""
 	}
-	return tasks
+	if len(tasks) == 0 {
+		return nil, ""
+	}
+	return tasks, tasks[len(tasks)-1].Id
 }
 
 // PutNode is an RPC endpoint that is used by nodes to send heartbeats
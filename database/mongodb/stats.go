@@ -0,0 +1,267 @@
+package mongodb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// epoch is the Unix epoch. Subtracting it from a date gives milliseconds.
+var epoch = time.Unix(0, 0).UTC()
+
+// GetTaskStats computes task stats with an aggregation pipeline. Times are
+// stored as strings, so they are parsed by the pipeline, to the millisecond.
+// MongoDB doesn't compute percentiles, so the durations of each group are
+// sorted, and the percentiles are picked by their nearest rank.
+func (db *MongoDB) GetTaskStats(ctx context.Context, req *tes.GetTaskStatsRequest) (*tes.GetTaskStatsResponse, error) {
+	filter := tes.StatsFilter(req)
+
+	tags := []interface{}{}
+	for _, k := range req.GroupByTags {
+		tags = append(tags, fmt.Sprintf("$tags.%s", k))
+	}
+
+	fields := bson.M{
+		"state":   1,
+		"created": parseTime("$creationtime"),
+		"first":   bson.M{"$arrayElemAt": []interface{}{"$logs", 0}},
+		"last":    bson.M{"$arrayElemAt": []interface{}{"$logs", -1}},
+	}
+	if len(tags) > 0 {
+		fields["tags"] = tags
+	}
+	pipeline := []bson.M{
+		{"$match": listQuery(filter)},
+		{"$project": fields},
+	}
+
+	after, before, err := tes.CreationTimeRange(filter)
+	if err != nil {
+		return nil, err
+	}
+	created := bson.M{}
+	if !after.IsZero() {
+		created["$gte"] = after
+	}
+	if !before.IsZero() {
+		created["$lt"] = before
+	}
+	if len(created) > 0 {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"created": created}})
+	}
+
+	durations := bson.M{
+		"state": 1,
+		"tags":  1,
+		"run": secondsBetween(
+			parseTime("$last.starttime"),
+			parseTime("$last.endtime"),
+		),
+		"queue": secondsBetween(
+			"$created",
+			parseTime("$first.starttime"),
+		),
+	}
+	if size, offset := tes.TimeBucketSize(req.GroupByTime); size != 0 {
+		durations["bucket"] = timeBucketStart("$created", size, offset)
+	}
+	pipeline = append(pipeline, bson.M{"$project": durations})
+
+	// The group of a task. Missing tags are null, so they are distinct
+	// from empty tags.
+	id := bson.M{}
+	if len(tags) > 0 {
+		id["tags"] = "$tags"
+	}
+	if req.GroupByState {
+		id["state"] = "$state"
+	}
+	if req.GroupByTime != tes.TimeBucket_ALL_TIME {
+		id["bucket"] = "$bucket"
+	}
+
+	percentiles := tes.StatsPercentiles(req)
+	pipeline = append(pipeline, bson.M{"$facet": bson.M{
+		"count": []bson.M{
+			{"$group": bson.M{"_id": id, "count": bson.M{"$sum": 1}}},
+		},
+		"run":   durationStats("run", id, percentiles),
+		"queue": durationStats("queue", id, percentiles),
+	}})
+
+	var res statsResult
+	err = db.tasks.Pipe(pipeline).AllowDiskUse().One(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	run := map[string]*tes.DurationStats{}
+	for _, r := range res.Run {
+		run[r.ID.key()] = r.durationStats()
+	}
+	queue := map[string]*tes.DurationStats{}
+	for _, r := range res.Queue {
+		queue[r.ID.key()] = r.durationStats()
+	}
+
+	var groups []*tes.TaskStatsGroup
+	for _, r := range res.Count {
+		g := &tes.TaskStatsGroup{
+			State:     r.ID.State,
+			Count:     r.Count,
+			RunTime:   &tes.DurationStats{},
+			QueueTime: &tes.DurationStats{},
+		}
+		if r.ID.Bucket != nil {
+			g.TimeBucket = r.ID.Bucket.UTC().Format(time.RFC3339)
+		}
+		for i, v := range r.ID.Tags {
+			if v != nil {
+				if g.Tags == nil {
+					g.Tags = map[string]string{}
+				}
+				g.Tags[req.GroupByTags[i]] = *v
+			}
+		}
+		if ds, ok := run[r.ID.key()]; ok {
+			g.RunTime = ds
+		}
+		if ds, ok := queue[r.ID.key()]; ok {
+			g.QueueTime = ds
+		}
+		groups = append(groups, g)
+	}
+	return tes.NewTaskStatsResponse(req, groups), nil
+}
+
+type statsResult struct {
+	Count []statsGroup `bson:"count"`
+	Run   []statsGroup `bson:"run"`
+	Queue []statsGroup `bson:"queue"`
+}
+
+type statsGroup struct {
+	ID          statsID   `bson:"_id"`
+	Count       int64     `bson:"count"`
+	Mean        float64   `bson:"mean"`
+	Min         float64   `bson:"min"`
+	Max         float64   `bson:"max"`
+	Percentiles []float64 `bson:"percentiles"`
+}
+
+type statsID struct {
+	State  tes.State  `bson:"state"`
+	Bucket *time.Time `bson:"bucket"`
+	Tags   []*string  `bson:"tags"`
+}
+
+// key distinguishes the groups of the facets.
+func (id statsID) key() string {
+	key := fmt.Sprintf("%d", id.State)
+	if id.Bucket != nil {
+		key += fmt.Sprintf(" %d", id.Bucket.UnixNano())
+	} else {
+		key += " -"
+	}
+	for _, v := range id.Tags {
+		if v != nil {
+			key += fmt.Sprintf(" %q", *v)
+		} else {
+			key += " -"
+		}
+	}
+	return key
+}
+
+func (g statsGroup) durationStats() *tes.DurationStats {
+	return &tes.DurationStats{
+		Count:       g.Count,
+		Mean:        g.Mean,
+		Min:         g.Min,
+		Max:         g.Max,
+		Percentiles: g.Percentiles,
+	}
+}
+
+// parseTime returns the date of an RFC 3339 time string,
+// or null if the string is empty or missing.
+func parseTime(field interface{}) bson.M {
+	return bson.M{"$cond": []interface{}{
+		bson.M{"$gt": []interface{}{field, ""}},
+		bson.M{"$dateFromString": bson.M{"dateString": field}},
+		nil,
+	}}
+}
+
+// secondsBetween returns the seconds between the dates,
+// or null if either is null.
+func secondsBetween(start, end interface{}) bson.M {
+	return bson.M{"$divide": []interface{}{
+		bson.M{"$subtract": []interface{}{end, start}},
+		1000,
+	}}
+}
+
+// timeBucketStart returns the start of the time bucket which includes
+// the date, like tes.TimeBucketStart.
+func timeBucketStart(date interface{}, size, offset time.Duration) bson.M {
+	ms := bson.M{"$subtract": []interface{}{
+		bson.M{"$subtract": []interface{}{date, epoch}},
+		int64(offset / time.Millisecond),
+	}}
+	s := int64(size / time.Millisecond)
+	// The remainder is negative for dates before the epoch.
+	r := bson.M{"$mod": []interface{}{
+		bson.M{"$add": []interface{}{bson.M{"$mod": []interface{}{ms, s}}, s}},
+		s,
+	}}
+	return bson.M{"$subtract": []interface{}{date, r}}
+}
+
+// durationStats returns the facet which computes the stats of the durations
+// of each group. The durations are sorted before they are grouped, so the
+// percentiles are picked from the sorted values.
+func durationStats(field string, id bson.M, percentiles []float64) []bson.M {
+	ref := "$" + field
+	n := bson.M{"$size": "$values"}
+
+	var ps []interface{}
+	for _, p := range percentiles {
+		// The nearest rank, from 1 to n, like tes.PercentileRank.
+		rank := bson.M{"$ceil": bson.M{"$divide": []interface{}{
+			bson.M{"$multiply": []interface{}{p, n}},
+			100,
+		}}}
+		index := bson.M{"$max": []interface{}{
+			0,
+			bson.M{"$min": []interface{}{
+				bson.M{"$subtract": []interface{}{n, 1}},
+				bson.M{"$subtract": []interface{}{rank, 1}},
+			}},
+		}}
+		ps = append(ps, bson.M{"$arrayElemAt": []interface{}{"$values", index}})
+	}
+
+	return []bson.M{
+		{"$match": bson.M{field: bson.M{"$ne": nil}}},
+		{"$sort": bson.M{field: 1}},
+		{"$group": bson.M{
+			"_id":    id,
+			"count":  bson.M{"$sum": 1},
+			"mean":   bson.M{"$avg": ref},
+			"min":    bson.M{"$min": ref},
+			"max":    bson.M{"$max": ref},
+			"values": bson.M{"$push": ref},
+		}},
+		{"$project": bson.M{
+			"count":       1,
+			"mean":        1,
+			"min":         1,
+			"max":         1,
+			"percentiles": ps,
+		}},
+	}
+}
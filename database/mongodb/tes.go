@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/ohsu-comp-bio/funnel/tes"
 	"golang.org/x/net/context"
@@ -16,6 +17,7 @@ var basicView = bson.M{
 	"inputs.content":   0,
 }
 var minimalView = bson.M{"id": 1, "state": 1}
+var minimalTimeView = bson.M{"id": 1, "state": 1, "creationtime": 1}
 
 // GetTask gets a task, which describes a running task
 func (db *MongoDB) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.Task, error) {
@@ -45,43 +47,113 @@ func (db *MongoDB) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.T
 func (db *MongoDB) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
 	pageSize := tes.GetPageSize(req.GetPageSize())
 
-	var query = bson.M{}
-	var q *mgo.Query
-	var err error
-	if req.PageToken != "" {
-		query["id"] = bson.M{"$lt": req.PageToken}
+	query := listQuery(req)
+
+	// Creation times are strings, which can't be compared in queries,
+	// so the tasks are filtered by creation time after the query,
+	// and read in batches until the page is full.
+	filterTime := req.CreatedAfter != "" || req.CreatedBefore != ""
+
+	out := tes.ListTasksResponse{}
+	last := req.PageToken
+	for {
+		if last != "" {
+			query["id"] = bson.M{"$lt": last}
+		}
+		q := db.tasks.Find(query).Sort("-creationtime").Limit(pageSize)
+
+		switch req.View {
+		case tes.TaskView_BASIC:
+			q = q.Select(basicView)
+		case tes.TaskView_MINIMAL:
+			if filterTime {
+				q = q.Select(minimalTimeView)
+			} else {
+				q = q.Select(minimalView)
+			}
+		}
+
+		var tasks []*tes.Task
+		err := q.All(&tasks)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, task := range tasks {
+			last = task.Id
+			if !tes.MatchesCreationTime(task, req) {
+				continue
+			}
+			if filterTime && req.View == tes.TaskView_MINIMAL {
+				task = task.GetMinimalView()
+			}
+			out.Tasks = append(out.Tasks, task)
+
+			if len(out.Tasks) == pageSize {
+				// The next page starts after this task, unless it's the last one.
+				if i < len(tasks)-1 || len(tasks) == pageSize {
+					out.NextPageToken = last
+				}
+				return &out, nil
+			}
+		}
+
+		if len(tasks) < pageSize {
+			return &out, nil
+		}
 	}
+}
+
+// listQuery returns the query of the ListTasks request's filters,
+// except the creation time range.
+func listQuery(req *tes.ListTasksRequest) bson.M {
+	query := bson.M{}
 
+	state := bson.M{}
 	if req.State != tes.Unknown {
-		query["state"] = bson.M{"$eq": req.State}
+		state["$eq"] = req.State
 	}
-
-	for k, v := range req.Tags {
-		query[fmt.Sprintf("tags.%s", k)] = bson.M{"$eq": v}
+	if len(req.States) > 0 {
+		state["$in"] = req.States
 	}
-
-	q = db.tasks.Find(query).Sort("-creationtime").Limit(pageSize)
-
-	switch req.View {
-	case tes.TaskView_BASIC:
-		q = q.Select(basicView)
-	case tes.TaskView_MINIMAL:
-		q = q.Select(minimalView)
+	if len(state) > 0 {
+		query["state"] = state
 	}
 
-	var tasks []*tes.Task
-	err = q.All(&tasks)
-	if err != nil {
-		return nil, err
+	if req.NamePrefix != "" {
+		query["name"] = bson.M{"$regex": "^" + regexp.QuoteMeta(req.NamePrefix)}
 	}
 
-	out := tes.ListTasksResponse{
-		Tasks: tasks,
+	tags := map[string]bson.M{}
+	tag := func(k string) bson.M {
+		if tags[k] == nil {
+			tags[k] = bson.M{}
+		}
+		return tags[k]
+	}
+	for k, v := range req.Tags {
+		tag(k)["$eq"] = v
+	}
+	for _, k := range req.TagKeys {
+		tag(k)["$exists"] = true
 	}
-	// TODO figure out when not to return a next page token
-	if len(tasks) > 0 {
-		out.NextPageToken = tasks[len(tasks)-1].Id
+	for k, v := range req.TagPrefixes {
+		tag(k)["$regex"] = "^" + regexp.QuoteMeta(v)
 	}
+	for k, v := range tags {
+		query[fmt.Sprintf("tags.%s", k)] = v
+	}
+	return query
+}
 
-	return &out, nil
+// DeleteTask deletes a task. Deleting a task which doesn't exist isn't an error.
+func (db *MongoDB) DeleteTask(ctx context.Context, id string) error {
+	if _, err := db.events.RemoveAll(bson.M{"taskid": id}); err != nil {
+		return err
+	}
+	err := db.tasks.Remove(bson.M{"id": id})
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
 }
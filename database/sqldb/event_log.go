@@ -0,0 +1,48 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+)
+
+// logEvent appends the event to the task's event log.
+func (db *SQL) logEvent(ctx context.Context, ev *events.Event) error {
+	data, err := proto.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	// An event replayed from a worker's event spool is logged once.
+	// Events without an idempotency key have a NULL key, which is never equal
+	// to another key.
+	key := sql.NullString{String: ev.IdempotencyKey, Valid: ev.IdempotencyKey != ""}
+	_, err = db.db.ExecContext(ctx, `INSERT INTO task_events (task_id, idempotency_key, data)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, ev.Id, key, data)
+	return err
+}
+
+// ListTaskEvents returns the logged events of the task, in the order
+// they were written.
+func (db *SQL) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT data FROM task_events WHERE task_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evs []*events.Event
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		ev := &events.Event{}
+		if err := proto.Unmarshal(data, ev); err != nil {
+			return nil, err
+		}
+		evs = append(evs, ev)
+	}
+	return evs, rows.Err()
+}
@@ -0,0 +1,213 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// stateUpdateRetries is the number of times a task state update is attempted
+// when the state changes concurrently.
+const stateUpdateRetries = 5
+
+// WriteEvent creates an event for the server to handle.
+func (db *SQL) WriteEvent(ctx context.Context, req *events.Event) error {
+	if err := db.writeEvent(ctx, req); err != nil {
+		return err
+	}
+	if events.Logged(req) {
+		return db.logEvent(ctx, req)
+	}
+	return nil
+}
+
+func (db *SQL) writeEvent(ctx context.Context, req *events.Event) error {
+	if req.Type == events.Type_TASK_CREATED {
+		return db.createTask(ctx, req.GetTask())
+	}
+
+	if req.Type == events.Type_TASK_STATE {
+		return db.transitionTaskState(ctx, req.Id, req.GetState())
+	}
+
+	// Check that the task exists, and create the log of the attempt.
+	err := db.ensureTaskLog(ctx, req.Id, req.Attempt)
+	if err != nil {
+		return err
+	}
+
+	switch req.Type {
+	case events.Type_TASK_START_TIME:
+		_, err = db.db.ExecContext(ctx, `UPDATE task_logs SET start_time = $1
+			WHERE task_id = $2 AND attempt = $3`, req.GetStartTime(), req.Id, req.Attempt)
+
+	case events.Type_TASK_END_TIME:
+		_, err = db.db.ExecContext(ctx, `UPDATE task_logs SET end_time = $1
+			WHERE task_id = $2 AND attempt = $3`, req.GetEndTime(), req.Id, req.Attempt)
+
+	case events.Type_TASK_OUTPUTS:
+		var b []byte
+		b, err = proto.Marshal(req.GetOutputs())
+		if err != nil {
+			return err
+		}
+		_, err = db.db.ExecContext(ctx, `UPDATE task_logs SET outputs = $1
+			WHERE task_id = $2 AND attempt = $3`, b, req.Id, req.Attempt)
+
+	case events.Type_TASK_METADATA:
+		err = db.inTx(ctx, func(tx *sql.Tx) error {
+			for k, v := range req.GetMetadata().Value {
+				_, err := tx.ExecContext(ctx, `INSERT INTO task_metadata (task_id, attempt, name, value)
+					VALUES ($1, $2, $3, $4)
+					ON CONFLICT (task_id, attempt, name) DO UPDATE SET value = excluded.value`,
+					req.Id, req.Attempt, k, v)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	case events.Type_EXECUTOR_START_TIME:
+		err = db.updateExecutorLog(ctx, req, "start_time", req.GetStartTime())
+
+	case events.Type_EXECUTOR_END_TIME:
+		err = db.updateExecutorLog(ctx, req, "end_time", req.GetEndTime())
+
+	case events.Type_EXECUTOR_EXIT_CODE:
+		err = db.updateExecutorLog(ctx, req, "exit_code", req.GetExitCode())
+
+	case events.Type_EXECUTOR_STDOUT:
+		err = db.updateExecutorLog(ctx, req, "stdout", req.GetStdout())
+
+	case events.Type_EXECUTOR_STDERR:
+		err = db.updateExecutorLog(ctx, req, "stderr", req.GetStderr())
+
+	case events.Type_SYSTEM_LOG:
+		// A system log replayed from a worker's event spool is added once.
+		key := sql.NullString{String: req.IdempotencyKey, Valid: req.IdempotencyKey != ""}
+		_, err = db.db.ExecContext(ctx, `INSERT INTO system_logs (task_id, attempt, msg, idempotency_key)
+			VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, req.Id, req.Attempt, req.SysLogString(), key)
+	}
+
+	return err
+}
+
+// createTask stores a new, queued task.
+func (db *SQL) createTask(ctx context.Context, task *tes.Task) error {
+	// The state and logs are stored in their own tables.
+	spec := proto.Clone(task).(*tes.Task)
+	spec.State = tes.State_UNKNOWN
+	spec.Logs = nil
+	data, err := proto.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	err = db.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO tasks (id, state, data, name, creation_time)
+			VALUES ($1, $2, $3, $4, $5)`, task.Id, int32(tes.State_QUEUED), data, task.Name, creationTime(task))
+		if err != nil {
+			return err
+		}
+		for k, v := range task.Tags {
+			_, err := tx.ExecContext(ctx, `INSERT INTO task_tags (task_id, name, value) VALUES ($1, $2, $3)`,
+				task.Id, k, v)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO task_logs (task_id, attempt) VALUES ($1, 0)`, task.Id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error storing task in database: %s", err)
+	}
+	return nil
+}
+
+// creationTime returns the creation time of the task in Unix nanoseconds,
+// or 0 if it can't be parsed.
+func creationTime(task *tes.Task) int64 {
+	t, err := time.Parse(time.RFC3339Nano, task.CreationTime)
+	if err != nil {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// transitionTaskState updates the task state, if the transition is valid.
+// The update is conditional on the current state, so concurrent updates
+// can't make an invalid transition.
+func (db *SQL) transitionTaskState(ctx context.Context, id string, to tes.State) error {
+	for i := 0; i < stateUpdateRetries; i++ {
+		var from int32
+		err := db.db.QueryRowContext(ctx, `SELECT state FROM tasks WHERE id = $1`, id).Scan(&from)
+		if err == sql.ErrNoRows {
+			return tes.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tes.ValidateTransition(tes.State(from), to); err != nil {
+			return err
+		}
+		if tes.State(from) == to {
+			return nil
+		}
+
+		res, err := db.db.ExecContext(ctx, `UPDATE tasks SET state = $1 WHERE id = $2 AND state = $3`,
+			int32(to), id, from)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 1 {
+			return err
+		}
+	}
+	return fmt.Errorf("task %s state changed concurrently", id)
+}
+
+// ensureTaskLog creates the log of the task attempt, if it doesn't exist.
+// tes.ErrNotFound is returned if the task doesn't exist.
+func (db *SQL) ensureTaskLog(ctx context.Context, id string, attempt uint32) error {
+	var exists int
+	err := db.db.QueryRowContext(ctx, `SELECT 1 FROM tasks WHERE id = $1`, id).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return tes.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	_, err = db.db.ExecContext(ctx, `INSERT INTO task_logs (task_id, attempt) VALUES ($1, $2)
+		ON CONFLICT (task_id, attempt) DO NOTHING`, id, attempt)
+	return err
+}
+
+// updateExecutorLog sets a column of the executor log of the event's attempt and index.
+func (db *SQL) updateExecutorLog(ctx context.Context, req *events.Event, col string, val interface{}) error {
+	_, err := db.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO executor_logs (task_id, attempt, idx, %[1]s)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (task_id, attempt, idx) DO UPDATE SET %[1]s = excluded.%[1]s`, col),
+		req.Id, req.Attempt, req.Index, val)
+	return err
+}
+
+// inTx runs "fn" in a transaction, which is committed if "fn" succeeds.
+func (db *SQL) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
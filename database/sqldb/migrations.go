@@ -0,0 +1,216 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// migration is a change to the database schema. After the statements,
+// the optional backfill fills new columns from the existing rows.
+type migration struct {
+	stmts    []string
+	backfill func(*sql.Tx) error
+}
+
+// migrations are the changes to the database schema, in order. A migration's
+// version is its index + 1. Applied migrations must never be changed;
+// schema changes are made by adding a migration.
+var migrations = []migration{
+	// 1: initial schema
+	{stmts: []string{
+		// Tasks, without their state and logs, are stored as tes.Task protobuf messages.
+		`CREATE TABLE tasks (
+			id TEXT PRIMARY KEY,
+			state INTEGER NOT NULL,
+			data {{blob}} NOT NULL
+		)`,
+		`CREATE INDEX tasks_state ON tasks (state, id)`,
+		`CREATE TABLE task_tags (
+			task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (task_id, name)
+		)`,
+		`CREATE INDEX task_tags_name_value ON task_tags (name, value)`,
+		// Outputs are stored as events.Outputs protobuf messages.
+		`CREATE TABLE task_logs (
+			task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			start_time TEXT NOT NULL DEFAULT '',
+			end_time TEXT NOT NULL DEFAULT '',
+			outputs {{blob}},
+			PRIMARY KEY (task_id, attempt)
+		)`,
+		`CREATE TABLE task_metadata (
+			task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (task_id, attempt, name)
+		)`,
+		`CREATE TABLE executor_logs (
+			task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			idx INTEGER NOT NULL,
+			start_time TEXT NOT NULL DEFAULT '',
+			end_time TEXT NOT NULL DEFAULT '',
+			exit_code INTEGER NOT NULL DEFAULT 0,
+			stdout TEXT NOT NULL DEFAULT '',
+			stderr TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (task_id, attempt, idx)
+		)`,
+		`CREATE TABLE system_logs (
+			id {{serial}},
+			task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+			attempt INTEGER NOT NULL,
+			msg TEXT NOT NULL
+		)`,
+		`CREATE INDEX system_logs_task ON system_logs (task_id, attempt, id)`,
+		// Nodes are stored as scheduler.Node protobuf messages.
+		`CREATE TABLE nodes (
+			id TEXT PRIMARY KEY,
+			version BIGINT NOT NULL,
+			data {{blob}} NOT NULL
+		)`,
+	}},
+	// 2: task name and creation time, for ListTasks filters
+	{
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN name TEXT NOT NULL DEFAULT ''`,
+			// Unix time in nanoseconds.
+			`ALTER TABLE tasks ADD COLUMN creation_time BIGINT NOT NULL DEFAULT 0`,
+			`CREATE INDEX tasks_name ON tasks (name)`,
+			`CREATE INDEX tasks_creation_time ON tasks (creation_time)`,
+		},
+		backfill: backfillTaskColumns,
+	},
+	// 3: task event log
+	{stmts: []string{
+		// Events are stored as events.Event protobuf messages.
+		`CREATE TABLE task_events (
+			id {{serial}},
+			task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+			data {{blob}} NOT NULL
+		)`,
+		`CREATE INDEX task_events_task ON task_events (task_id, id)`,
+	}},
+	// 4: idempotency keys of logged events
+	{stmts: []string{
+		`ALTER TABLE task_events ADD COLUMN idempotency_key TEXT`,
+		`CREATE UNIQUE INDEX task_events_key ON task_events (task_id, idempotency_key)`,
+	}},
+	// 5: idempotency keys of system logs
+	{stmts: []string{
+		`ALTER TABLE system_logs ADD COLUMN idempotency_key TEXT`,
+		`CREATE UNIQUE INDEX system_logs_key ON system_logs (task_id, idempotency_key)`,
+	}},
+}
+
+// backfillTaskColumns sets the columns of the tasks table which are
+// copied from the task data. The tasks are read in batches, because
+// a query's rows must be closed before the next statement.
+func backfillTaskColumns(tx *sql.Tx) error {
+	const batchSize = 1000
+	last := ""
+	for {
+		tasks, err := readTaskBatch(tx, last, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			_, err := tx.Exec(`UPDATE tasks SET name = $1, creation_time = $2 WHERE id = $3`,
+				task.Name, creationTime(task), task.Id)
+			if err != nil {
+				return err
+			}
+		}
+		if len(tasks) < batchSize {
+			return nil
+		}
+		last = tasks[len(tasks)-1].Id
+	}
+}
+
+func readTaskBatch(tx *sql.Tx, after string, n int) ([]*tes.Task, error) {
+	rows, err := tx.Query(`SELECT id, data FROM tasks WHERE id > $1 ORDER BY id LIMIT $2`, after, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*tes.Task
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		task := &tes.Task{}
+		if err := proto.Unmarshal(data, task); err != nil {
+			return nil, err
+		}
+		task.Id = id
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// migrate applies the migrations which haven't been applied yet,
+// each in its own transaction.
+func (db *SQL) migrate() error {
+	_, err := db.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations table: %v", err)
+	}
+
+	current, err := db.schemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this version of Funnel supports (%d)",
+			current, len(migrations))
+	}
+
+	for v := current + 1; v <= len(migrations); v++ {
+		tx, err := db.db.Begin()
+		if err != nil {
+			return err
+		}
+		m := migrations[v-1]
+		for _, stmt := range m.stmts {
+			if _, err := tx.Exec(db.ddl(stmt)); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("applying schema migration %d: %v", v, err)
+			}
+		}
+		if m.backfill != nil {
+			if err := m.backfill(tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("applying schema migration %d: %v", v, err)
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, v); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying schema migration %d: %v", v, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("applying schema migration %d: %v", v, err)
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the version of the last applied migration,
+// or 0 if none were applied.
+func (db *SQL) schemaVersion() (int, error) {
+	var v sql.NullInt64
+	err := db.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&v)
+	if err != nil {
+		return 0, fmt.Errorf("reading schema version: %v", err)
+	}
+	return int(v.Int64), nil
+}
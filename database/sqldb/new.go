@@ -0,0 +1,117 @@
+// Package sqldb contains a Funnel database backend for SQL databases,
+// PostgreSQL and SQLite.
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/util/fsutil"
+)
+
+// dialect describes the differences between the supported SQL databases.
+type dialect struct {
+	name string
+	// Column type of binary data.
+	blob string
+	// Column type of an auto-incrementing primary key.
+	serial string
+	// Expression converting an RFC 3339 time to Unix seconds,
+	// or NULL if the time is empty.
+	epoch string
+}
+
+var (
+	postgres = dialect{
+		name:   "postgres",
+		blob:   "BYTEA",
+		serial: "BIGSERIAL PRIMARY KEY",
+		epoch:  "EXTRACT(EPOCH FROM CAST(NULLIF(%s, '') AS TIMESTAMPTZ))",
+	}
+	sqlite = dialect{
+		name:   "sqlite",
+		blob:   "BLOB",
+		serial: "INTEGER PRIMARY KEY AUTOINCREMENT",
+		epoch:  "((julianday(NULLIF(%s, '')) - 2440587.5) * 86400.0)",
+	}
+)
+
+// SQL provides a database backend for PostgreSQL and SQLite.
+// Tasks, logs and nodes are stored in relational tables.
+//
+// Queries use Postgres style placeholders, which SQLite treats as named
+// parameters, bound in order of first appearance. So placeholders must
+// be numbered in the order they appear in a query.
+type SQL struct {
+	db      *sql.DB
+	dialect dialect
+	// Postgres schema, created by Init.
+	schema string
+}
+
+// NewPostgres returns a new SQL instance, accessing the PostgreSQL database
+// at the configured URL.
+func NewPostgres(conf config.Postgres) (*SQL, error) {
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Postgres URL: %v", err)
+	}
+	if conf.Schema != "" {
+		// The schema is set per connection, so that queries don't need to name it.
+		q := u.Query()
+		q.Set("search_path", pq.QuoteIdentifier(conf.Schema))
+		u.RawQuery = q.Encode()
+	}
+
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		return nil, err
+	}
+	return &SQL{db: db, dialect: postgres, schema: conf.Schema}, nil
+}
+
+// NewSQLite returns a new SQL instance, accessing the SQLite database file
+// at the configured path.
+func NewSQLite(conf config.SQLite) (*SQL, error) {
+	if !sqliteSupported {
+		return nil, fmt.Errorf("this build of Funnel doesn't support SQLite, which requires cgo; " +
+			"use a Linux release, or build Funnel with CGO_ENABLED=1")
+	}
+	fsutil.EnsurePath(conf.Path)
+	// LIKE is case sensitive, as in Postgres.
+	db, err := sql.Open("sqlite3", "file:"+conf.Path+"?_foreign_keys=1&_busy_timeout=5000&_cslike=1")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer at a time.
+	db.SetMaxOpenConns(1)
+	return &SQL{db: db, dialect: sqlite}, nil
+}
+
+// Init creates the database schema, or migrates it to the latest version.
+func (db *SQL) Init() error {
+	if db.schema != "" {
+		_, err := db.db.Exec("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(db.schema))
+		if err != nil {
+			return fmt.Errorf("creating schema %s: %v", db.schema, err)
+		}
+	}
+	return db.migrate()
+}
+
+// Close closes the database.
+func (db *SQL) Close() error {
+	return db.db.Close()
+}
+
+// ddl replaces the dialect specific column types in a schema statement.
+func (db *SQL) ddl(stmt string) string {
+	return strings.NewReplacer(
+		"{{blob}}", db.dialect.blob,
+		"{{serial}}", db.dialect.serial,
+	).Replace(stmt)
+}
@@ -0,0 +1,144 @@
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var errNotFound = errors.New("not found")
+
+// ReadQueue returns a slice of queued Tasks. Up to "n" tasks are returned,
+// oldest first, starting after the task with ID "after" (if not empty),
+// with the ID of the last queued task scanned (see scheduler.TaskQueue).
+func (db *SQL) ReadQueue(n int, after string) ([]*tes.Task, string) {
+	ctx := context.Background()
+	ids, err := db.queryIDs(ctx, `SELECT id FROM tasks WHERE state = $1 AND id > $2 ORDER BY id LIMIT $3`,
+		int32(tes.State_QUEUED), after, n)
+	if err != nil || len(ids) == 0 {
+		return nil, ""
+	}
+
+	var tasks []*tes.Task
+	for _, id := range ids {
+		task, err := db.getTask(ctx, id, tes.TaskView_FULL)
+		if err == nil {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, ids[len(ids)-1]
+}
+
+// PutNode puts a node in the database.
+//
+// For optimisic locking, if the node already exists and node.Version
+// doesn't match the version in the database, an error is returned.
+// A zero version doesn't overwrite an existing node either.
+func (db *SQL) PutNode(ctx context.Context, node *scheduler.Node) (*scheduler.PutNodeResponse, error) {
+	existing, err := db.getNode(ctx, node.Id)
+	found := err == nil
+	if err == errNotFound {
+		existing = &scheduler.Node{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if found && node.GetVersion() != existing.GetVersion() {
+		return nil, fmt.Errorf("Version outdated")
+	}
+
+	err = scheduler.UpdateNode(ctx, db, node, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := proto.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	// The version is only bumped if it didn't change since the node was read.
+	var res sql.Result
+	if !found {
+		res, err = db.db.ExecContext(ctx, `INSERT INTO nodes (id, version, data) VALUES ($1, 1, $2)
+			ON CONFLICT (id) DO NOTHING`, node.Id, data)
+	} else {
+		res, err = db.db.ExecContext(ctx, `UPDATE nodes SET version = version + 1, data = $1
+			WHERE id = $2 AND version = $3`, data, node.Id, existing.GetVersion())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, fmt.Errorf("Version outdated")
+	}
+	return &scheduler.PutNodeResponse{}, nil
+}
+
+// GetNode gets a node
+func (db *SQL) GetNode(ctx context.Context, req *scheduler.GetNodeRequest) (*scheduler.Node, error) {
+	node, err := db.getNode(ctx, req.Id)
+	if err == errNotFound {
+		return nil, grpc.Errorf(codes.NotFound, "%v: nodeID: %s", err, req.Id)
+	}
+	return node, err
+}
+
+func (db *SQL) getNode(ctx context.Context, id string) (*scheduler.Node, error) {
+	var version int64
+	var data []byte
+	err := db.db.QueryRowContext(ctx, `SELECT version, data FROM nodes WHERE id = $1`, id).Scan(&version, &data)
+	if err == sql.ErrNoRows {
+		return nil, errNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node := &scheduler.Node{}
+	if err := proto.Unmarshal(data, node); err != nil {
+		return nil, err
+	}
+	// Must happen after the unmarshal
+	node.Version = version
+	return node, nil
+}
+
+// DeleteNode deletes the given node.
+// Currently, the node's version field is not checked.
+func (db *SQL) DeleteNode(ctx context.Context, node *scheduler.Node) (*scheduler.DeleteNodeResponse, error) {
+	_, err := db.db.ExecContext(ctx, `DELETE FROM nodes WHERE id = $1`, node.Id)
+	return &scheduler.DeleteNodeResponse{}, err
+}
+
+// ListNodes is an API endpoint that returns a list of nodes.
+func (db *SQL) ListNodes(ctx context.Context, req *scheduler.ListNodesRequest) (*scheduler.ListNodesResponse, error) {
+	resp := &scheduler.ListNodesResponse{}
+	err := db.eachRow(ctx, `SELECT version, data FROM nodes ORDER BY id`, nil, func(rows *sql.Rows) error {
+		var version int64
+		var data []byte
+		if err := rows.Scan(&version, &data); err != nil {
+			return err
+		}
+		node := &scheduler.Node{}
+		if err := proto.Unmarshal(data, node); err != nil {
+			return err
+		}
+		node.Version = version
+		resp.Nodes = append(resp.Nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
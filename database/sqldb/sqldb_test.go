@@ -0,0 +1,279 @@
+package sqldb
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// testSQLite returns a new, initialized SQLite database in a temporary directory.
+func testSQLite(t *testing.T) (*SQL, func()) {
+	if !sqliteSupported {
+		t.Skip("SQLite requires cgo")
+	}
+	dir, err := ioutil.TempDir("", "funnel-test-sqldb-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewSQLite(config.SQLite{Path: filepath.Join(dir, "funnel.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func createTask(ctx context.Context, t *testing.T, db *SQL, name string) *tes.Task {
+	task := &tes.Task{
+		Name:      name,
+		Executors: []*tes.Executor{{Image: "alpine", Command: []string{"echo", "hi"}}},
+	}
+	if err := tes.InitTask(task); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WriteEvent(ctx, events.NewTaskCreated(task)); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func TestMigrations(t *testing.T) {
+	db, cleanup := testSQLite(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	v, err := db.schemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != len(migrations) {
+		t.Fatalf("expected schema version %d, got %d", len(migrations), v)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatal("expected Init to be idempotent:", err)
+	}
+
+	// Reopen the database with only the first migration applied, and
+	// store a task the way that version of Funnel did.
+	_, err = db.db.Exec(`DROP TABLE task_events`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.db.Exec(`DELETE FROM schema_migrations WHERE version > 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`DROP INDEX tasks_name`,
+		`DROP INDEX tasks_creation_time`,
+		`ALTER TABLE tasks RENAME TO old_tasks`,
+		`CREATE TABLE tasks (id TEXT PRIMARY KEY, state INTEGER NOT NULL, data BLOB NOT NULL)`,
+		`DROP TABLE old_tasks`,
+		`DROP INDEX system_logs_key`,
+		`ALTER TABLE system_logs RENAME TO old_system_logs`,
+		`CREATE TABLE system_logs (id INTEGER PRIMARY KEY AUTOINCREMENT, task_id TEXT NOT NULL, attempt INTEGER NOT NULL, msg TEXT NOT NULL)`,
+		`DROP TABLE old_system_logs`,
+	} {
+		if _, err := db.db.Exec(stmt); err != nil {
+			t.Fatal(stmt, err)
+		}
+	}
+	task := &tes.Task{
+		Name:      "old",
+		Executors: []*tes.Executor{{Image: "alpine", Command: []string{"echo", "hi"}}},
+	}
+	if err := tes.InitTask(task); err != nil {
+		t.Fatal(err)
+	}
+	data, err := proto.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.db.Exec(`INSERT INTO tasks (id, state, data) VALUES ($1, $2, $3)`, task.Id, int32(tes.Queued), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The remaining migrations backfill the new columns.
+	if err := db.Init(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := db.ListTasks(ctx, &tes.ListTasksRequest{NamePrefix: "ol"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Tasks) != 1 || resp.Tasks[0].Id != task.Id {
+		t.Fatalf("expected the migrated task to be listed by name, got %v", resp.Tasks)
+	}
+
+	// A schema migrated by a newer version of Funnel is rejected.
+	_, err = db.db.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, len(migrations)+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Init(); err == nil {
+		t.Error("expected an error for a newer schema version")
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	db, cleanup := testSQLite(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	task := createTask(ctx, t, db, "task")
+	now := time.Now()
+	sysLog := events.NewSystemLog(task.Id, 0, 0, "info", "hello", nil)
+	sysLog.IdempotencyKey = "sys-1"
+	for _, ev := range []*events.Event{
+		events.NewState(task.Id, tes.Initializing),
+		events.NewState(task.Id, tes.Running),
+		events.NewStartTime(task.Id, 0, now),
+		events.NewStdout(task.Id, 0, 0, "hi\n"),
+		events.NewExitCode(task.Id, 0, 0, 0),
+		sysLog,
+		// Replayed from a worker's event spool.
+		sysLog,
+		events.NewState(task.Id, tes.Complete),
+		events.NewState(task.Id, tes.Complete),
+	} {
+		if err := db.WriteEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Terminal states can't change.
+	if err := db.WriteEvent(ctx, events.NewState(task.Id, tes.Running)); err == nil {
+		t.Error("expected an error for an invalid state transition")
+	}
+
+	got, err := db.GetTask(ctx, &tes.GetTaskRequest{Id: task.Id, View: tes.TaskView_FULL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != tes.Complete {
+		t.Errorf("expected COMPLETE, got %s", got.State)
+	}
+	if len(got.Logs) != 1 || len(got.Logs[0].Logs) != 1 {
+		t.Fatalf("unexpected logs: %v", got.Logs)
+	}
+	if got.Logs[0].Logs[0].Stdout != "hi\n" {
+		t.Errorf("unexpected stdout: %q", got.Logs[0].Logs[0].Stdout)
+	}
+	if len(got.Logs[0].SystemLogs) != 1 {
+		t.Errorf("expected the replayed system log to be written once, got %v", got.Logs[0].SystemLogs)
+	}
+
+	evs, err := db.ListTaskEvents(ctx, task.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sysLogs int
+	for _, ev := range evs {
+		if ev.Type == events.Type_SYSTEM_LOG {
+			sysLogs++
+		}
+	}
+	if sysLogs != 1 {
+		t.Errorf("expected the replayed system log to be logged once, got %d", sysLogs)
+	}
+	if evs[0].Type != events.Type_TASK_CREATED || evs[len(evs)-1].GetState() != tes.Complete {
+		t.Errorf("unexpected event log: %v", evs)
+	}
+}
+
+func TestReadQueue(t *testing.T) {
+	db, cleanup := testSQLite(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	var queued []string
+	for i := 0; i < 5; i++ {
+		task := createTask(ctx, t, db, "task")
+		if i == 2 {
+			if err := db.WriteEvent(ctx, events.NewState(task.Id, tes.Initializing)); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		queued = append(queued, task.Id)
+	}
+
+	var read []string
+	after := ""
+	for {
+		page, last := db.ReadQueue(3, after)
+		for _, task := range page {
+			read = append(read, task.Id)
+		}
+		if last == "" {
+			break
+		}
+		after = last
+	}
+	if len(read) != len(queued) {
+		t.Fatalf("expected %v, got %v", queued, read)
+	}
+	for i := range queued {
+		if read[i] != queued[i] {
+			t.Fatalf("expected %v, got %v", queued, read)
+		}
+	}
+}
+
+func TestPutNode(t *testing.T) {
+	db, cleanup := testSQLite(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	node := &scheduler.Node{Id: "node-1", State: scheduler.NodeState_ALIVE}
+	if _, err := db.PutNode(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetNode(ctx, &scheduler.GetNodeRequest{Id: "node-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 1 || got.State != scheduler.NodeState_ALIVE {
+		t.Fatalf("unexpected node: %v", got)
+	}
+
+	// Updates must be based on the current version.
+	got.State = scheduler.NodeState_DRAIN
+	if _, err := db.PutNode(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	stale := &scheduler.Node{Id: "node-1", Version: 1, State: scheduler.NodeState_ALIVE}
+	if _, err := db.PutNode(ctx, stale); err == nil {
+		t.Error("expected an error for an outdated version")
+	}
+
+	resp, err := db.ListNodes(ctx, &scheduler.ListNodesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Nodes) != 1 || resp.Nodes[0].Version != 2 || resp.Nodes[0].State != scheduler.NodeState_DRAIN {
+		t.Fatalf("unexpected nodes: %v", resp.Nodes)
+	}
+
+	if _, err := db.DeleteNode(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetNode(ctx, &scheduler.GetNodeRequest{Id: "node-1"}); err == nil {
+		t.Error("expected the node to be deleted")
+	}
+}
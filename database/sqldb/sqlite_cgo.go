@@ -0,0 +1,10 @@
+//go:build cgo
+// +build cgo
+
+package sqldb
+
+// Registers the "sqlite3" driver, which requires cgo.
+import _ "github.com/mattn/go-sqlite3"
+
+// sqliteSupported is true if Funnel was built with cgo.
+const sqliteSupported = true
@@ -0,0 +1,8 @@
+//go:build !cgo
+// +build !cgo
+
+package sqldb
+
+// sqliteSupported is false, because the SQLite driver requires cgo,
+// and Funnel was built without it, e.g. the macOS release binaries.
+const sqliteSupported = false
@@ -0,0 +1,227 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+)
+
+// GetTaskStats computes task stats in the database. Percentiles are
+// selected with window functions, which need SQLite 3.25 or later.
+func (db *SQL) GetTaskStats(ctx context.Context, req *tes.GetTaskStatsRequest) (*tes.GetTaskStatsResponse, error) {
+	var args queryArgs
+	cte, keys, err := db.statsTable(req, &args)
+	if err != nil {
+		return nil, err
+	}
+
+	var groupBy, partition string
+	if len(keys) > 0 {
+		groupBy = " GROUP BY " + strings.Join(keys, ", ")
+		partition = "PARTITION BY " + strings.Join(keys, ", ")
+	}
+	var keyCols string
+	for _, k := range keys {
+		keyCols += k + ", "
+	}
+
+	groups := map[string]*tes.TaskStatsGroup{}
+	group := func(r *statsRow) *tes.TaskStatsGroup {
+		key := r.key()
+		g, ok := groups[key]
+		if !ok {
+			g = r.group()
+			groups[key] = g
+		}
+		return g
+	}
+
+	q := cte + " SELECT " + keyCols + "COUNT(*) FROM t" + groupBy
+	err = db.eachRow(ctx, q, args, func(rows *sql.Rows) error {
+		r := newStatsRow(req)
+		var count int64
+		if err := rows.Scan(append(r.dest(), &count)...); err != nil {
+			return err
+		}
+		group(r).Count = count
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the rows with the rank of a requested percentile are selected.
+	// The rank is computed the same way as tes.PercentileRank.
+	percentiles := tes.StatsPercentiles(req)
+	var ranks []string
+	for _, p := range percentiles {
+		x := fmt.Sprintf("CAST(%s AS DOUBLE PRECISION) * n / 100", strconv.FormatFloat(p, 'g', -1, 64))
+		ranks = append(ranks, fmt.Sprintf("(rn - 1 < %[1]s AND %[1]s <= rn)", x))
+	}
+
+	metrics := []struct {
+		col   string
+		stats func(*tes.TaskStatsGroup) **tes.DurationStats
+	}{
+		{"run_time", func(g *tes.TaskStatsGroup) **tes.DurationStats { return &g.RunTime }},
+		{"queue_time", func(g *tes.TaskStatsGroup) **tes.DurationStats { return &g.QueueTime }},
+	}
+	for _, m := range metrics {
+		q := cte + fmt.Sprintf(` SELECT %[1]sn, mean, mn, mx, rn, %[2]s FROM (
+			SELECT %[1]s%[2]s,
+				ROW_NUMBER() OVER (%[3]s ORDER BY %[2]s) AS rn,
+				COUNT(*) OVER (%[3]s) AS n,
+				AVG(%[2]s) OVER (%[3]s) AS mean,
+				MIN(%[2]s) OVER (%[3]s) AS mn,
+				MAX(%[2]s) OVER (%[3]s) AS mx
+			FROM t WHERE %[2]s IS NOT NULL
+		) r WHERE %[4]s`, keyCols, m.col, partition, strings.Join(ranks, " OR "))
+
+		err := db.eachRow(ctx, q, args, func(rows *sql.Rows) error {
+			r := newStatsRow(req)
+			var n, rn int64
+			var mean, min, max, v float64
+			if err := rows.Scan(append(r.dest(), &n, &mean, &min, &max, &rn, &v)...); err != nil {
+				return err
+			}
+			ds := m.stats(group(r))
+			if *ds == nil {
+				*ds = &tes.DurationStats{
+					Count:       n,
+					Mean:        mean,
+					Min:         min,
+					Max:         max,
+					Percentiles: make([]float64, len(percentiles)),
+				}
+			}
+			for i, p := range percentiles {
+				if int64(tes.PercentileRank(p, int(n))) == rn {
+					(*ds).Percentiles[i] = v
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var out []*tes.TaskStatsGroup
+	for _, g := range groups {
+		if g.RunTime == nil {
+			g.RunTime = &tes.DurationStats{}
+		}
+		if g.QueueTime == nil {
+			g.QueueTime = &tes.DurationStats{}
+		}
+		out = append(out, g)
+	}
+	return tes.NewTaskStatsResponse(req, out), nil
+}
+
+// statsTable returns a WITH clause selecting the filtered tasks as table "t",
+// with their group keys and durations, and the names of the group key columns.
+func (db *SQL) statsTable(req *tes.GetTaskStatsRequest, args *queryArgs) (string, []string, error) {
+	var keys []string
+	cols := []string{"state"}
+	if req.GroupByState {
+		keys = append(keys, "state")
+	}
+
+	if req.GroupByTime != tes.TimeBucket_ALL_TIME {
+		size, offset := tes.TimeBucketSize(req.GroupByTime)
+		cols = append(cols, fmt.Sprintf(
+			"CASE WHEN creation_time = 0 THEN NULL ELSE (creation_time + %[2]d) / %[1]d * %[1]d - %[2]d END AS bucket",
+			int64(size), -int64(offset)))
+		keys = append(keys, "bucket")
+	}
+
+	for i, k := range req.GroupByTags {
+		col := fmt.Sprintf("tag%d", i)
+		cols = append(cols, fmt.Sprintf(
+			"(SELECT value FROM task_tags WHERE task_id = tasks.id AND name = %s) AS %s",
+			args.add(k), col))
+		keys = append(keys, col)
+	}
+
+	epoch := func(col string) string {
+		return fmt.Sprintf(db.dialect.epoch, col)
+	}
+	cols = append(cols,
+		fmt.Sprintf(`(SELECT %s - %s FROM task_logs WHERE task_id = tasks.id
+			ORDER BY attempt DESC LIMIT 1) AS run_time`, epoch("end_time"), epoch("start_time")),
+		fmt.Sprintf(`CASE WHEN creation_time = 0 THEN NULL ELSE
+			(SELECT %s FROM task_logs WHERE task_id = tasks.id AND attempt = 0) - creation_time / 1000000000.0
+			END AS queue_time`, epoch("start_time")),
+	)
+
+	where, err := listFilters(tes.StatsFilter(req), args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	q := "WITH t AS (SELECT " + strings.Join(cols, ", ") + " FROM tasks"
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	q += ")"
+	return q, keys, nil
+}
+
+// statsRow holds the group key columns of a row of the stats queries.
+type statsRow struct {
+	state  int32
+	bucket sql.NullInt64
+	tags   []sql.NullString
+	req    *tes.GetTaskStatsRequest
+}
+
+func newStatsRow(req *tes.GetTaskStatsRequest) *statsRow {
+	return &statsRow{req: req, tags: make([]sql.NullString, len(req.GroupByTags))}
+}
+
+// dest returns the scan destinations of the group key columns.
+func (r *statsRow) dest() []interface{} {
+	var dest []interface{}
+	if r.req.GroupByState {
+		dest = append(dest, &r.state)
+	}
+	if r.req.GroupByTime != tes.TimeBucket_ALL_TIME {
+		dest = append(dest, &r.bucket)
+	}
+	for i := range r.tags {
+		dest = append(dest, &r.tags[i])
+	}
+	return dest
+}
+
+// key returns a key which identifies the group of the row.
+func (r *statsRow) key() string {
+	key := fmt.Sprintf("%d %v %d", r.state, r.bucket.Valid, r.bucket.Int64)
+	for _, t := range r.tags {
+		key += fmt.Sprintf(" %t %q", t.Valid, t.String)
+	}
+	return key
+}
+
+// group returns a new group with the row's group keys.
+func (r *statsRow) group() *tes.TaskStatsGroup {
+	g := &tes.TaskStatsGroup{State: tes.State(r.state)}
+	if r.bucket.Valid {
+		g.TimeBucket = time.Unix(0, r.bucket.Int64).UTC().Format(time.RFC3339)
+	}
+	for i, t := range r.tags {
+		if t.Valid {
+			if g.Tags == nil {
+				g.Tags = map[string]string{}
+			}
+			g.Tags[r.req.GroupByTags[i]] = t.String
+		}
+	}
+	return g
+}
@@ -0,0 +1,307 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+)
+
+// GetTask gets a task, which describes a running task
+func (db *SQL) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.Task, error) {
+	return db.getTask(ctx, req.Id, req.View)
+}
+
+// ListTasks returns a list of tasks, most recent first.
+func (db *SQL) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
+	pageSize := tes.GetPageSize(req.GetPageSize())
+
+	var args queryArgs
+	var where []string
+	if req.PageToken != "" {
+		where = append(where, "id < "+args.add(req.PageToken))
+	}
+	filters, err := listFilters(req, &args)
+	if err != nil {
+		return nil, err
+	}
+	where = append(where, filters...)
+
+	q := "SELECT id FROM tasks"
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	q += " ORDER BY id DESC LIMIT " + args.add(pageSize)
+
+	ids, err := db.queryIDs(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &tes.ListTasksResponse{}
+	for _, id := range ids {
+		task, err := db.getTask(ctx, id, req.View)
+		if err == tes.ErrNotFound {
+			// Deleted since the query.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.Tasks = append(out.Tasks, task)
+	}
+
+	if len(ids) == pageSize {
+		out.NextPageToken = ids[len(ids)-1]
+	}
+	return out, nil
+}
+
+// queryArgs are the arguments of a query.
+type queryArgs []interface{}
+
+// add adds an argument and returns its placeholder.
+func (a *queryArgs) add(v interface{}) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// listFilters returns the conditions on the tasks table of the ListTasks
+// request's filters.
+func listFilters(req *tes.ListTasksRequest, args *queryArgs) ([]string, error) {
+	var where []string
+	if req.State != tes.Unknown {
+		where = append(where, "state = "+args.add(int32(req.State)))
+	}
+	if len(req.States) > 0 {
+		var states []string
+		for _, s := range req.States {
+			states = append(states, args.add(int32(s)))
+		}
+		where = append(where, "state IN ("+strings.Join(states, ", ")+")")
+	}
+	if req.NamePrefix != "" {
+		where = append(where, "name LIKE "+args.add(likePrefix(req.NamePrefix))+` ESCAPE '\'`)
+	}
+	after, before, err := tes.CreationTimeRange(req)
+	if err != nil {
+		return nil, err
+	}
+	if !after.IsZero() {
+		where = append(where, "creation_time >= "+args.add(after.UnixNano()))
+	}
+	if !before.IsZero() {
+		where = append(where, "creation_time < "+args.add(before.UnixNano()))
+	}
+	for k, v := range req.Tags {
+		where = append(where, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM task_tags WHERE task_id = tasks.id AND name = %s AND value = %s)",
+			args.add(k), args.add(v)))
+	}
+	for _, k := range req.TagKeys {
+		where = append(where, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM task_tags WHERE task_id = tasks.id AND name = %s)",
+			args.add(k)))
+	}
+	for k, v := range req.TagPrefixes {
+		where = append(where, fmt.Sprintf(
+			`EXISTS (SELECT 1 FROM task_tags WHERE task_id = tasks.id AND name = %s AND value LIKE %s ESCAPE '\')`,
+			args.add(k), args.add(likePrefix(v))))
+	}
+	return where, nil
+}
+
+// likePrefix returns a LIKE pattern matching strings which start with "p".
+func likePrefix(p string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(p) + "%"
+}
+
+// queryIDs returns the IDs selected by the query. The rows are read before
+// returning, so that the connection is free for other queries.
+func (db *SQL) queryIDs(ctx context.Context, q string, args ...interface{}) ([]string, error) {
+	rows, err := db.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (db *SQL) getTask(ctx context.Context, id string, view tes.TaskView) (*tes.Task, error) {
+	switch view {
+	case tes.TaskView_MINIMAL, tes.TaskView_BASIC, tes.TaskView_FULL:
+	default:
+		return nil, fmt.Errorf("Unknown view: %s", view.String())
+	}
+
+	var state int32
+	var data []byte
+	err := db.db.QueryRowContext(ctx, `SELECT state, data FROM tasks WHERE id = $1`, id).Scan(&state, &data)
+	if err == sql.ErrNoRows {
+		return nil, tes.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if view == tes.TaskView_MINIMAL {
+		return &tes.Task{Id: id, State: tes.State(state)}, nil
+	}
+
+	task := &tes.Task{}
+	if err := proto.Unmarshal(data, task); err != nil {
+		return nil, err
+	}
+	task.State = tes.State(state)
+
+	task.Logs, err = db.getTaskLogs(ctx, id, view)
+	if err != nil {
+		return nil, err
+	}
+
+	if view == tes.TaskView_BASIC {
+		for _, input := range task.Inputs {
+			input.Content = ""
+		}
+	}
+	return task, nil
+}
+
+// getTaskLogs returns the logs of each attempt of the task. The executors'
+// stdout/stderr and the system logs are only included in the full view.
+func (db *SQL) getTaskLogs(ctx context.Context, id string, view tes.TaskView) ([]*tes.TaskLog, error) {
+	var logs []*tes.TaskLog
+
+	err := db.eachRow(ctx, `SELECT attempt, start_time, end_time, outputs FROM task_logs
+		WHERE task_id = $1 ORDER BY attempt`, []interface{}{id}, func(rows *sql.Rows) error {
+		var i int
+		var outputs []byte
+		tl := &tes.TaskLog{}
+		if err := rows.Scan(&i, &tl.StartTime, &tl.EndTime, &outputs); err != nil {
+			return err
+		}
+		if outputs != nil {
+			o := &events.Outputs{}
+			if err := proto.Unmarshal(outputs, o); err != nil {
+				return err
+			}
+			tl.Outputs = o.Value
+		}
+		for len(logs) < i {
+			logs = append(logs, &tes.TaskLog{})
+		}
+		logs = append(logs, tl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// attempt returns the log of the attempt. Every event creates the
+	// task log of its attempt, so the other rows always have one.
+	attempt := func(i int) *tes.TaskLog {
+		if i < len(logs) {
+			return logs[i]
+		}
+		return nil
+	}
+
+	err = db.eachRow(ctx, `SELECT attempt, name, value FROM task_metadata
+		WHERE task_id = $1`, []interface{}{id}, func(rows *sql.Rows) error {
+		var i int
+		var k, v string
+		if err := rows.Scan(&i, &k, &v); err != nil {
+			return err
+		}
+		if tl := attempt(i); tl != nil {
+			if tl.Metadata == nil {
+				tl.Metadata = map[string]string{}
+			}
+			tl.Metadata[k] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.eachRow(ctx, `SELECT attempt, idx, start_time, end_time, exit_code, stdout, stderr
+		FROM executor_logs WHERE task_id = $1 ORDER BY attempt, idx`, []interface{}{id}, func(rows *sql.Rows) error {
+		var i, j int
+		el := &tes.ExecutorLog{}
+		if err := rows.Scan(&i, &j, &el.StartTime, &el.EndTime, &el.ExitCode, &el.Stdout, &el.Stderr); err != nil {
+			return err
+		}
+		if view != tes.TaskView_FULL {
+			el.Stdout = ""
+			el.Stderr = ""
+		}
+		if tl := attempt(i); tl != nil {
+			for len(tl.Logs) <= j {
+				tl.Logs = append(tl.Logs, &tes.ExecutorLog{})
+			}
+			tl.Logs[j] = el
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if view != tes.TaskView_FULL {
+		return logs, nil
+	}
+
+	err = db.eachRow(ctx, `SELECT attempt, msg FROM system_logs
+		WHERE task_id = $1 ORDER BY attempt, id`, []interface{}{id}, func(rows *sql.Rows) error {
+		var i int
+		var msg string
+		if err := rows.Scan(&i, &msg); err != nil {
+			return err
+		}
+		if tl := attempt(i); tl != nil {
+			tl.SystemLogs = append(tl.SystemLogs, msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// eachRow calls "fn" for each row selected by the query.
+func (db *SQL) eachRow(ctx context.Context, q string, args []interface{}, fn func(*sql.Rows) error) error {
+	rows, err := db.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// DeleteTask deletes a task. Its tags, logs and events are deleted by the foreign keys.
+// Deleting a task which doesn't exist isn't an error.
+func (db *SQL) DeleteTask(ctx context.Context, id string) error {
+	_, err := db.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// WriteArchive writes the events of each task, from TaskEvents, to "w"
+// as JSON lines, one event per line. The tasks should be in the full view.
+func WriteArchive(ctx context.Context, w io.Writer, l EventLog, tasks []*tes.Task) error {
+	bw := bufio.NewWriter(w)
+	for _, task := range tasks {
+		evs, err := TaskEvents(ctx, l, task)
+		if err != nil {
+			return err
+		}
+		for _, ev := range evs {
+			b, err := spoolMarshaler.MarshalToString(ev)
+			if err != nil {
+				return fmt.Errorf("archiving task %s: %v", task.Id, err)
+			}
+			bw.WriteString(b)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadArchive reads an archive written by WriteArchive, calling "fn" with
+// the events of each task, in order. Reading stops at the first error
+// returned by "fn".
+func ReadArchive(r io.Reader, fn func(id string, evs []*Event) error) error {
+	br := bufio.NewReader(r)
+	var evs []*Event
+
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("reading archive: %v", err)
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			ev := &Event{}
+			if uerr := Unmarshal(line, ev); uerr != nil {
+				return fmt.Errorf("reading archive: line %d: %v", n, uerr)
+			}
+			if len(evs) > 0 && evs[0].Id != ev.Id {
+				if ferr := fn(evs[0].Id, evs); ferr != nil {
+					return ferr
+				}
+				evs = nil
+			}
+			evs = append(evs, ev)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if len(evs) > 0 {
+		return fn(evs[0].Id, evs)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"github.com/ohsu-comp-bio/funnel/util"
+)
+
+// maxDeliveries is the number of times a message which fails to be written
+// is delivered before it is rejected.
+const maxDeliveries = 5
+
+// messageHandler applies events read from a message queue (Kafka, Pub/Sub)
+// to a Writer, e.g. the server's database.
+type messageHandler struct {
+	writer Writer
+	log    *logger.Logger
+	// retrier is copied for each message, so partitions and
+	// subscription callbacks may be handled concurrently.
+	retrier       util.Retrier
+	maxDeliveries int
+
+	mtx sync.Mutex
+	// failures counts the failed deliveries of each message, by ID.
+	failures map[string]int
+}
+
+func newMessageHandler(w Writer, log *logger.Logger) *messageHandler {
+	r := util.NewRetrier()
+	r.MaxInterval = time.Second * 10
+	r.MaxElapsedTime = time.Minute
+	r.ShouldRetry = func(err error) bool {
+		return !isRejected(err)
+	}
+	return &messageHandler{
+		writer:        w,
+		log:           log,
+		retrier:       *r,
+		maxDeliveries: maxDeliveries,
+		failures:      map[string]int{},
+	}
+}
+
+// rejectedError describes a message which can never be applied,
+// such as invalid JSON or a state event for an unknown task.
+type rejectedError struct {
+	err error
+}
+
+func (r *rejectedError) Error() string {
+	return r.err.Error()
+}
+
+func isRejected(err error) bool {
+	_, ok := err.(*rejectedError)
+	return ok
+}
+
+// handle decodes a message and writes the event. Write errors are retried.
+// "id" identifies the message across deliveries.
+//
+// A *rejectedError is returned if the message can never be applied, or if
+// it failed to be written maxDeliveries times; the caller should dead-letter
+// it and move on. Any other error is transient and the message should be
+// redelivered later.
+func (c *messageHandler) handle(ctx context.Context, id string, data []byte) error {
+	err := c.write(ctx, data)
+	if ctx.Err() != nil {
+		// The consumer is stopping, which isn't a failed delivery.
+		return err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err == nil || isRejected(err) {
+		delete(c.failures, id)
+		return err
+	}
+	c.failures[id]++
+	if n := c.failures[id]; n >= c.maxDeliveries {
+		delete(c.failures, id)
+		return &rejectedError{fmt.Errorf("failed %d deliveries: %v", n, err)}
+	}
+	return err
+}
+
+func (c *messageHandler) write(ctx context.Context, data []byte) error {
+	ev := &Event{}
+	if err := Unmarshal(data, ev); err != nil {
+		return &rejectedError{fmt.Errorf("decoding event: %v", err)}
+	}
+
+	r := c.retrier
+	r.Notify = func(err error, d time.Duration) {
+		c.log.Error("error writing consumed event; retrying",
+			"error", err, "taskID", ev.Id, "event_type", ev.Type.String(), "retry_in", d)
+	}
+	return r.Retry(ctx, func() error {
+		err := c.writer.WriteEvent(ctx, ev)
+		if err == tes.ErrNotFound {
+			return &rejectedError{fmt.Errorf("task %s: %v", ev.Id, err)}
+		}
+		if _, ok := err.(*tes.TransitionError); ok {
+			return &rejectedError{fmt.Errorf("task %s: %v", ev.Id, err)}
+		}
+		return err
+	})
+}
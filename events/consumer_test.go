@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// errWriter returns the next error from "errs" on each write.
+type errWriter struct {
+	errs   []error
+	writes int
+}
+
+func (e *errWriter) WriteEvent(ctx context.Context, ev *Event) error {
+	e.writes++
+	if len(e.errs) == 0 {
+		return nil
+	}
+	err := e.errs[0]
+	e.errs = e.errs[1:]
+	return err
+}
+
+func TestMessageHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger("test-consumer", logger.DebugConfig())
+	msg, err := Marshal(NewState("task-1", tes.Running))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handle := func(w *errWriter, data string) error {
+		h := newMessageHandler(w, log)
+		h.retrier.InitialInterval = time.Millisecond
+		h.retrier.MaxTries = 3
+		return h.handle(ctx, "msg-1", []byte(data))
+	}
+
+	// Transient errors are retried.
+	w := &errWriter{errs: []error{errors.New("unavailable")}}
+	if err := handle(w, msg); err != nil {
+		t.Error("unexpected error", err)
+	}
+	if w.writes != 2 {
+		t.Error("expected a retry, got writes:", w.writes)
+	}
+
+	// Transient errors are returned once retries run out.
+	w = &errWriter{errs: []error{errors.New("1"), errors.New("2"), errors.New("3")}}
+	if err := handle(w, msg); err == nil || isRejected(err) {
+		t.Error("expected transient error, got", err)
+	}
+
+	// Messages which can never be written are rejected without retries.
+	if err := handle(&errWriter{}, "not json"); !isRejected(err) {
+		t.Error("expected invalid JSON to be rejected, got", err)
+	}
+	for _, werr := range []error{tes.ErrNotFound, &tes.TransitionError{From: tes.Complete, To: tes.Running}} {
+		w = &errWriter{errs: []error{werr}}
+		if err := handle(w, msg); !isRejected(err) {
+			t.Error("expected rejection, got", err)
+		}
+		if w.writes != 1 {
+			t.Error("unexpected retry of rejected message")
+		}
+	}
+}
+
+func TestMessageHandlerMaxDeliveries(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger("test-consumer", logger.DebugConfig())
+	msg, err := Marshal(NewSystemLog("task-1", 0, 0, "info", "hello", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The writer never succeeds, with an error which isn't known
+	// to be permanent.
+	w := &errWriter{}
+	for i := 0; i < 100; i++ {
+		w.errs = append(w.errs, errors.New("unknown"))
+	}
+	h := newMessageHandler(w, log)
+	h.retrier.InitialInterval = time.Millisecond
+	h.retrier.MaxTries = 2
+	h.maxDeliveries = 3
+
+	for i := 1; i < h.maxDeliveries; i++ {
+		if err := h.handle(ctx, "msg-1", []byte(msg)); err == nil || isRejected(err) {
+			t.Fatalf("expected a transient error on delivery %d, got %v", i, err)
+		}
+	}
+	// Another message doesn't count towards the first one's deliveries.
+	if err := h.handle(ctx, "msg-2", []byte(msg)); err == nil || isRejected(err) {
+		t.Fatal("expected a transient error, got", err)
+	}
+	if err := h.handle(ctx, "msg-1", []byte(msg)); !isRejected(err) {
+		t.Fatal("expected the message to be rejected, got", err)
+	}
+	if _, ok := h.failures["msg-1"]; ok {
+		t.Error("expected the failures of the rejected message to be forgotten")
+	}
+
+	// A successful delivery resets the count.
+	w.errs = nil
+	if err := h.handle(ctx, "msg-2", []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.failures) != 0 {
+		t.Errorf("unexpected failures %v", h.failures)
+	}
+}
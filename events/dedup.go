@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Dedup is an event writer which drops events with an idempotency key it
+// already wrote, e.g. events replayed from a worker's event spool after
+// they were written, but their response was lost.
+//
+// Dedup remembers a fixed number of the most recent keys.
+type Dedup struct {
+	Writer Writer
+
+	mtx      sync.Mutex
+	seen     map[string]bool
+	inflight map[string]bool
+	keys     []string
+	next     int
+}
+
+// NewDedup returns a Dedup which writes to "w", remembering the last "size" keys.
+func NewDedup(w Writer, size int) *Dedup {
+	return &Dedup{
+		Writer:   w,
+		seen:     map[string]bool{},
+		inflight: map[string]bool{},
+		keys:     make([]string, size),
+	}
+}
+
+// WriteEvent writes the event to the underlying writer, unless an event with
+// the same idempotency key was already written. Events without a key are
+// always written.
+func (d *Dedup) WriteEvent(ctx context.Context, ev *Event) error {
+	key := ev.IdempotencyKey
+	if key == "" || len(d.keys) == 0 {
+		return d.Writer.WriteEvent(ctx, ev)
+	}
+
+	// The key is recorded as in flight before the event is written, so
+	// that a concurrent delivery of the same event isn't written too.
+	d.mtx.Lock()
+	if d.seen[key] || d.inflight[key] {
+		d.mtx.Unlock()
+		return nil
+	}
+	d.inflight[key] = true
+	d.mtx.Unlock()
+
+	err := d.Writer.WriteEvent(ctx, ev)
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	delete(d.inflight, key)
+	if err != nil {
+		return err
+	}
+	delete(d.seen, d.keys[d.next])
+	d.keys[d.next] = key
+	d.next = (d.next + 1) % len(d.keys)
+	d.seen[key] = true
+	return nil
+}
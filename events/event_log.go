@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// EventLog describes a database which stores the events written for each task.
+type EventLog interface {
+	// ListTaskEvents returns the logged events of the task, in the order
+	// they were written.
+	ListTaskEvents(ctx context.Context, taskID string) ([]*Event, error)
+}
+
+// Logged returns true if the event is stored in the task's event log.
+// Executor stdout and stderr events aren't, because each one holds the whole
+// tail of the output, which is kept in the task log.
+func Logged(ev *Event) bool {
+	return ev.Type != Type_EXECUTOR_STDOUT && ev.Type != Type_EXECUTOR_STDERR
+}
+
+// TaskEvents returns the events which recreate the task. If "l" isn't nil,
+// the logged events are returned, so that the task keeps its event log,
+// followed by the executor stdout and stderr, which aren't logged. Otherwise,
+// and for tasks created before events were logged, the events are derived
+// from the task by TaskHistory. The task should be in the full view.
+func TaskEvents(ctx context.Context, l EventLog, task *tes.Task) ([]*Event, error) {
+	history := TaskHistory(task)
+	if l == nil {
+		return history, nil
+	}
+
+	logged, err := l.ListTaskEvents(ctx, task.Id)
+	if err != nil {
+		return nil, fmt.Errorf("listing events of task %s: %v", task.Id, err)
+	}
+	if len(logged) == 0 || logged[0].Type != Type_TASK_CREATED {
+		return history, nil
+	}
+	for _, ev := range history {
+		if !Logged(ev) {
+			logged = append(logged, ev)
+		}
+	}
+	return logged, nil
+}
@@ -0,0 +1,187 @@
+package events
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// TaskHistory returns a sequence of events which rebuilds the task when
+// written to a database or a TaskBuilder. The task should be in the full view.
+//
+// The task is created in the QUEUED state and moved directly to its current
+// state, which is always a valid transition, before the task logs are written.
+// The events are timestamped with the times in the task logs, where they're known.
+func TaskHistory(task *tes.Task) []*Event {
+	created := proto.Clone(task).(*tes.Task)
+	created.State = tes.Queued
+	created.Logs = nil
+
+	evs := []*Event{{
+		Id:        task.Id,
+		Timestamp: timestamp(task.CreationTime),
+		Type:      Type_TASK_CREATED,
+		Data:      &Event_Task{Task: created},
+	}}
+	last := task.CreationTime
+
+	for i, tl := range task.Logs {
+		attempt := uint32(i)
+		add := func(ts string, ev *Event) {
+			if ts == "" {
+				ts = last
+			}
+			last = ts
+			ev.Id = task.Id
+			ev.Timestamp = timestamp(ts)
+			ev.Attempt = attempt
+			evs = append(evs, ev)
+		}
+
+		if tl.StartTime != "" {
+			add(tl.StartTime, &Event{
+				Type: Type_TASK_START_TIME,
+				Data: &Event_StartTime{StartTime: tl.StartTime},
+			})
+		}
+
+		for _, sl := range tl.SystemLogs {
+			ev := parseSysLog(sl)
+			add(ev.Timestamp, ev)
+		}
+
+		if len(tl.Metadata) > 0 {
+			add("", &Event{
+				Type: Type_TASK_METADATA,
+				Data: &Event_Metadata{Metadata: &Metadata{Value: tl.Metadata}},
+			})
+		}
+
+		for j, el := range tl.Logs {
+			index := uint32(j)
+			addExec := func(ts string, ev *Event) {
+				ev.Index = index
+				add(ts, ev)
+			}
+			if el.StartTime != "" {
+				addExec(el.StartTime, &Event{
+					Type: Type_EXECUTOR_START_TIME,
+					Data: &Event_StartTime{StartTime: el.StartTime},
+				})
+			}
+			if el.Stdout != "" {
+				addExec("", &Event{
+					Type: Type_EXECUTOR_STDOUT,
+					Data: &Event_Stdout{Stdout: el.Stdout},
+				})
+			}
+			if el.Stderr != "" {
+				addExec("", &Event{
+					Type: Type_EXECUTOR_STDERR,
+					Data: &Event_Stderr{Stderr: el.Stderr},
+				})
+			}
+			if el.EndTime != "" || el.ExitCode != 0 {
+				addExec(el.EndTime, &Event{
+					Type: Type_EXECUTOR_EXIT_CODE,
+					Data: &Event_ExitCode{ExitCode: el.ExitCode},
+				})
+			}
+			if el.EndTime != "" {
+				addExec(el.EndTime, &Event{
+					Type: Type_EXECUTOR_END_TIME,
+					Data: &Event_EndTime{EndTime: el.EndTime},
+				})
+			}
+		}
+
+		if len(tl.Outputs) > 0 {
+			add("", &Event{
+				Type: Type_TASK_OUTPUTS,
+				Data: &Event_Outputs{Outputs: &Outputs{Value: tl.Outputs}},
+			})
+		}
+
+		if tl.EndTime != "" {
+			add(tl.EndTime, &Event{
+				Type: Type_TASK_END_TIME,
+				Data: &Event_EndTime{EndTime: tl.EndTime},
+			})
+		}
+	}
+
+	// The state is set right after the task is created, so that a restored
+	// task isn't scheduled while it's briefly queued.
+	if task.State != tes.Queued && task.State != tes.Unknown {
+		var attempt uint32
+		if len(task.Logs) > 0 {
+			attempt = uint32(len(task.Logs) - 1)
+		}
+		state := &Event{
+			Id:        task.Id,
+			Timestamp: timestamp(last),
+			Type:      Type_TASK_STATE,
+			Attempt:   attempt,
+			Data:      &Event_State{State: task.State},
+		}
+		evs = append(evs[:1], append([]*Event{state}, evs[1:]...)...)
+	}
+	return evs
+}
+
+// timestamp returns the RFC 3339 time "ts", or the current time if "ts"
+// isn't set.
+func timestamp(ts string) string {
+	if ts == "" {
+		return time.Now().Format(time.RFC3339Nano)
+	}
+	return ts
+}
+
+// sysLogPart matches a "key='value'" part of a system log string,
+// where quotes in the value are escaped.
+var sysLogPart = regexp.MustCompile(`([^\s=]+)='((?:[^'\\]|\\.)*)'`)
+
+// parseSysLog parses a system log string, written by Event.SysLogString,
+// back into a system log event. A string which can't be parsed is kept
+// as the message of an info log.
+func parseSysLog(s string) *Event {
+	sl := &SystemLog{Fields: map[string]string{}}
+	ev := &Event{
+		Type: Type_SYSTEM_LOG,
+		Data: &Event_SystemLog{SystemLog: sl},
+	}
+
+	for _, m := range sysLogPart.FindAllStringSubmatch(s, -1) {
+		v := strings.Replace(m[2], "\\'", "'", -1)
+		switch m[1] {
+		case "level":
+			sl.Level = v
+		case "msg":
+			sl.Msg = v
+		case "timestamp":
+			ev.Timestamp = v
+		case "executor_index":
+			i, _ := strconv.ParseUint(v, 10, 32)
+			ev.Index = uint32(i)
+		case "task_attempt":
+			// The attempt is set by the task log the string is in.
+		default:
+			sl.Fields[m[1]] = v
+		}
+	}
+
+	if sl.Level == "" {
+		sl.Level = "info"
+		sl.Msg = s
+		sl.Fields = map[string]string{}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, ev.Timestamp); err != nil {
+		ev.Timestamp = ""
+	}
+	return ev
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+func historyTask() *tes.Task {
+	sl := NewSystemLog("task-1", 1, 0, "info", "it's running", map[string]string{"node": "node-1"})
+	return &tes.Task{
+		Id:           "task-1",
+		State:        tes.Complete,
+		Name:         "history",
+		CreationTime: "2018-01-15T12:00:00Z",
+		Tags:         map[string]string{"project": "a"},
+		Executors:    []*tes.Executor{{Image: "alpine", Command: []string{"echo", "hi"}}},
+		Logs: []*tes.TaskLog{
+			{
+				StartTime:  "2018-01-15T12:00:10Z",
+				EndTime:    "2018-01-15T12:00:20Z",
+				SystemLogs: []string{"worker crashed"},
+			},
+			{
+				StartTime:  "2018-01-15T12:01:00Z",
+				EndTime:    "2018-01-15T12:01:30Z",
+				Metadata:   map[string]string{"hostname": "node-1"},
+				Outputs:    []*tes.OutputFileLog{{Url: "file:///out", Path: "/out", SizeBytes: 3}},
+				SystemLogs: []string{sl.SysLogString()},
+				Logs: []*tes.ExecutorLog{
+					{
+						StartTime: "2018-01-15T12:01:05Z",
+						EndTime:   "2018-01-15T12:01:25Z",
+						Stdout:    "hi\n",
+						Stderr:    "oops\n",
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTaskHistory(t *testing.T) {
+	task := historyTask()
+	evs := TaskHistory(task)
+
+	if evs[0].Type != Type_TASK_CREATED || evs[0].GetTask().State != tes.Queued || evs[0].GetTask().Logs != nil {
+		t.Fatalf("expected a TASK_CREATED event in the QUEUED state, got %v", evs[0])
+	}
+	if evs[1].Type != Type_TASK_STATE || evs[1].GetState() != tes.Complete || evs[1].Attempt != 1 {
+		t.Fatalf("expected a TASK_STATE event for the last attempt, got %v", evs[1])
+	}
+
+	b := TaskBuilder{Task: proto.Clone(evs[0].GetTask()).(*tes.Task)}
+	for _, ev := range evs[1:] {
+		if ev.Id != task.Id || ev.Timestamp == "" {
+			t.Errorf("unexpected event %v", ev)
+		}
+		if err := b.WriteEvent(context.Background(), ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The unparsable system log is kept as a message.
+	if sl := b.Logs[0].SystemLogs[0]; sl != "level='info' msg='worker crashed' timestamp='2018-01-15T12:00:10Z' task_attempt='0' executor_index='0'" {
+		t.Errorf("unexpected system log %s", sl)
+	}
+	b.Logs[0].SystemLogs = task.Logs[0].SystemLogs
+
+	if !proto.Equal(b.Task, task) {
+		t.Errorf("expected %v, got %v", task, b.Task)
+	}
+}
+
+func TestParseSysLog(t *testing.T) {
+	ev := NewSystemLog("task-1", 2, 1, "error", "can't pull 'alpine'", map[string]string{
+		"error": "it's gone",
+	})
+	ev.Timestamp = "2018-01-15T12:00:10.5Z"
+
+	parsed := parseSysLog(ev.SysLogString())
+	if parsed.Timestamp != ev.Timestamp || parsed.Index != 1 {
+		t.Errorf("unexpected event %v", parsed)
+	}
+	if !proto.Equal(parsed.GetSystemLog(), ev.GetSystemLog()) {
+		t.Errorf("expected %v, got %v", ev.GetSystemLog(), parsed.GetSystemLog())
+	}
+}
+
+func TestSysLogStringFields(t *testing.T) {
+	ev := NewSystemLog("task-1", 0, 0, "info", "hello", map[string]string{
+		"c": "3", "a": "1", "b": "2", "d": "4",
+	})
+	ev.Timestamp = "2018-01-15T12:00:10.5Z"
+
+	expected := "level='info' msg='hello' timestamp='2018-01-15T12:00:10.5Z' task_attempt='0' executor_index='0' a='1' b='2' c='3' d='4'"
+	for i := 0; i < 10; i++ {
+		if s := ev.SysLogString(); s != expected {
+			t.Fatalf("expected %q, got %q", expected, s)
+		}
+	}
+}
+
+func TestArchive(t *testing.T) {
+	a := historyTask()
+	b := historyTask()
+	b.Id = "task-2"
+	b.State = tes.Queued
+	b.Logs = nil
+
+	var buf bytes.Buffer
+	if err := WriteArchive(context.Background(), &buf, nil, []*tes.Task{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != len(TaskHistory(a))+1 {
+		t.Errorf("expected one event per line, got %d lines", n)
+	}
+
+	var ids []string
+	err := ReadArchive(&buf, func(id string, evs []*Event) error {
+		ids = append(ids, id)
+		if evs[0].Type != Type_TASK_CREATED || evs[0].GetTask().Id != id {
+			t.Errorf("unexpected first event %v", evs[0])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"task-1", "task-2"}) {
+		t.Errorf("unexpected tasks %v", ids)
+	}
+
+	err = ReadArchive(bytes.NewBufferString("{}\nnot json\n"), func(string, []*Event) error { return nil })
+	if err == nil {
+		t.Error("expected an error for an invalid line")
+	}
+}
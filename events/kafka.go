@@ -2,9 +2,14 @@ package events
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
 )
 
 // KafkaWriter writes events to a Kafka topic.
@@ -26,15 +31,9 @@ func NewKafkaWriter(ctx context.Context, conf config.Kafka) (*KafkaWriter, error
 	return &KafkaWriter{conf, producer}, nil
 }
 
-// WriteEvent writes the event. Events may be sent in batches in the background by the
-// Kafka client library. Currently stdout, stderr, and system log events are dropped.
+// WriteEvent writes the event. Events are keyed by task ID, so all events
+// of a task land in the same partition and are consumed in order.
 func (k *KafkaWriter) WriteEvent(ctx context.Context, ev *Event) error {
-
-	switch ev.Type {
-	case Type_EXECUTOR_STDOUT, Type_EXECUTOR_STDERR, Type_SYSTEM_LOG:
-		return nil
-	}
-
 	s, err := Marshal(ev)
 	if err != nil {
 		return err
@@ -42,50 +41,445 @@ func (k *KafkaWriter) WriteEvent(ctx context.Context, ev *Event) error {
 
 	msg := &sarama.ProducerMessage{
 		Topic: k.conf.Topic,
-		Key:   nil,
+		Key:   sarama.StringEncoder(ev.Id),
 		Value: sarama.StringEncoder(s),
 	}
 	_, _, err = k.producer.SendMessage(msg)
 	return err
 }
 
-// KafkaReader reads events to a Kafka topic and writes them
-// to a Writer.
-type KafkaReader struct {
-	conf config.Kafka
-	con  sarama.Consumer
-	pcon sarama.PartitionConsumer
+const (
+	kafkaSessionTimeout = time.Second * 30
+	// kafkaCommitInterval is how often the consumer commits offsets
+	// and sends a heartbeat to the group coordinator.
+	kafkaCommitInterval = time.Second
+	kafkaRejoinInterval = time.Second * 5
+)
+
+// KafkaConsumer reads events from a Kafka topic and writes them to a Writer,
+// as a member of the consumer group configured by conf.Group.
+//
+// The group's partitions are split between its members. Offsets are committed
+// to the group after events are written, so a restarted consumer resumes where
+// the group left off. Messages which can never be written (invalid JSON,
+// unknown tasks, invalid state transitions) are sent to conf.DeadLetterTopic.
+type KafkaConsumer struct {
+	conf       config.Kafka
+	handler    *messageHandler
+	log        *logger.Logger
+	client     sarama.Client
+	consumer   sarama.Consumer
+	deadLetter sarama.SyncProducer
+	memberID   string
 }
 
-// NewKafkaReader creates a new event reader for reading events from a Kafka topic and writing them to the given Writer.
-func NewKafkaReader(ctx context.Context, conf config.Kafka, w Writer) (*KafkaReader, error) {
-	con, err := sarama.NewConsumer(conf.Servers, nil)
+// NewKafkaConsumer creates a new consumer which reads events from the
+// configured Kafka topic and writes them to "w".
+func NewKafkaConsumer(conf config.Kafka, w Writer, log *logger.Logger) (*KafkaConsumer, error) {
+	if conf.Group == "" {
+		return nil, fmt.Errorf("Kafka.Group is required to consume events")
+	}
+
+	cfg := sarama.NewConfig()
+	cfg.ClientID = "funnel"
+	// Group membership requires Kafka 0.9
+	cfg.Version = sarama.V0_9_0_0
+	cfg.Consumer.Return.Errors = true
+	cfg.Producer.Return.Successes = true
+
+	client, err := sarama.NewClient(conf.Servers, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO better handling of partition and offset.
-	p, err := con.ConsumePartition(conf.Topic, 0, sarama.OffsetNewest)
+	consumer, err := sarama.NewConsumerFromClient(client)
 	if err != nil {
+		client.Close()
 		return nil, err
 	}
 
-	go func() {
-		for msg := range p.Messages() {
-			ev := &Event{}
-			err := Unmarshal(msg.Value, ev)
+	k := &KafkaConsumer{
+		conf:     conf,
+		handler:  newMessageHandler(w, log),
+		log:      log,
+		client:   client,
+		consumer: consumer,
+	}
+
+	if conf.DeadLetterTopic != "" {
+		k.deadLetter, err = sarama.NewSyncProducerFromClient(client)
+		if err != nil {
+			consumer.Close()
+			client.Close()
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// Run consumes events until the context is canceled, rejoining the group
+// whenever a session ends, e.g. on a rebalance or a failed write.
+func (k *KafkaConsumer) Run(ctx context.Context) error {
+	defer k.close()
+
+	for {
+		err := k.session(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		k.log.Error("kafka consumer session ended; rejoining group",
+			"error", err, "group", k.conf.Group, "topic", k.conf.Topic)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(kafkaRejoinInterval):
+		}
+	}
+}
+
+func (k *KafkaConsumer) close() {
+	if k.deadLetter != nil {
+		k.deadLetter.Close()
+	}
+	k.consumer.Close()
+	k.client.Close()
+}
+
+// session joins the group and consumes the assigned partitions until
+// the context is canceled or an error occurs.
+func (k *KafkaConsumer) session(ctx context.Context) error {
+	coord, err := k.client.Coordinator(k.conf.Group)
+	if err != nil {
+		return err
+	}
+
+	gen, assigned, err := k.join(coord)
+	if err != nil {
+		k.client.RefreshCoordinator(k.conf.Group)
+		return err
+	}
+	k.log.Info("joined kafka consumer group",
+		"group", k.conf.Group, "member", k.memberID, "generation", gen, "partitions", assigned)
+
+	offsets, err := k.fetchOffsets(coord, assigned)
+	if err != nil {
+		return err
+	}
+
+	s := &kafkaSession{
+		KafkaConsumer: k,
+		coord:         coord,
+		generation:    gen,
+		offsets:       map[int32]int64{},
+		committed:     map[int32]int64{},
+	}
+	return s.run(ctx, offsets)
+}
+
+// join joins the consumer group and returns the generation and
+// the partitions assigned to this member.
+func (k *KafkaConsumer) join(coord *sarama.Broker) (int32, []int32, error) {
+	join := &sarama.JoinGroupRequest{
+		GroupId:        k.conf.Group,
+		SessionTimeout: int32(kafkaSessionTimeout / time.Millisecond),
+		MemberId:       k.memberID,
+		ProtocolType:   "consumer",
+	}
+	meta := &sarama.ConsumerGroupMemberMetadata{Topics: []string{k.conf.Topic}}
+	if err := join.AddGroupProtocolMetadata("range", meta); err != nil {
+		return 0, nil, err
+	}
+
+	jres, err := coord.JoinGroup(join)
+	if err != nil {
+		return 0, nil, err
+	}
+	if jres.Err == sarama.ErrUnknownMemberId {
+		k.memberID = ""
+	}
+	if jres.Err != sarama.ErrNoError {
+		return 0, nil, jres.Err
+	}
+	k.memberID = jres.MemberId
+
+	syncReq := &sarama.SyncGroupRequest{
+		GroupId:      k.conf.Group,
+		GenerationId: jres.GenerationId,
+		MemberId:     k.memberID,
+	}
+
+	// The group leader assigns partitions to all members.
+	if jres.LeaderId == jres.MemberId {
+		var members []string
+		for id := range jres.Members {
+			members = append(members, id)
+		}
+		partitions, err := k.client.Partitions(k.conf.Topic)
+		if err != nil {
+			return 0, nil, err
+		}
+		for id, parts := range assignPartitions(members, partitions) {
+			err := syncReq.AddGroupAssignmentMember(id, &sarama.ConsumerGroupMemberAssignment{
+				Topics: map[string][]int32{k.conf.Topic: parts},
+			})
 			if err != nil {
-				// TODO
-				continue
+				return 0, nil, err
 			}
-			w.WriteEvent(context.Background(), ev)
 		}
-	}()
+	}
 
-	go func() {
-		<-ctx.Done()
-		con.Close()
-		p.Close()
-	}()
-	return &KafkaReader{conf, con, p}, nil
+	sres, err := coord.SyncGroup(syncReq)
+	if err != nil {
+		return 0, nil, err
+	}
+	if sres.Err != sarama.ErrNoError {
+		return 0, nil, sres.Err
+	}
+
+	// Members without partitions may receive an empty assignment.
+	if len(sres.MemberAssignment) == 0 {
+		return jres.GenerationId, nil, nil
+	}
+	assignment, err := sres.GetMemberAssignment()
+	if err != nil {
+		return 0, nil, err
+	}
+	return jres.GenerationId, assignment.Topics[k.conf.Topic], nil
+}
+
+// assignPartitions distributes the partitions between the members round-robin.
+// Every member is included in the result, possibly with no partitions.
+func assignPartitions(members []string, partitions []int32) map[string][]int32 {
+	sort.Strings(members)
+	parts := append([]int32{}, partitions...)
+	sort.Slice(parts, func(i, j int) bool { return parts[i] < parts[j] })
+
+	out := map[string][]int32{}
+	for _, m := range members {
+		out[m] = []int32{}
+	}
+	if len(members) == 0 {
+		return out
+	}
+	for i, p := range parts {
+		m := members[i%len(members)]
+		out[m] = append(out[m], p)
+	}
+	return out
+}
+
+// fetchOffsets returns the group's committed offsets for the partitions.
+// Partitions without a committed offset start from the oldest message.
+func (k *KafkaConsumer) fetchOffsets(coord *sarama.Broker, partitions []int32) (map[int32]int64, error) {
+	offsets := map[int32]int64{}
+	if len(partitions) == 0 {
+		return offsets, nil
+	}
+
+	req := &sarama.OffsetFetchRequest{ConsumerGroup: k.conf.Group, Version: 1}
+	for _, p := range partitions {
+		req.AddPartition(k.conf.Topic, p)
+	}
+	res, err := coord.FetchOffset(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range partitions {
+		block := res.GetBlock(k.conf.Topic, p)
+		if block == nil {
+			return nil, fmt.Errorf("missing offset for partition %d", p)
+		}
+		if block.Err != sarama.ErrNoError {
+			return nil, block.Err
+		}
+		offsets[p] = block.Offset
+		if block.Offset < 0 {
+			offsets[p] = sarama.OffsetOldest
+		}
+	}
+	return offsets, nil
+}
+
+// kafkaSession tracks the offsets consumed in one generation of the group.
+type kafkaSession struct {
+	*KafkaConsumer
+	coord      *sarama.Broker
+	generation int32
+
+	mtx       sync.Mutex
+	offsets   map[int32]int64
+	committed map[int32]int64
+}
+
+func (s *kafkaSession) run(ctx context.Context, offsets map[int32]int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errc := make(chan error, len(offsets))
+
+	// Stop the partition consumers and commit what they wrote.
+	// Leave the group only on shutdown, so that the coordinator
+	// doesn't wait for the session to time out.
+	stop := func(leave bool) {
+		cancel()
+		wg.Wait()
+		if err := s.commit(); err != nil {
+			s.log.Error("error committing kafka offsets", "error", err, "group", s.conf.Group)
+		}
+		if leave {
+			s.coord.LeaveGroup(&sarama.LeaveGroupRequest{
+				GroupId:  s.conf.Group,
+				MemberId: s.memberID,
+			})
+		}
+	}
+
+	for p, offset := range offsets {
+		pc, err := s.consumer.ConsumePartition(s.conf.Topic, p, offset)
+		if err == sarama.ErrOffsetOutOfRange {
+			// The committed offset was deleted by the topic's retention.
+			pc, err = s.consumer.ConsumePartition(s.conf.Topic, p, sarama.OffsetOldest)
+		}
+		if err != nil {
+			stop(false)
+			return err
+		}
+
+		wg.Add(1)
+		go func(pc sarama.PartitionConsumer) {
+			defer wg.Done()
+			defer pc.Close()
+			if err := s.consumePartition(ctx, pc); err != nil {
+				errc <- err
+			}
+		}(pc)
+	}
+
+	ticker := time.NewTicker(kafkaCommitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			stop(true)
+			return nil
+
+		case err := <-errc:
+			stop(false)
+			return err
+
+		case <-ticker.C:
+			if err := s.commit(); err != nil {
+				stop(false)
+				return err
+			}
+			if err := s.heartbeat(); err != nil {
+				stop(false)
+				return err
+			}
+		}
+	}
+}
+
+func (s *kafkaSession) consumePartition(ctx context.Context, pc sarama.PartitionConsumer) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err := <-pc.Errors():
+			// The partition consumer retries on its own.
+			s.log.Error("error consuming kafka partition",
+				"error", err.Err, "topic", err.Topic, "partition", err.Partition)
+
+		case msg := <-pc.Messages():
+			id := fmt.Sprintf("%s/%d/%d", msg.Topic, msg.Partition, msg.Offset)
+			err := s.handler.handle(ctx, id, msg.Value)
+			if isRejected(err) {
+				err = s.reject(msg, err)
+			}
+			if err != nil {
+				return err
+			}
+			s.mark(msg.Partition, msg.Offset+1)
+		}
+	}
+}
+
+// reject sends a message which can't be written to the dead letter topic.
+func (s *kafkaSession) reject(msg *sarama.ConsumerMessage, reason error) error {
+	s.log.Error("rejected kafka message",
+		"error", reason, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset,
+		"dead_letter_topic", s.conf.DeadLetterTopic)
+
+	if s.deadLetter == nil {
+		return nil
+	}
+	_, _, err := s.deadLetter.SendMessage(&sarama.ProducerMessage{
+		Topic: s.conf.DeadLetterTopic,
+		Key:   sarama.ByteEncoder(msg.Key),
+		Value: sarama.ByteEncoder(msg.Value),
+	})
+	return err
+}
+
+// mark records the offset of the next message to consume from the partition.
+func (s *kafkaSession) mark(partition int32, offset int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.offsets[partition] = offset
+}
+
+// commit commits the offsets marked since the last commit.
+func (s *kafkaSession) commit() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	req := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           s.conf.Group,
+		ConsumerGroupGeneration: s.generation,
+		ConsumerID:              s.memberID,
+		RetentionTime:           -1,
+	}
+	n := 0
+	for p, offset := range s.offsets {
+		if s.committed[p] != offset {
+			req.AddBlock(s.conf.Topic, p, offset, 0, "")
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	res, err := s.coord.CommitOffset(req)
+	if err != nil {
+		return err
+	}
+	for p, offset := range s.offsets {
+		if kerr := res.Errors[s.conf.Topic][p]; kerr != sarama.ErrNoError {
+			return kerr
+		}
+		s.committed[p] = offset
+	}
+	return nil
+}
+
+func (s *kafkaSession) heartbeat() error {
+	res, err := s.coord.Heartbeat(&sarama.HeartbeatRequest{
+		GroupId:      s.conf.Group,
+		GenerationId: s.generation,
+		MemberId:     s.memberID,
+	})
+	if err != nil {
+		return err
+	}
+	if res.Err != sarama.ErrNoError {
+		return res.Err
+	}
+	return nil
 }
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssignPartitions(t *testing.T) {
+	got := assignPartitions([]string{"b", "c", "a"}, []int32{4, 0, 3, 1, 2})
+	expected := map[string][]int32{
+		"a": {0, 3},
+		"b": {1, 4},
+		"c": {2},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected assignment: %v", got)
+	}
+
+	// Members without partitions still get an (empty) assignment.
+	got = assignPartitions([]string{"a", "b"}, []int32{0})
+	expected = map[string][]int32{
+		"a": {0},
+		"b": {},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected assignment: %v", got)
+	}
+}
@@ -2,9 +2,11 @@ package events
 
 import (
 	"context"
+	"fmt"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
 	oldctx "golang.org/x/net/context"
 	"google.golang.org/api/option"
 )
@@ -18,30 +20,16 @@ type PubSubWriter struct {
 //
 // The given context is used to shut down the Pub/Sub client and flush
 // any buffered messages.
-//
-// Stdout, stderr, and system log events are not sent.
 func NewPubSubWriter(ctx context.Context, conf config.PubSub) (*PubSubWriter, error) {
-	opts := []option.ClientOption{}
-	if conf.CredentialsFile != "" {
-		opts = append(opts, option.WithCredentialsFile(conf.CredentialsFile))
-	}
-
-	client, err := pubsub.NewClient(ctx, conf.Project, opts...)
+	client, err := newPubSubClient(ctx, conf)
 	if err != nil {
 		return nil, err
 	}
 
-	topic := client.Topic(conf.Topic)
-	ok, err := topic.Exists(ctx)
+	topic, err := ensurePubSubTopic(ctx, client, conf.Topic)
 	if err != nil {
 		return nil, err
 	}
-	if !ok {
-		topic, err = client.CreateTopic(ctx, conf.Topic)
-		if err != nil {
-			return nil, err
-		}
-	}
 
 	go func() {
 		<-ctx.Done()
@@ -52,13 +40,7 @@ func NewPubSubWriter(ctx context.Context, conf config.PubSub) (*PubSubWriter, er
 
 // WriteEvent writes an event to the configured Pub/Sub topic.
 // Events are buffered and sent in batches by a background routine.
-// Stdout, stderr, and system log events are not sent.
 func (p *PubSubWriter) WriteEvent(ctx context.Context, ev *Event) error {
-	switch ev.Type {
-	case Type_EXECUTOR_STDOUT, Type_EXECUTOR_STDERR, Type_SYSTEM_LOG:
-		return nil
-	}
-
 	s, err := Marshal(ev)
 	if err != nil {
 		return err
@@ -70,40 +52,145 @@ func (p *PubSubWriter) WriteEvent(ctx context.Context, ev *Event) error {
 	return nil
 }
 
-// ReadPubSub reads events from the topic configured by "conf".
-// The subscription "subname" will be created if it doesn't exist.
-// This blocks until the context is canceled.
-func ReadPubSub(ctx context.Context, conf config.PubSub, subname string, w Writer) error {
-	cl, err := pubsub.NewClient(ctx, conf.Project)
+// PubSubConsumer reads events from a Pub/Sub subscription and writes them
+// to a Writer. Servers sharing the subscription split the messages between them.
+//
+// Messages are acknowledged after their event is written, and redelivered
+// by Pub/Sub if writing fails. Messages which can never be written (invalid
+// JSON, unknown tasks, invalid state transitions) are published to
+// conf.DeadLetterTopic, with the reason in the "error" attribute.
+//
+// Messages are handled one at a time, in the order they're delivered.
+// Pub/Sub doesn't guarantee that order, so a task's state events may arrive
+// out of order; a state event which would move a task back to an earlier
+// state is rejected.
+type PubSubConsumer struct {
+	conf       config.PubSub
+	handler    *messageHandler
+	log        *logger.Logger
+	client     *pubsub.Client
+	sub        *pubsub.Subscription
+	deadLetter *pubsub.Topic
+}
+
+// NewPubSubConsumer creates a new consumer which reads events from the
+// configured subscription and writes them to "w". The subscription
+// is created if it doesn't exist.
+func NewPubSubConsumer(ctx context.Context, conf config.PubSub, w Writer, log *logger.Logger) (*PubSubConsumer, error) {
+	if conf.Subscription == "" {
+		return nil, fmt.Errorf("PubSub.Subscription is required to consume events")
+	}
+
+	client, err := newPubSubClient(ctx, conf)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	sub := cl.Subscription(subname)
+	sub := client.Subscription(conf.Subscription)
 	ok, err := sub.Exists(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !ok {
-		topic := cl.Topic(conf.Topic)
-
-		sub, err = cl.CreateSubscription(ctx, subname, pubsub.SubscriptionConfig{
+		topic, err := ensurePubSubTopic(ctx, client, conf.Topic)
+		if err != nil {
+			return nil, err
+		}
+		sub, err = client.CreateSubscription(ctx, conf.Subscription, pubsub.SubscriptionConfig{
 			Topic: topic,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	sub.Receive(ctx, func(ctx oldctx.Context, m *pubsub.Message) {
-		ev := &Event{}
-		err := Unmarshal(m.Data, ev)
+	sub.ReceiveSettings.NumGoroutines = 1
+	sub.ReceiveSettings.MaxOutstandingMessages = 1
+
+	p := &PubSubConsumer{
+		conf:    conf,
+		handler: newMessageHandler(w, log),
+		log:     log,
+		client:  client,
+		sub:     sub,
+	}
+
+	if conf.DeadLetterTopic != "" {
+		p.deadLetter, err = ensurePubSubTopic(ctx, client, conf.DeadLetterTopic)
 		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Run consumes events until the context is canceled.
+func (p *PubSubConsumer) Run(ctx context.Context) error {
+	defer p.client.Close()
+	if p.deadLetter != nil {
+		defer p.deadLetter.Stop()
+	}
+
+	err := p.sub.Receive(ctx, func(ctx oldctx.Context, m *pubsub.Message) {
+		err := p.handler.handle(ctx, m.ID, m.Data)
+		if isRejected(err) {
+			err = p.reject(ctx, m, err)
+		}
+		if err != nil {
+			p.log.Error("error consuming pubsub message; it will be redelivered",
+				"error", err, "subscription", p.conf.Subscription, "id", m.ID)
+			m.Nack()
 			return
 		}
-		w.WriteEvent(context.Background(), ev)
 		m.Ack()
 	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
 
-	return nil
+// reject publishes a message which can't be written to the dead letter topic.
+func (p *PubSubConsumer) reject(ctx context.Context, m *pubsub.Message, reason error) error {
+	p.log.Error("rejected pubsub message",
+		"error", reason, "subscription", p.conf.Subscription, "id", m.ID,
+		"dead_letter_topic", p.conf.DeadLetterTopic)
+
+	if p.deadLetter == nil {
+		return nil
+	}
+	attrs := map[string]string{
+		"error":        reason.Error(),
+		"subscription": p.conf.Subscription,
+		"message_id":   m.ID,
+	}
+	for k, v := range m.Attributes {
+		attrs[k] = v
+	}
+	_, err := p.deadLetter.Publish(ctx, &pubsub.Message{
+		Data:       m.Data,
+		Attributes: attrs,
+	}).Get(ctx)
+	return err
+}
+
+func newPubSubClient(ctx context.Context, conf config.PubSub) (*pubsub.Client, error) {
+	opts := []option.ClientOption{}
+	if conf.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(conf.CredentialsFile))
+	}
+	return pubsub.NewClient(ctx, conf.Project, opts...)
+}
+
+// ensurePubSubTopic returns the topic, creating it if it doesn't exist.
+func ensurePubSubTopic(ctx context.Context, client *pubsub.Client, id string) (*pubsub.Topic, error) {
+	topic := client.Topic(id)
+	ok, err := topic.Exists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return client.CreateTopic(ctx, id)
+	}
+	return topic, nil
 }
@@ -1,10 +1,21 @@
 package events
 
-import "golang.org/x/net/context"
+import (
+	"fmt"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
 
 // Service is a wrapper for providing a Writer as a gRPC service.
 type Service struct {
 	Writer
+	// Log and Tasks provide the ListTaskEvents endpoint. If Log is nil,
+	// the endpoint returns an Unimplemented error.
+	Log   EventLog
+	Tasks tes.ReadOnlyServer
 }
 
 // WriteEvent accepts an RPC call and writes the event to the underlying server.
@@ -13,3 +24,46 @@ type Service struct {
 func (s *Service) WriteEvent(ctx context.Context, e *Event) (*WriteEventResponse, error) {
 	return &WriteEventResponse{}, s.Writer.WriteEvent(ctx, e)
 }
+
+// ListTaskEvents returns the event log of a task, filtered by type.
+func (s *Service) ListTaskEvents(ctx context.Context, req *ListTaskEventsRequest) (*ListTaskEventsResponse, error) {
+	if s.Log == nil {
+		return nil, grpc.Errorf(codes.Unimplemented, "the database doesn't store task events")
+	}
+
+	evs, err := s.Log.ListTaskEvents(ctx, req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	// The log of a task created before events were logged is empty,
+	// so an empty log doesn't mean that the task doesn't exist.
+	if len(evs) == 0 && s.Tasks != nil {
+		_, err := s.Tasks.GetTask(ctx, &tes.GetTaskRequest{Id: req.TaskId, View: tes.TaskView_MINIMAL})
+		if err == tes.ErrNotFound {
+			return nil, grpc.Errorf(codes.NotFound, "%v: taskID: %s", err, req.TaskId)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting task: %v", err)
+		}
+	}
+
+	resp := &ListTaskEventsResponse{}
+	for _, ev := range evs {
+		if matchesTypes(ev, req.Types) {
+			resp.Events = append(resp.Events, ev)
+		}
+	}
+	return resp, nil
+}
+
+func matchesTypes(ev *Event, types []Type) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if ev.Type == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memLog is an in-memory event log.
+type memLog map[string][]*Event
+
+func (m memLog) WriteEvent(ctx context.Context, ev *Event) error {
+	m[ev.Id] = append(m[ev.Id], ev)
+	return nil
+}
+
+func (m memLog) ListTaskEvents(ctx context.Context, id string) ([]*Event, error) {
+	return m[id], nil
+}
+
+// memTasks holds tasks by ID.
+type memTasks map[string]*tes.Task
+
+func (m memTasks) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.Task, error) {
+	task, ok := m[req.Id]
+	if !ok {
+		return nil, tes.ErrNotFound
+	}
+	return task, nil
+}
+
+func (m memTasks) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
+	return &tes.ListTasksResponse{}, nil
+}
+
+func TestListTaskEvents(t *testing.T) {
+	ctx := context.Background()
+	log := memLog{}
+	s := &Service{
+		Writer: log,
+		Log:    log,
+		Tasks:  memTasks{"task-1": {Id: "task-1"}, "task-2": {Id: "task-2"}},
+	}
+	for _, ev := range TaskHistory(historyTask()) {
+		if _, err := s.WriteEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := s.ListTaskEvents(ctx, &ListTaskEventsRequest{TaskId: "task-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != len(log["task-1"]) {
+		t.Errorf("expected %d events, got %d", len(log["task-1"]), len(resp.Events))
+	}
+
+	resp, err = s.ListTaskEvents(ctx, &ListTaskEventsRequest{
+		TaskId: "task-1",
+		Types:  []Type{Type_TASK_STATE, Type_TASK_START_TIME},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != 3 {
+		t.Errorf("expected 3 events, got %v", resp.Events)
+	}
+	for _, ev := range resp.Events {
+		if ev.Type != Type_TASK_STATE && ev.Type != Type_TASK_START_TIME {
+			t.Errorf("unexpected event %v", ev)
+		}
+	}
+
+	// A task without logged events has an empty log.
+	resp, err = s.ListTaskEvents(ctx, &ListTaskEventsRequest{TaskId: "task-2"})
+	if err != nil || len(resp.Events) != 0 {
+		t.Errorf("expected no events, got %v: %v", resp, err)
+	}
+
+	_, err = s.ListTaskEvents(ctx, &ListTaskEventsRequest{TaskId: "task-3"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Error("expected a NotFound error, got", err)
+	}
+
+	s.Log = nil
+	_, err = s.ListTaskEvents(ctx, &ListTaskEventsRequest{TaskId: "task-1"})
+	if st, _ := status.FromError(err); st.Code() != codes.Unimplemented {
+		t.Error("expected an Unimplemented error, got", err)
+	}
+}
@@ -0,0 +1,328 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"github.com/ohsu-comp-bio/funnel/util/fsutil"
+)
+
+// spoolMarshaler writes one event per line.
+var spoolMarshaler = &jsonpb.Marshaler{}
+
+// replayedMark overwrites the first byte of an event in a spool file once
+// it's replayed.
+const replayedMark = '#'
+
+// maxSpoolFiles limits the number of spool files of a writer, i.e. the number
+// of workers which can spool events to the same directory at once.
+const maxSpoolFiles = 1000
+
+// Spool is an event writer which buffers events in an append-only file on disk
+// when the underlying writer fails, e.g. while the server is unreachable, and
+// replays them in order once the writer works again.
+//
+// Each spool locks its own file in the spool directory, e.g. "rpc-0.spool",
+// which is kept when the worker exits. When a spool is opened, it replays the
+// events left in its file, and in the unlocked files of the same writer, e.g.
+// by a worker which crashed or exited before its events were written.
+//
+// Replayed events are marked in the file, so that after a crash, at most the
+// event being replayed is written again. Events are given an idempotency key
+// before they're first written, so that duplicates can be dropped, e.g. an
+// event which was written, but whose response was lost, and which is replayed
+// from the spool.
+type Spool struct {
+	writer Writer
+	rate   time.Duration
+	log    *logger.Logger
+
+	mtx  sync.Mutex
+	file *os.File
+	// Events from offset up to size haven't been replayed yet.
+	offset int64
+	size   int64
+}
+
+// NewSpool returns a Spool which writes events to "w", buffering them in the
+// first unlocked "<name>-<n>.spool" file in "dir". Spooled events are replayed
+// every "rate", until "ctx" is done.
+func NewSpool(ctx context.Context, w Writer, dir, name string, rate time.Duration, log *logger.Logger) (*Spool, error) {
+	if err := fsutil.EnsureDir(dir); err != nil {
+		return nil, fmt.Errorf("creating event spool directory: %v", err)
+	}
+
+	s := &Spool{writer: w, rate: rate, log: log}
+	for i := 0; i < maxSpoolFiles && s.file == nil; i++ {
+		f, err := lockSpoolFile(filepath.Join(dir, fmt.Sprintf("%s-%d.spool", name, i)))
+		if err != nil {
+			return nil, fmt.Errorf("opening event spool: %v", err)
+		}
+		s.file = f
+	}
+	if s.file == nil {
+		return nil, fmt.Errorf("opening event spool: all %d %s spool files in %s are in use", maxSpoolFiles, name, dir)
+	}
+	if err := s.load(); err != nil {
+		s.file.Close()
+		return nil, fmt.Errorf("opening event spool %s: %v", s.file.Name(), err)
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, name+"-*.spool"))
+	for _, path := range paths {
+		if path == s.file.Name() {
+			continue
+		}
+		if err := s.adopt(path); err != nil {
+			s.log.Error("Error reading event spool", "error", err, "path", path)
+		}
+	}
+
+	go s.retry(ctx)
+	return s, nil
+}
+
+// lockSpoolFile opens and locks the spool file at "path", creating it if needed.
+// If the file is locked by another spool, nil is returned.
+func lockSpoolFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		f.Close()
+		return nil, nil
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// WriteEvent writes the event to the underlying writer. If that fails, or earlier
+// events are still spooled, the event is appended to the spool file instead.
+// An error is returned only if the event couldn't be spooled.
+func (s *Spool) WriteEvent(ctx context.Context, ev *Event) error {
+	if ev.IdempotencyKey == "" {
+		ev.IdempotencyKey = tes.GenerateID()
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// Events are written in order, so once an event is spooled,
+	// the following events are spooled too, until the spool is replayed.
+	if s.pending() {
+		s.replay(ctx)
+	}
+	if !s.pending() {
+		err := s.writer.WriteEvent(ctx, ev)
+		if err == nil {
+			return nil
+		}
+		s.log.Info("Error writing event, spooling it", "error", err, "path", s.file.Name())
+	}
+	return s.append(ev)
+}
+
+// Flush replays the spooled events, retrying every "rate" until they're
+// all written or "ctx" is done.
+func (s *Spool) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(s.rate)
+	defer ticker.Stop()
+
+	for {
+		s.mtx.Lock()
+		err := s.replay(ctx)
+		s.mtx.Unlock()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("replaying event spool %s: %v", s.file.Name(), err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes and unlocks the spool file. Events which weren't replayed are
+// left in the file, and are replayed by the next spool which opens it.
+func (s *Spool) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.pending() {
+		s.log.Error("Closing event spool with events which weren't written", "path", s.file.Name())
+	}
+	return s.file.Close()
+}
+
+// retry replays the spooled events every "rate" until "ctx" is done,
+// so that they're written even if no new events arrive.
+func (s *Spool) retry(ctx context.Context) {
+	ticker := time.NewTicker(s.rate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mtx.Lock()
+			if s.pending() && s.replay(ctx) == nil {
+				s.log.Info("Replayed spooled events", "path", s.file.Name())
+			}
+			s.mtx.Unlock()
+		}
+	}
+}
+
+func (s *Spool) pending() bool {
+	return s.offset < s.size
+}
+
+// load finds the events in the spool file which weren't replayed yet.
+func (s *Spool) load() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(s.file, 0, info.Size()))
+	s.offset, s.size = 0, 0
+	for {
+		// An event which was being appended when the worker crashed is
+		// incomplete, and is dropped.
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if s.offset == s.size && line[0] == replayedMark {
+			s.offset += int64(len(line))
+		}
+		s.size += int64(len(line))
+	}
+
+	if !s.pending() {
+		s.offset, s.size = 0, 0
+	}
+	if s.size < info.Size() {
+		return s.file.Truncate(s.size)
+	}
+	return nil
+}
+
+// adopt moves the events which weren't replayed from the spool file at "path"
+// to this spool, unless the file is locked by another spool.
+func (s *Spool) adopt(path string) error {
+	f, err := lockSpoolFile(path)
+	if err != nil || f == nil {
+		return err
+	}
+	defer f.Close()
+
+	other := &Spool{file: f}
+	if err := other.load(); err != nil {
+		return err
+	}
+	if !other.pending() {
+		return nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, other.offset, other.size-other.offset))
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if line[0] == replayedMark {
+			continue
+		}
+		if err := s.appendLine(line); err != nil {
+			return err
+		}
+	}
+	s.log.Info("Adopted spooled events", "path", path, "spool", s.file.Name())
+	return f.Truncate(0)
+}
+
+// append writes the event to the end of the spool file.
+func (s *Spool) append(ev *Event) error {
+	b, err := spoolMarshaler.MarshalToString(ev)
+	if err != nil {
+		return fmt.Errorf("spooling event: %v", err)
+	}
+	return s.appendLine(append([]byte(b), '\n'))
+}
+
+// appendLine writes a spooled event to the end of the spool file.
+func (s *Spool) appendLine(line []byte) error {
+	if _, err := s.file.WriteAt(line, s.size); err != nil {
+		return fmt.Errorf("spooling event: %v", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("spooling event: %v", err)
+	}
+	s.size += int64(len(line))
+	return nil
+}
+
+// replay writes the spooled events to the underlying writer, in order, stopping
+// at the first error. Each written event is marked as replayed, and the spool
+// file is truncated once all the events are written.
+func (s *Spool) replay(ctx context.Context) error {
+	r := bufio.NewReader(io.NewSectionReader(s.file, s.offset, s.size-s.offset))
+	for s.pending() {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("reading event spool: %v", err)
+		}
+
+		// Events marked as replayed were written before the worker crashed.
+		if line[0] != replayedMark {
+			ev := &Event{}
+			if err := Unmarshal(line, ev); err != nil {
+				s.log.Error("Dropping spooled event which can't be read", "error", err, "path", s.file.Name())
+			} else if err := s.writer.WriteEvent(ctx, ev); err != nil {
+				return err
+			} else if err := s.markReplayed(); err != nil {
+				return err
+			}
+		}
+		s.offset += int64(len(line))
+	}
+
+	s.offset, s.size = 0, 0
+	return s.file.Truncate(0)
+}
+
+// markReplayed marks the event at the current offset as replayed.
+func (s *Spool) markReplayed() error {
+	if _, err := s.file.WriteAt([]byte{replayedMark}, s.offset); err != nil {
+		return fmt.Errorf("marking spooled event: %v", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("marking spooled event: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// flakyWriter records events, failing while "down" is true, or once
+// "limit" events are written, if it's set.
+type flakyWriter struct {
+	mtx    sync.Mutex
+	down   bool
+	limit  int
+	events []*Event
+}
+
+func (f *flakyWriter) WriteEvent(ctx context.Context, ev *Event) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.down || (f.limit > 0 && len(f.events) >= f.limit) {
+		return errors.New("server unreachable")
+	}
+	f.events = append(f.events, ev)
+	return nil
+}
+
+func (f *flakyWriter) setDown(down bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.down = down
+}
+
+func (f *flakyWriter) states() []tes.State {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var states []tes.State
+	for _, ev := range f.events {
+		states = append(states, ev.GetState())
+	}
+	return states
+}
+
+func TestSpool(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-spool-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &flakyWriter{}
+	s, err := NewSpool(ctx, w, dir, "rpc", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(state tes.State) {
+		if err := s.WriteEvent(ctx, NewState("task-1", state)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(tes.State_INITIALIZING)
+	w.setDown(true)
+	write(tes.State_RUNNING)
+	write(tes.State_COMPLETE)
+	if len(w.states()) != 1 {
+		t.Fatalf("expected 1 written event, got %v", w.states())
+	}
+
+	// The next write replays the spooled events first, in order.
+	w.setDown(false)
+	write(tes.State_COMPLETE)
+
+	expected := []tes.State{
+		tes.State_INITIALIZING,
+		tes.State_RUNNING,
+		tes.State_COMPLETE,
+		tes.State_COMPLETE,
+	}
+	states := w.states()
+	if len(states) != len(expected) {
+		t.Fatalf("unexpected events: %v", states)
+	}
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Fatalf("unexpected events: %v", states)
+		}
+	}
+	for _, ev := range w.events {
+		if ev.IdempotencyKey == "" {
+			t.Error("expected events to have an idempotency key")
+		}
+	}
+
+	// Flush replays events without a new write.
+	w.setDown(true)
+	write(tes.State_SYSTEM_ERROR)
+	w.setDown(false)
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.states()) != 5 {
+		t.Fatalf("expected the spooled event to be written, got %v", w.states())
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 || files[0].Size() != 0 {
+		t.Error("expected an empty spool file to be left")
+	}
+}
+
+func TestSpoolRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-spool-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	open := func(w Writer) *Spool {
+		s, err := NewSpool(ctx, w, dir, "rpc", time.Hour, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	write := func(s *Spool, state tes.State) {
+		if err := s.WriteEvent(ctx, NewState("task-1", state)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flush := func(s *Spool) error {
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+		defer cancel()
+		return s.Flush(ctx)
+	}
+
+	// The first spool exits after replaying one of its spooled events.
+	w1 := &flakyWriter{down: true}
+	s1 := open(w1)
+	write(s1, tes.State_INITIALIZING)
+	write(s1, tes.State_RUNNING)
+	write(s1, tes.State_COMPLETE)
+	w1.mtx.Lock()
+	w1.down, w1.limit = false, 1
+	w1.mtx.Unlock()
+	if err := flush(s1); err == nil {
+		t.Fatal("expected an error flushing the spool")
+	}
+	if err := s1.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next spool replays only the events which weren't written.
+	w2 := &flakyWriter{}
+	s2 := open(w2)
+	if err := flush(s2); err != nil {
+		t.Fatal(err)
+	}
+	states := w2.states()
+	if len(states) != 2 || states[0] != tes.State_RUNNING || states[1] != tes.State_COMPLETE {
+		t.Fatalf("unexpected replayed events: %v", states)
+	}
+
+	// A spool which is still open keeps its file. Events left in the
+	// file of another spool are adopted by the next spool to open.
+	w3 := &flakyWriter{down: true}
+	s3 := open(w3)
+	write(s3, tes.State_SYSTEM_ERROR)
+	if s3.file.Name() == s2.file.Name() {
+		t.Fatal("expected open spools to use different files")
+	}
+	s3.Close()
+	s2.Close()
+
+	w4 := &flakyWriter{}
+	s4 := open(w4)
+	defer s4.Close()
+	if s4.file.Name() != s2.file.Name() {
+		t.Errorf("expected the first spool file to be reused, got %s", s4.file.Name())
+	}
+	if err := flush(s4); err != nil {
+		t.Fatal(err)
+	}
+	states = w4.states()
+	if len(states) != 1 || states[0] != tes.State_SYSTEM_ERROR {
+		t.Fatalf("expected the adopted event to be replayed, got %v", states)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	w := &flakyWriter{}
+	d := NewDedup(w, 2)
+	ctx := context.Background()
+
+	write := func(key string) {
+		ev := NewState("task-1", tes.State_RUNNING)
+		ev.IdempotencyKey = key
+		if err := d.WriteEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("a")
+	write("a")
+	write("")
+	write("")
+	if len(w.events) != 3 {
+		t.Fatalf("expected the duplicate event to be dropped, got %d events", len(w.events))
+	}
+
+	// Only the last 2 keys are remembered.
+	write("b")
+	write("c")
+	write("a")
+	if len(w.events) != 6 {
+		t.Fatalf("expected the forgotten key to be written again, got %d events", len(w.events))
+	}
+}
+
+// blockingWriter blocks writes until "release" is closed.
+type blockingWriter struct {
+	started chan struct{}
+	release chan struct{}
+	flakyWriter
+}
+
+func (b *blockingWriter) WriteEvent(ctx context.Context, ev *Event) error {
+	b.started <- struct{}{}
+	<-b.release
+	return b.flakyWriter.WriteEvent(ctx, ev)
+}
+
+func TestDedupInFlight(t *testing.T) {
+	w := &blockingWriter{
+		started: make(chan struct{}, 2),
+		release: make(chan struct{}),
+	}
+	d := NewDedup(w, 2)
+	ctx := context.Background()
+
+	ev := NewState("task-1", tes.State_RUNNING)
+	ev.IdempotencyKey = "a"
+	errs := make(chan error)
+	go func() {
+		errs <- d.WriteEvent(ctx, ev)
+	}()
+	<-w.started
+
+	// A concurrent delivery of the event being written is dropped.
+	if err := d.WriteEvent(ctx, ev); err != nil {
+		t.Fatal(err)
+	}
+	close(w.release)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(w.events) != 1 {
+		t.Fatalf("expected the event to be written once, got %d events", len(w.events))
+	}
+
+	// An event which failed to be written is written again.
+	w.setDown(true)
+	ev = NewState("task-1", tes.State_COMPLETE)
+	ev.IdempotencyKey = "b"
+	if err := d.WriteEvent(ctx, ev); err == nil {
+		t.Fatal("expected an error")
+	}
+	w.setDown(false)
+	if err := d.WriteEvent(ctx, ev); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.events) != 2 {
+		t.Fatalf("expected the failed event to be written again, got %d events", len(w.events))
+	}
+}
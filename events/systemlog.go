@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/ohsu-comp-bio/funnel/util"
@@ -88,8 +89,16 @@ func (s *Event) SysLogString() string {
 		fmt.Sprintf("task_attempt='%v'", s.Attempt),
 		fmt.Sprintf("executor_index='%v'", s.Index),
 	}
-	for k, v := range s.GetSystemLog().Fields {
-		parts = append(parts, fmt.Sprintf("%s='%s'", safeKey(k), escape(v)))
+	// The fields are sorted, so that the string of a replayed event
+	// matches the string which was stored.
+	fields := s.GetSystemLog().Fields
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s='%s'", safeKey(k), escape(fields[k])))
 	}
 	return strings.Join(parts, " ")
 }
@@ -0,0 +1,242 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"github.com/ohsu-comp-bio/funnel/util"
+)
+
+// WebhookWriter POSTs task events as JSON to HTTP endpoints,
+// e.g. to notify other systems when tasks finish.
+//
+// Each endpoint has a queue of events, which are sent in order by a background
+// routine, so that slow or failing endpoints don't delay writing events to
+// other writers. Failed requests are retried with backoff.
+type WebhookWriter struct {
+	hooks []*webhook
+}
+
+type webhook struct {
+	conf    config.WebhookEndpoint
+	types   map[Type]bool
+	states  map[tes.State]bool
+	queue   chan *Event
+	client  *http.Client
+	retrier *util.Retrier
+	tasks   tes.ReadOnlyServer
+	log     *logger.Logger
+}
+
+// NewWebhookWriter creates a new event writer which sends events to the
+// configured endpoints. "tasks" is used to look up the tags of the task of
+// an event, for endpoints with tag filters. The background routines are
+// stopped when the context is canceled.
+func NewWebhookWriter(ctx context.Context, conf config.Webhook, tasks tes.ReadOnlyServer, log *logger.Logger) (*WebhookWriter, error) {
+	w := &WebhookWriter{}
+
+	for _, e := range conf.Endpoints {
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing webhook URL %s: %v", e.URL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("webhook URL must be http or https: %s", e.URL)
+		}
+		if len(e.Tags) > 0 && tasks == nil {
+			return nil, fmt.Errorf("webhook %s: tag filters require access to the task database", e.URL)
+		}
+
+		h := &webhook{
+			conf:   e,
+			types:  map[Type]bool{},
+			states: map[tes.State]bool{},
+			queue:  make(chan *Event, conf.QueueSize),
+			client: &http.Client{Timeout: time.Duration(conf.Timeout)},
+			tasks:  tasks,
+			log:    log.WithFields("url", e.URL),
+		}
+		for _, t := range e.Types {
+			v, ok := Type_value[t]
+			if !ok {
+				return nil, fmt.Errorf("webhook %s: unknown event type: %s", e.URL, t)
+			}
+			h.types[Type(v)] = true
+		}
+		for _, s := range e.States {
+			v, ok := tes.State_value[s]
+			if !ok {
+				return nil, fmt.Errorf("webhook %s: unknown state: %s", e.URL, s)
+			}
+			h.states[tes.State(v)] = true
+		}
+
+		h.retrier = util.NewRetrier()
+		h.retrier.MaxTries = conf.MaxTries
+		h.retrier.MaxElapsedTime = 0
+		h.retrier.ShouldRetry = shouldRetryWebhook
+		h.retrier.Notify = func(err error, d time.Duration) {
+			h.log.Error("webhook request failed; retrying", "error", err, "retry_in", d)
+		}
+
+		w.hooks = append(w.hooks, h)
+	}
+
+	for _, h := range w.hooks {
+		go h.run(ctx)
+	}
+	return w, nil
+}
+
+// WriteEvent queues the event for each endpoint whose type and state filters
+// match it. Events are dropped, and an error logged, if a queue is full.
+func (w *WebhookWriter) WriteEvent(ctx context.Context, ev *Event) error {
+	for _, h := range w.hooks {
+		if !h.match(ev) {
+			continue
+		}
+		select {
+		case h.queue <- ev:
+		default:
+			h.log.Error("webhook queue is full; dropping event",
+				"taskID", ev.Id, "event_type", ev.Type.String())
+		}
+	}
+	return nil
+}
+
+// match returns true if the event matches the type and state filters.
+// Tags are matched later, by the background routine, because looking them
+// up requires a database read.
+func (h *webhook) match(ev *Event) bool {
+	if len(h.types) > 0 && !h.types[ev.Type] {
+		return false
+	}
+	if len(h.states) > 0 && ev.Type == Type_TASK_STATE && !h.states[ev.GetState()] {
+		return false
+	}
+	return true
+}
+
+// matchTags returns true if the event's task has the tags of the tag filter.
+func (h *webhook) matchTags(ctx context.Context, ev *Event) (bool, error) {
+	if len(h.conf.Tags) == 0 {
+		return true, nil
+	}
+
+	var tags map[string]string
+	if ev.Type == Type_TASK_CREATED {
+		tags = ev.GetTask().GetTags()
+	} else {
+		task, err := h.tasks.GetTask(ctx, &tes.GetTaskRequest{Id: ev.Id, View: tes.TaskView_BASIC})
+		if err != nil {
+			return false, err
+		}
+		tags = task.Tags
+	}
+
+	for k, v := range h.conf.Tags {
+		actual, ok := tags[k]
+		if !ok || (v != "" && v != actual) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (h *webhook) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-h.queue:
+			if err := h.deliver(ctx, ev); err != nil && ctx.Err() == nil {
+				h.log.Error("error sending event to webhook",
+					"error", err, "taskID", ev.Id, "event_type", ev.Type.String())
+			}
+		}
+	}
+}
+
+func (h *webhook) deliver(ctx context.Context, ev *Event) error {
+	ok, err := h.matchTags(ctx, ev)
+	if err != nil {
+		return fmt.Errorf("getting task tags: %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	s, err := Marshal(ev)
+	if err != nil {
+		return err
+	}
+	body := []byte(s)
+
+	return h.retrier.Retry(ctx, func() error {
+		return h.post(ctx, ev, body)
+	})
+}
+
+func (h *webhook) post(ctx context.Context, ev *Event, body []byte) error {
+	req, err := http.NewRequest("POST", h.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Funnel-Event", ev.Type.String())
+	if h.conf.Secret != "" {
+		req.Header.Set("X-Funnel-Signature", "sha256="+SignWebhook(h.conf.Secret, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &webhookStatusError{resp.StatusCode}
+	}
+	return nil
+}
+
+// SignWebhook returns the hex-encoded HMAC-SHA256 of the body,
+// as sent in the "X-Funnel-Signature" header.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookStatusError struct {
+	code int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("unexpected response status: %d %s", e.code, http.StatusText(e.code))
+}
+
+// shouldRetryWebhook returns false for client errors, other than
+// "429 Too Many Requests", which won't succeed when retried.
+func shouldRetryWebhook(err error) bool {
+	if e, ok := err.(*webhookStatusError); ok {
+		return e.code == http.StatusTooManyRequests || e.code >= 500
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package events
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// webhookServer records the events it receives. It responds with
+// the statuses in "fail" before succeeding.
+type webhookServer struct {
+	mtx      sync.Mutex
+	fail     []int
+	requests int
+	events   []*Event
+	headers  []http.Header
+	bodies   [][]byte
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.requests++
+	if len(s.fail) > 0 {
+		w.WriteHeader(s.fail[0])
+		s.fail = s.fail[1:]
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	ev := &Event{}
+	if err := Unmarshal(body, ev); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.events = append(s.events, ev)
+	s.headers = append(s.headers, r.Header)
+	s.bodies = append(s.bodies, body)
+}
+
+func (s *webhookServer) count() (requests, events int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.requests, len(s.events)
+}
+
+func waitForWebhook(t *testing.T, s *webhookServer, requests int) {
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		if n, _ := s.count(); n >= requests {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for webhook requests")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func testWebhookConfig(endpoints ...config.WebhookEndpoint) config.Webhook {
+	conf := config.DefaultConfig().Webhook
+	conf.Endpoints = endpoints
+	return conf
+}
+
+func TestWebhookFilters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	log := logger.NewLogger("test-webhook", logger.DebugConfig())
+
+	states, tagged := &webhookServer{}, &webhookServer{}
+	srvStates := httptest.NewServer(states)
+	defer srvStates.Close()
+	srvTagged := httptest.NewServer(tagged)
+	defer srvTagged.Close()
+
+	tasks := memTasks{
+		"task-1": {Id: "task-1", Tags: map[string]string{"project": "lims", "notify": "yes"}},
+		"task-2": {Id: "task-2", Tags: map[string]string{"project": "other"}},
+	}
+	conf := testWebhookConfig(
+		config.WebhookEndpoint{
+			URL:    srvStates.URL,
+			Types:  []string{"TASK_STATE"},
+			States: []string{"COMPLETE", "EXECUTOR_ERROR", "SYSTEM_ERROR", "CANCELED"},
+		},
+		config.WebhookEndpoint{
+			URL:  srvTagged.URL,
+			Tags: map[string]string{"project": "lims", "notify": ""},
+		},
+	)
+	w, err := NewWebhookWriter(ctx, conf, tasks, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evs := []*Event{
+		NewState("task-1", tes.Running),
+		NewStdout("task-1", 0, 0, "hello\n"),
+		NewState("task-2", tes.Complete),
+		NewState("task-1", tes.Complete),
+	}
+	for _, ev := range evs {
+		if err := w.WriteEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Terminal state events of any task.
+	waitForWebhook(t, states, 2)
+	if states.events[0].Id != "task-2" || states.events[1].Id != "task-1" {
+		t.Error("unexpected events", states.events)
+	}
+	if states.headers[0].Get("X-Funnel-Event") != "TASK_STATE" {
+		t.Error("unexpected event type header", states.headers[0])
+	}
+
+	// Any event of task-1, which has the "project=lims" and "notify" tags.
+	waitForWebhook(t, tagged, 3)
+	for _, ev := range tagged.events {
+		if ev.Id != "task-1" {
+			t.Error("unexpected event", ev)
+		}
+	}
+}
+
+func TestWebhookRetryAndSignature(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	log := logger.NewLogger("test-webhook", logger.DebugConfig())
+
+	s := &webhookServer{fail: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	conf := testWebhookConfig(config.WebhookEndpoint{URL: srv.URL, Secret: "secret"})
+	w, err := NewWebhookWriter(ctx, conf, nil, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.hooks[0].retrier.InitialInterval = time.Millisecond
+
+	w.WriteEvent(ctx, NewState("task-1", tes.Complete))
+	waitForWebhook(t, s, 3)
+
+	_, n := s.count()
+	if n != 1 {
+		t.Fatal("expected event to be delivered after retries")
+	}
+	sig := s.headers[0].Get("X-Funnel-Signature")
+	if sig != "sha256="+SignWebhook("secret", s.bodies[0]) {
+		t.Error("unexpected signature", sig)
+	}
+
+	// Client errors aren't retried.
+	s.mtx.Lock()
+	s.fail = []int{http.StatusBadRequest}
+	s.mtx.Unlock()
+	w.WriteEvent(ctx, NewState("task-2", tes.Complete))
+	w.WriteEvent(ctx, NewState("task-3", tes.Complete))
+	waitForWebhook(t, s, 5)
+	time.Sleep(time.Millisecond * 50)
+
+	requests, n := s.count()
+	if requests != 5 || n != 2 || s.events[1].Id != "task-3" {
+		t.Error("expected the rejected event to be dropped", requests, n)
+	}
+}
+
+func TestWebhookConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogger("test-webhook", logger.DebugConfig())
+
+	for _, e := range []config.WebhookEndpoint{
+		{URL: "ftp://example.com"},
+		{URL: "http://example.com", Types: []string{"NOT_A_TYPE"}},
+		{URL: "http://example.com", States: []string{"DONE"}},
+		// Tag filters require a task database.
+		{URL: "http://example.com", Tags: map[string]string{"project": "lims"}},
+	} {
+		if _, err := NewWebhookWriter(ctx, testWebhookConfig(e), nil, log); err == nil {
+			t.Error("expected error for endpoint", e)
+		}
+	}
+}
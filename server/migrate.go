@@ -0,0 +1,259 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ohsu-comp-bio/funnel/compute/scheduler"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// MigrationDatabase describes a database which tasks and nodes are migrated
+// from or to.
+type MigrationDatabase interface {
+	tes.ReadOnlyServer
+	events.Writer
+	TaskDeleter
+	scheduler.SchedulerServiceServer
+}
+
+// Migrator copies the tasks and nodes of one database to another.
+//
+// Tasks are listed in the full view and replayed to the target database
+// as events, so that the target database keeps its own state, e.g. its task
+// queue, consistent with the tasks. The source database shouldn't change
+// during the migration, i.e. the server should be stopped.
+type Migrator struct {
+	From MigrationDatabase
+	To   MigrationDatabase
+	// Path of a file recording the progress of the migration, so that an
+	// interrupted migration resumes where it stopped. Empty disables it.
+	Checkpoint string
+	Log        *logger.Logger
+}
+
+// migrationCheckpoint records the tasks which have been migrated.
+type migrationCheckpoint struct {
+	// Page token of the next page of source tasks.
+	PageToken string
+	// Number of tasks migrated before the page.
+	Tasks int
+}
+
+// Migrate copies the tasks and nodes, and verifies that all of them exist
+// in the target database. It returns the number of migrated tasks and nodes.
+func (m *Migrator) Migrate(ctx context.Context) (tasks, nodes int, err error) {
+	tasks, err = m.migrateTasks(ctx)
+	if err != nil {
+		return tasks, 0, err
+	}
+	nodes, err = m.migrateNodes(ctx)
+	if err != nil {
+		return tasks, nodes, err
+	}
+	if err := m.verify(ctx); err != nil {
+		return tasks, nodes, err
+	}
+	if m.Checkpoint != "" {
+		os.Remove(m.Checkpoint)
+	}
+	return tasks, nodes, nil
+}
+
+func (m *Migrator) migrateTasks(ctx context.Context) (int, error) {
+	cp, err := m.readCheckpoint()
+	if err != nil {
+		return 0, err
+	}
+	if cp.Tasks > 0 {
+		m.Log.Info("Resuming migration", "tasks", cp.Tasks, "checkpoint", m.Checkpoint)
+	}
+
+	req := &tes.ListTasksRequest{
+		View:      tes.TaskView_FULL,
+		PageSize:  100,
+		PageToken: cp.PageToken,
+	}
+	for {
+		resp, err := m.From.ListTasks(ctx, req)
+		if err != nil {
+			return cp.Tasks, fmt.Errorf("listing tasks: %v", err)
+		}
+
+		for _, task := range resp.Tasks {
+			if err := m.migrateTask(ctx, task); err != nil {
+				return cp.Tasks, err
+			}
+		}
+
+		cp.Tasks += len(resp.Tasks)
+		cp.PageToken = resp.NextPageToken
+		if resp.NextPageToken == "" {
+			return cp.Tasks, nil
+		}
+		if err := m.writeCheckpoint(cp); err != nil {
+			return cp.Tasks, err
+		}
+		m.Log.Info("Migrated tasks", "count", cp.Tasks)
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// migrateTask replays the events of the task to the target database.
+// A task which already exists in the target database, e.g. because a
+// previous migration stopped before the task was fully written,
+// is deleted first.
+func (m *Migrator) migrateTask(ctx context.Context, task *tes.Task) error {
+	_, err := m.To.GetTask(ctx, &tes.GetTaskRequest{Id: task.Id, View: tes.TaskView_MINIMAL})
+	switch {
+	case err == nil:
+		if err := m.To.DeleteTask(ctx, task.Id); err != nil {
+			return fmt.Errorf("deleting partially migrated task %s: %v", task.Id, err)
+		}
+	case err != tes.ErrNotFound && grpc.Code(err) != codes.NotFound:
+		return fmt.Errorf("getting task %s: %v", task.Id, err)
+	}
+
+	// If the source database logs task events, the logged events are
+	// replayed, so that the target database has the same event log.
+	l, _ := m.From.(events.EventLog)
+	evs, err := events.TaskEvents(ctx, l, task)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		if err := m.To.WriteEvent(ctx, ev); err != nil {
+			return fmt.Errorf("migrating task %s: %v", task.Id, err)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) migrateNodes(ctx context.Context) (int, error) {
+	resp, err := m.From.ListNodes(ctx, &scheduler.ListNodesRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("listing nodes: %v", err)
+	}
+
+	for _, node := range resp.Nodes {
+		// Nodes are replaced, regardless of the version in the target database.
+		existing, err := m.To.GetNode(ctx, &scheduler.GetNodeRequest{Id: node.Id})
+		switch {
+		case err == nil:
+			node.Version = existing.Version
+		case grpc.Code(err) == codes.NotFound:
+			node.Version = 0
+		default:
+			return 0, fmt.Errorf("getting node %s: %v", node.Id, err)
+		}
+
+		if _, err := m.To.PutNode(ctx, node); err != nil {
+			return 0, fmt.Errorf("migrating node %s: %v", node.Id, err)
+		}
+	}
+	return len(resp.Nodes), nil
+}
+
+// verify returns an error if a task or node of the source database
+// doesn't exist in the target database.
+func (m *Migrator) verify(ctx context.Context) error {
+	from, err := taskIDs(ctx, m.From)
+	if err != nil {
+		return err
+	}
+	to, err := taskIDs(ctx, m.To)
+	if err != nil {
+		return err
+	}
+	missing := 0
+	for id := range from {
+		if !to[id] {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return fmt.Errorf("verifying migration: %d of %d tasks are missing from the target database", missing, len(from))
+	}
+
+	fromNodes, err := m.From.ListNodes(ctx, &scheduler.ListNodesRequest{})
+	if err != nil {
+		return fmt.Errorf("listing nodes: %v", err)
+	}
+	toNodes, err := m.To.ListNodes(ctx, &scheduler.ListNodesRequest{})
+	if err != nil {
+		return fmt.Errorf("listing nodes: %v", err)
+	}
+	nodes := map[string]bool{}
+	for _, node := range toNodes.Nodes {
+		nodes[node.Id] = true
+	}
+	for _, node := range fromNodes.Nodes {
+		if !nodes[node.Id] {
+			return fmt.Errorf("verifying migration: node %s is missing from the target database", node.Id)
+		}
+	}
+	return nil
+}
+
+// taskIDs returns the IDs of all the tasks in the database.
+func taskIDs(ctx context.Context, r tes.ReadOnlyServer) (map[string]bool, error) {
+	ids := map[string]bool{}
+	req := &tes.ListTasksRequest{View: tes.TaskView_MINIMAL, PageSize: 2048}
+	for {
+		resp, err := r.ListTasks(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("listing tasks: %v", err)
+		}
+		for _, task := range resp.Tasks {
+			ids[task.Id] = true
+		}
+		if resp.NextPageToken == "" {
+			return ids, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+func (m *Migrator) readCheckpoint() (*migrationCheckpoint, error) {
+	cp := &migrationCheckpoint{}
+	if m.Checkpoint == "" {
+		return cp, nil
+	}
+	b, err := ioutil.ReadFile(m.Checkpoint)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading migration checkpoint: %v", err)
+	}
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("reading migration checkpoint %s: %v", m.Checkpoint, err)
+	}
+	return cp, nil
+}
+
+func (m *Migrator) writeCheckpoint(cp *migrationCheckpoint) error {
+	if m.Checkpoint == "" {
+		return nil
+	}
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	// Write and rename, so that an interrupted write doesn't lose the checkpoint.
+	tmp := m.Checkpoint + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("writing migration checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, m.Checkpoint); err != nil {
+		return fmt.Errorf("writing migration checkpoint: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/storage"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+)
+
+// TaskDeleter describes a database which can delete tasks.
+type TaskDeleter interface {
+	// DeleteTask deletes the task and its logs.
+	// Deleting a task which doesn't exist isn't an error.
+	DeleteTask(ctx context.Context, id string) error
+}
+
+// Expirer deletes the tasks which match a retention policy. If an archive URL
+// is configured, the events of the tasks are written to storage first, so that
+// they can be restored with RestoreTasks.
+type Expirer struct {
+	Conf    config.Retention
+	Log     *logger.Logger
+	Tasks   tes.ReadOnlyServer
+	Deleter TaskDeleter
+	// Store is only used if Conf.ArchiveURL is set.
+	Store storage.Storage
+
+	policies []retentionPolicy
+}
+
+type retentionPolicy struct {
+	maxAge time.Duration
+	states []tes.State
+	tags   map[string]string
+	keys   []string
+}
+
+// NewExpirer returns an Expirer, or an error if the retention config is invalid.
+func NewExpirer(conf config.Retention, tasks tes.ReadOnlyServer, deleter TaskDeleter, store storage.Storage, log *logger.Logger) (*Expirer, error) {
+	e := &Expirer{
+		Conf:    conf,
+		Log:     log,
+		Tasks:   tasks,
+		Deleter: deleter,
+		Store:   store,
+	}
+
+	for i, p := range conf.Policies {
+		if p.MaxAge <= 0 {
+			return nil, fmt.Errorf("Retention.Policies[%d].MaxAge: must be greater than zero", i)
+		}
+		rp := retentionPolicy{
+			maxAge: time.Duration(p.MaxAge),
+			tags:   map[string]string{},
+		}
+		for _, s := range p.States {
+			state := tes.State(tes.State_value[strings.ToUpper(s)])
+			if !tes.TerminalState(state) {
+				return nil, fmt.Errorf("Retention.Policies[%d].States: %s is not a terminal state", i, s)
+			}
+			rp.states = append(rp.states, state)
+		}
+		if len(rp.states) == 0 {
+			rp.states = []tes.State{tes.Complete, tes.ExecutorError, tes.SystemError, tes.Canceled}
+		}
+		for k, v := range p.Tags {
+			if v == "" {
+				rp.keys = append(rp.keys, k)
+			} else {
+				rp.tags[k] = v
+			}
+		}
+		e.policies = append(e.policies, rp)
+	}
+
+	if conf.ArchiveURL != "" {
+		if store == nil {
+			return nil, fmt.Errorf("Retention.ArchiveURL: no storage backend")
+		}
+		if err := store.UnsupportedOperations(conf.ArchiveURL).Put; err != nil {
+			return nil, fmt.Errorf("Retention.ArchiveURL: %v", err)
+		}
+	}
+	return e, nil
+}
+
+// Run starts the retention loop. This blocks.
+func (e *Expirer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(e.Conf.Rate))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			_, err := e.Expire(ctx)
+			if err != nil {
+				e.Log.Error("Error expiring tasks", "error", err)
+			}
+		}
+	}
+}
+
+// Expire archives and deletes up to Conf.BatchSize expired tasks,
+// and returns the number of deleted tasks.
+func (e *Expirer) Expire(ctx context.Context) (int, error) {
+	now := time.Now()
+	tasks, err := e.expired(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("listing expired tasks: %v", err)
+	}
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	if e.Conf.ArchiveURL != "" {
+		url, err := e.archive(ctx, tasks, now)
+		if err != nil {
+			return 0, err
+		}
+		e.Log.Info("Archived expired tasks", "count", len(tasks), "url", url)
+	}
+
+	for i, task := range tasks {
+		if err := e.Deleter.DeleteTask(ctx, task.Id); err != nil {
+			return i, fmt.Errorf("deleting task %s: %v", task.Id, err)
+		}
+	}
+	e.Log.Info("Deleted expired tasks", "count", len(tasks))
+	return len(tasks), nil
+}
+
+// expired returns up to Conf.BatchSize tasks which match a retention policy,
+// in the full view.
+func (e *Expirer) expired(ctx context.Context, now time.Time) ([]*tes.Task, error) {
+	var tasks []*tes.Task
+	seen := map[string]bool{}
+
+	for _, p := range e.policies {
+		req := &tes.ListTasksRequest{
+			View:          tes.TaskView_FULL,
+			PageSize:      uint32(e.Conf.BatchSize),
+			States:        p.states,
+			Tags:          p.tags,
+			TagKeys:       p.keys,
+			CreatedBefore: now.Add(-p.maxAge).UTC().Format(time.RFC3339Nano),
+		}
+
+		for {
+			resp, err := e.Tasks.ListTasks(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			for _, task := range resp.Tasks {
+				if seen[task.Id] {
+					continue
+				}
+				seen[task.Id] = true
+				tasks = append(tasks, task)
+				if len(tasks) >= e.Conf.BatchSize {
+					return tasks, nil
+				}
+			}
+			if resp.NextPageToken == "" {
+				break
+			}
+			req.PageToken = resp.NextPageToken
+		}
+	}
+	return tasks, nil
+}
+
+// archive writes the tasks to a new file in the archive directory,
+// and returns its URL.
+func (e *Expirer) archive(ctx context.Context, tasks []*tes.Task, now time.Time) (string, error) {
+	f, err := ioutil.TempFile("", "funnel-archive-")
+	if err != nil {
+		return "", fmt.Errorf("creating archive: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	// If the database logs task events, the logged events are archived,
+	// so that restored tasks keep their event log.
+	l, _ := e.Tasks.(events.EventLog)
+	err = events.WriteArchive(ctx, f, l, tasks)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("writing archive: %v", err)
+	}
+
+	name := fmt.Sprintf("tasks-%s-%s.jsonl", now.UTC().Format("20060102T150405Z"), tes.GenerateID())
+	url, err := e.Store.Join(e.Conf.ArchiveURL, name)
+	if err != nil {
+		return "", fmt.Errorf("uploading archive: %v", err)
+	}
+	if _, err := e.Store.Put(ctx, url, f.Name()); err != nil {
+		return "", fmt.Errorf("uploading archive: %v", err)
+	}
+	return url, nil
+}
+
+// RestoreTasks writes the events of the tasks in an archive, written by an
+// Expirer, to "w". Tasks for which "exists" returns true are skipped.
+// It returns the number of restored and skipped tasks.
+func RestoreTasks(ctx context.Context, r io.Reader, w events.Writer, exists func(ctx context.Context, id string) (bool, error)) (restored, skipped int, err error) {
+	err = events.ReadArchive(r, func(id string, evs []*events.Event) error {
+		ok, err := exists(ctx, id)
+		if err != nil {
+			return fmt.Errorf("checking task %s: %v", id, err)
+		}
+		if ok {
+			skipped++
+			return nil
+		}
+		for _, ev := range evs {
+			if err := w.WriteEvent(ctx, ev); err != nil {
+				return fmt.Errorf("restoring task %s: %v", id, err)
+			}
+		}
+		restored++
+		return nil
+	})
+	return restored, skipped, err
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/logger"
+	"github.com/ohsu-comp-bio/funnel/storage"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+)
+
+// memTasks is an in-memory task database, which logs task events.
+type memTasks struct {
+	tasks map[string]*tes.Task
+	logs  map[string][]*events.Event
+}
+
+func (m *memTasks) ids() []string {
+	var ids []string
+	for id := range m.tasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (m *memTasks) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*tes.Task, error) {
+	task, ok := m.tasks[req.Id]
+	if !ok {
+		return nil, tes.ErrNotFound
+	}
+	return task, nil
+}
+
+// ListTasks returns pages of one task.
+func (m *memTasks) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
+	resp := &tes.ListTasksResponse{}
+	for _, id := range m.ids() {
+		if id > req.PageToken && tes.MatchesFilters(m.tasks[id], req) {
+			resp.Tasks = []*tes.Task{m.tasks[id]}
+			resp.NextPageToken = id
+			break
+		}
+	}
+	return resp, nil
+}
+
+func (m *memTasks) DeleteTask(ctx context.Context, id string) error {
+	delete(m.tasks, id)
+	delete(m.logs, id)
+	return nil
+}
+
+func (m *memTasks) ListTaskEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	return m.logs[id], nil
+}
+
+// WriteEvent creates tasks and updates their logs.
+func (m *memTasks) WriteEvent(ctx context.Context, ev *events.Event) error {
+	if events.Logged(ev) {
+		m.logs[ev.Id] = append(m.logs[ev.Id], ev)
+	}
+	if ev.Type == events.Type_TASK_CREATED {
+		m.tasks[ev.Id] = proto.Clone(ev.GetTask()).(*tes.Task)
+		return nil
+	}
+	return events.TaskBuilder{Task: m.tasks[ev.Id]}.WriteEvent(ctx, ev)
+}
+
+func retentionTask(id string, state tes.State, age time.Duration, tags map[string]string) *tes.Task {
+	created := time.Now().Add(-age)
+	task := &tes.Task{
+		Id:           id,
+		State:        state,
+		CreationTime: created.Format(time.RFC3339Nano),
+		Tags:         tags,
+	}
+	if state != tes.Queued {
+		task.Logs = []*tes.TaskLog{{
+			StartTime:  created.Add(time.Second).Format(time.RFC3339Nano),
+			SystemLogs: []string{fmt.Sprintf("level='info' msg='started' timestamp='%s' task_attempt='0' executor_index='0'", created.Format(time.RFC3339Nano))},
+		}}
+	}
+	return task
+}
+
+func TestExpirer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-retention-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := storage.NewLocal(config.LocalStorage{AllowedDirs: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := 24 * time.Hour
+	db := &memTasks{tasks: map[string]*tes.Task{}, logs: map[string][]*events.Event{}}
+	ctx := context.Background()
+
+	// This task has an event log, which goes through the states
+	// that its history skips.
+	logged := events.TaskHistory(retentionTask("complete-old", tes.Complete, 10*day, nil))
+	logged = append(logged[:1], append([]*events.Event{
+		events.NewState("complete-old", tes.Initializing),
+		events.NewState("complete-old", tes.Running),
+	}, logged[1:]...)...)
+	for _, ev := range logged {
+		if err := db.WriteEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, task := range []*tes.Task{
+		retentionTask("complete-new", tes.Complete, day, nil),
+		retentionTask("error-old", tes.ExecutorError, 10*day, nil),
+		retentionTask("running-old", tes.Running, 10*day, nil),
+		retentionTask("queued-old", tes.Queued, 10*day, nil),
+		retentionTask("scratch-new", tes.SystemError, day, map[string]string{"project": "scratch"}),
+		retentionTask("other-new", tes.SystemError, day, map[string]string{"project": "other"}),
+	} {
+		db.tasks[task.Id] = task
+	}
+	before := proto.Clone(db.tasks["complete-old"]).(*tes.Task)
+	history := events.TaskHistory(db.tasks["error-old"])
+
+	conf := config.DefaultConfig().Retention
+	conf.ArchiveURL = filepath.Join(dir, "archive")
+	conf.BatchSize = 2
+	conf.Policies = []config.RetentionPolicy{
+		{MaxAge: config.Duration(7 * day), States: []string{"complete", "EXECUTOR_ERROR"}},
+		{MaxAge: config.Duration(time.Hour), Tags: map[string]string{"project": "scratch"}},
+	}
+	log := logger.NewLogger("test-retention", logger.DebugConfig())
+	e, err := NewExpirer(conf, db, db, store, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := e.Expire(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 expired tasks, got %d: %v", n, err)
+	}
+	n, err = e.Expire(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 expired task, got %d: %v", n, err)
+	}
+	if n, _ := e.Expire(ctx); n != 0 {
+		t.Fatalf("expected no expired tasks, got %d", n)
+	}
+
+	expected := []string{"complete-new", "other-new", "queued-old", "running-old"}
+	if ids := db.ids(); fmt.Sprint(ids) != fmt.Sprint(expected) {
+		t.Fatalf("expected tasks %v, got %v", expected, ids)
+	}
+
+	archives, err := filepath.Glob(filepath.Join(dir, "archive", "tasks-*.jsonl"))
+	if err != nil || len(archives) != 2 {
+		t.Fatalf("expected 2 archives, got %v: %v", archives, err)
+	}
+
+	// Restore the archives, including a task which exists again.
+	db.tasks["scratch-new"] = retentionTask("scratch-new", tes.Queued, 0, nil)
+	exists := func(ctx context.Context, id string) (bool, error) {
+		_, ok := db.tasks[id]
+		return ok, nil
+	}
+	var restored, skipped int
+	for _, a := range archives {
+		f, err := os.Open(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, s, err := RestoreTasks(ctx, f, db, exists)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		restored += r
+		skipped += s
+	}
+	if restored != 2 || skipped != 1 {
+		t.Errorf("expected 2 restored and 1 skipped tasks, got %d and %d", restored, skipped)
+	}
+	if !proto.Equal(db.tasks["complete-old"], before) {
+		t.Errorf("expected %v, got %v", before, db.tasks["complete-old"])
+	}
+	if len(db.logs["complete-old"]) != len(logged) {
+		t.Errorf("expected the restored task to keep its event log, got %v", db.logs["complete-old"])
+	}
+	if len(db.logs["error-old"]) != len(history) {
+		t.Errorf("expected the restored task to have its history, got %v", db.logs["error-old"])
+	}
+	if db.tasks["scratch-new"].State != tes.Queued {
+		t.Error("expected the existing task not to be restored")
+	}
+}
+
+func TestNewExpirerErrors(t *testing.T) {
+	log := logger.NewLogger("test-retention", logger.DebugConfig())
+	db := &memTasks{}
+	store, _ := storage.NewLocal(config.LocalStorage{AllowedDirs: []string{"/tmp"}})
+
+	invalid := []config.Retention{
+		{Policies: []config.RetentionPolicy{{}}},
+		{Policies: []config.RetentionPolicy{{MaxAge: 1, States: []string{"RUNNING"}}}},
+		{Policies: []config.RetentionPolicy{{MaxAge: 1, States: []string{"DONE"}}}},
+		{ArchiveURL: "/not-allowed/archive"},
+	}
+	for _, conf := range invalid {
+		if _, err := NewExpirer(conf, db, db, store, log); err == nil {
+			t.Errorf("expected an error for %+v", conf)
+		}
+	}
+}
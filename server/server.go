@@ -28,6 +28,8 @@ type Server struct {
 	Tasks            tes.TaskServiceServer
 	Events           events.EventServiceServer
 	Nodes            scheduler.SchedulerServiceServer
+	NodeAdmin        scheduler.NodeAdminServiceServer
+	NodeSessions     scheduler.NodeSessionServiceServer
 	DisableHTTPCache bool
 	Log              *logger.Logger
 }
@@ -124,6 +126,12 @@ func (s *Server) Serve(pctx context.Context) error {
 	// Register Events service
 	if s.Events != nil {
 		events.RegisterEventServiceServer(grpcServer, s.Events)
+		err := events.RegisterEventServiceHandlerFromEndpoint(
+			ctx, grpcMux, s.RPCAddress, dialOpts,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Register Scheduler RPC service
@@ -137,6 +145,22 @@ func (s *Server) Serve(pctx context.Context) error {
 		}
 	}
 
+	// Register Node Admin RPC service
+	if s.NodeAdmin != nil {
+		scheduler.RegisterNodeAdminServiceServer(grpcServer, s.NodeAdmin)
+		err := scheduler.RegisterNodeAdminServiceHandlerFromEndpoint(
+			ctx, grpcMux, s.RPCAddress, dialOpts,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Register Node Session RPC service
+	if s.NodeSessions != nil {
+		scheduler.RegisterNodeSessionServiceServer(grpcServer, s.NodeSessions)
+	}
+
 	httpServer := &http.Server{
 		Addr:    ":" + s.HTTPPort,
 		Handler: mux,
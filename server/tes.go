@@ -29,12 +29,24 @@ type TaskService struct {
 	Log     *logger.Logger
 }
 
+// TaskValidator is implemented by compute backends which have their own
+// requirements for tasks, e.g. the tags allowed by the HPC backends.
+type TaskValidator interface {
+	ValidateTask(*tes.Task) error
+}
+
 // CreateTask provides an HTTP/gRPC endpoint for creating a task.
 // This is part of the TES implementation.
 func (ts *TaskService) CreateTask(ctx context.Context, task *tes.Task) (*tes.CreateTaskResponse, error) {
 
 	if err := tes.InitTask(task); err != nil {
-		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	if v, ok := ts.Compute.(TaskValidator); ok {
+		if err := v.ValidateTask(task); err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+		}
 	}
 
 	if err := ts.Event.WriteEvent(ctx, events.NewTaskCreated(task)); err != nil {
@@ -59,9 +71,25 @@ func (ts *TaskService) GetTask(ctx context.Context, req *tes.GetTaskRequest) (*t
 
 // ListTasks calls ListTasks on the underlying tes.ReadOnlyServer.
 func (ts *TaskService) ListTasks(ctx context.Context, req *tes.ListTasksRequest) (*tes.ListTasksResponse, error) {
+	if err := tes.ValidateListTasksRequest(req); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
 	return ts.Read.ListTasks(ctx, req)
 }
 
+// GetTaskStats calls GetTaskStats on the underlying tes.ReadOnlyServer,
+// if it computes stats itself. Otherwise, the stats are computed from
+// the list of tasks.
+func (ts *TaskService) GetTaskStats(ctx context.Context, req *tes.GetTaskStatsRequest) (*tes.GetTaskStatsResponse, error) {
+	if err := tes.ValidateGetTaskStatsRequest(req); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	if s, ok := ts.Read.(tes.TaskStatsServer); ok {
+		return s.GetTaskStats(ctx, req)
+	}
+	return tes.ComputeTaskStats(ctx, ts.Read, req)
+}
+
 // CancelTask cancels a task
 func (ts *TaskService) CancelTask(ctx context.Context, req *tes.CancelTaskRequest) (*tes.CancelTaskResponse, error) {
 	// dispatch to compute backend
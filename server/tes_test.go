@@ -0,0 +1,37 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// rejectCompute is a compute backend which rejects every task.
+type rejectCompute struct {
+	events.Noop
+}
+
+func (rejectCompute) ValidateTask(*tes.Task) error {
+	return errors.New("tag slurm.constraint is not allowed")
+}
+
+func TestCreateTaskComputeValidation(t *testing.T) {
+	ts := &TaskService{
+		Event:   events.Noop{},
+		Compute: rejectCompute{},
+	}
+	task := &tes.Task{
+		Executors: []*tes.Executor{
+			{Image: "alpine", Command: []string{"echo"}},
+		},
+	}
+	_, err := ts.CreateTask(context.Background(), task)
+	if grpc.Code(err) != codes.InvalidArgument {
+		t.Error("expected InvalidArgument error, got", err)
+	}
+}
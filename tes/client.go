@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 	"github.com/ohsu-comp-bio/funnel/util"
 	"golang.org/x/net/context"
 )
@@ -83,16 +84,48 @@ func (c *Client) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTas
 	addString(v, "page_token", req.GetPageToken())
 	addString(v, "view", req.GetView().String())
 
-	if req.GetState() != Unknown {
-		addString(v, "state", req.State.String())
+	addListFilters(v, "", req)
+
+	// Send request
+	u := c.address + "/v1/tasks?" + v.Encode()
+	hreq, _ := http.NewRequest("GET", u, nil)
+	hreq.WithContext(ctx)
+	hreq.SetBasicAuth(c.User, c.Password)
+	body, err := util.CheckHTTPResponse(c.client.Do(hreq))
+	if err != nil {
+		return nil, err
 	}
+	// Parse response
+	resp := &ListTasksResponse{}
+	err = jsonpb.UnmarshalString(string(body), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
 
-	for key, val := range req.Tags {
-		v.Add(fmt.Sprintf("tags[%s]", key), val)
+// GetTaskStats returns the result of GET /v1/tasks:stats
+func (c *Client) GetTaskStats(ctx context.Context, req *GetTaskStatsRequest) (*GetTaskStatsResponse, error) {
+	// Build url query parameters
+	v := url.Values{}
+	if req.Filter != nil {
+		addListFilters(v, "filter.", req.Filter)
+	}
+	if req.GroupByState {
+		v.Add("group_by_state", "true")
+	}
+	for _, key := range req.GroupByTags {
+		v.Add("group_by_tags", key)
+	}
+	if req.GroupByTime != TimeBucket_ALL_TIME {
+		addString(v, "group_by_time", req.GroupByTime.String())
+	}
+	for _, p := range req.Percentiles {
+		v.Add("percentiles", fmt.Sprint(p))
 	}
 
 	// Send request
-	u := c.address + "/v1/tasks?" + v.Encode()
+	u := c.address + "/v1/tasks:stats?" + v.Encode()
 	hreq, _ := http.NewRequest("GET", u, nil)
 	hreq.WithContext(ctx)
 	hreq.SetBasicAuth(c.User, c.Password)
@@ -101,7 +134,7 @@ func (c *Client) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTas
 		return nil, err
 	}
 	// Parse response
-	resp := &ListTasksResponse{}
+	resp := &GetTaskStatsResponse{}
 	err = jsonpb.UnmarshalString(string(body), resp)
 	if err != nil {
 		return nil, err
@@ -109,6 +142,26 @@ func (c *Client) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTas
 	return resp, nil
 }
 
+// GetJSON sends GET to the path, relative to the server address, with
+// the query parameters, and unmarshals the JSON response into "resp".
+// It calls endpoints of other Funnel services, e.g. /v1/tasks/{id}/events.
+func (c *Client) GetJSON(ctx context.Context, path string, v url.Values, resp proto.Message) error {
+	// Send request
+	u := c.address + path
+	if len(v) > 0 {
+		u += "?" + v.Encode()
+	}
+	hreq, _ := http.NewRequest("GET", u, nil)
+	hreq.WithContext(ctx)
+	hreq.SetBasicAuth(c.User, c.Password)
+	body, err := util.CheckHTTPResponse(c.client.Do(hreq))
+	if err != nil {
+		return err
+	}
+	// Parse response
+	return jsonpb.UnmarshalString(string(body), resp)
+}
+
 // CreateTask POSTs a Task message to /v1/tasks
 func (c *Client) CreateTask(ctx context.Context, task *Task) (*CreateTaskResponse, error) {
 	verr := Validate(task)
@@ -7,6 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
 	"golang.org/x/net/context"
 )
 
@@ -120,3 +123,99 @@ func TestClientTimeout(t *testing.T) {
 		t.Fatal("Request did not timeout.")
 	}
 }
+
+func TestListTasksFilters(t *testing.T) {
+	expected := &ListTasksRequest{
+		View:          TaskView_BASIC,
+		State:         State_RUNNING,
+		Tags:          map[string]string{"foo": "bar"},
+		NamePrefix:    "align-",
+		CreatedAfter:  "2018-01-01T00:00:00Z",
+		CreatedBefore: "2018-02-01T00:00:00Z",
+		States:        []State{State_QUEUED, State_COMPLETE},
+		TagKeys:       []string{"project"},
+		TagPrefixes:   map[string]string{"sample": "NA12"},
+	}
+
+	// Set up test server response, which decodes the query string
+	// the same way the HTTP gateway does.
+	var got ListTasksRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+		err := runtime.PopulateQueryParameters(&got, r.URL.Query(), &utilities.DoubleArray{})
+		if err != nil {
+			t.Error(err)
+		}
+		Marshaler.Marshal(w, &ListTasksResponse{})
+	})
+
+	ts := testServer(mux)
+	defer ts.Close()
+
+	c, err := NewClient("http://localhost:20001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.ListTasks(context.Background(), expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(&got, expected) {
+		t.Errorf("unexpected request\nexpected: %v\nactual: %v", expected, &got)
+	}
+}
+
+func TestGetTaskStatsQuery(t *testing.T) {
+	expected := &GetTaskStatsRequest{
+		Filter: &ListTasksRequest{
+			State:        State_COMPLETE,
+			Tags:         map[string]string{"foo": "bar"},
+			CreatedAfter: "2018-01-01T00:00:00Z",
+		},
+		GroupByState: true,
+		GroupByTags:  []string{"project", "user"},
+		GroupByTime:  TimeBucket_WEEK,
+		Percentiles:  []float64{50, 99.9},
+	}
+	resp := &GetTaskStatsResponse{
+		Groups: []*TaskStatsGroup{
+			{
+				State:      State_COMPLETE,
+				TimeBucket: "2018-01-01T00:00:00Z",
+				Count:      2,
+				RunTime:    &DurationStats{Count: 2, Mean: 1.5, Min: 1, Max: 2, Percentiles: []float64{1, 2}},
+				QueueTime:  &DurationStats{},
+			},
+		},
+	}
+
+	var got GetTaskStatsRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/tasks:stats", func(w http.ResponseWriter, r *http.Request) {
+		err := runtime.PopulateQueryParameters(&got, r.URL.Query(), &utilities.DoubleArray{})
+		if err != nil {
+			t.Error(err)
+		}
+		Marshaler.Marshal(w, resp)
+	})
+
+	ts := testServer(mux)
+	defer ts.Close()
+
+	c, err := NewClient("http://localhost:20001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.GetTaskStats(context.Background(), expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(&got, expected) {
+		t.Errorf("unexpected request\nexpected: %v\nactual: %v", expected, &got)
+	}
+	if !proto.Equal(r, resp) {
+		t.Errorf("unexpected response\nexpected: %v\nactual: %v", resp, r)
+	}
+}
@@ -0,0 +1,119 @@
+package tes
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Task tags which control how the worker runs the executors. The tags are
+// set per executor, by adding the executor's index, e.g. "executor-group.1".
+const (
+	// ExecutorGroupTag puts consecutive executors with the same value in a
+	// parallel group. The executors of a group run at the same time.
+	ExecutorGroupTag = "executor-group"
+	// ExecutorBackgroundTag runs the executor in the background when set to "true",
+	// e.g. for a database or server needed by the next executors. The next executors
+	// start without waiting for it, it's stopped once the foreground executors
+	// are done, and its exit code doesn't fail the task.
+	ExecutorBackgroundTag = "executor-background"
+	// ExecutorIgnoreErrorTag lets the task continue when the executor exits
+	// with a non-zero exit code, when set to "true".
+	ExecutorIgnoreErrorTag = "executor-ignore-error"
+)
+
+// ExecutorStage is a set of executors which the worker starts at the same time.
+type ExecutorStage struct {
+	// Indexes of the executors in the stage.
+	Executors []int
+	// If true, the stage is a single background executor.
+	Background bool
+}
+
+// ExecutorGroup returns the parallel group of the executor at index "i",
+// from the "executor-group.<i>" tag. An empty string means the executor
+// isn't in a group.
+func ExecutorGroup(t *Task, i int) string {
+	return t.GetTags()[ExecutorGroupTag+"."+strconv.Itoa(i)]
+}
+
+// ExecutorBackground returns true if the executor at index "i" runs in the
+// background, from the "executor-background.<i>" tag.
+func ExecutorBackground(t *Task, i int) (bool, error) {
+	return executorBoolTag(t, ExecutorBackgroundTag, i)
+}
+
+// ExecutorIgnoreError returns true if the task continues when the executor
+// at index "i" fails, from the "executor-ignore-error.<i>" tag.
+func ExecutorIgnoreError(t *Task, i int) (bool, error) {
+	return executorBoolTag(t, ExecutorIgnoreErrorTag, i)
+}
+
+// ExecutorStages splits the executors of the task into the stages the worker
+// runs, in order. Each background executor is a stage of its own, each parallel
+// group is a stage, and so is each other executor.
+func ExecutorStages(t *Task) ([]ExecutorStage, error) {
+	var stages []ExecutorStage
+	var foreground bool
+	seen := map[string]bool{}
+
+	for i := range t.GetExecutors() {
+		bg, err := ExecutorBackground(t, i)
+		if err != nil {
+			return nil, err
+		}
+		group := ExecutorGroup(t, i)
+
+		switch {
+		case bg && group != "":
+			return nil, fmt.Errorf("executor %d: background executors can't be in a parallel group", i)
+
+		case bg:
+			stages = append(stages, ExecutorStage{Executors: []int{i}, Background: true})
+
+		case group != "" && i > 0 && ExecutorGroup(t, i-1) == group:
+			last := &stages[len(stages)-1]
+			last.Executors = append(last.Executors, i)
+
+		case seen[group]:
+			return nil, fmt.Errorf("executor %d: executors in parallel group %s must be consecutive", i, group)
+
+		default:
+			if group != "" {
+				seen[group] = true
+			}
+			stages = append(stages, ExecutorStage{Executors: []int{i}})
+		}
+		foreground = foreground || !bg
+	}
+
+	if len(stages) > 0 && !foreground {
+		return nil, fmt.Errorf("at least one executor must run in the foreground")
+	}
+	return stages, nil
+}
+
+// executorTags validates the executor tags of the task.
+func executorTags(t *Task) error {
+	if _, err := ExecutorStages(t); err != nil {
+		return err
+	}
+	for i := range t.GetExecutors() {
+		if _, err := ExecutorIgnoreError(t, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executorBoolTag(t *Task, prefix string, i int) (bool, error) {
+	tag := prefix + "." + strconv.Itoa(i)
+	v, ok := t.GetTags()[tag]
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("tag %s=%s: must be true or false", tag, v)
+	}
+	return b, nil
+}
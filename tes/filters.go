@@ -0,0 +1,119 @@
+package tes
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValidateListTasksRequest returns an error if the filters of the
+// ListTasks request are invalid.
+func ValidateListTasksRequest(req *ListTasksRequest) error {
+	if _, _, err := CreationTimeRange(req); err != nil {
+		return err
+	}
+	for _, s := range req.States {
+		if s == Unknown {
+			return fmt.Errorf("States: filtering by the UNKNOWN state is not allowed")
+		}
+	}
+	return nil
+}
+
+// CreationTimeRange returns the creation time range filter of the request.
+// A zero time means the range is open on that side.
+func CreationTimeRange(req *ListTasksRequest) (after, before time.Time, err error) {
+	if req.CreatedAfter != "" {
+		after, err = time.Parse(time.RFC3339Nano, req.CreatedAfter)
+		if err != nil {
+			return after, before, fmt.Errorf("CreatedAfter: %s", err)
+		}
+	}
+	if req.CreatedBefore != "" {
+		before, err = time.Parse(time.RFC3339Nano, req.CreatedBefore)
+		if err != nil {
+			return after, before, fmt.Errorf("CreatedBefore: %s", err)
+		}
+	}
+	return after, before, nil
+}
+
+// NeedsBasicView returns true if the request filters on task fields which
+// aren't in the minimal view, so backends must load the basic view
+// to filter the tasks.
+func NeedsBasicView(req *ListTasksRequest) bool {
+	return len(req.Tags) > 0 || len(req.TagKeys) > 0 || len(req.TagPrefixes) > 0 ||
+		req.NamePrefix != "" || req.CreatedAfter != "" || req.CreatedBefore != ""
+}
+
+// MatchesFilters returns true if the task matches all the filters of the
+// ListTasks request. The task must include the fields of the basic view,
+// if NeedsBasicView(req) is true.
+func MatchesFilters(task *Task, req *ListTasksRequest) bool {
+	if req.State != Unknown && req.State != task.State {
+		return false
+	}
+
+	if len(req.States) > 0 {
+		found := false
+		for _, s := range req.States {
+			if s == task.State {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !strings.HasPrefix(task.Name, req.NamePrefix) {
+		return false
+	}
+
+	for k, v := range req.Tags {
+		tval, ok := task.Tags[k]
+		if !ok || tval != v {
+			return false
+		}
+	}
+
+	for _, k := range req.TagKeys {
+		if _, ok := task.Tags[k]; !ok {
+			return false
+		}
+	}
+
+	for k, v := range req.TagPrefixes {
+		tval, ok := task.Tags[k]
+		if !ok || !strings.HasPrefix(tval, v) {
+			return false
+		}
+	}
+
+	return MatchesCreationTime(task, req)
+}
+
+// MatchesCreationTime returns true if the task was created in the creation
+// time range of the ListTasks request. Backends which can't compare the
+// creation times in queries use this to filter tasks.
+func MatchesCreationTime(task *Task, req *ListTasksRequest) bool {
+	if req.CreatedAfter == "" && req.CreatedBefore == "" {
+		return true
+	}
+	after, before, err := CreationTimeRange(req)
+	if err != nil {
+		return false
+	}
+	created, err := time.Parse(time.RFC3339Nano, task.CreationTime)
+	if err != nil {
+		return false
+	}
+	if !after.IsZero() && created.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !created.Before(before) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package tes
+
+import (
+	"testing"
+)
+
+func TestMatchesFilters(t *testing.T) {
+	task := &Task{
+		Id:           "task-1",
+		State:        State_RUNNING,
+		Name:         "align-sample-1",
+		CreationTime: "2018-01-15T12:00:00.123456789Z",
+		Tags: map[string]string{
+			"project": "1000g",
+			"sample":  "NA12878",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		req     *ListTasksRequest
+		matches bool
+	}{
+		{"no filters", &ListTasksRequest{}, true},
+		{"state", &ListTasksRequest{State: State_RUNNING}, true},
+		{"wrong state", &ListTasksRequest{State: State_QUEUED}, false},
+		{"states", &ListTasksRequest{States: []State{State_QUEUED, State_RUNNING}}, true},
+		{"wrong states", &ListTasksRequest{States: []State{State_QUEUED, State_COMPLETE}}, false},
+		{"name prefix", &ListTasksRequest{NamePrefix: "align-"}, true},
+		{"wrong name prefix", &ListTasksRequest{NamePrefix: "call-"}, false},
+		{"tag", &ListTasksRequest{Tags: map[string]string{"project": "1000g"}}, true},
+		{"wrong tag", &ListTasksRequest{Tags: map[string]string{"project": "tcga"}}, false},
+		{"tag keys", &ListTasksRequest{TagKeys: []string{"project", "sample"}}, true},
+		{"missing tag key", &ListTasksRequest{TagKeys: []string{"project", "owner"}}, false},
+		{"tag prefix", &ListTasksRequest{TagPrefixes: map[string]string{"sample": "NA12"}}, true},
+		{"wrong tag prefix", &ListTasksRequest{TagPrefixes: map[string]string{"sample": "HG"}}, false},
+		{"missing tag prefix key", &ListTasksRequest{TagPrefixes: map[string]string{"owner": ""}}, false},
+		{"created after", &ListTasksRequest{CreatedAfter: "2018-01-15T12:00:00.123456789Z"}, true},
+		{"created after, other zone", &ListTasksRequest{CreatedAfter: "2018-01-15T05:00:00-07:00"}, true},
+		{"not created after", &ListTasksRequest{CreatedAfter: "2018-01-16T00:00:00Z"}, false},
+		{"created before", &ListTasksRequest{CreatedBefore: "2018-01-16T00:00:00Z"}, true},
+		{"not created before", &ListTasksRequest{CreatedBefore: "2018-01-15T12:00:00.123456789Z"}, false},
+		{"time range", &ListTasksRequest{
+			CreatedAfter:  "2018-01-01T00:00:00Z",
+			CreatedBefore: "2018-02-01T00:00:00Z",
+		}, true},
+		{"all filters", &ListTasksRequest{
+			State:         State_RUNNING,
+			States:        []State{State_RUNNING},
+			NamePrefix:    "align",
+			CreatedAfter:  "2018-01-01T00:00:00Z",
+			Tags:          map[string]string{"project": "1000g"},
+			TagKeys:       []string{"sample"},
+			TagPrefixes:   map[string]string{"sample": "NA"},
+			CreatedBefore: "2018-02-01T00:00:00Z",
+		}, true},
+	}
+
+	for _, test := range tests {
+		if m := MatchesFilters(task, test.req); m != test.matches {
+			t.Errorf("%s: expected match to be %v, got %v", test.name, test.matches, m)
+		}
+	}
+}
+
+func TestMatchesCreationTimeUnparseable(t *testing.T) {
+	task := &Task{CreationTime: "not a time"}
+	if !MatchesCreationTime(task, &ListTasksRequest{}) {
+		t.Error("expected a task to match when there is no time range")
+	}
+	if MatchesCreationTime(task, &ListTasksRequest{CreatedAfter: "2018-01-01T00:00:00Z"}) {
+		t.Error("expected a task with an unparseable creation time not to match a time range")
+	}
+}
+
+func TestValidateListTasksRequest(t *testing.T) {
+	valid := []*ListTasksRequest{
+		{},
+		{CreatedAfter: "2018-01-01T00:00:00Z", CreatedBefore: "2018-01-01T00:00:00.5+02:00"},
+		{States: []State{State_QUEUED, State_RUNNING}},
+	}
+	for _, req := range valid {
+		if err := ValidateListTasksRequest(req); err != nil {
+			t.Errorf("unexpected error for %v: %s", req, err)
+		}
+	}
+
+	invalid := []*ListTasksRequest{
+		{CreatedAfter: "2018-01-01"},
+		{CreatedBefore: "yesterday"},
+		{States: []State{State_QUEUED, State_UNKNOWN}},
+	}
+	for _, req := range invalid {
+		if err := ValidateListTasksRequest(req); err == nil {
+			t.Errorf("expected an error for %v", req)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package tes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputsOnErrorTag is the task tag which lists the outputs uploaded when an
+// executor fails, as a comma-separated list of output paths, or "all".
+// By default, outputs are only uploaded when all the executors succeed.
+const OutputsOnErrorTag = "outputs-on-error"
+
+// OutputsOnError returns the paths of the outputs uploaded when an executor
+// fails, from the "outputs-on-error" tag.
+func OutputsOnError(t *Task) ([]string, error) {
+	v, ok := t.GetTags()[OutputsOnErrorTag]
+	if !ok {
+		return nil, nil
+	}
+
+	declared := map[string]bool{}
+	var all []string
+	for _, o := range t.GetOutputs() {
+		declared[o.Path] = true
+		all = append(all, o.Path)
+	}
+	if v == "all" {
+		return all, nil
+	}
+
+	var paths []string
+	for _, p := range strings.Split(v, ",") {
+		p = strings.TrimSpace(p)
+		if !declared[p] {
+			return nil, fmt.Errorf("tag %s: %q is not an output path", OutputsOnErrorTag, p)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
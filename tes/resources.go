@@ -0,0 +1,36 @@
+package tes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResourceTagPrefix is the prefix of task tags which request custom resources,
+// e.g. "resource.nvidia.com/gpu": "1" requests one "nvidia.com/gpu".
+const ResourceTagPrefix = "resource."
+
+// CustomResources returns the custom resources requested by the task tags,
+// by resource name. An error is returned if a requested amount isn't
+// a non-negative number.
+func CustomResources(t *Task) (map[string]float64, error) {
+	var res map[string]float64
+	for k, v := range t.GetTags() {
+		if !strings.HasPrefix(k, ResourceTagPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(k, ResourceTagPrefix)
+		if name == "" {
+			return nil, fmt.Errorf("tag %s: empty resource name", k)
+		}
+		amount, err := strconv.ParseFloat(v, 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("tag %s=%s: resource amount must be a non-negative number", k, v)
+		}
+		if res == nil {
+			res = map[string]float64{}
+		}
+		res[name] = amount
+	}
+	return res, nil
+}
@@ -52,8 +52,11 @@ func ValidateTransition(from, to State) error {
 	case Initializing:
 
 		switch to {
-		case Unknown, Queued:
+		case Unknown:
 			return &TransitionError{from, to}
+		case Queued:
+			// The task is re-queued, e.g. when its node shut down.
+			return nil
 		case Running, ExecutorError, SystemError, Canceled:
 			return nil
 		}
@@ -61,8 +64,11 @@ func ValidateTransition(from, to State) error {
 	case Running:
 
 		switch to {
-		case Unknown, Queued:
+		case Unknown:
 			return &TransitionError{from, to}
+		case Queued:
+			// The task is re-queued, e.g. when its node shut down.
+			return nil
 		case Complete, ExecutorError, SystemError, Canceled:
 			return nil
 		}
@@ -0,0 +1,297 @@
+package tes
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+)
+
+// TaskStatsServer is implemented by databases which compute task stats
+// in their queries. Stats of the other databases are computed by
+// ComputeTaskStats.
+type TaskStatsServer interface {
+	GetTaskStats(ctx context.Context, req *GetTaskStatsRequest) (*GetTaskStatsResponse, error)
+}
+
+// DefaultPercentiles are the percentiles of task stats durations,
+// if the request doesn't set them.
+var DefaultPercentiles = []float64{50, 90, 99}
+
+// ValidateGetTaskStatsRequest returns an error if the GetTaskStats request
+// is invalid.
+func ValidateGetTaskStatsRequest(req *GetTaskStatsRequest) error {
+	if err := ValidateListTasksRequest(StatsFilter(req)); err != nil {
+		return fmt.Errorf("Filter.%s", err)
+	}
+	for _, p := range req.Percentiles {
+		if !(p > 0 && p <= 100) {
+			return fmt.Errorf("Percentiles: %v is not between 0 (exclusive) and 100 (inclusive)", p)
+		}
+	}
+	seen := map[string]bool{}
+	for _, k := range req.GroupByTags {
+		if seen[k] {
+			return fmt.Errorf("GroupByTags: duplicate tag %q", k)
+		}
+		seen[k] = true
+	}
+	if _, ok := TimeBucket_name[int32(req.GroupByTime)]; !ok {
+		return fmt.Errorf("GroupByTime: unknown time bucket %d", req.GroupByTime)
+	}
+	return nil
+}
+
+// StatsFilter returns the filter of the GetTaskStats request.
+// It is never nil.
+func StatsFilter(req *GetTaskStatsRequest) *ListTasksRequest {
+	if req.Filter == nil {
+		return &ListTasksRequest{}
+	}
+	return req.Filter
+}
+
+// StatsPercentiles returns the percentiles of the GetTaskStats request,
+// or DefaultPercentiles if they aren't set.
+func StatsPercentiles(req *GetTaskStatsRequest) []float64 {
+	if len(req.Percentiles) == 0 {
+		return DefaultPercentiles
+	}
+	return req.Percentiles
+}
+
+// TimeBucketSize returns the size of the time bucket and the offset of the
+// start of the buckets from the Unix epoch. Weeks start on Monday, and the
+// epoch was a Thursday.
+func TimeBucketSize(b TimeBucket) (size, offset time.Duration) {
+	switch b {
+	case TimeBucket_HOUR:
+		return time.Hour, 0
+	case TimeBucket_DAY:
+		return 24 * time.Hour, 0
+	case TimeBucket_WEEK:
+		return 7 * 24 * time.Hour, -3 * 24 * time.Hour
+	}
+	return 0, 0
+}
+
+// TimeBucketStart returns the start of the time bucket which includes "t".
+func TimeBucketStart(t time.Time, b TimeBucket) time.Time {
+	size, offset := TimeBucketSize(b)
+	if size == 0 {
+		return time.Time{}
+	}
+	ns := t.UnixNano() - int64(offset)
+	r := ns % int64(size)
+	if r < 0 {
+		// Times before the epoch
+		r += int64(size)
+	}
+	return time.Unix(0, ns-r+int64(offset)).UTC()
+}
+
+// TaskRunTime returns the run time of the task's last attempt,
+// if it started and ended.
+func TaskRunTime(task *Task) (time.Duration, bool) {
+	if len(task.Logs) == 0 {
+		return 0, false
+	}
+	last := task.Logs[len(task.Logs)-1]
+	return timeBetween(last.StartTime, last.EndTime)
+}
+
+// TaskQueueTime returns the time from the creation of the task to the start
+// of its first attempt, if it started.
+func TaskQueueTime(task *Task) (time.Duration, bool) {
+	if len(task.Logs) == 0 {
+		return 0, false
+	}
+	return timeBetween(task.CreationTime, task.Logs[0].StartTime)
+}
+
+func timeBetween(start, end string) (time.Duration, bool) {
+	s, err := time.Parse(time.RFC3339Nano, start)
+	if err != nil {
+		return 0, false
+	}
+	e, err := time.Parse(time.RFC3339Nano, end)
+	if err != nil {
+		return 0, false
+	}
+	return e.Sub(s), true
+}
+
+// NewDurationStats returns the stats of the durations, in seconds.
+// The durations are sorted in place.
+func NewDurationStats(durations []float64, percentiles []float64) *DurationStats {
+	n := len(durations)
+	if n == 0 {
+		return &DurationStats{}
+	}
+	sort.Float64s(durations)
+
+	sum := 0.0
+	for _, d := range durations {
+		sum += d
+	}
+
+	ds := &DurationStats{
+		Count: int64(n),
+		Mean:  sum / float64(n),
+		Min:   durations[0],
+		Max:   durations[n-1],
+	}
+	for _, p := range percentiles {
+		ds.Percentiles = append(ds.Percentiles, durations[PercentileRank(p, n)-1])
+	}
+	return ds
+}
+
+// PercentileRank returns the nearest rank, from 1 to n, of the "p" percentile
+// of n sorted values.
+func PercentileRank(p float64, n int) int {
+	rank := int(math.Ceil(p * float64(n) / 100))
+	if rank < 1 {
+		return 1
+	}
+	if rank > n {
+		return n
+	}
+	return rank
+}
+
+// NewTaskStatsResponse returns the response with the groups of stats,
+// sorted by time bucket, state and tag values. If the request doesn't
+// group tasks and no tasks were counted, the response has one empty group.
+func NewTaskStatsResponse(req *GetTaskStatsRequest, groups []*TaskStatsGroup) *GetTaskStatsResponse {
+	grouped := req.GroupByState || len(req.GroupByTags) > 0 || req.GroupByTime != TimeBucket_ALL_TIME
+	if len(groups) == 0 && !grouped {
+		groups = append(groups, &TaskStatsGroup{
+			RunTime:   &DurationStats{},
+			QueueTime: &DurationStats{},
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.TimeBucket != b.TimeBucket {
+			return a.TimeBucket < b.TimeBucket
+		}
+		if a.State != b.State {
+			return a.State < b.State
+		}
+		for _, k := range req.GroupByTags {
+			av, aok := a.Tags[k]
+			bv, bok := b.Tags[k]
+			if aok != bok {
+				return !aok
+			}
+			if av != bv {
+				return av < bv
+			}
+		}
+		return false
+	})
+	return &GetTaskStatsResponse{Groups: groups}
+}
+
+// TaskStatsBuilder computes task stats from the tasks added to it.
+// Tasks must include the fields of the basic view.
+type TaskStatsBuilder struct {
+	req    *GetTaskStatsRequest
+	groups map[string]*statsGroup
+}
+
+type statsGroup struct {
+	group     *TaskStatsGroup
+	runTime   []float64
+	queueTime []float64
+}
+
+// NewTaskStatsBuilder returns a new TaskStatsBuilder for the request.
+// The request filters aren't applied to the tasks.
+func NewTaskStatsBuilder(req *GetTaskStatsRequest) *TaskStatsBuilder {
+	return &TaskStatsBuilder{req: req, groups: map[string]*statsGroup{}}
+}
+
+// Add counts the task in its group.
+func (b *TaskStatsBuilder) Add(task *Task) {
+	g := &TaskStatsGroup{}
+	if b.req.GroupByState {
+		g.State = task.State
+	}
+	for _, k := range b.req.GroupByTags {
+		if v, ok := task.Tags[k]; ok {
+			if g.Tags == nil {
+				g.Tags = map[string]string{}
+			}
+			g.Tags[k] = v
+		}
+	}
+	if b.req.GroupByTime != TimeBucket_ALL_TIME {
+		created, err := time.Parse(time.RFC3339Nano, task.CreationTime)
+		if err == nil {
+			g.TimeBucket = TimeBucketStart(created, b.req.GroupByTime).Format(time.RFC3339)
+		}
+	}
+
+	// The key distinguishes missing tags from empty tags.
+	key := fmt.Sprintf("%d %q", g.State, g.TimeBucket)
+	for _, k := range b.req.GroupByTags {
+		v, ok := g.Tags[k]
+		key += fmt.Sprintf(" %t %q", ok, v)
+	}
+
+	sg, ok := b.groups[key]
+	if !ok {
+		sg = &statsGroup{group: g}
+		b.groups[key] = sg
+	}
+	sg.group.Count++
+	if d, ok := TaskRunTime(task); ok {
+		sg.runTime = append(sg.runTime, d.Seconds())
+	}
+	if d, ok := TaskQueueTime(task); ok {
+		sg.queueTime = append(sg.queueTime, d.Seconds())
+	}
+}
+
+// Response returns the stats of the tasks added so far.
+func (b *TaskStatsBuilder) Response() *GetTaskStatsResponse {
+	percentiles := StatsPercentiles(b.req)
+	var groups []*TaskStatsGroup
+	for _, sg := range b.groups {
+		sg.group.RunTime = NewDurationStats(sg.runTime, percentiles)
+		sg.group.QueueTime = NewDurationStats(sg.queueTime, percentiles)
+		groups = append(groups, sg.group)
+	}
+	return NewTaskStatsResponse(b.req, groups)
+}
+
+// ComputeTaskStats computes task stats by listing all the tasks which
+// match the request's filter.
+func ComputeTaskStats(ctx context.Context, r ReadOnlyServer, req *GetTaskStatsRequest) (*GetTaskStatsResponse, error) {
+	list := proto.Clone(StatsFilter(req)).(*ListTasksRequest)
+	list.View = TaskView_BASIC
+	list.PageSize = 2048
+	list.PageToken = ""
+
+	b := NewTaskStatsBuilder(req)
+	for {
+		resp, err := r.ListTasks(ctx, list)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range resp.Tasks {
+			b.Add(task)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		list.PageToken = resp.NextPageToken
+	}
+	return b.Response(), nil
+}
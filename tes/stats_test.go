@@ -0,0 +1,234 @@
+package tes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestPercentileRank(t *testing.T) {
+	tests := []struct {
+		p    float64
+		n    int
+		rank int
+	}{
+		{50, 1, 1},
+		{50, 4, 2},
+		{50, 5, 3},
+		{90, 10, 9},
+		{99, 10, 10},
+		{100, 10, 10},
+		{0.1, 10, 1},
+	}
+	for _, test := range tests {
+		if r := PercentileRank(test.p, test.n); r != test.rank {
+			t.Errorf("rank of p%v of %d values: expected %d, got %d", test.p, test.n, test.rank, r)
+		}
+	}
+}
+
+func TestNewDurationStats(t *testing.T) {
+	ds := NewDurationStats([]float64{4, 1, 3, 2}, []float64{50, 100})
+	expected := &DurationStats{
+		Count:       4,
+		Mean:        2.5,
+		Min:         1,
+		Max:         4,
+		Percentiles: []float64{2, 4},
+	}
+	if !reflect.DeepEqual(ds, expected) {
+		t.Errorf("expected %v, got %v", expected, ds)
+	}
+
+	if ds := NewDurationStats(nil, []float64{50}); !reflect.DeepEqual(ds, &DurationStats{}) {
+		t.Errorf("expected empty stats, got %v", ds)
+	}
+}
+
+func TestTimeBucketStart(t *testing.T) {
+	// A Wednesday
+	ts := time.Date(2018, 1, 17, 13, 45, 0, 0, time.FixedZone("", -7*60*60))
+	tests := []struct {
+		bucket TimeBucket
+		start  string
+	}{
+		{TimeBucket_HOUR, "2018-01-17T20:00:00Z"},
+		{TimeBucket_DAY, "2018-01-17T00:00:00Z"},
+		{TimeBucket_WEEK, "2018-01-15T00:00:00Z"},
+	}
+	for _, test := range tests {
+		s := TimeBucketStart(ts, test.bucket).Format(time.RFC3339)
+		if s != test.start {
+			t.Errorf("%s: expected %s, got %s", test.bucket, test.start, s)
+		}
+	}
+
+	// Times before the epoch
+	s := TimeBucketStart(time.Date(1969, 12, 31, 23, 0, 0, 0, time.UTC), TimeBucket_WEEK)
+	if e := time.Date(1969, 12, 29, 0, 0, 0, 0, time.UTC); !s.Equal(e) {
+		t.Errorf("expected %s, got %s", e, s)
+	}
+}
+
+func TestTaskDurations(t *testing.T) {
+	task := &Task{
+		CreationTime: "2018-01-15T12:00:00Z",
+		Logs: []*TaskLog{
+			{StartTime: "2018-01-15T12:00:10Z", EndTime: "2018-01-15T12:00:20Z"},
+			{StartTime: "2018-01-15T12:01:00Z", EndTime: "2018-01-15T12:01:30.5Z"},
+		},
+	}
+	if d, ok := TaskRunTime(task); !ok || d != 30500*time.Millisecond {
+		t.Errorf("unexpected run time %s, %v", d, ok)
+	}
+	if d, ok := TaskQueueTime(task); !ok || d != 10*time.Second {
+		t.Errorf("unexpected queue time %s, %v", d, ok)
+	}
+
+	task.Logs[1].EndTime = ""
+	if _, ok := TaskRunTime(task); ok {
+		t.Error("expected no run time for an attempt which didn't end")
+	}
+	if _, ok := TaskQueueTime(&Task{CreationTime: task.CreationTime}); ok {
+		t.Error("expected no queue time for a task which didn't start")
+	}
+}
+
+func statsTask(id string, state State, created string, run time.Duration, tags map[string]string) *Task {
+	task := &Task{Id: id, State: state, CreationTime: created, Tags: tags}
+	if run > 0 {
+		start, _ := time.Parse(time.RFC3339, created)
+		start = start.Add(time.Second)
+		task.Logs = []*TaskLog{{
+			StartTime: start.Format(time.RFC3339Nano),
+			EndTime:   start.Add(run).Format(time.RFC3339Nano),
+		}}
+	}
+	return task
+}
+
+func TestTaskStatsBuilder(t *testing.T) {
+	req := &GetTaskStatsRequest{
+		GroupByState: true,
+		GroupByTags:  []string{"project"},
+		GroupByTime:  TimeBucket_DAY,
+		Percentiles:  []float64{50},
+	}
+	b := NewTaskStatsBuilder(req)
+	b.Add(statsTask("1", State_COMPLETE, "2018-01-15T12:00:00Z", 10*time.Second, map[string]string{"project": "a"}))
+	b.Add(statsTask("2", State_COMPLETE, "2018-01-15T13:00:00Z", 20*time.Second, map[string]string{"project": "a"}))
+	b.Add(statsTask("3", State_COMPLETE, "2018-01-15T14:00:00Z", 30*time.Second, map[string]string{"project": ""}))
+	b.Add(statsTask("4", State_COMPLETE, "2018-01-15T15:00:00Z", 40*time.Second, nil))
+	b.Add(statsTask("5", State_QUEUED, "2018-01-15T16:00:00Z", 0, map[string]string{"project": "a"}))
+	b.Add(statsTask("6", State_COMPLETE, "2018-01-14T16:00:00Z", 50*time.Second, map[string]string{"project": "a"}))
+
+	type group struct {
+		bucket  string
+		state   State
+		tags    map[string]string
+		count   int64
+		runTime []float64
+	}
+	expected := []group{
+		{"2018-01-14T00:00:00Z", State_COMPLETE, map[string]string{"project": "a"}, 1, []float64{50}},
+		{"2018-01-15T00:00:00Z", State_QUEUED, map[string]string{"project": "a"}, 1, nil},
+		{"2018-01-15T00:00:00Z", State_COMPLETE, nil, 1, []float64{40}},
+		{"2018-01-15T00:00:00Z", State_COMPLETE, map[string]string{"project": ""}, 1, []float64{30}},
+		{"2018-01-15T00:00:00Z", State_COMPLETE, map[string]string{"project": "a"}, 2, []float64{10}},
+	}
+
+	resp := b.Response()
+	if len(resp.Groups) != len(expected) {
+		t.Fatalf("expected %d groups, got %d: %v", len(expected), len(resp.Groups), resp.Groups)
+	}
+	for i, e := range expected {
+		g := resp.Groups[i]
+		if g.TimeBucket != e.bucket || g.State != e.state || !reflect.DeepEqual(g.Tags, e.tags) || g.Count != e.count {
+			t.Errorf("group %d: expected %v, got %v", i, e, g)
+		}
+		if !reflect.DeepEqual(g.RunTime.Percentiles, e.runTime) {
+			t.Errorf("group %d: expected run time percentiles %v, got %v", i, e.runTime, g.RunTime.Percentiles)
+		}
+		if e.runTime != nil && g.QueueTime.Max != 1 {
+			t.Errorf("group %d: expected a queue time of 1s, got %v", i, g.QueueTime)
+		}
+	}
+}
+
+func TestTaskStatsUngrouped(t *testing.T) {
+	resp := NewTaskStatsBuilder(&GetTaskStatsRequest{}).Response()
+	if len(resp.Groups) != 1 || resp.Groups[0].Count != 0 || resp.Groups[0].RunTime == nil {
+		t.Errorf("expected one empty group, got %v", resp.Groups)
+	}
+
+	grouped := NewTaskStatsBuilder(&GetTaskStatsRequest{GroupByState: true}).Response()
+	if len(grouped.Groups) != 0 {
+		t.Errorf("expected no groups, got %v", grouped.Groups)
+	}
+}
+
+type listTasksServer struct {
+	ReadOnlyServer
+	tasks []*Task
+}
+
+// ListTasks returns pages of one task.
+func (s *listTasksServer) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTasksResponse, error) {
+	resp := &ListTasksResponse{}
+	for i, task := range s.tasks {
+		if task.Id > req.PageToken && MatchesFilters(task, req) {
+			resp.Tasks = []*Task{task}
+			if i < len(s.tasks)-1 {
+				resp.NextPageToken = task.Id
+			}
+			break
+		}
+	}
+	return resp, nil
+}
+
+func TestComputeTaskStats(t *testing.T) {
+	s := &listTasksServer{tasks: []*Task{
+		statsTask("1", State_COMPLETE, "2018-01-15T12:00:00Z", 10*time.Second, nil),
+		statsTask("2", State_COMPLETE, "2018-01-15T12:00:00Z", 20*time.Second, nil),
+		statsTask("3", State_COMPLETE, "2018-01-15T12:00:00Z", 30*time.Second, nil),
+		statsTask("4", State_RUNNING, "2018-01-15T12:00:00Z", 0, nil),
+	}}
+	resp, err := ComputeTaskStats(context.Background(), s, &GetTaskStatsRequest{
+		Filter: &ListTasksRequest{State: State_COMPLETE},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &DurationStats{Count: 3, Mean: 20, Min: 10, Max: 30, Percentiles: []float64{20, 30, 30}}
+	if len(resp.Groups) != 1 || resp.Groups[0].Count != 3 || !reflect.DeepEqual(resp.Groups[0].RunTime, expected) {
+		t.Errorf("unexpected stats %v", resp.Groups)
+	}
+}
+
+func TestValidateGetTaskStatsRequest(t *testing.T) {
+	valid := []*GetTaskStatsRequest{
+		{},
+		{GroupByTags: []string{"a", "b"}, Percentiles: []float64{0.1, 100}, GroupByTime: TimeBucket_WEEK},
+	}
+	for _, req := range valid {
+		if err := ValidateGetTaskStatsRequest(req); err != nil {
+			t.Errorf("unexpected error for %v: %s", req, err)
+		}
+	}
+
+	invalid := []*GetTaskStatsRequest{
+		{Filter: &ListTasksRequest{CreatedAfter: "yesterday"}},
+		{Percentiles: []float64{0}},
+		{Percentiles: []float64{101}},
+		{GroupByTags: []string{"a", "a"}},
+		{GroupByTime: TimeBucket(10)},
+	}
+	for _, req := range invalid {
+		if err := ValidateGetTaskStatsRequest(req); err == nil {
+			t.Errorf("expected an error for %v", req)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package tes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Task tags which set time limits. Values are durations, e.g. "90s", "30m" or "2h".
+const (
+	// TimeoutTag limits the wall-clock time of the whole task.
+	TimeoutTag = "timeout"
+	// ExecutorTimeoutTag limits the run time of each executor. The limit of
+	// a single executor is set by adding its index, e.g. "executor-timeout.0".
+	ExecutorTimeoutTag = "executor-timeout"
+)
+
+// Timeout returns the wall-clock time limit of the task, from the "timeout" tag.
+// 0 means the task doesn't set a limit.
+func Timeout(t *Task) (time.Duration, error) {
+	return timeoutTag(t, TimeoutTag)
+}
+
+// ExecutorTimeout returns the time limit of the executor at index "i", from the
+// "executor-timeout.<i>" tag, or else the "executor-timeout" tag.
+// 0 means the task doesn't set a limit.
+func ExecutorTimeout(t *Task, i int) (time.Duration, error) {
+	d, err := timeoutTag(t, ExecutorTimeoutTag+"."+strconv.Itoa(i))
+	if err != nil || d > 0 {
+		return d, err
+	}
+	return timeoutTag(t, ExecutorTimeoutTag)
+}
+
+// timeouts validates the time limits set by the task tags.
+// "executor-timeout.<i>" tags must refer to one of the task's executors.
+func timeouts(t *Task) error {
+	if _, err := Timeout(t); err != nil {
+		return err
+	}
+	prefix := ExecutorTimeoutTag + "."
+	for tag := range t.GetTags() {
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(tag, prefix))
+		if err != nil || i < 0 || i >= len(t.GetExecutors()) {
+			return fmt.Errorf("tag %s: no executor with index %s", tag, strings.TrimPrefix(tag, prefix))
+		}
+	}
+	for i := range t.GetExecutors() {
+		if _, err := ExecutorTimeout(t, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func timeoutTag(t *Task, tag string) (time.Duration, error) {
+	v, ok := t.GetTags()[tag]
+	if !ok {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("tag %s=%s: timeout must be a positive duration, e.g. 30m", tag, v)
+	}
+	return d, nil
+}
@@ -15,3 +15,31 @@ func addUInt32(u url.Values, key string, value uint32) {
 		u.Add(key, fmt.Sprint(value))
 	}
 }
+
+// addListFilters adds the filters of the ListTasks request,
+// with the given prefix, e.g. "filter.".
+func addListFilters(u url.Values, prefix string, req *ListTasksRequest) {
+	if req.GetState() != Unknown {
+		addString(u, prefix+"state", req.State.String())
+	}
+
+	for key, val := range req.Tags {
+		u.Add(fmt.Sprintf("%stags[%s]", prefix, key), val)
+	}
+
+	addString(u, prefix+"name_prefix", req.GetNamePrefix())
+	addString(u, prefix+"created_after", req.GetCreatedAfter())
+	addString(u, prefix+"created_before", req.GetCreatedBefore())
+
+	for _, state := range req.States {
+		u.Add(prefix+"states", state.String())
+	}
+
+	for _, key := range req.TagKeys {
+		u.Add(prefix+"tag_keys", key)
+	}
+
+	for key, val := range req.TagPrefixes {
+		u.Add(fmt.Sprintf("%stag_prefixes[%s]", prefix, key), val)
+	}
+}
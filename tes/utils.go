@@ -110,6 +110,15 @@ func (task *Task) GetTaskLog(i int) *TaskLog {
 	return task.Logs[i]
 }
 
+// Attempt returns the index of the task's current attempt, i.e. of its last
+// task log. A re-queued task runs again as a new attempt.
+func (task *Task) Attempt() uint32 {
+	if n := len(task.GetLogs()); n > 0 {
+		return uint32(n - 1)
+	}
+	return 0
+}
+
 // GetExecLog gets the executor log entry at the given index "i".
 // If the entry doesn't exist, empty logs will be appended up to "i".
 func (task *Task) GetExecLog(attempt int, i int) *ExecutorLog {
@@ -96,5 +96,21 @@ func Validate(t *Task) ValidationError {
 		}
 	}
 
+	if _, err := CustomResources(t); err != nil {
+		errs.add("Task.Tags: %s", err)
+	}
+
+	if err := timeouts(t); err != nil {
+		errs.add("Task.Tags: %s", err)
+	}
+
+	if err := executorTags(t); err != nil {
+		errs.add("Task.Tags: %s", err)
+	}
+
+	if _, err := OutputsOnError(t); err != nil {
+		errs.add("Task.Tags: %s", err)
+	}
+
 	return errs
 }
@@ -1,6 +1,10 @@
 package tes
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestValidation(t *testing.T) {
 	v := Validate(&Task{})
@@ -25,3 +29,167 @@ func TestEmptyTagKeyValidation(t *testing.T) {
 		t.Fatal("expected 1 validation error")
 	}
 }
+
+func TestCustomResourceTagValidation(t *testing.T) {
+	task := &Task{
+		Tags: map[string]string{
+			"resource.nvidia.com/gpu":  "2",
+			"resource.licenses.matlab": "0.5",
+		},
+		Executors: []*Executor{
+			{
+				Image:   "alpine",
+				Command: []string{"echo"},
+			},
+		},
+	}
+	if v := Validate(task); len(v) != 0 {
+		t.Fatal("unexpected validation errors", v)
+	}
+
+	res, err := CustomResources(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["nvidia.com/gpu"] != 2 || res["licenses.matlab"] != 0.5 {
+		t.Errorf("unexpected custom resources: %v", res)
+	}
+
+	task.Tags["resource.nvidia.com/gpu"] = "two"
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error")
+	}
+}
+
+func TestTimeoutTags(t *testing.T) {
+	task := &Task{
+		Tags: map[string]string{
+			"timeout":            "2h",
+			"executor-timeout":   "30m",
+			"executor-timeout.1": "90s",
+		},
+		Executors: []*Executor{
+			{Image: "alpine", Command: []string{"echo"}},
+			{Image: "alpine", Command: []string{"echo"}},
+		},
+	}
+	if v := Validate(task); len(v) != 0 {
+		t.Fatal("unexpected validation errors", v)
+	}
+
+	if d, _ := Timeout(task); d != 2*time.Hour {
+		t.Errorf("unexpected task timeout: %s", d)
+	}
+	if d, _ := ExecutorTimeout(task, 0); d != 30*time.Minute {
+		t.Errorf("unexpected executor 0 timeout: %s", d)
+	}
+	if d, _ := ExecutorTimeout(task, 1); d != 90*time.Second {
+		t.Errorf("unexpected executor 1 timeout: %s", d)
+	}
+
+	task.Tags["executor-timeout.1"] = "soon"
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error")
+	}
+	task.Tags["executor-timeout.1"] = "90s"
+
+	for _, tag := range []string{"executor-timeout.2", "executor-timeout.-1", "executor-timeout.first"} {
+		task.Tags[tag] = "1m"
+		if v := Validate(task); len(v) != 1 {
+			t.Errorf("expected validation error for %s", tag)
+		}
+		delete(task.Tags, tag)
+	}
+}
+
+func TestExecutorStageTags(t *testing.T) {
+	task := &Task{
+		Tags: map[string]string{
+			"executor-background.0": "true",
+			"executor-group.2":      "align",
+			"executor-group.3":      "align",
+		},
+		Executors: []*Executor{
+			{Image: "postgres", Command: []string{"postgres"}},
+			{Image: "alpine", Command: []string{"echo"}},
+			{Image: "alpine", Command: []string{"echo"}},
+			{Image: "alpine", Command: []string{"echo"}},
+			{Image: "alpine", Command: []string{"echo"}},
+		},
+	}
+	if v := Validate(task); len(v) != 0 {
+		t.Fatal("unexpected validation errors", v)
+	}
+
+	stages, _ := ExecutorStages(task)
+	expected := []ExecutorStage{
+		{Executors: []int{0}, Background: true},
+		{Executors: []int{1}},
+		{Executors: []int{2, 3}},
+		{Executors: []int{4}},
+	}
+	if !reflect.DeepEqual(stages, expected) {
+		t.Errorf("unexpected stages: %+v", stages)
+	}
+
+	task.Tags["executor-group.4"] = "align"
+	task.Tags["executor-group.3"] = "other"
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error for a split group", v)
+	}
+	delete(task.Tags, "executor-group.3")
+	delete(task.Tags, "executor-group.4")
+
+	task.Tags["executor-group.0"] = "align"
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error for a grouped background executor", v)
+	}
+	delete(task.Tags, "executor-group.0")
+
+	task.Tags["executor-background.1"] = "yes"
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error for an invalid background tag", v)
+	}
+
+	task.Executors = task.Executors[:1]
+	task.Tags = map[string]string{"executor-background.0": "true"}
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error for a task without foreground executors", v)
+	}
+}
+
+func TestOutputsOnErrorTag(t *testing.T) {
+	task := &Task{
+		Tags: map[string]string{
+			"outputs-on-error":        "/outputs/log.txt",
+			"executor-ignore-error.0": "true",
+		},
+		Outputs: []*Output{
+			{Url: "file:///tmp/log.txt", Path: "/outputs/log.txt"},
+			{Url: "file:///tmp/result.txt", Path: "/outputs/result.txt"},
+		},
+		Executors: []*Executor{
+			{Image: "alpine", Command: []string{"echo"}},
+		},
+	}
+	if v := Validate(task); len(v) != 0 {
+		t.Fatal("unexpected validation errors", v)
+	}
+
+	task.Tags["outputs-on-error"] = "all"
+	paths, _ := OutputsOnError(task)
+	if !reflect.DeepEqual(paths, []string{"/outputs/log.txt", "/outputs/result.txt"}) {
+		t.Errorf("unexpected outputs: %v", paths)
+	}
+
+	task.Tags["outputs-on-error"] = "/outputs/other.txt"
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error for an unknown output", v)
+	}
+	task.Tags["outputs-on-error"] = "all"
+
+	task.Tags["executor-ignore-error.0"] = "maybe"
+	if v := Validate(task); len(v) != 1 {
+		t.Fatal("expected 1 validation error for an invalid ignore error tag", v)
+	}
+}
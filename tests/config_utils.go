@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -62,6 +63,7 @@ func TestifyConfig(conf config.Config) config.Config {
 	conf.MongoDB.Database = prefix
 	conf.DynamoDB.TableBasename = prefix
 	conf.Datastore.Project = prefix
+	conf.Postgres.Schema = strings.Replace(prefix, "-", "_", -1)
 
 	reconcile := config.Duration(time.Second * 5)
 	conf.HTCondor.ReconcileRate = reconcile
@@ -102,6 +104,7 @@ func TempDirConfig(conf config.Config) config.Config {
 	conf.Worker.WorkDir = f
 	conf.BoltDB.Path = path.Join(f, "funnel.db")
 	conf.Badger.Path = path.Join(f, "funnel.badger.db")
+	conf.SQLite.Path = path.Join(f, "funnel.sqlite.db")
 	return conf
 }
 
@@ -1116,6 +1116,198 @@ func TestListTaskMultipleFilters(t *testing.T) {
 	}
 }
 
+func TestListTaskExtendedFilters(t *testing.T) {
+	tests.SetLogOutput(log, t)
+
+	c := tests.DefaultConfig()
+	f := tests.NewFunnel(c)
+	f.StartServer()
+	ctx := context.Background()
+
+	id1 := f.Run(`'echo hello' --name align-1 --tag sample=NA12878`)
+	id2 := f.Run(`'echo hello' --name align-2 --tag sample=HG00096 --tag project=1000g`)
+	id3 := f.Run(`'echo hello' --name call-1 --tag sample=NA12891`)
+
+	f.Wait(id1)
+	f.Wait(id2)
+	f.Wait(id3)
+
+	ids := func(r *tes.ListTasksResponse) []string {
+		var out []string
+		for _, task := range r.Tasks {
+			out = append(out, task.Id)
+		}
+		return out
+	}
+
+	r, err := f.HTTP.ListTasks(ctx, &tes.ListTasksRequest{
+		View:       tes.TaskView_MINIMAL,
+		NamePrefix: "align-",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Tasks) != 2 || r.Tasks[0].Id != id2 || r.Tasks[1].Id != id1 {
+		t.Error("unexpected name prefix task IDs", ids(r), id2, id1)
+	}
+
+	r, err = f.HTTP.ListTasks(ctx, &tes.ListTasksRequest{
+		View:   tes.TaskView_MINIMAL,
+		States: []tes.State{tes.Complete, tes.Canceled},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Tasks) != 3 {
+		t.Error("expected 3 tasks", ids(r))
+	}
+
+	r, err = f.HTTP.ListTasks(ctx, &tes.ListTasksRequest{
+		View:   tes.TaskView_MINIMAL,
+		States: []tes.State{tes.Queued, tes.Running},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Tasks) != 0 {
+		t.Error("expected 0 tasks", ids(r))
+	}
+
+	r, err = f.HTTP.ListTasks(ctx, &tes.ListTasksRequest{
+		View:    tes.TaskView_BASIC,
+		TagKeys: []string{"project"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Tasks) != 1 || r.Tasks[0].Id != id2 {
+		t.Error("unexpected tag key task IDs", ids(r), id2)
+	}
+
+	r, err = f.HTTP.ListTasks(ctx, &tes.ListTasksRequest{
+		View: tes.TaskView_BASIC,
+		TagPrefixes: map[string]string{
+			"sample": "NA",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Tasks) != 2 || r.Tasks[0].Id != id3 || r.Tasks[1].Id != id1 {
+		t.Error("unexpected tag prefix task IDs", ids(r), id3, id1)
+	}
+
+	task2, err := f.HTTP.GetTask(ctx, &tes.GetTaskRequest{Id: id2, View: tes.TaskView_BASIC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task3, err := f.HTTP.GetTask(ctx, &tes.GetTaskRequest{Id: id3, View: tes.TaskView_BASIC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = f.HTTP.ListTasks(ctx, &tes.ListTasksRequest{
+		View:          tes.TaskView_MINIMAL,
+		CreatedAfter:  task2.CreationTime,
+		CreatedBefore: task3.CreationTime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Tasks) != 1 || r.Tasks[0].Id != id2 {
+		t.Error("unexpected creation time task IDs", ids(r), id2)
+	}
+
+	_, err = f.HTTP.ListTasks(ctx, &tes.ListTasksRequest{
+		CreatedAfter: "yesterday",
+	})
+	if err == nil {
+		t.Error("expected error for invalid creation time filter")
+	}
+}
+
+func TestGetTaskStats(t *testing.T) {
+	tests.SetLogOutput(log, t)
+
+	c := tests.DefaultConfig()
+	f := tests.NewFunnel(c)
+	f.StartServer()
+	ctx := context.Background()
+
+	// The tag keeps tasks of other tests, in a shared database, out of the stats.
+	run := tests.RandomString(10)
+	id1 := f.Run(`'echo hello' --tag stats=` + run + ` --tag project=a`)
+	id2 := f.Run(`'echo hello' --tag stats=` + run + ` --tag project=a`)
+	id3 := f.Run(`--sh 'exit 1' --tag stats=` + run + ` --tag project=b`)
+	id4 := f.Run(`'echo hello' --tag stats=` + run)
+
+	f.Wait(id1)
+	f.Wait(id2)
+	f.Wait(id3)
+	f.Wait(id4)
+
+	r, err := f.HTTP.GetTaskStats(ctx, &tes.GetTaskStatsRequest{
+		Filter:       &tes.ListTasksRequest{Tags: map[string]string{"stats": run}},
+		GroupByState: true,
+		GroupByTags:  []string{"project"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type group struct {
+		state   tes.State
+		project string
+		count   int64
+	}
+	expected := []group{
+		{tes.Complete, "", 1},
+		{tes.Complete, "a", 2},
+		{tes.ExecutorError, "b", 1},
+	}
+	if len(r.Groups) != len(expected) {
+		t.Fatal("unexpected groups", r.Groups)
+	}
+	for i, e := range expected {
+		g := r.Groups[i]
+		if g.State != e.state || g.Tags["project"] != e.project || g.Count != e.count {
+			t.Error("unexpected group", i, g)
+		}
+		if e.state == tes.Complete && (g.RunTime.Count != e.count || len(g.RunTime.Percentiles) != len(tes.DefaultPercentiles)) {
+			t.Error("unexpected run time stats", g.RunTime)
+		}
+		if g.QueueTime.Count != e.count || g.QueueTime.Min < 0 {
+			t.Error("unexpected queue time stats", g.QueueTime)
+		}
+	}
+
+	// The tasks may have been created on either side of midnight.
+	gr, err := f.RPC.GetTaskStats(ctx, &tes.GetTaskStatsRequest{
+		Filter:      &tes.ListTasksRequest{Tags: map[string]string{"stats": run}},
+		GroupByTime: tes.TimeBucket_DAY,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	for _, g := range gr.Groups {
+		if g.TimeBucket == "" {
+			t.Error("expected a time bucket", g)
+		}
+		count += g.Count
+	}
+	if len(gr.Groups) == 0 || len(gr.Groups) > 2 || count != 4 {
+		t.Error("unexpected time bucket stats", gr.Groups)
+	}
+
+	_, err = f.HTTP.GetTaskStats(ctx, &tes.GetTaskStatsRequest{
+		Percentiles: []float64{101},
+	})
+	if err == nil {
+		t.Error("expected an error for an invalid percentile")
+	}
+}
+
 func TestConcurrentStateUpdate(t *testing.T) {
 	tests.SetLogOutput(log, t)
 
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/ohsu-comp-bio/funnel/cmd/task"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/tests"
+)
+
+func TestTaskEvents(t *testing.T) {
+	tests.SetLogOutput(log, t)
+	f := tests.NewFunnel(tests.DefaultConfig())
+	f.StartServer()
+
+	id := f.Run(`'echo hello'`)
+	f.Wait(id)
+
+	var out bytes.Buffer
+	err := task.Events(f.Conf.Server.HTTPAddress(), id, nil, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var evs []*events.Event
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		ev := &events.Event{}
+		if err := jsonpb.UnmarshalString(line, ev); err != nil {
+			t.Fatal(err)
+		}
+		evs = append(evs, ev)
+	}
+	if evs[0].Type != events.Type_TASK_CREATED || evs[0].Id != id {
+		t.Fatal("expected the log to start with the created task, got", evs[0])
+	}
+	last := evs[len(evs)-1]
+	if last.Type != events.Type_TASK_STATE || last.GetState().String() != "COMPLETE" {
+		t.Error("expected the log to end with the complete state, got", last)
+	}
+	for _, ev := range evs {
+		if ev.Type == events.Type_EXECUTOR_STDOUT || ev.Type == events.Type_EXECUTOR_STDERR {
+			t.Error("unexpected executor output event", ev)
+		}
+	}
+
+	// Filter by type.
+	out.Reset()
+	err = task.Events(f.Conf.Server.HTTPAddress(), id, []string{"task_state"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		ev := &events.Event{}
+		if err := jsonpb.UnmarshalString(line, ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.Type != events.Type_TASK_STATE {
+			t.Error("expected only state events, got", ev)
+		}
+	}
+
+	if err := task.Events(f.Conf.Server.HTTPAddress(), "nonexistent", nil, &out); err == nil {
+		t.Error("expected an error for a nonexistent task")
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/cmd/admin"
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/tes"
+	"github.com/ohsu-comp-bio/funnel/tests"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetention(t *testing.T) {
+	tests.SetLogOutput(log, t)
+
+	// The tag keeps tasks of other tests, in a shared database, from expiring.
+	run := tests.RandomString(10)
+	c := tests.DefaultConfig()
+	c.Retention.Policies = []config.RetentionPolicy{
+		{MaxAge: config.Duration(time.Millisecond), Tags: map[string]string{"retention": run}},
+	}
+	c.Retention.ArchiveURL = filepath.Join(c.LocalStorage.AllowedDirs[0], "archive")
+	f := tests.NewFunnel(c)
+	f.StartServer()
+	ctx := context.Background()
+
+	id := f.Run(`'echo hello' --tag retention=` + run)
+	kept := f.Run(`'echo hello'`)
+	before := f.Wait(id)
+	f.Wait(kept)
+
+	// The retention rate is an hour, so tasks only expire when the test says so.
+	n, err := f.Srv.Expirer.Expire(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 expired task, got %d: %v", n, err)
+	}
+	_, err = f.RPC.GetTask(ctx, &tes.GetTaskRequest{Id: id})
+	if s, _ := status.FromError(err); s.Code() != codes.NotFound {
+		t.Fatal("expected the expired task to be deleted", err)
+	}
+	f.Get(kept)
+
+	archives, err := filepath.Glob(filepath.Join(c.Retention.ArchiveURL, "tasks-*.jsonl"))
+	if err != nil || len(archives) != 1 {
+		t.Fatalf("expected 1 archive, got %v: %v", archives, err)
+	}
+
+	// Restoring twice skips the restored task.
+	var out bytes.Buffer
+	if err := admin.Restore(ctx, f.Conf, archives, &out); err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.Restore(ctx, f.Conf, archives, &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := archives[0] + ": restored 1 tasks, skipped 0 existing tasks\n" +
+		archives[0] + ": restored 0 tasks, skipped 1 existing tasks\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	after := f.Get(id)
+	if after.State != before.State || after.CreationTime != before.CreationTime ||
+		len(after.Logs) != len(before.Logs) {
+		t.Fatalf("expected %v, got %v", before, after)
+	}
+	bl, al := before.Logs[0], after.Logs[0]
+	if al.StartTime != bl.StartTime || al.EndTime != bl.EndTime ||
+		len(al.SystemLogs) != len(bl.SystemLogs) || len(al.Logs) != len(bl.Logs) {
+		t.Fatalf("expected %v, got %v", bl, al)
+	}
+	if al.Logs[0].Stdout != "hello\n" || al.Logs[0].ExitCode != bl.Logs[0].ExitCode {
+		t.Errorf("expected %v, got %v", bl.Logs[0], al.Logs[0])
+	}
+}
@@ -7,7 +7,6 @@ import (
 
 	workerCmd "github.com/ohsu-comp-bio/funnel/cmd/worker"
 	"github.com/ohsu-comp-bio/funnel/config"
-	"github.com/ohsu-comp-bio/funnel/events"
 	"github.com/ohsu-comp-bio/funnel/logger"
 	"github.com/ohsu-comp-bio/funnel/tes"
 	"github.com/ohsu-comp-bio/funnel/tests"
@@ -22,7 +21,7 @@ func TestMain(m *testing.M) {
 	conf.Compute = "noop"
 
 	var active bool
-	for _, val := range conf.EventWriters {
+	for _, val := range conf.EventReaders {
 		if val == "kafka" {
 			active = true
 		}
@@ -42,18 +41,8 @@ func TestMain(m *testing.M) {
 func TestKafkaWorkerRun(t *testing.T) {
 	tests.SetLogOutput(log, t)
 
-	ctx := context.Background()
-	// Task builder collects events into a task view.
-	task := &tes.Task{}
-	b := events.TaskBuilder{Task: task}
-	l := &events.Logger{Log: log}
-	m := &events.MultiWriter{b, l}
-
-	// Read events from kafka, write into task builder.
-	_, err := events.NewKafkaReader(ctx, conf.Kafka, m)
-	if err != nil {
-		t.Fatal(err)
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// this only writes the task to the DB since the 'noop'
 	// compute backend is in use
@@ -61,14 +50,19 @@ func TestKafkaWorkerRun(t *testing.T) {
     --sh 'echo hello world'
   `)
 
-	err = workerCmd.Run(ctx, conf, log, id)
+	// The worker publishes its events only to Kafka. The server
+	// consumes them into the database.
+	err := workerCmd.Run(ctx, conf, log, id)
 	if err != nil {
 		t.Fatal("unexpected error", err)
 	}
 	fun.Wait(id)
 
-	// Check the task (built from a stream of kafka events).
+	task := fun.Get(id)
 	if task.State != tes.State_COMPLETE {
-		t.Fatal("unexpected state")
+		t.Fatal("unexpected state", task.State)
+	}
+	if task.Logs[0].Logs[0].Stdout != "hello world\n" {
+		t.Fatal("missing stdout", task.Logs[0].Logs[0].Stdout)
 	}
 }
@@ -4,11 +4,9 @@ import (
 	"context"
 	"os"
 	"testing"
-	"time"
 
 	workerCmd "github.com/ohsu-comp-bio/funnel/cmd/worker"
 	"github.com/ohsu-comp-bio/funnel/config"
-	"github.com/ohsu-comp-bio/funnel/events"
 	"github.com/ohsu-comp-bio/funnel/logger"
 	"github.com/ohsu-comp-bio/funnel/tes"
 	"github.com/ohsu-comp-bio/funnel/tests"
@@ -23,7 +21,7 @@ func TestMain(m *testing.M) {
 	conf.Compute = "noop"
 
 	var active bool
-	for _, val := range conf.EventWriters {
+	for _, val := range conf.EventReaders {
 		if val == "pubsub" {
 			active = true
 		}
@@ -45,32 +43,26 @@ func TestPubSubWorkerRun(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	// Task builder collects events into a task view.
-	task := &tes.Task{}
-	b := events.TaskBuilder{Task: task}
-
-	// Read events from pubsub, write into task builder.
-	subname := "test-pubsub-" + tests.RandomString(10)
-	go func() {
-		err := events.ReadPubSub(ctx, conf.PubSub, subname, b)
-		if err != nil {
-			t.Fatal(err)
-		}
-	}()
 
 	// this only writes the task to the DB since the 'noop'
 	// compute backend is in use
-	id := fun.Run(`'echo hello world'`)
+	id := fun.Run(`
+    --sh 'echo hello world'
+  `)
 
-	err := workerCmd.Run(ctx, conf, nil, id)
+	// The worker publishes its events only to Pub/Sub. The server
+	// consumes them into the database.
+	err := workerCmd.Run(ctx, conf, log, id)
 	if err != nil {
 		t.Fatal("unexpected error", err)
 	}
 	fun.Wait(id)
-	time.Sleep(time.Second)
 
-	// Check the task (built from a stream of kafka events).
+	task := fun.Get(id)
 	if task.State != tes.State_COMPLETE {
 		t.Fatal("unexpected state", task.State)
 	}
+	if task.Logs[0].Logs[0].Stdout != "hello world\n" {
+		t.Fatal("missing stdout", task.Logs[0].Logs[0].Stdout)
+	}
 }
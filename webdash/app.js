@@ -210,6 +210,109 @@ app.controller("TaskListController", function($rootScope, $scope, $http, $timeou
   });
 });
 
+app.controller("TaskStatsController", function($rootScope, $scope, $http, $timeout, TaskFilters) {
+  $rootScope.pageTitle = "Task Stats";
+  $scope.groups = [];
+  $scope.groupBy = {
+    state: true,
+    tags: "",
+    time: "ALL_TIME",
+  };
+
+  function tagKeys() {
+    return $scope.groupBy.tags.split(",").map(function(k) {
+      return k.trim();
+    }).filter(function(k) {
+      return k !== "";
+    });
+  }
+
+  function getStats() {
+    var params = {
+      "group_by_state": $scope.groupBy.state,
+      "group_by_tags": tagKeys(),
+      "group_by_time": $scope.groupBy.time,
+    };
+    if (TaskFilters.state != "any") {
+      params["filter.state"] = TaskFilters.state;
+    }
+    for (var i in TaskFilters.tags) {
+      var tag = TaskFilters.tags[i];
+      if (tag.key) {
+        params["filter.tags[" + tag.key + "]"] = tag.value || "";
+      }
+    }
+    return $http.get("/v1/tasks:stats", {params: params});
+  }
+
+  function refresh(callback) {
+    getStats().then(function(response) {
+      $scope.$applyAsync(function() {
+        $scope.error = "";
+        $scope.groups = response.data.groups || [];
+        $scope.tagKeys = tagKeys();
+        if (callback) {
+          callback();
+        }
+      });
+    }, function(response) {
+      $scope.$applyAsync(function() {
+        $scope.error = response.data && response.data.error || response.statusText;
+        $scope.groups = [];
+        if (callback) {
+          callback();
+        }
+      });
+    });
+  }
+
+  // Durations are in seconds.
+  $scope.duration = function(seconds) {
+    if (seconds === undefined) {
+      return "--";
+    }
+    return formatElapsedTime(seconds * 1000);
+  }
+
+  $scope.percentile = function(stats, i) {
+    if (!stats || !stats.count || !stats.percentiles) {
+      return "--";
+    }
+    return $scope.duration(stats.percentiles[i]);
+  }
+
+  $scope.mean = function(stats) {
+    if (!stats || !stats.count) {
+      return "--";
+    }
+    return $scope.duration(stats.mean || 0);
+  }
+
+  $scope.$watch("groupBy", function() {
+    refresh();
+  }, true)
+
+  TaskFilters.$watch("state", function() {
+    refresh();
+  })
+
+  TaskFilters.$watch("tags", function() {
+    refresh();
+  }, true)
+
+  function autoRefresh() {
+    refresh(function() {
+      stop = $timeout(autoRefresh, 5000);
+    });
+  }
+
+  autoRefresh();
+
+  $scope.$on("$destroy", function() {
+    $timeout.cancel(stop);
+  });
+});
+
 app.controller("NodeListController", function($rootScope, $scope, $http, $timeout) {
   $rootScope.pageTitle = "Nodes";
   $scope.url = "/v1/nodes";
@@ -445,6 +548,11 @@ app.config(
        templateUrl: "/static/node.html",
        pageId: "node-info",
      }
+     var taskStats = {
+       templateUrl: "/static/stats.html",
+       title: "Task Stats",
+       pageId: "task-stats",
+     }
      var serviceInfo = {
        templateUrl: "/static/service.html",
        title: "Service",
@@ -460,6 +568,7 @@ app.config(
        when("/v1/tasks", taskList).
        when("/tasks/:task_id", taskInfo).
        when("/v1/tasks/:task_id", taskInfo).
+       when("/stats", taskStats).
        when("/nodes", nodeList).
        when("/v1/nodes", nodeList).
        when("/nodes/:node_id", nodeInfo).
@@ -26,6 +26,8 @@ type DockerCommand struct {
 	Stdout          io.Writer
 	Stderr          io.Writer
 	Event           *events.ExecutorWriter
+	// Extra arguments to "docker run", added before the image.
+	Args []string
 }
 
 // Run runs the Docker command and blocks until done.
@@ -67,6 +69,7 @@ func (dcmd DockerCommand) Run(ctx context.Context) error {
 		args = append(args, "-v", arg)
 	}
 
+	args = append(args, dcmd.Args...)
 	args = append(args, dcmd.Image)
 	args = append(args, dcmd.Command...)
 
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// runExecutors runs the executor stages in order, calling "run" for each executor.
+// The executors of a stage run at the same time, and if one of them fails,
+// the others are stopped. Background executors run until the foreground
+// executors are done, and their errors are ignored.
+func runExecutors(ctx context.Context, stages []tes.ExecutorStage, run func(context.Context, int) error) error {
+	// bgctx stops the background executors once the foreground executors are done.
+	bgctx, stopBackground := context.WithCancel(ctx)
+	var bg sync.WaitGroup
+	defer func() {
+		stopBackground()
+		bg.Wait()
+	}()
+
+	for _, stage := range stages {
+		if stage.Background {
+			for _, i := range stage.Executors {
+				bg.Add(1)
+				go func(i int) {
+					defer bg.Done()
+					run(bgctx, i)
+				}(i)
+			}
+			continue
+		}
+
+		if err := runStage(ctx, stage.Executors, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runStage runs the executors at the same time and returns the first error.
+func runStage(ctx context.Context, executors []int, run func(context.Context, int) error) error {
+	if len(executors) == 1 {
+		return run(ctx, executors[0])
+	}
+
+	stagectx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(executors))
+	for _, i := range executors {
+		go func(i int) {
+			errs <- run(stagectx, i)
+		}(i)
+	}
+
+	var first error
+	for range executors {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+			cancel()
+		}
+	}
+	return first
+}
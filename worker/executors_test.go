@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+func TestRunExecutors(t *testing.T) {
+	stages := []tes.ExecutorStage{
+		{Executors: []int{0}, Background: true},
+		{Executors: []int{1, 2}},
+		{Executors: []int{3}},
+	}
+
+	var mtx sync.Mutex
+	var order []string
+	record := func(s string) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		order = append(order, s)
+	}
+
+	// Executors 1 and 2 each wait for the other to start.
+	started := map[int]chan struct{}{
+		1: make(chan struct{}),
+		2: make(chan struct{}),
+	}
+
+	err := runExecutors(context.Background(), stages, func(ctx context.Context, i int) error {
+		switch i {
+		case 0:
+			<-ctx.Done()
+			record("0 stopped")
+			return ctx.Err()
+		case 1, 2:
+			close(started[i])
+			select {
+			case <-started[3-i]:
+			case <-time.After(time.Second):
+				return errors.New("executors 1 and 2 didn't run at the same time")
+			}
+		case 3:
+			record("3 done")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"3 done", "0 stopped"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected order: %v", order)
+	}
+}
+
+func TestRunExecutorsFailure(t *testing.T) {
+	stages := []tes.ExecutorStage{
+		{Executors: []int{0, 1}},
+		{Executors: []int{2}},
+	}
+	failed := errors.New("exit code 1")
+
+	var ran bool
+	err := runExecutors(context.Background(), stages, func(ctx context.Context, i int) error {
+		switch i {
+		case 0:
+			return failed
+		case 1:
+			// Stopped when executor 0 fails.
+			<-ctx.Done()
+			return ctx.Err()
+		default:
+			ran = true
+		}
+		return nil
+	})
+	if err != failed {
+		t.Errorf("expected executor 0's error, got %v", err)
+	}
+	if ran {
+		t.Error("expected executor 2 not to run")
+	}
+}
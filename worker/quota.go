@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watchDiskQuota returns a context which is canceled when the size of "dir"
+// exceeds "diskGb". "exceeded" is called with an error describing the usage
+// before the context is canceled. If the quota isn't enabled, or the task didn't
+// request disk space, "pctx" is returned unchanged.
+func (r *DefaultWorker) watchDiskQuota(pctx context.Context, dir string, diskGb float64, exceeded func(error)) context.Context {
+	if !r.Conf.DiskQuota.Enabled || diskGb <= 0 {
+		return pctx
+	}
+	limit := int64(diskGb * math.Pow(1000, 3))
+	quotactx, cancel := context.WithCancel(pctx)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(r.Conf.DiskQuota.Rate))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-quotactx.Done():
+				return
+			case <-ticker.C:
+				size := dirSize(dir)
+				if size > limit {
+					exceeded(fmt.Errorf(
+						"task working directory uses %.3f GB, which exceeds the requested %.3f GB",
+						float64(size)/math.Pow(1000, 3), diskGb))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return quotactx
+}
+
+// dirSize returns the total size of the regular files in "dir".
+// Files which can't be read are skipped.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+)
+
+func TestDiskQuota(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-quota-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := config.DefaultConfig().Worker
+	conf.DiskQuota.Enabled = true
+	conf.DiskQuota.Rate = config.Duration(time.Millisecond)
+	r := &DefaultWorker{Conf: conf}
+
+	// 1 KB quota.
+	exceeded := make(chan error, 1)
+	ctx := r.watchDiskQuota(context.Background(), dir, 1e-6, func(err error) { exceeded <- err })
+
+	err = ioutil.WriteFile(filepath.Join(dir, "small"), make([]byte, 500), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 20)
+	if ctx.Err() != nil {
+		t.Fatal("unexpected disk quota error")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "large"), make([]byte, 1000), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected context to be canceled when the quota is exceeded")
+	}
+	if err := <-exceeded; err == nil {
+		t.Error("expected disk quota error")
+	}
+
+	// Tasks which don't request disk space aren't limited.
+	ctx = r.watchDiskQuota(context.Background(), dir, 0, func(error) {})
+	if ctx != context.Background() {
+		t.Error("expected no disk quota")
+	}
+}
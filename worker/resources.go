@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+// customResources returns the "docker run" arguments and environment variables
+// for the custom resources requested by the task. Each requested resource is
+// passed to the container as FUNNEL_RESOURCE_<NAME>=<amount>, e.g.
+// FUNNEL_RESOURCE_NVIDIA_COM_GPU=1 for "nvidia.com/gpu".
+func customResources(task *tes.Task, conf []config.CustomResource) ([]string, map[string]string, error) {
+	req, err := tes.CustomResources(task)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	for name := range req {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	env := map[string]string{}
+	for _, name := range names {
+		amount := strconv.FormatFloat(req[name], 'f', -1, 64)
+		env[resourceEnvName(name)] = amount
+
+		for _, c := range conf {
+			if c.Name != name {
+				continue
+			}
+			for _, arg := range c.DockerArgs {
+				args = append(args, strings.Replace(arg, "{{.Amount}}", amount, -1))
+			}
+		}
+	}
+	return args, env, nil
+}
+
+func resourceEnvName(name string) string {
+	out := []rune("FUNNEL_RESOURCE_")
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			out = append(out, r)
+		} else {
+			out = append(out, '_')
+		}
+	}
+	return string(out)
+}
+
+// mergeEnv returns the executor environment with the custom resource
+// variables added. Variables set by the executor take precedence.
+func mergeEnv(env, resources map[string]string) map[string]string {
+	if len(resources) == 0 {
+		return env
+	}
+	out := map[string]string{}
+	for k, v := range resources {
+		out[k] = v
+	}
+	for k, v := range env {
+		out[k] = v
+	}
+	return out
+}
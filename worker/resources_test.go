@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+func TestCustomResources(t *testing.T) {
+	conf := []config.CustomResource{
+		{Name: "nvidia.com/gpu", Amount: 4, DockerArgs: []string{"--gpus", "{{.Amount}}"}},
+		{Name: "licenses.matlab", Amount: 2},
+	}
+	task := &tes.Task{
+		Tags: map[string]string{
+			"resource.nvidia.com/gpu":  "2",
+			"resource.licenses.matlab": "1",
+		},
+	}
+
+	args, env, err := customResources(task, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(args, []string{"--gpus", "2"}) {
+		t.Errorf("unexpected docker args: %v", args)
+	}
+	expected := map[string]string{
+		"FUNNEL_RESOURCE_NVIDIA_COM_GPU":  "2",
+		"FUNNEL_RESOURCE_LICENSES_MATLAB": "1",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("unexpected env: %v", env)
+	}
+
+	merged := mergeEnv(map[string]string{"FUNNEL_RESOURCE_NVIDIA_COM_GPU": "0", "FOO": "bar"}, env)
+	if merged["FUNNEL_RESOURCE_NVIDIA_COM_GPU"] != "0" || merged["FOO"] != "bar" ||
+		merged["FUNNEL_RESOURCE_LICENSES_MATLAB"] != "1" {
+		t.Errorf("unexpected merged env: %v", merged)
+	}
+}
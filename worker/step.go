@@ -2,23 +2,45 @@ package worker
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"os/exec"
 	"time"
 
 	"github.com/ohsu-comp-bio/funnel/config"
 	"github.com/ohsu-comp-bio/funnel/events"
 )
 
+// timeoutExitCode is the exit code recorded for an executor which timed out,
+// the same code the "timeout" command exits with.
+const timeoutExitCode = 124
+
+// dockerErrorExitCode is the exit code of "docker run" when docker itself
+// fails, e.g. when the image can't be found.
+const dockerErrorExitCode = 125
+
 type stepWorker struct {
 	Conf    config.Worker
 	Command *DockerCommand
 	Event   *events.ExecutorWriter
 	IP      string
+	// Timeout stops the executor if it runs for longer. 0 means no limit.
+	Timeout time.Duration
+	// If true, a non-zero exit code doesn't fail the task.
+	IgnoreError bool
 }
 
 func (s *stepWorker) Run(ctx context.Context) error {
 	s.Event.StartTime(time.Now())
 
+	// pctx is done when the task times out, as opposed to the executor.
+	pctx := ctx
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(pctx, s.Timeout)
+		defer cancel()
+	}
+
 	// subctx helps ensure that these goroutines are cleaned up,
 	// even when the task is canceled.
 	subctx, cleanup := context.WithCancel(ctx)
@@ -54,15 +76,35 @@ func (s *stepWorker) Run(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			// Likely the task was canceled.
+			// Likely the task was canceled, or the executor or task timed out.
 			go s.Command.Stop()
 			s.Event.EndTime(time.Now())
+			if ctx.Err() == context.DeadlineExceeded {
+				if pctx.Err() == nil {
+					s.Event.Error("Executor timed out", "timeout", s.Timeout.String())
+				}
+				s.Event.ExitCode(timeoutExitCode)
+				return fmt.Errorf("executor timed out")
+			}
 			return ctx.Err()
 
 		case result := <-done:
 			s.Event.EndTime(time.Now())
 			s.Event.ExitCode(getExitCode(result))
+			if s.IgnoreError && isExecutorExitError(result) {
+				s.Event.Info("Executor failed, continuing because its errors are ignored", "error", result)
+				return nil
+			}
 			return result
 		}
 	}
 }
+
+// isExecutorExitError returns true if the executor ran and exited with a
+// non-zero exit code, as opposed to docker or the worker failing to run it.
+func isExecutorExitError(err error) bool {
+	if _, ok := err.(*exec.ExitError); !ok {
+		return false
+	}
+	return getExitCode(err) != dockerErrorExitCode
+}
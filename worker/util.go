@@ -41,7 +41,9 @@ type helper struct {
 	syserr       error
 	execerr      error
 	taskCanceled bool
-	ctx          context.Context
+	// set when the task's working directory exceeds its disk quota.
+	quotaErr error
+	ctx      context.Context
 }
 
 func (h *helper) ok() bool {
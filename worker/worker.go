@@ -23,6 +23,13 @@ type DefaultWorker struct {
 	Store       storage.Storage
 	TaskReader  TaskReader
 	EventWriter events.Writer
+	// Custom resources of the node, used to pass requested resources
+	// (e.g. GPUs) to the container runtime.
+	CustomResources []config.CustomResource
+	// If true, the worker doesn't write a final task state when it's stopped,
+	// i.e. its context is canceled, before the task finished. Nodes set this,
+	// because they hand unfinished tasks back to the scheduler when they shut down.
+	SkipStateOnStop bool
 }
 
 // Run runs the Worker.
@@ -43,9 +50,15 @@ func (r *DefaultWorker) Run(pctx context.Context, taskID string) (runerr error)
 	var mapper *FileMapper
 	var run helper
 	var task *tes.Task
+	var timeout time.Duration
+	var deadline time.Time
+	// The disk quota watcher sends an error when the quota is exceeded.
+	quotaExceeded := make(chan error, 1)
+
+	task, run.syserr = r.TaskReader.Task(pctx, taskID)
 
 	// set up task specific utilities
-	event = events.NewTaskWriter(taskID, 0, r.EventWriter)
+	event = events.NewTaskWriter(taskID, task.Attempt(), r.EventWriter)
 	mapper = NewFileMapper(filepath.Join(r.Conf.WorkDir, taskID))
 
 	event.Info("Version", version.LogFields()...)
@@ -56,19 +69,37 @@ func (r *DefaultWorker) Run(pctx context.Context, taskID string) (runerr error)
 		event.Metadata(map[string]string{"hostname": name})
 	}
 
-	task, run.syserr = r.TaskReader.Task(pctx, taskID)
-
 	// Run the final logging/state steps in a deferred function
 	// to ensure they always run, even if there's a missed error.
 	defer func() {
 		event.EndTime(time.Now())
 
+		select {
+		case run.quotaErr = <-quotaExceeded:
+		default:
+		}
+
 		switch {
 		case run.taskCanceled:
 			// The task was canceled.
 			event.Info("Canceled")
 			event.State(tes.State_CANCELED)
 			runerr = fmt.Errorf("task canceled")
+		case run.quotaErr != nil:
+			// The task used more disk space than it requested.
+			event.Error("Disk quota exceeded", "error", run.quotaErr)
+			event.State(tes.State_SYSTEM_ERROR)
+			runerr = run.quotaErr
+		case r.SkipStateOnStop && pctx.Err() != nil && (run.syserr != nil || run.execerr != nil):
+			// The worker was stopped, e.g. because the node is shutting down.
+			// The task state is left for the node to deal with.
+			event.Info("Worker stopped before the task finished")
+			runerr = fmt.Errorf("worker stopped: %s", pctx.Err())
+		case !deadline.IsZero() && !time.Now().Before(deadline) && (run.syserr != nil || run.execerr != nil):
+			// The task ran for longer than its time limit.
+			event.Error("Task timed out", "timeout", timeout.String())
+			event.State(tes.State_EXECUTOR_ERROR)
+			runerr = fmt.Errorf("task timed out after %s", timeout)
 		case run.syserr != nil:
 			// Something else failed
 			event.Error("System error", "error", run.syserr)
@@ -96,6 +127,19 @@ func (r *DefaultWorker) Run(pctx context.Context, taskID string) (runerr error)
 	})
 
 	ctx := r.pollForCancel(pctx, taskID, func() { run.taskCanceled = true })
+	ctx = r.watchDiskQuota(ctx, mapper.WorkDir, task.GetResources().GetDiskGb(), func(err error) { quotaExceeded <- err })
+
+	// Limit the wall-clock time of the task, from the "timeout" tag
+	// or else the cluster default.
+	if run.ok() {
+		timeout, run.syserr = taskTimeout(task, r.Conf.TaskTimeout)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		deadline = time.Now().Add(timeout)
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
 	run.ctx = ctx
 
 	// Prepare file mapper, which maps task file URLs to host filesystem paths
@@ -116,8 +160,23 @@ func (r *DefaultWorker) Run(pctx context.Context, taskID string) (runerr error)
 		event.State(tes.State_RUNNING)
 	}
 
+	// Custom resources requested by the task, e.g. GPUs.
+	var resArgs []string
+	var resEnv map[string]string
+	if run.ok() {
+		resArgs, resEnv, run.syserr = customResources(task, r.CustomResources)
+	}
+
+	// Split the executors into stages: executors in a parallel group
+	// run at the same time, background executors run alongside the others.
+	var stages []tes.ExecutorStage
+	if run.ok() {
+		stages, run.syserr = tes.ExecutorStages(task)
+	}
+
 	// Run steps
 	if run.ok() {
+		steps := make([]*stepWorker, len(task.GetExecutors()))
 		for i, d := range task.GetExecutors() {
 			s := &stepWorker{
 				Conf:  r.Conf,
@@ -125,7 +184,8 @@ func (r *DefaultWorker) Run(pctx context.Context, taskID string) (runerr error)
 				Command: &DockerCommand{
 					Image:         d.Image,
 					Command:       d.Command,
-					Env:           d.Env,
+					Env:           mergeEnv(d.Env, resEnv),
+					Args:          resArgs,
 					Volumes:       mapper.Volumes,
 					Workdir:       d.Workdir,
 					ContainerName: fmt.Sprintf("%s-%d", task.Id, i),
@@ -135,13 +195,29 @@ func (r *DefaultWorker) Run(pctx context.Context, taskID string) (runerr error)
 				},
 			}
 
-			// Opens stdin/out/err files and updates those fields on "cmd".
 			if run.ok() {
-				run.syserr = r.openStepLogs(mapper, s, d)
+				s.Timeout, run.syserr = executorTimeout(task, i, r.Conf.ExecutorTimeout)
 			}
 
 			if run.ok() {
-				run.execerr = s.Run(ctx)
+				s.IgnoreError, run.syserr = tes.ExecutorIgnoreError(task, i)
+			}
+			steps[i] = s
+		}
+
+		if run.ok() {
+			err := runExecutors(ctx, stages, func(ctx context.Context, i int) error {
+				// Open the stdin/out/err files just before the executor starts,
+				// since they may be written by earlier executors.
+				if err := r.openStepLogs(mapper, steps[i], task.Executors[i]); err != nil {
+					return &stepLogsError{err}
+				}
+				return steps[i].Run(ctx)
+			})
+			if _, ok := err.(*stepLogsError); ok {
+				run.syserr = err
+			} else {
+				run.execerr = err
 			}
 		}
 	}
@@ -157,6 +233,14 @@ func (r *DefaultWorker) Run(pctx context.Context, taskID string) (runerr error)
 	var outputLog []*tes.OutputFileLog
 	if run.ok() {
 		outputLog, run.syserr = UploadOutputs(ctx, mapper.Outputs, r.Store, event)
+	} else if run.execerr != nil && !(r.SkipStateOnStop && pctx.Err() != nil) {
+		// An executor failed, timed out, or was stopped because the task was
+		// canceled. Upload the outputs the task asked for anyway, e.g. partial
+		// results and logs useful for debugging. The task context may be done,
+		// so the uploads get a context of their own.
+		uctx, cancel := context.WithTimeout(context.Background(), outputsOnErrorTimeout)
+		outputLog = r.uploadOutputsOnError(uctx, task, mapper, event)
+		cancel()
 	}
 
 	// unmap paths for OutputFileLog
@@ -205,6 +289,20 @@ func (r *DefaultWorker) openStepLogs(mapper *FileMapper, s *stepWorker, d *tes.E
 	return nil
 }
 
+// outputsOnErrorTimeout limits the time spent uploading outputs after
+// an executor error.
+const outputsOnErrorTimeout = time.Minute * 10
+
+// stepLogsError is returned when the stdin/out/err files of an executor
+// can't be opened, which is a system error rather than an executor error.
+type stepLogsError struct {
+	err error
+}
+
+func (e *stepLogsError) Error() string {
+	return e.err.Error()
+}
+
 // Validate the downloads/uploads.
 func (r *DefaultWorker) validate(mapper *FileMapper) error {
 	// TODO need to switch on directory type and check list as well.
@@ -223,6 +321,64 @@ func (r *DefaultWorker) validate(mapper *FileMapper) error {
 	return nil
 }
 
+// uploadOutputsOnError uploads the outputs listed by the task's "outputs-on-error"
+// tag, skipping those which weren't created. Upload errors are logged, since
+// the task already failed.
+func (r *DefaultWorker) uploadOutputsOnError(ctx context.Context, task *tes.Task, mapper *FileMapper, event *events.TaskWriter) []*tes.OutputFileLog {
+	paths, err := tes.OutputsOnError(task)
+	if err != nil {
+		event.Error("Couldn't upload outputs after executor error", "error", err)
+		return nil
+	}
+	upload := map[string]bool{}
+	for _, p := range paths {
+		upload[filepath.Clean(p)] = true
+	}
+
+	var outputs []*tes.Output
+	for _, output := range mapper.Outputs {
+		if !upload[mapper.ContainerPath(output.Path)] {
+			continue
+		}
+		if _, err := os.Stat(output.Path); err != nil {
+			event.Info("Output not found after executor error, skipping", "path", mapper.ContainerPath(output.Path))
+			continue
+		}
+		fixLinks(mapper, output.Path)
+		outputs = append(outputs, output)
+	}
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	event.Info("Uploading outputs after executor error")
+	logs, err := UploadOutputs(ctx, outputs, r.Store, event)
+	if err != nil {
+		event.Error("Couldn't upload outputs after executor error", "error", err)
+	}
+	return logs
+}
+
+// taskTimeout returns the time limit of the task, falling back to "def"
+// if the task doesn't set one.
+func taskTimeout(task *tes.Task, def config.Duration) (time.Duration, error) {
+	d, err := tes.Timeout(task)
+	if err == nil && d == 0 {
+		d = time.Duration(def)
+	}
+	return d, err
+}
+
+// executorTimeout returns the time limit of the executor at index "i",
+// falling back to "def" if the task doesn't set one.
+func executorTimeout(task *tes.Task, i int, def config.Duration) (time.Duration, error) {
+	d, err := tes.ExecutorTimeout(task, i)
+	if err == nil && d == 0 {
+		d = time.Duration(def)
+	}
+	return d, err
+}
+
 func (r *DefaultWorker) pollForCancel(pctx context.Context, taskID string, cancelCallback func()) context.Context {
 	taskctx, cancel := context.WithCancel(pctx)
 
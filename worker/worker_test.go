@@ -0,0 +1,417 @@
+package worker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ohsu-comp-bio/funnel/config"
+	"github.com/ohsu-comp-bio/funnel/events"
+	"github.com/ohsu-comp-bio/funnel/storage"
+	"github.com/ohsu-comp-bio/funnel/tes"
+)
+
+func TestUploadOutputsOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-outputs-on-error-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := storage.NewLocal(config.LocalStorage{AllowedDirs: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &DefaultWorker{Store: store}
+
+	out := filepath.Join(dir, "out")
+	task := &tes.Task{
+		Id: "task-1",
+		Tags: map[string]string{
+			"outputs-on-error": "/outputs/log.txt, /outputs/missing.txt",
+		},
+		Outputs: []*tes.Output{
+			{Url: filepath.Join(out, "log.txt"), Path: "/outputs/log.txt"},
+			{Url: filepath.Join(out, "result.txt"), Path: "/outputs/result.txt"},
+			{Url: filepath.Join(out, "missing.txt"), Path: "/outputs/missing.txt"},
+		},
+	}
+
+	mapper := NewFileMapper(filepath.Join(dir, "work"))
+	if err := mapper.MapTask(task); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"log.txt", "result.txt"} {
+		err := ioutil.WriteFile(filepath.Join(mapper.WorkDir, "outputs", name), []byte(name), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ev := events.NewTaskWriter("task-1", 0, events.Noop{})
+	logs := r.uploadOutputsOnError(context.Background(), task, mapper, ev)
+
+	if len(logs) != 1 || logs[0].Url != filepath.Join(out, "log.txt") {
+		t.Errorf("expected only log.txt to be uploaded, got %v", logs)
+	}
+	if _, err := os.Stat(filepath.Join(out, "log.txt")); err != nil {
+		t.Error("expected log.txt to be uploaded", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "result.txt")); err == nil {
+		t.Error("expected result.txt not to be uploaded")
+	}
+}
+
+// fakeDockerScript runs the executor's command on the host, instead of in a
+// container, and records its PID so that "docker stop" can kill it.
+// The "missing" image fails like docker does when an image can't be found.
+const fakeDockerScript = `#!/bin/sh
+dir=$(dirname "$0")
+case "$1" in
+run)
+  shift
+  while [ $# -gt 0 ]; do
+    case "$1" in
+      -i|--read-only|--rm) shift ;;
+      --name) name=$2; shift 2 ;;
+      -e|-w|-v) shift 2 ;;
+      *) break ;;
+    esac
+  done
+  if [ "$1" = missing ]; then
+    echo "Unable to find image 'missing:latest' locally" >&2
+    exit 125
+  fi
+  # Skip the image.
+  shift
+  echo $$ > "$dir/$name.pid"
+  exec "$@"
+  ;;
+stop)
+  kill $(cat "$dir/$2.pid")
+  ;;
+esac
+`
+
+// fakeDocker puts a fake "docker" command first on the PATH.
+// The returned function restores the PATH.
+func fakeDocker(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "funnel-test-docker-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "docker"), []byte(fakeDockerScript), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	os.Setenv("DOCKER_API_VERSION", "1.0")
+	return func() {
+		os.Setenv("PATH", path)
+		os.Unsetenv("DOCKER_API_VERSION")
+		os.RemoveAll(dir)
+	}
+}
+
+// eventRecorder records the events written by a worker.
+type eventRecorder struct {
+	mtx    sync.Mutex
+	events []*events.Event
+}
+
+func (e *eventRecorder) WriteEvent(ctx context.Context, ev *events.Event) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.events = append(e.events, ev)
+	return nil
+}
+
+// state returns the last state written.
+func (e *eventRecorder) state() tes.State {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	state := tes.State_UNKNOWN
+	for _, ev := range e.events {
+		if ev.Type == events.Type_TASK_STATE {
+			state = ev.GetState()
+		}
+	}
+	return state
+}
+
+// exitCodes returns the exit code of each executor which finished, by index.
+func (e *eventRecorder) exitCodes() map[uint32]int32 {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	codes := map[uint32]int32{}
+	for _, ev := range e.events {
+		if ev.Type == events.Type_EXECUTOR_EXIT_CODE {
+			codes[ev.Index] = ev.GetExitCode()
+		}
+	}
+	return codes
+}
+
+// hasSystemLog returns true if a system log message contains "msg".
+func (e *eventRecorder) hasSystemLog(msg string) bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	for _, ev := range e.events {
+		if ev.Type == events.Type_SYSTEM_LOG && strings.Contains(ev.GetSystemLog().GetMsg(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// runTestTask runs the task with a worker using the fake docker command,
+// and returns the recorded events and the worker's error. If conf.WorkDir
+// isn't set, a temporary directory is used.
+func runTestTask(t *testing.T, conf config.Worker, task *tes.Task) (*eventRecorder, error) {
+	defer fakeDocker(t)()
+
+	dir := conf.WorkDir
+	if dir == "" {
+		var err error
+		dir, err = ioutil.TempDir("", "funnel-test-worker-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		conf.WorkDir = dir
+	}
+	conf.LeaveWorkDir = true
+
+	store, err := storage.NewLocal(config.LocalStorage{AllowedDirs: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task.Id = "task-1"
+	for _, e := range task.Executors {
+		if e.Image == "" {
+			e.Image = "alpine"
+		}
+	}
+	rec := &eventRecorder{}
+	w := &DefaultWorker{
+		Conf:  conf,
+		Store: store,
+		TaskReader: NewGenericTaskReader(func(ctx context.Context, in *tes.GetTaskRequest) (*tes.Task, error) {
+			return task, nil
+		}),
+		EventWriter: rec,
+	}
+	err = w.Run(context.Background(), task.Id)
+	return rec, err
+}
+
+func TestWorkerDiskQuotaExceeded(t *testing.T) {
+	conf := config.DefaultConfig().Worker
+	conf.DiskQuota.Enabled = true
+	conf.DiskQuota.Rate = config.Duration(time.Millisecond * 10)
+
+	task := &tes.Task{
+		// 1 KB quota.
+		Resources: &tes.Resources{DiskGb: 1e-6},
+		Executors: []*tes.Executor{
+			{
+				Command: []string{"sh", "-c", "head -c 5000 /dev/zero; sleep 10"},
+				Stdout:  "/outputs/big",
+			},
+		},
+	}
+
+	start := time.Now()
+	rec, err := runTestTask(t, conf, task)
+	if err == nil || !strings.Contains(err.Error(), "exceeds the requested") {
+		t.Error("expected disk quota error, got", err)
+	}
+	if time.Since(start) > time.Second*5 {
+		t.Error("expected the executor to be stopped")
+	}
+	if rec.state() != tes.State_SYSTEM_ERROR {
+		t.Error("expected SYSTEM_ERROR state, got", rec.state())
+	}
+	if !rec.hasSystemLog("Disk quota exceeded") {
+		t.Error("expected disk quota system log")
+	}
+}
+
+func TestWorkerExecutorTimeout(t *testing.T) {
+	task := &tes.Task{
+		Tags: map[string]string{"executor-timeout.1": "200ms"},
+		Executors: []*tes.Executor{
+			{Command: []string{"true"}},
+			{Command: []string{"sleep", "10"}},
+		},
+	}
+
+	start := time.Now()
+	rec, err := runTestTask(t, config.DefaultConfig().Worker, task)
+	if err == nil {
+		t.Error("expected error")
+	}
+	if time.Since(start) > time.Second*5 {
+		t.Error("expected the executor to be stopped")
+	}
+	if rec.state() != tes.State_EXECUTOR_ERROR {
+		t.Error("expected EXECUTOR_ERROR state, got", rec.state())
+	}
+	codes := rec.exitCodes()
+	if codes[0] != 0 || codes[1] != timeoutExitCode {
+		t.Error("unexpected exit codes", codes)
+	}
+	if !rec.hasSystemLog("Executor timed out") {
+		t.Error("expected executor timeout system log")
+	}
+}
+
+func TestWorkerTaskTimeout(t *testing.T) {
+	task := &tes.Task{
+		Tags: map[string]string{"timeout": "200ms"},
+		Executors: []*tes.Executor{
+			{Command: []string{"sleep", "10"}},
+		},
+	}
+
+	start := time.Now()
+	rec, err := runTestTask(t, config.DefaultConfig().Worker, task)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Error("expected timeout error, got", err)
+	}
+	if time.Since(start) > time.Second*5 {
+		t.Error("expected the executor to be stopped")
+	}
+	if rec.state() != tes.State_EXECUTOR_ERROR {
+		t.Error("expected EXECUTOR_ERROR state, got", rec.state())
+	}
+	if codes := rec.exitCodes(); codes[0] != timeoutExitCode {
+		t.Error("unexpected exit codes", codes)
+	}
+	if !rec.hasSystemLog("Task timed out") {
+		t.Error("expected task timeout system log")
+	}
+}
+
+func TestWorkerStdinFromEarlierExecutor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-worker-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	conf := config.DefaultConfig().Worker
+	conf.WorkDir = dir
+
+	// The fake docker command runs executors on the host,
+	// so the first executor writes "bye.txt" to its host path.
+	outputs := filepath.Join(dir, "task-1", "outputs")
+	task := &tes.Task{
+		Executors: []*tes.Executor{
+			{
+				Command: []string{"sh", "-c", "echo hello; echo bye > " + filepath.Join(outputs, "bye.txt")},
+				Stdout:  "/outputs/hello.txt",
+			},
+			// Read the files written by the first executor.
+			{Command: []string{"cat"}, Stdin: "/outputs/hello.txt", Stdout: "/outputs/hello-copy.txt"},
+			{Command: []string{"cat"}, Stdin: "/outputs/bye.txt", Stdout: "/outputs/bye-copy.txt"},
+		},
+	}
+
+	rec, err := runTestTask(t, conf, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.state() != tes.State_COMPLETE {
+		t.Error("expected COMPLETE state, got", rec.state())
+	}
+	for name, expected := range map[string]string{"hello-copy.txt": "hello\n", "bye-copy.txt": "bye\n"} {
+		b, err := ioutil.ReadFile(filepath.Join(outputs, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected {
+			t.Errorf("unexpected %s content %q", name, b)
+		}
+	}
+}
+
+func TestWorkerIgnoreError(t *testing.T) {
+	task := &tes.Task{
+		Tags: map[string]string{"executor-ignore-error.0": "true"},
+		Executors: []*tes.Executor{
+			{Command: []string{"sh", "-c", "exit 3"}},
+			{Command: []string{"true"}},
+		},
+	}
+	rec, err := runTestTask(t, config.DefaultConfig().Worker, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.state() != tes.State_COMPLETE {
+		t.Error("expected COMPLETE state, got", rec.state())
+	}
+	if codes := rec.exitCodes(); codes[0] != 3 || codes[1] != 0 {
+		t.Error("unexpected exit codes", codes)
+	}
+
+	// Docker errors aren't ignored.
+	task.Executors[0].Image = "missing"
+	rec, err = runTestTask(t, config.DefaultConfig().Worker, task)
+	if err == nil {
+		t.Error("expected error")
+	}
+	if rec.state() != tes.State_EXECUTOR_ERROR {
+		t.Error("expected EXECUTOR_ERROR state, got", rec.state())
+	}
+	if _, ok := rec.exitCodes()[1]; ok {
+		t.Error("expected the second executor not to run")
+	}
+}
+
+func TestWorkerOutputsOnTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "funnel-test-worker-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	conf := config.DefaultConfig().Worker
+	conf.WorkDir = dir
+
+	// The fake docker command runs executors on the host,
+	// so the executor writes the output's host path.
+	out := filepath.Join(dir, "out", "log.txt")
+	task := &tes.Task{
+		Tags: map[string]string{
+			"timeout":          "500ms",
+			"outputs-on-error": "all",
+		},
+		Outputs: []*tes.Output{
+			{Url: out, Path: "/outputs/log.txt"},
+		},
+		Executors: []*tes.Executor{
+			{Command: []string{"sh", "-c", "echo partial > " + filepath.Join(dir, "task-1", "outputs", "log.txt") + "; sleep 10"}},
+		},
+	}
+
+	rec, err := runTestTask(t, conf, task)
+	if err == nil {
+		t.Error("expected error")
+	}
+	if rec.state() != tes.State_EXECUTOR_ERROR {
+		t.Error("expected EXECUTOR_ERROR state, got", rec.state())
+	}
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal("expected the output to be uploaded after the timeout:", err)
+	}
+	if string(b) != "partial\n" {
+		t.Errorf("unexpected output %q", b)
+	}
+}